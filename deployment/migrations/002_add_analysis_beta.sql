@@ -0,0 +1,6 @@
+-- Beta, correlation and alpha of analysis results against a reference index
+ALTER TABLE analysis_packages ADD COLUMN IF NOT EXISTS reference_index text;
+
+ALTER TABLE analysis_results ADD COLUMN IF NOT EXISTS beta double precision;
+ALTER TABLE analysis_results ADD COLUMN IF NOT EXISTS correlation double precision;
+ALTER TABLE analysis_results ADD COLUMN IF NOT EXISTS alpha double precision;
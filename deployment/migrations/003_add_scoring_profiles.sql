@@ -0,0 +1,15 @@
+-- Saved weighted scoring profiles per user
+CREATE TABLE IF NOT EXISTS scoring_profiles (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name text NOT NULL,
+    weight_mean double precision DEFAULT 1 NOT NULL,
+    weight_stddev double precision DEFAULT 1 NOT NULL,
+    weight_min double precision DEFAULT 0 NOT NULL,
+    weight_max double precision DEFAULT 0 NOT NULL,
+    weight_beta double precision DEFAULT 0 NOT NULL,
+    normalization text DEFAULT 'zscore' NOT NULL CHECK (normalization IN ('zscore', 'rank')),
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    UNIQUE (user_id, name)
+);
@@ -0,0 +1,21 @@
+-- Research journal: freeform notes linked to any number of tickers
+CREATE TABLE IF NOT EXISTS user_journal (
+    id serial PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title text NOT NULL,
+    content text DEFAULT '' NOT NULL,
+    type text DEFAULT 'note' NOT NULL CHECK (type IN ('note', 'idea', 'news', 'strategy')),
+    tags jsonb DEFAULT '[]' NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_journal_user_created ON user_journal (user_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS journal_tickers (
+    journal_id integer NOT NULL REFERENCES user_journal(id) ON DELETE CASCADE,
+    ticker character varying(20) NOT NULL,
+    PRIMARY KEY (journal_id, ticker)
+);
+
+CREATE INDEX IF NOT EXISTS idx_journal_tickers_ticker ON journal_tickers (ticker);
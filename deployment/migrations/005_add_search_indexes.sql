@@ -0,0 +1,15 @@
+-- Full-text search indexes for /api/search
+-- The expressions must match the ones used in pkg/db/search.go for the indexes to be used.
+CREATE INDEX IF NOT EXISTS idx_symbols_search ON symbols USING gin ((
+    setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(industry, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'C')
+));
+
+CREATE INDEX IF NOT EXISTS idx_user_ratings_notes_search ON user_ratings
+    USING gin (to_tsvector('english', coalesce(notes, '')));
+
+CREATE INDEX IF NOT EXISTS idx_user_journal_search ON user_journal USING gin ((
+    setweight(to_tsvector('english', title), 'A') ||
+    setweight(to_tsvector('english', content), 'B')
+));
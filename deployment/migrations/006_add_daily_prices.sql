@@ -0,0 +1,18 @@
+-- Daily OHLCV bars, written by the price and quote updaters
+-- USD values in open..adj_close, original currency values in *_orig (NULL for USD symbols)
+CREATE TABLE IF NOT EXISTS daily_prices (
+    date timestamp with time zone NOT NULL,
+    symbol_ticker text NOT NULL REFERENCES symbols(ticker),
+    open double precision NOT NULL,
+    high double precision NOT NULL,
+    low double precision NOT NULL,
+    close double precision NOT NULL,
+    adj_close double precision,
+    volume bigint,
+    open_orig double precision,
+    high_orig double precision,
+    low_orig double precision,
+    close_orig double precision,
+    adj_close_orig double precision,
+    PRIMARY KEY (symbol_ticker, date)
+);
@@ -0,0 +1,25 @@
+-- Dividend and split history per ticker, written by the price updater
+CREATE TABLE IF NOT EXISTS dividends (
+    symbol_ticker text NOT NULL REFERENCES symbols(ticker),
+    date timestamp with time zone NOT NULL,
+    dividend double precision NOT NULL,
+    adj_dividend double precision NOT NULL,
+    payment_date timestamp with time zone,
+    PRIMARY KEY (symbol_ticker, date)
+);
+
+CREATE TABLE IF NOT EXISTS splits (
+    symbol_ticker text NOT NULL REFERENCES symbols(ticker),
+    date timestamp with time zone NOT NULL,
+    numerator double precision NOT NULL,
+    denominator double precision NOT NULL,
+    PRIMARY KEY (symbol_ticker, date)
+);
+
+-- Total-return YoY (dividends reinvested), filled on the next price update
+ALTER TABLE monthly_prices ADD COLUMN IF NOT EXISTS yoy_tr double precision;
+ALTER TABLE weekly_prices ADD COLUMN IF NOT EXISTS yoy_tr double precision;
+
+-- Which YoY series an analysis package uses
+ALTER TABLE analysis_packages ADD COLUMN IF NOT EXISTS return_basis text DEFAULT 'price' NOT NULL
+    CHECK (return_basis IN ('price', 'total_return'));
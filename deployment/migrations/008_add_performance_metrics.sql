@@ -0,0 +1,5 @@
+-- Risk/return metrics per analysis result (CAGR, drawdown, Sharpe, Sortino, YoY percentiles)
+ALTER TABLE analysis_results ADD COLUMN IF NOT EXISTS metrics jsonb;
+
+-- Annual risk-free rate in percent used for Sharpe/Sortino
+ALTER TABLE analysis_packages ADD COLUMN IF NOT EXISTS risk_free_rate double precision DEFAULT 0 NOT NULL;
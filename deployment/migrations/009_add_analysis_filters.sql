@@ -0,0 +1,8 @@
+-- Symbol selection of analysis packages: market cap ceiling and include/exclude filters
+ALTER TABLE analysis_packages ADD COLUMN IF NOT EXISTS mcap_max bigint;
+ALTER TABLE analysis_packages ADD COLUMN IF NOT EXISTS filters jsonb DEFAULT '{}'::jsonb NOT NULL;
+
+-- Existing packages were computed with the OTC exchanges excluded
+UPDATE analysis_packages
+SET filters = '{"universe": "all", "exchanges": {"exclude": ["OTC", "PINK", "GREY", "OTCQB", "OTCQX"]}}'::jsonb
+WHERE filters = '{}'::jsonb;
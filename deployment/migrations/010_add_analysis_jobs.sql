@@ -0,0 +1,24 @@
+-- Persisted job queue for analysis packages
+CREATE TABLE IF NOT EXISTS analysis_jobs (
+    package_id uuid PRIMARY KEY REFERENCES analysis_packages(id) ON DELETE CASCADE,
+    user_id uuid NOT NULL,
+    state text DEFAULT 'queued' NOT NULL
+        CONSTRAINT analysis_jobs_state_check CHECK (state IN ('queued', 'running', 'cancelled', 'failed', 'ready')),
+    attempts integer DEFAULT 0 NOT NULL,
+    processed integer DEFAULT 0 NOT NULL,
+    total integer DEFAULT 0 NOT NULL,
+    results integer DEFAULT 0 NOT NULL,
+    rejections jsonb DEFAULT '{}'::jsonb NOT NULL,
+    error text,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    started_at timestamp with time zone,
+    finished_at timestamp with time zone,
+    heartbeat_at timestamp with time zone
+);
+
+CREATE INDEX IF NOT EXISTS idx_analysis_jobs_state ON analysis_jobs (state, created_at);
+
+-- Packages that were processing without a job can never finish
+UPDATE analysis_packages SET status = 'failed'
+WHERE status = 'processing'
+  AND id NOT IN (SELECT package_id FROM analysis_jobs);
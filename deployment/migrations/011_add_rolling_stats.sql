@@ -0,0 +1,3 @@
+-- Rolling-window YoY statistics: window of a package and the summary per result
+ALTER TABLE analysis_packages ADD COLUMN IF NOT EXISTS rolling_window_months integer DEFAULT 0 NOT NULL;
+ALTER TABLE analysis_results ADD COLUMN IF NOT EXISTS rolling jsonb;
@@ -0,0 +1,3 @@
+-- Selectable outlier strategy per analysis package and the number of values it removed per result
+ALTER TABLE analysis_packages ADD COLUMN IF NOT EXISTS outliers jsonb DEFAULT '{}'::jsonb NOT NULL;
+ALTER TABLE analysis_results ADD COLUMN IF NOT EXISTS outliers_removed integer DEFAULT 0 NOT NULL;
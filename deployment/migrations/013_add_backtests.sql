@@ -0,0 +1,11 @@
+-- Stored portfolio backtests: config and simulation result per user
+CREATE TABLE IF NOT EXISTS backtests (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name text NOT NULL,
+    config jsonb NOT NULL,
+    result jsonb NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_backtests_user ON backtests (user_id, created_at);
@@ -0,0 +1,27 @@
+-- Portfolios of a user with their buy, sell and dividend transactions
+CREATE TABLE IF NOT EXISTS portfolios (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name text NOT NULL,
+    cost_basis text DEFAULT 'fifo' NOT NULL CHECK (cost_basis IN ('fifo', 'average')),
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS portfolio_transactions (
+    id uuid PRIMARY KEY,
+    portfolio_id uuid NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    ticker text NOT NULL,
+    type text NOT NULL CHECK (type IN ('buy', 'sell', 'dividend')),
+    date date NOT NULL,
+    quantity double precision DEFAULT 0 NOT NULL,
+    price double precision DEFAULT 0 NOT NULL,
+    amount double precision DEFAULT 0 NOT NULL,
+    fee double precision DEFAULT 0 NOT NULL,
+    currency text DEFAULT 'USD' NOT NULL,
+    fx_rate double precision NOT NULL,
+    note text,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_portfolio_transactions_portfolio ON portfolio_transactions (portfolio_id, date);
@@ -0,0 +1,46 @@
+-- Named, ordered watchlists per user, replacing user_favorites.
+-- Favorites become the entries of a default "Favorites" watchlist per user.
+CREATE TABLE IF NOT EXISTS watchlists (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name text NOT NULL,
+    is_default boolean DEFAULT false NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_watchlists_default ON watchlists (user_id) WHERE is_default;
+
+CREATE TABLE IF NOT EXISTS watchlist_entries (
+    watchlist_id uuid NOT NULL REFERENCES watchlists(id) ON DELETE CASCADE,
+    ticker text NOT NULL,
+    sort_order integer DEFAULT 0 NOT NULL,
+    note text,
+    target_price double precision,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    PRIMARY KEY (watchlist_id, ticker)
+);
+
+CREATE INDEX IF NOT EXISTS idx_watchlist_entries_ticker ON watchlist_entries (ticker);
+
+-- Move favorites into the default watchlists, oldest favorite first. Favorites of unknown users are dropped.
+DO $$
+BEGIN
+    IF to_regclass('public.user_favorites') IS NOT NULL THEN
+        INSERT INTO watchlists (id, user_id, name, is_default)
+        SELECT gen_random_uuid(), u.id, 'Favorites', true
+        FROM users u
+        WHERE EXISTS (SELECT 1 FROM user_favorites f WHERE f.user_id = u.id)
+        ON CONFLICT DO NOTHING;
+
+        INSERT INTO watchlist_entries (watchlist_id, ticker, sort_order, created_at)
+        SELECT w.id, f.ticker,
+               ROW_NUMBER() OVER (PARTITION BY f.user_id ORDER BY f.created_at, f.ticker) - 1,
+               COALESCE(f.created_at, now())
+        FROM user_favorites f
+        JOIN watchlists w ON w.user_id = f.user_id AND w.is_default
+        ON CONFLICT DO NOTHING;
+
+        DROP TABLE user_favorites;
+    END IF;
+END $$;
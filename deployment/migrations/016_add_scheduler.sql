@@ -0,0 +1,22 @@
+-- State of the scheduled updaters (schedule overrides, pause, skip and manual triggers) and their run history
+CREATE TABLE IF NOT EXISTS scheduler_jobs (
+    name text PRIMARY KEY,
+    schedule text,
+    paused boolean DEFAULT false NOT NULL,
+    skip_next boolean DEFAULT false NOT NULL,
+    triggered_at timestamp with time zone,
+    last_scheduled_at timestamp with time zone,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS scheduler_runs (
+    id uuid PRIMARY KEY,
+    job text NOT NULL,
+    trigger text NOT NULL,
+    status text NOT NULL,
+    error text,
+    started_at timestamp with time zone NOT NULL,
+    finished_at timestamp with time zone
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduler_runs_job ON scheduler_runs (job, started_at DESC);
@@ -0,0 +1,3 @@
+-- Prior-year period each YoY value compares with, filled on the next price update or `gofins reset yoy`
+ALTER TABLE monthly_prices ADD COLUMN IF NOT EXISTS yoy_ref timestamp with time zone;
+ALTER TABLE weekly_prices ADD COLUMN IF NOT EXISTS yoy_ref timestamp with time zone;
@@ -0,0 +1,22 @@
+-- Findings of the price data auditor (gofins audit prices), linked to the error logged by the audit run
+CREATE TABLE IF NOT EXISTS data_issues (
+    id serial PRIMARY KEY,
+    ticker text NOT NULL REFERENCES symbols(ticker) ON DELETE CASCADE,
+    "interval" text,
+    kind text NOT NULL,
+    period timestamp with time zone,
+    severity text NOT NULL,
+    message text NOT NULL,
+    details text,
+    status text DEFAULT 'open' NOT NULL,
+    error_id integer REFERENCES errors(id) ON DELETE SET NULL,
+    first_seen timestamp with time zone NOT NULL,
+    last_seen timestamp with time zone NOT NULL,
+    resolved_at timestamp with time zone
+);
+
+-- One row per finding: symbol-level findings have no interval, findings of the whole series no period
+CREATE UNIQUE INDEX IF NOT EXISTS idx_data_issues_key
+    ON data_issues (ticker, kind, COALESCE("interval", ''), COALESCE(period, '-infinity'::timestamp with time zone));
+
+CREATE INDEX IF NOT EXISTS idx_data_issues_status ON data_issues (status, last_seen DESC);
@@ -0,0 +1,9 @@
+-- Weight used per day by the API rate limiters, for the daily and monthly quotas of the data provider
+CREATE TABLE IF NOT EXISTS api_usage (
+    limiter text NOT NULL,
+    day date NOT NULL,
+    weight bigint DEFAULT 0 NOT NULL,
+    requests bigint DEFAULT 0 NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    PRIMARY KEY (limiter, day)
+);
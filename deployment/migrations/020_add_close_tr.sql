@@ -0,0 +1,4 @@
+-- Total-return index at each period's close, the basis of the return metrics of total-return
+-- analyses; filled on the next price update
+ALTER TABLE monthly_prices ADD COLUMN IF NOT EXISTS close_tr double precision;
+ALTER TABLE weekly_prices ADD COLUMN IF NOT EXISTS close_tr double precision;
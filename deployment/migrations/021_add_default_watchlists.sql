@@ -0,0 +1,7 @@
+-- Every user gets a favorites watchlist, new users get it in CreateUser, so listing the watchlists
+-- no longer has to create it
+INSERT INTO watchlists (id, user_id, name, is_default)
+SELECT gen_random_uuid(), u.id, 'Favorites', true
+FROM users u
+WHERE NOT EXISTS (SELECT 1 FROM watchlists w WHERE w.user_id = u.id AND w.is_default)
+ON CONFLICT DO NOTHING;
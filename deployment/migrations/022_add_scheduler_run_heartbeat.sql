@@ -0,0 +1,7 @@
+-- Scheduler runs record the process running them and its heartbeat, so a scheduler only fails
+-- runs it lost itself or whose process stopped sending heartbeats
+ALTER TABLE scheduler_runs ADD COLUMN IF NOT EXISTS owner uuid;
+ALTER TABLE scheduler_runs ADD COLUMN IF NOT EXISTS heartbeat_at timestamp with time zone;
+
+-- Runs from before have no heartbeat, they count as stale
+UPDATE scheduler_runs SET heartbeat_at = started_at WHERE heartbeat_at IS NULL;
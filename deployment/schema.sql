@@ -20,6 +20,28 @@ SET default_tablespace = '';
 
 SET default_table_access_method = heap;
 
+--
+-- Name: analysis_jobs; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.analysis_jobs (
+    package_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    state text DEFAULT 'queued'::text NOT NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    processed integer DEFAULT 0 NOT NULL,
+    total integer DEFAULT 0 NOT NULL,
+    results integer DEFAULT 0 NOT NULL,
+    rejections jsonb DEFAULT '{}'::jsonb NOT NULL,
+    error text,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    started_at timestamp with time zone,
+    finished_at timestamp with time zone,
+    heartbeat_at timestamp with time zone,
+    CONSTRAINT analysis_jobs_state_check CHECK ((state = ANY (ARRAY['queued'::text, 'running'::text, 'cancelled'::text, 'failed'::text, 'ready'::text])))
+);
+
+
 --
 -- Name: analysis_packages; Type: TABLE; Schema: public; Owner: -
 --
@@ -38,7 +60,15 @@ CREATE TABLE public.analysis_packages (
     inception_max timestamp with time zone,
     symbol_count integer,
     status text NOT NULL,
-    user_id uuid DEFAULT '00000000-0000-0000-0000-000000000000'::uuid NOT NULL
+    user_id uuid DEFAULT '00000000-0000-0000-0000-000000000000'::uuid NOT NULL,
+    reference_index text,
+    return_basis text DEFAULT 'price'::text NOT NULL,
+    risk_free_rate double precision DEFAULT 0 NOT NULL,
+    mcap_max bigint,
+    filters jsonb DEFAULT '{}'::jsonb NOT NULL,
+    rolling_window_months integer DEFAULT 0 NOT NULL,
+    outliers jsonb DEFAULT '{}'::jsonb NOT NULL,
+    CONSTRAINT analysis_packages_return_basis_check CHECK ((return_basis = ANY (ARRAY['price'::text, 'total_return'::text])))
 );
 
 
@@ -56,7 +86,40 @@ CREATE TABLE public.analysis_results (
     min double precision NOT NULL,
     max double precision NOT NULL,
     histogram jsonb NOT NULL,
-    chart_path text
+    chart_path text,
+    beta double precision,
+    correlation double precision,
+    alpha double precision,
+    metrics jsonb,
+    rolling jsonb,
+    outliers_removed integer DEFAULT 0 NOT NULL
+);
+
+
+--
+-- Name: api_usage; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.api_usage (
+    limiter text NOT NULL,
+    day date NOT NULL,
+    weight bigint DEFAULT 0 NOT NULL,
+    requests bigint DEFAULT 0 NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: backtests; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.backtests (
+    id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    name text NOT NULL,
+    config jsonb NOT NULL,
+    result jsonb NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
 );
 
 
@@ -96,6 +159,81 @@ CREATE SEQUENCE public.batch_update_log_id_seq
 ALTER SEQUENCE public.batch_update_log_id_seq OWNED BY public.batch_update_log.id;
 
 
+--
+-- Name: daily_prices; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.daily_prices (
+    date timestamp with time zone NOT NULL,
+    symbol_ticker text NOT NULL,
+    open double precision NOT NULL,
+    high double precision NOT NULL,
+    low double precision NOT NULL,
+    close double precision NOT NULL,
+    adj_close double precision,
+    volume bigint,
+    open_orig double precision,
+    high_orig double precision,
+    low_orig double precision,
+    close_orig double precision,
+    adj_close_orig double precision
+);
+
+
+--
+-- Name: data_issues; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.data_issues (
+    id integer NOT NULL,
+    ticker text NOT NULL,
+    "interval" text,
+    kind text NOT NULL,
+    period timestamp with time zone,
+    severity text NOT NULL,
+    message text NOT NULL,
+    details text,
+    status text DEFAULT 'open'::text NOT NULL,
+    error_id integer,
+    first_seen timestamp with time zone NOT NULL,
+    last_seen timestamp with time zone NOT NULL,
+    resolved_at timestamp with time zone
+);
+
+
+--
+-- Name: data_issues_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.data_issues_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: data_issues_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE public.data_issues_id_seq OWNED BY public.data_issues.id;
+
+
+--
+-- Name: dividends; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.dividends (
+    symbol_ticker text NOT NULL,
+    date timestamp with time zone NOT NULL,
+    dividend double precision NOT NULL,
+    adj_dividend double precision NOT NULL,
+    payment_date timestamp with time zone
+);
+
+
 --
 -- Name: errors; Type: TABLE; Schema: public; Owner: -
 --
@@ -130,6 +268,16 @@ CREATE SEQUENCE public.errors_id_seq
 ALTER SEQUENCE public.errors_id_seq OWNED BY public.errors.id;
 
 
+--
+-- Name: journal_tickers; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.journal_tickers (
+    journal_id integer NOT NULL,
+    ticker character varying(20) NOT NULL
+);
+
+
 --
 -- Name: monthly_prices; Type: TABLE; Schema: public; Owner: -
 --
@@ -147,7 +295,10 @@ CREATE TABLE public.monthly_prices (
     high_orig double precision,
     low_orig double precision,
     avg_orig double precision,
-    close_orig double precision
+    close_orig double precision,
+    yoy_tr double precision,
+    yoy_ref timestamp with time zone,
+    close_tr double precision
 );
 
 
@@ -165,6 +316,106 @@ CREATE TABLE public.notes (
 );
 
 
+--
+-- Name: portfolio_transactions; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.portfolio_transactions (
+    id uuid NOT NULL,
+    portfolio_id uuid NOT NULL,
+    ticker text NOT NULL,
+    type text NOT NULL,
+    date date NOT NULL,
+    quantity double precision DEFAULT 0 NOT NULL,
+    price double precision DEFAULT 0 NOT NULL,
+    amount double precision DEFAULT 0 NOT NULL,
+    fee double precision DEFAULT 0 NOT NULL,
+    currency text DEFAULT 'USD'::text NOT NULL,
+    fx_rate double precision NOT NULL,
+    note text,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT portfolio_transactions_type_check CHECK ((type = ANY (ARRAY['buy'::text, 'sell'::text, 'dividend'::text])))
+);
+
+
+--
+-- Name: portfolios; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.portfolios (
+    id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    name text NOT NULL,
+    cost_basis text DEFAULT 'fifo'::text NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT portfolios_cost_basis_check CHECK ((cost_basis = ANY (ARRAY['fifo'::text, 'average'::text])))
+);
+
+
+--
+-- Name: scheduler_jobs; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.scheduler_jobs (
+    name text NOT NULL,
+    schedule text,
+    paused boolean DEFAULT false NOT NULL,
+    skip_next boolean DEFAULT false NOT NULL,
+    triggered_at timestamp with time zone,
+    last_scheduled_at timestamp with time zone,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: scheduler_runs; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.scheduler_runs (
+    id uuid NOT NULL,
+    job text NOT NULL,
+    trigger text NOT NULL,
+    status text NOT NULL,
+    error text,
+    started_at timestamp with time zone NOT NULL,
+    finished_at timestamp with time zone,
+    owner uuid,
+    heartbeat_at timestamp with time zone
+);
+
+
+--
+-- Name: scoring_profiles; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.scoring_profiles (
+    id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    name text NOT NULL,
+    weight_mean double precision DEFAULT 1 NOT NULL,
+    weight_stddev double precision DEFAULT 1 NOT NULL,
+    weight_min double precision DEFAULT 0 NOT NULL,
+    weight_max double precision DEFAULT 0 NOT NULL,
+    weight_beta double precision DEFAULT 0 NOT NULL,
+    normalization text DEFAULT 'zscore'::text NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT scoring_profiles_normalization_check CHECK ((normalization = ANY (ARRAY['zscore'::text, 'rank'::text])))
+);
+
+
+--
+-- Name: splits; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.splits (
+    symbol_ticker text NOT NULL,
+    date timestamp with time zone NOT NULL,
+    numerator double precision NOT NULL,
+    denominator double precision NOT NULL
+);
+
+
 --
 -- Name: symbols; Type: TABLE; Schema: public; Owner: -
 --
@@ -200,16 +451,42 @@ CREATE TABLE public.symbols (
 
 
 --
--- Name: user_favorites; Type: TABLE; Schema: public; Owner: -
+-- Name: user_journal; Type: TABLE; Schema: public; Owner: -
 --
 
-CREATE TABLE public.user_favorites (
-    ticker character varying(20) NOT NULL,
-    created_at timestamp without time zone DEFAULT now(),
-    user_id uuid DEFAULT '00000000-0000-0000-0000-000000000000'::uuid NOT NULL
+CREATE TABLE public.user_journal (
+    id integer NOT NULL,
+    user_id uuid NOT NULL,
+    title text NOT NULL,
+    content text DEFAULT ''::text NOT NULL,
+    type text DEFAULT 'note'::text NOT NULL,
+    tags jsonb DEFAULT '[]'::jsonb NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT user_journal_type_check CHECK ((type = ANY (ARRAY['note'::text, 'idea'::text, 'news'::text, 'strategy'::text])))
 );
 
 
+--
+-- Name: user_journal_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.user_journal_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: user_journal_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE public.user_journal_id_seq OWNED BY public.user_journal.id;
+
+
 --
 -- Name: user_ratings; Type: TABLE; Schema: public; Owner: -
 --
@@ -257,6 +534,33 @@ CREATE TABLE public.users (
 );
 
 
+--
+-- Name: watchlist_entries; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.watchlist_entries (
+    watchlist_id uuid NOT NULL,
+    ticker text NOT NULL,
+    sort_order integer DEFAULT 0 NOT NULL,
+    note text,
+    target_price double precision,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: watchlists; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.watchlists (
+    id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    name text NOT NULL,
+    is_default boolean DEFAULT false NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
 --
 -- Name: weekly_prices; Type: TABLE; Schema: public; Owner: -
 --
@@ -274,7 +578,10 @@ CREATE TABLE public.weekly_prices (
     high_orig double precision,
     low_orig double precision,
     avg_orig double precision,
-    close_orig double precision
+    close_orig double precision,
+    yoy_tr double precision,
+    yoy_ref timestamp with time zone,
+    close_tr double precision
 );
 
 
@@ -285,6 +592,13 @@ CREATE TABLE public.weekly_prices (
 ALTER TABLE ONLY public.batch_update_log ALTER COLUMN id SET DEFAULT nextval('public.batch_update_log_id_seq'::regclass);
 
 
+--
+-- Name: data_issues id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.data_issues ALTER COLUMN id SET DEFAULT nextval('public.data_issues_id_seq'::regclass);
+
+
 --
 -- Name: errors id; Type: DEFAULT; Schema: public; Owner: -
 --
@@ -292,6 +606,13 @@ ALTER TABLE ONLY public.batch_update_log ALTER COLUMN id SET DEFAULT nextval('pu
 ALTER TABLE ONLY public.errors ALTER COLUMN id SET DEFAULT nextval('public.errors_id_seq'::regclass);
 
 
+--
+-- Name: user_journal id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_journal ALTER COLUMN id SET DEFAULT nextval('public.user_journal_id_seq'::regclass);
+
+
 --
 -- Name: user_ratings id; Type: DEFAULT; Schema: public; Owner: -
 --
@@ -299,6 +620,14 @@ ALTER TABLE ONLY public.errors ALTER COLUMN id SET DEFAULT nextval('public.error
 ALTER TABLE ONLY public.user_ratings ALTER COLUMN id SET DEFAULT nextval('public.user_ratings_id_seq'::regclass);
 
 
+--
+-- Name: analysis_jobs analysis_jobs_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.analysis_jobs
+    ADD CONSTRAINT analysis_jobs_pkey PRIMARY KEY (package_id);
+
+
 --
 -- Name: analysis_packages analysis_packages_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -315,6 +644,22 @@ ALTER TABLE ONLY public.analysis_results
     ADD CONSTRAINT analysis_results_pkey PRIMARY KEY (package_id, ticker);
 
 
+--
+-- Name: api_usage api_usage_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.api_usage
+    ADD CONSTRAINT api_usage_pkey PRIMARY KEY (limiter, day);
+
+
+--
+-- Name: backtests backtests_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.backtests
+    ADD CONSTRAINT backtests_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: batch_update_log batch_update_log_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -323,6 +668,30 @@ ALTER TABLE ONLY public.batch_update_log
     ADD CONSTRAINT batch_update_log_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: daily_prices daily_prices_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.daily_prices
+    ADD CONSTRAINT daily_prices_pkey PRIMARY KEY (symbol_ticker, date);
+
+
+--
+-- Name: data_issues data_issues_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.data_issues
+    ADD CONSTRAINT data_issues_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: dividends dividends_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.dividends
+    ADD CONSTRAINT dividends_pkey PRIMARY KEY (symbol_ticker, date);
+
+
 --
 -- Name: errors errors_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -331,6 +700,78 @@ ALTER TABLE ONLY public.errors
     ADD CONSTRAINT errors_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: journal_tickers journal_tickers_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.journal_tickers
+    ADD CONSTRAINT journal_tickers_pkey PRIMARY KEY (journal_id, ticker);
+
+
+--
+-- Name: portfolio_transactions portfolio_transactions_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.portfolio_transactions
+    ADD CONSTRAINT portfolio_transactions_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: portfolios portfolios_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.portfolios
+    ADD CONSTRAINT portfolios_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: portfolios portfolios_user_id_name_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.portfolios
+    ADD CONSTRAINT portfolios_user_id_name_key UNIQUE (user_id, name);
+
+
+--
+-- Name: scheduler_jobs scheduler_jobs_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.scheduler_jobs
+    ADD CONSTRAINT scheduler_jobs_pkey PRIMARY KEY (name);
+
+
+--
+-- Name: scheduler_runs scheduler_runs_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.scheduler_runs
+    ADD CONSTRAINT scheduler_runs_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: scoring_profiles scoring_profiles_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.scoring_profiles
+    ADD CONSTRAINT scoring_profiles_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: scoring_profiles scoring_profiles_user_id_name_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.scoring_profiles
+    ADD CONSTRAINT scoring_profiles_user_id_name_key UNIQUE (user_id, name);
+
+
+--
+-- Name: splits splits_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.splits
+    ADD CONSTRAINT splits_pkey PRIMARY KEY (symbol_ticker, date);
+
+
 --
 -- Name: symbols idx_16389_sqlite_autoindex_symbols_1; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -340,43 +781,59 @@ ALTER TABLE ONLY public.symbols
 
 
 --
--- Name: weekly_prices idx_16394_sqlite_autoindex_weekly_prices_1; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: user_journal user_journal_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.weekly_prices
-    ADD CONSTRAINT idx_16394_sqlite_autoindex_weekly_prices_1 PRIMARY KEY (date, symbol_ticker);
+ALTER TABLE ONLY public.user_journal
+    ADD CONSTRAINT user_journal_pkey PRIMARY KEY (id);
 
 
 --
--- Name: monthly_prices idx_16403_sqlite_autoindex_monthly_prices_1; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: watchlist_entries watchlist_entries_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.monthly_prices
-    ADD CONSTRAINT idx_16403_sqlite_autoindex_monthly_prices_1 PRIMARY KEY (date, symbol_ticker);
+ALTER TABLE ONLY public.watchlist_entries
+    ADD CONSTRAINT watchlist_entries_pkey PRIMARY KEY (watchlist_id, ticker);
 
 
 --
--- Name: notes idx_16521_sqlite_autoindex_notes_1; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: watchlists watchlists_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.notes
-    ADD CONSTRAINT idx_16521_sqlite_autoindex_notes_1 PRIMARY KEY (id);
+ALTER TABLE ONLY public.watchlists
+    ADD CONSTRAINT watchlists_pkey PRIMARY KEY (id);
 
 
 --
--- Name: user_favorites user_favorites_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: watchlists watchlists_user_id_name_key; Type: CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.user_favorites
-    ADD CONSTRAINT user_favorites_pkey PRIMARY KEY (ticker);
+ALTER TABLE ONLY public.watchlists
+    ADD CONSTRAINT watchlists_user_id_name_key UNIQUE (user_id, name);
 
 
 --
--- Name: user_favorites user_favorites_user_ticker_unique; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: weekly_prices idx_16394_sqlite_autoindex_weekly_prices_1; Type: CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.user_favorites
-    ADD CONSTRAINT user_favorites_user_ticker_unique UNIQUE (user_id, ticker);
+ALTER TABLE ONLY public.weekly_prices
+    ADD CONSTRAINT idx_16394_sqlite_autoindex_weekly_prices_1 PRIMARY KEY (date, symbol_ticker);
+
+
+--
+-- Name: monthly_prices idx_16403_sqlite_autoindex_monthly_prices_1; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.monthly_prices
+    ADD CONSTRAINT idx_16403_sqlite_autoindex_monthly_prices_1 PRIMARY KEY (date, symbol_ticker);
+
+
+--
+-- Name: notes idx_16521_sqlite_autoindex_notes_1; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notes
+    ADD CONSTRAINT idx_16521_sqlite_autoindex_notes_1 PRIMARY KEY (id);
 
 
 --
@@ -417,6 +874,13 @@ CREATE INDEX idx_16394_idx_weekly_symbol_date ON public.weekly_prices USING btre
 CREATE INDEX idx_16403_idx_monthly_symbol_date ON public.monthly_prices USING btree (symbol_ticker, date);
 
 
+--
+-- Name: idx_analysis_jobs_state; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_analysis_jobs_state ON public.analysis_jobs USING btree (state, created_at);
+
+
 --
 -- Name: idx_analysis_mean; Type: INDEX; Schema: public; Owner: -
 --
@@ -445,6 +909,27 @@ CREATE INDEX idx_analysis_stddev ON public.analysis_results USING btree (package
 CREATE INDEX idx_analysis_variance ON public.analysis_results USING btree (package_id, variance);
 
 
+--
+-- Name: idx_backtests_user; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_backtests_user ON public.backtests USING btree (user_id, created_at);
+
+
+--
+-- Name: idx_data_issues_key; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX idx_data_issues_key ON public.data_issues USING btree (ticker, kind, COALESCE("interval", ''::text), COALESCE(period, '-infinity'::timestamp with time zone));
+
+
+--
+-- Name: idx_data_issues_status; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_data_issues_status ON public.data_issues USING btree (status, last_seen DESC);
+
+
 --
 -- Name: idx_errors_source; Type: INDEX; Schema: public; Owner: -
 --
@@ -460,10 +945,52 @@ CREATE INDEX idx_errors_timestamp ON public.errors USING btree ("timestamp" DESC
 
 
 --
--- Name: idx_user_favorites_user; Type: INDEX; Schema: public; Owner: -
+-- Name: idx_journal_tickers_ticker; Type: INDEX; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_user_favorites_user ON public.user_favorites USING btree (user_id);
+CREATE INDEX idx_journal_tickers_ticker ON public.journal_tickers USING btree (ticker);
+
+
+--
+-- Name: idx_portfolio_transactions_portfolio; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_portfolio_transactions_portfolio ON public.portfolio_transactions USING btree (portfolio_id, date);
+
+
+--
+-- Name: idx_scheduler_runs_job; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_scheduler_runs_job ON public.scheduler_runs USING btree (job, started_at DESC);
+
+
+--
+-- Name: idx_symbols_search; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_symbols_search ON public.symbols USING gin ((((setweight(to_tsvector('english'::regconfig, COALESCE(name, ''::text)), 'A'::"char") || setweight(to_tsvector('english'::regconfig, COALESCE(industry, ''::text)), 'B'::"char")) || setweight(to_tsvector('english'::regconfig, COALESCE(description, ''::text)), 'C'::"char"))));
+
+
+--
+-- Name: idx_user_journal_search; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_user_journal_search ON public.user_journal USING gin (((setweight(to_tsvector('english'::regconfig, title), 'A'::"char") || setweight(to_tsvector('english'::regconfig, content), 'B'::"char"))));
+
+
+--
+-- Name: idx_user_journal_user_created; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_user_journal_user_created ON public.user_journal USING btree (user_id, created_at DESC);
+
+
+--
+-- Name: idx_user_ratings_notes_search; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_user_ratings_notes_search ON public.user_ratings USING gin (to_tsvector('english'::regconfig, COALESCE(notes, ''::text)));
 
 
 --
@@ -494,6 +1021,28 @@ CREATE INDEX idx_user_ratings_user ON public.user_ratings USING btree (user_id);
 CREATE INDEX idx_user_ratings_user_ticker ON public.user_ratings USING btree (user_id, ticker);
 
 
+--
+-- Name: idx_watchlist_entries_ticker; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_watchlist_entries_ticker ON public.watchlist_entries USING btree (ticker);
+
+
+--
+-- Name: idx_watchlists_default; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX idx_watchlists_default ON public.watchlists USING btree (user_id) WHERE is_default;
+
+
+--
+-- Name: analysis_jobs analysis_jobs_package_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.analysis_jobs
+    ADD CONSTRAINT analysis_jobs_package_id_fkey FOREIGN KEY (package_id) REFERENCES public.analysis_packages(id) ON DELETE CASCADE;
+
+
 --
 -- Name: analysis_results analysis_results_package_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -502,6 +1051,54 @@ ALTER TABLE ONLY public.analysis_results
     ADD CONSTRAINT analysis_results_package_id_fkey FOREIGN KEY (package_id) REFERENCES public.analysis_packages(id) ON DELETE CASCADE;
 
 
+--
+-- Name: backtests backtests_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.backtests
+    ADD CONSTRAINT backtests_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: daily_prices daily_prices_symbol_ticker_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.daily_prices
+    ADD CONSTRAINT daily_prices_symbol_ticker_fkey FOREIGN KEY (symbol_ticker) REFERENCES public.symbols(ticker);
+
+
+--
+-- Name: data_issues data_issues_error_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.data_issues
+    ADD CONSTRAINT data_issues_error_id_fkey FOREIGN KEY (error_id) REFERENCES public.errors(id) ON DELETE SET NULL;
+
+
+--
+-- Name: data_issues data_issues_ticker_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.data_issues
+    ADD CONSTRAINT data_issues_ticker_fkey FOREIGN KEY (ticker) REFERENCES public.symbols(ticker) ON DELETE CASCADE;
+
+
+--
+-- Name: dividends dividends_symbol_ticker_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.dividends
+    ADD CONSTRAINT dividends_symbol_ticker_fkey FOREIGN KEY (symbol_ticker) REFERENCES public.symbols(ticker);
+
+
+--
+-- Name: journal_tickers journal_tickers_journal_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.journal_tickers
+    ADD CONSTRAINT journal_tickers_journal_id_fkey FOREIGN KEY (journal_id) REFERENCES public.user_journal(id) ON DELETE CASCADE;
+
+
 --
 -- Name: monthly_prices monthly_prices_symbol_ticker_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -510,6 +1107,62 @@ ALTER TABLE ONLY public.monthly_prices
     ADD CONSTRAINT monthly_prices_symbol_ticker_fkey FOREIGN KEY (symbol_ticker) REFERENCES public.symbols(ticker);
 
 
+--
+-- Name: portfolio_transactions portfolio_transactions_portfolio_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.portfolio_transactions
+    ADD CONSTRAINT portfolio_transactions_portfolio_id_fkey FOREIGN KEY (portfolio_id) REFERENCES public.portfolios(id) ON DELETE CASCADE;
+
+
+--
+-- Name: portfolios portfolios_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.portfolios
+    ADD CONSTRAINT portfolios_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: scoring_profiles scoring_profiles_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.scoring_profiles
+    ADD CONSTRAINT scoring_profiles_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: splits splits_symbol_ticker_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.splits
+    ADD CONSTRAINT splits_symbol_ticker_fkey FOREIGN KEY (symbol_ticker) REFERENCES public.symbols(ticker);
+
+
+--
+-- Name: user_journal user_journal_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_journal
+    ADD CONSTRAINT user_journal_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: watchlist_entries watchlist_entries_watchlist_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.watchlist_entries
+    ADD CONSTRAINT watchlist_entries_watchlist_id_fkey FOREIGN KEY (watchlist_id) REFERENCES public.watchlists(id) ON DELETE CASCADE;
+
+
+--
+-- Name: watchlists watchlists_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.watchlists
+    ADD CONSTRAINT watchlists_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
 --
 -- Name: weekly_prices weekly_prices_symbol_ticker_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
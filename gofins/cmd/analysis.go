@@ -0,0 +1,8 @@
+package cmd
+
+import "github.com/flocko-motion/gofins/cmd/analysis"
+
+func init() {
+	// Register the analysis command and its subcommands
+	rootCmd.AddCommand(analysis.Cmd)
+}
@@ -0,0 +1,114 @@
+package analysis
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/flocko-motion/gofins/pkg/analysis"
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffSort  string
+	diffLimit int
+	diffJSON  bool
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [base-package-id] [other-package-id]",
+	Short: "Show tickers entering/leaving and rank and statistic changes between two packages",
+	Long: `Compares the results of two analysis packages, e.g. the same screen rerun with a
+different time window or interval. Tickers are ranked per package with the default
+scoring profile (high mean, low stddev).`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if diffSort != "rank" && diffSort != "rank_change" {
+			return fmt.Errorf("invalid sort '%s' (must be 'rank' or 'rank_change')", diffSort)
+		}
+		userID, err := resolveUser(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		baseID, otherID := args[0], args[1]
+		base, err := db.GetAnalysisResults(cmd.Context(), userID, baseID)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("package %s not found", baseID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get results of %s: %w", baseID, err)
+		}
+		other, err := db.GetAnalysisResults(cmd.Context(), userID, otherID)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("package %s not found", otherID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get results of %s: %w", otherID, err)
+		}
+
+		comparison := analysis.ComparePackages(baseID, base, otherID, other, analysis.DefaultScoringProfile)
+		if diffSort == "rank_change" {
+			analysis.SortChangesByRankChange(comparison.Changes)
+		}
+
+		if diffJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(comparison)
+		}
+		printComparison(comparison)
+		return nil
+	},
+}
+
+func printComparison(c types.PackageComparison) {
+	s := c.Summary
+	fmt.Printf("Base:  %s (%d results)\n", c.BasePackageID, s.BaseCount)
+	fmt.Printf("Other: %s (%d results)\n\n", c.OtherPackageID, s.OtherCount)
+	fmt.Printf("Common: %d | Entered: %d | Left: %d | Moved up: %d | Moved down: %d\n",
+		s.Common, s.Entered, s.Left, s.MovedUp, s.MovedDown)
+	fmt.Printf("Average change: mean %+.2f%%, stddev %+.2f%%\n", s.MeanDelta, s.StdDevDelta)
+
+	printRanked("Entered", c.Entered)
+	printRanked("Left", c.Left)
+
+	if len(c.Changes) == 0 {
+		return
+	}
+	fmt.Printf("\nChanges (%d):\n", len(c.Changes))
+	fmt.Printf("%-10s %6s %6s %6s %10s %10s %10s %10s\n", "TICKER", "BASE", "OTHER", "MOVE", "ΔMEAN", "ΔSTDDEV", "ΔMIN", "ΔMAX")
+	for i, change := range c.Changes {
+		if diffLimit > 0 && i >= diffLimit {
+			fmt.Printf("... %d more (use --limit 0 to show all)\n", len(c.Changes)-diffLimit)
+			break
+		}
+		fmt.Printf("%-10s %6d %6d %+6d %+10.2f %+10.2f %+10.2f %+10.2f\n",
+			change.Ticker, change.RankBase, change.RankOther, change.RankChange,
+			change.Mean.Delta, change.StdDev.Delta, change.Min.Delta, change.Max.Delta)
+	}
+}
+
+func printRanked(title string, tickers []types.RankedTicker) {
+	if len(tickers) == 0 {
+		return
+	}
+	fmt.Printf("\n%s (%d):\n", title, len(tickers))
+	for i, ticker := range tickers {
+		if diffLimit > 0 && i >= diffLimit {
+			fmt.Printf("  ... %d more\n", len(tickers)-diffLimit)
+			break
+		}
+		fmt.Printf("  #%-5d %s\n", ticker.Rank, ticker.Ticker)
+	}
+}
+
+func init() {
+	diffCmd.Flags().StringVar(&diffSort, "sort", "rank", "Order of changes: 'rank' (rank in other package) or 'rank_change' (largest moves first)")
+	diffCmd.Flags().IntVar(&diffLimit, "limit", 20, "Maximum tickers shown per section, 0 for all")
+	diffCmd.Flags().BoolVar(&diffJSON, "json", false, "Print the full comparison as JSON")
+	Cmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,8 @@
+package cmd
+
+import "github.com/flocko-motion/gofins/cmd/audit"
+
+func init() {
+	// Register the audit command and its subcommands
+	rootCmd.AddCommand(audit.Cmd)
+}
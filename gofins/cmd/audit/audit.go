@@ -0,0 +1,13 @@
+package audit
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Cmd is the parent command of the data quality audit
+var Cmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Audit stored data for quality issues",
+	Long: `Check the stored price series for gaps, duplicate periods, non-positive closes, unadjusted
+splits, currency conversion mismatches and stale updates. Findings are stored as data issues.`,
+}
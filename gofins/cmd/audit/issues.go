@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"cmp"
+	"fmt"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var issuesFilter = db.DataIssueFilter{Status: types.IssueOpen}
+
+var issuesCmd = &cobra.Command{
+	Use:   "issues",
+	Short: "List data issues found by the audit",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter := issuesFilter
+		if filter.Status == "all" {
+			filter.Status = ""
+		}
+		issues, err := db.ListDataIssues(cmd.Context(), filter)
+		if err != nil {
+			return fmt.Errorf("failed to list data issues: %w", err)
+		}
+		if len(issues) == 0 {
+			fmt.Println("No data issues found")
+			return nil
+		}
+
+		fmt.Printf("%-6s %-10s %-8s %-18s %-10s %-8s %s\n", "ID", "TICKER", "INTERVAL", "KIND", "PERIOD", "STATUS", "MESSAGE")
+		for _, issue := range issues {
+			fmt.Printf("%-6d %-10s %-8s %-18s %-10s %-8s %s\n", issue.ID, issue.Ticker, cmp.Or(string(issue.Interval), "-"),
+				issue.Kind, f.MaybeDateToString(issue.Period, "2006-01-02", "-"), issue.Status, issue.Message)
+		}
+		return nil
+	},
+}
+
+func init() {
+	issuesCmd.Flags().StringVar(&issuesFilter.Ticker, "ticker", "", "Only issues of this symbol")
+	issuesCmd.Flags().StringVar(&issuesFilter.Kind, "kind", "", "Only issues of this kind (gap, duplicate_period, non_positive_close, split_jump, currency_mismatch, stale)")
+	issuesCmd.Flags().StringVar(&issuesFilter.Status, "status", issuesFilter.Status, "open, resolved, ignored or all")
+	issuesCmd.Flags().IntVar(&issuesFilter.ErrorID, "error", 0, "Only issues linked to this error")
+	issuesCmd.Flags().IntVar(&issuesFilter.Limit, "limit", 50, "Number of issues to show")
+	Cmd.AddCommand(issuesCmd)
+}
@@ -0,0 +1,54 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/flocko-motion/gofins/pkg/audit"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pricesTicker string
+	pricesConfig = audit.DefaultConfig()
+)
+
+var pricesCmd = &cobra.Command{
+	Use:   "prices",
+	Short: "Scan weekly and monthly prices for data issues",
+	Long: `Scans the weekly and monthly prices of all symbols (or one with --ticker) and records the
+findings in data_issues. Issues that aren't found anymore are resolved, ignored issues stay
+ignored. New issues are summarized in an error (source audit.prices) they link to.
+The scheduler runs the same audit weekly as the "audit" job.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		summary, err := audit.Run(cmd.Context(), audit.Options{Ticker: pricesTicker, Config: pricesConfig})
+		if err != nil {
+			return err
+		}
+
+		kinds := make([]string, 0, len(summary.Found))
+		for kind := range summary.Found {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+
+		fmt.Printf("\n✓ Audited %d symbols (%d failed)\n", summary.Symbols, summary.Failed)
+		for _, kind := range kinds {
+			fmt.Printf("  %-20s %d\n", kind, summary.Found[kind])
+		}
+		fmt.Printf("New: %d, resolved: %d\n", summary.New, summary.Resolved)
+		if summary.ErrorID != nil {
+			fmt.Printf("Logged as error #%d, list with: gofins audit issues --error %d\n", *summary.ErrorID, *summary.ErrorID)
+		}
+		return nil
+	},
+}
+
+func init() {
+	pricesCmd.Flags().StringVar(&pricesTicker, "ticker", "", "Only audit this symbol")
+	pricesCmd.Flags().Float64Var(&pricesConfig.JumpFactor, "jump", pricesConfig.JumpFactor, "Minimum close-to-close factor compared with split ratios")
+	pricesCmd.Flags().Float64Var(&pricesConfig.CurrencyTolerance, "currency-tolerance", pricesConfig.CurrencyTolerance, "Maximum relative deviation of USD closes from the converted original closes")
+	pricesCmd.Flags().IntVar(&pricesConfig.AllowedWeeklyGap, "allowed-weekly-gap", pricesConfig.AllowedWeeklyGap, "Missing weeks in a row that aren't reported")
+	pricesCmd.Flags().DurationVar(&pricesConfig.StaleAfter, "stale-after", pricesConfig.StaleAfter, "Age of the last price update after which a symbol is stale")
+	Cmd.AddCommand(pricesCmd)
+}
@@ -0,0 +1,8 @@
+package cmd
+
+import "github.com/flocko-motion/gofins/cmd/backtest"
+
+func init() {
+	// Register the backtest command and its subcommands
+	rootCmd.AddCommand(backtest.Cmd)
+}
@@ -0,0 +1,14 @@
+package backtest
+
+import (
+	"github.com/flocko-motion/gofins/cmd/userflag"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "backtest",
+	Short: "Simulate portfolios on stored prices",
+}
+
+// resolveUser returns the ID of the --user, or of the configured default user
+var resolveUser = userflag.Add(Cmd, "User owning the backtests")
@@ -0,0 +1,75 @@
+package backtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+var showJSON bool
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved backtests",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		userID, err := resolveUser(cmd.Context())
+		if err != nil {
+			return err
+		}
+		backtests, err := db.ListBacktests(cmd.Context(), userID)
+		if err != nil {
+			return fmt.Errorf("failed to list backtests: %w", err)
+		}
+		if len(backtests) == 0 {
+			fmt.Println("No backtests saved")
+			return nil
+		}
+		for _, b := range backtests {
+			fmt.Printf("%s  %s  %s\n", b.ID, b.CreatedAt.Format("2006-01-02 15:04"), b.Name)
+		}
+		return nil
+	},
+}
+
+var showCmd = &cobra.Command{
+	Use:   "show [backtest-id]",
+	Short: "Show a saved backtest",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		backtestID, err := uuid.Parse(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid backtest ID '%s'", args[0])
+		}
+		userID, err := resolveUser(cmd.Context())
+		if err != nil {
+			return err
+		}
+		stored, err := db.GetBacktest(cmd.Context(), userID, backtestID)
+		if err != nil {
+			return fmt.Errorf("failed to get backtest: %w", err)
+		}
+		if stored == nil {
+			return fmt.Errorf("backtest %s not found", backtestID)
+		}
+
+		if showJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(stored)
+		}
+		fmt.Printf("%s (%s)\n\n", stored.Name, stored.ID)
+		printResult(stored.Config, stored.Result)
+		return nil
+	},
+}
+
+func init() {
+	showCmd.Flags().BoolVar(&showJSON, "json", false, "Print the backtest as JSON")
+	Cmd.AddCommand(listCmd)
+	Cmd.AddCommand(showCmd)
+}
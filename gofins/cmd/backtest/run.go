@@ -0,0 +1,195 @@
+package backtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/backtest"
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runHoldings  []string
+	runPackage   string
+	runTop       int
+	runProfile   string
+	runRebalance string
+	runInterval  string
+	runFrom      string
+	runTo        string
+	runBenchmark string
+	runSave      bool
+	runName      string
+	runJSON      bool
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run a backtest of fixed holdings or the top N of an analysis package",
+	Long: `Simulates a portfolio over weekly or monthly prices in USD, rebalanced to its target
+weights every month, quarter or year. Holdings are given as TICKER:WEIGHT (weights are
+normalized), or selected as the top N results of an analysis package, equally weighted.
+
+Examples:
+  gofins backtest run --holdings AAPL:0.6,MSFT:0.4 --from 2015 --benchmark ^GSPC
+  gofins backtest run --package <id> --top 20 --rebalance yearly --from 2010 --to 2020 --save`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := runConfig()
+		if err != nil {
+			return err
+		}
+		if err := backtest.Validate(config); err != nil {
+			return err
+		}
+		userID, err := resolveUser(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		var result *types.BacktestResult
+		if runSave {
+			created, err := backtest.Create(cmd.Context(), userID, runName, config)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "✓ Saved backtest %s (%s)\n", created.ID, created.Name)
+			result = created.Result
+		} else if result, err = backtest.Run(cmd.Context(), userID, config); err != nil {
+			return err
+		}
+
+		if runJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(result)
+		}
+		printResult(config, result)
+		return nil
+	},
+}
+
+// runConfig builds the backtest config from the flags
+func runConfig() (types.BacktestConfig, error) {
+	config := types.BacktestConfig{
+		Rebalance: runRebalance,
+		Interval:  types.PriceInterval(runInterval),
+	}
+
+	for _, holding := range runHoldings {
+		ticker, weight, found := strings.Cut(holding, ":")
+		h := types.BacktestHolding{Ticker: strings.ToUpper(strings.TrimSpace(ticker)), Weight: 1}
+		if found {
+			parsed, err := strconv.ParseFloat(weight, 64)
+			if err != nil {
+				return config, fmt.Errorf("invalid weight in '%s': %w", holding, err)
+			}
+			h.Weight = parsed
+		}
+		config.Holdings = append(config.Holdings, h)
+	}
+
+	if runPackage != "" {
+		config.TopN = &types.BacktestTopN{PackageID: runPackage, N: runTop}
+		if runProfile != "" {
+			profileID, err := uuid.Parse(runProfile)
+			if err != nil {
+				return config, fmt.Errorf("invalid profile ID '%s'", runProfile)
+			}
+			config.TopN.ProfileID = &profileID
+		}
+	}
+
+	if runBenchmark != "" {
+		config.Benchmark = f.Ptr(strings.ToUpper(runBenchmark))
+	}
+
+	var err error
+	if config.Start, err = f.ParseDate(runFrom); err != nil {
+		return config, fmt.Errorf("invalid --from: %w", err)
+	}
+	config.End = time.Now().UTC().Truncate(24 * time.Hour)
+	if runTo != "" {
+		if config.End, err = f.ParseDate(runTo); err != nil {
+			return config, fmt.Errorf("invalid --to: %w", err)
+		}
+	}
+	return config, nil
+}
+
+func printResult(config types.BacktestConfig, result *types.BacktestResult) {
+	first, last := result.Equity[0], result.Equity[len(result.Equity)-1]
+	fmt.Printf("Period:     %s to %s (%d %s periods, %s rebalancing)\n",
+		first.Date.Format("2006-01-02"), last.Date.Format("2006-01-02"), len(result.Equity), config.Interval, config.Rebalance)
+	fmt.Printf("Holdings:   %d", len(result.Holdings))
+	if len(result.Missing) > 0 {
+		fmt.Printf(" (no prices: %s)", strings.Join(result.Missing, ", "))
+	}
+	fmt.Printf("\nEnd value:  %.2f (start %.2f)\n", last.Value, backtest.StartValue)
+	fmt.Printf("Turnover:   %.1f%% total, %.1f%% per year\n\n", result.Turnover, result.AnnualTurnover)
+
+	fmt.Printf("%-12s %10s %10s %10s %10s\n", "", "CAGR", "VOLATILITY", "MAX DD", "SHARPE")
+	printMetrics("Portfolio", result.Metrics)
+	if config.Benchmark != nil {
+		printMetrics(*config.Benchmark, result.Benchmark)
+	}
+
+	fmt.Printf("\nRebalances (%d):\n", len(result.Rebalances))
+	for _, rebalance := range result.Rebalances {
+		holdings := make([]string, len(rebalance.Holdings))
+		for i, h := range rebalance.Holdings {
+			holdings[i] = fmt.Sprintf("%s %.1f%%", h.Ticker, h.Weight*100)
+		}
+		if rebalance.Cash > 0 {
+			holdings = append(holdings, "cash 100%")
+		}
+		fmt.Printf("  %s  value %9.2f  turnover %5.1f%%  %s\n",
+			rebalance.Date.Format("2006-01-02"), rebalance.Value, rebalance.Turnover, strings.Join(holdings, ", "))
+	}
+}
+
+func printMetrics(label string, metrics *types.PerformanceMetrics) {
+	if metrics == nil {
+		fmt.Printf("%-12s %10s\n", label, "too few periods")
+		return
+	}
+	fmt.Printf("%-12s %10s %9.2f%% %9.2f%% %10s\n", label,
+		formatPercent(metrics.CAGR), metrics.Volatility, metrics.MaxDrawdown, formatFloat(metrics.Sharpe))
+}
+
+func formatPercent(value *float64) string {
+	if value == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%%", *value)
+}
+
+func formatFloat(value *float64) string {
+	if value == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", *value)
+}
+
+func init() {
+	runCmd.Flags().StringSliceVar(&runHoldings, "holdings", nil, "Holdings as TICKER:WEIGHT, comma separated (weight defaults to 1)")
+	runCmd.Flags().StringVar(&runPackage, "package", "", "Analysis package to select the top N results from")
+	runCmd.Flags().IntVar(&runTop, "top", 10, "Number of top ranked results of --package to hold")
+	runCmd.Flags().StringVar(&runProfile, "profile", "", "Scoring profile ID used to rank --package (default: mean and stddev)")
+	runCmd.Flags().StringVar(&runRebalance, "rebalance", types.RebalanceMonthly, "Rebalancing schedule: monthly, quarterly or yearly")
+	runCmd.Flags().StringVar(&runInterval, "interval", string(types.IntervalMonthly), "Price interval: monthly or weekly")
+	runCmd.Flags().StringVar(&runFrom, "from", "2009", "Start date (YYYY, YYYY-MM or YYYY-MM-DD)")
+	runCmd.Flags().StringVar(&runTo, "to", "", "End date (default: today)")
+	runCmd.Flags().StringVar(&runBenchmark, "benchmark", "", "Index ticker to compare against, e.g. ^GSPC")
+	runCmd.Flags().BoolVar(&runSave, "save", false, "Store the backtest for the user (visible in the API)")
+	runCmd.Flags().StringVar(&runName, "name", "", "Name of a saved backtest (default: derived from the config)")
+	runCmd.Flags().BoolVar(&runJSON, "json", false, "Print the full result as JSON")
+	Cmd.AddCommand(runCmd)
+}
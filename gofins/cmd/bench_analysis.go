@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"runtime"
 	"time"
 
 	"github.com/flocko-motion/gofins/pkg/analysis"
@@ -14,8 +15,8 @@ var benchAnalysisCmd = &cobra.Command{
 	Use:   "bench-analysis",
 	Short: "Benchmark YoY analysis performance",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Get 100 stocks that have price data
-		tickers, err := db.GetTickersWithPrices(cmd.Context(), 100)
+		// Get stocks that have price data
+		tickers, err := db.GetTickersWithPrices(cmd.Context(), benchSymbols)
 		if err != nil {
 			return fmt.Errorf("failed to get tickers: %w", err)
 		}
@@ -128,13 +129,85 @@ var benchAnalysisCmd = &cobra.Command{
 		fmt.Println("=== Comparison ===")
 		if individualSuccess > 0 && batchSuccess > 0 {
 			speedup := float64(individualElapsed) / float64(batchElapsed)
-			fmt.Printf("Batch is %.2fx faster than individual queries\n", speedup)
+			fmt.Printf("Batch is %.2fx faster than individual queries\n\n", speedup)
+		}
+
+		// ===== Method 3: Pipeline settings =====
+		fmt.Printf("=== Method 3: Pipeline (%s prices, chunks of %d) ===\n", benchInterval, benchChunkSize)
+		fmt.Printf("%-8s %12s %12s %14s %14s\n", "Workers", "Time", "Symbols/sec", "Allocated MB", "Peak heap MB")
+		for _, workers := range benchWorkers {
+			var results []analysis.SymbolStats
+			elapsed, allocMB, peakMB, err := measureRun(func() error {
+				var err error
+				results, err = analysis.AnalyzeBatch(cmd.Context(), analysis.AnalysisPackageConfig{
+					Tickers:    tickers,
+					TimeFrom:   from,
+					TimeTo:     to,
+					Interval:   types.PriceInterval(benchInterval),
+					HistConfig: histConfig,
+					Workers:    workers,
+					ChunkSize:  benchChunkSize,
+				})
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("failed to run pipeline with %d workers: %w", workers, err)
+			}
+			fmt.Printf("%-8d %12v %12.1f %14.1f %14.1f\n",
+				workers, elapsed.Round(time.Millisecond), float64(len(results))/elapsed.Seconds(), allocMB, peakMB)
 		}
 
 		return nil
 	},
 }
 
+// measureRun times fn and reports the memory it allocated and the peak heap in use while it ran
+func measureRun(fn func() error) (time.Duration, float64, float64, error) {
+	const mb = 1024 * 1024
+	runtime.GC()
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	peak := before.HeapInuse
+	done := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		ticker := time.NewTicker(20 * time.Millisecond)
+		defer ticker.Stop()
+		var stats runtime.MemStats
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				runtime.ReadMemStats(&stats)
+				peak = max(peak, stats.HeapInuse)
+			}
+		}
+	}()
+
+	start := time.Now()
+	err := fn()
+	elapsed := time.Since(start)
+	close(done)
+	<-sampled
+
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	return elapsed, float64(after.TotalAlloc-before.TotalAlloc) / mb, float64(peak) / mb, err
+}
+
+var benchSymbols int
+var benchInterval string
+var benchChunkSize int
+var benchWorkers []int
+
 func init() {
 	rootCmd.AddCommand(benchAnalysisCmd)
+
+	benchAnalysisCmd.Flags().IntVar(&benchSymbols, "symbols", 100, "Number of symbols to analyze")
+	benchAnalysisCmd.Flags().StringVar(&benchInterval, "interval", string(types.IntervalWeekly), "Price interval of the pipeline runs (weekly or monthly)")
+	benchAnalysisCmd.Flags().IntVar(&benchChunkSize, "chunk-size", analysis.DefaultChunkSize, "Tickers read per price query in the pipeline runs")
+	benchAnalysisCmd.Flags().IntSliceVar(&benchWorkers, "workers", []int{1, 2, 4, 8}, "Worker counts to compare in the pipeline runs")
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 
+	"github.com/flocko-motion/gofins/pkg/audit"
 	"github.com/flocko-motion/gofins/pkg/db"
 	"github.com/spf13/cobra"
 )
@@ -39,6 +40,19 @@ var infoCmd = &cobra.Command{
 			fmt.Printf("\nDetails:\n%s\n", *errorEntry.Details)
 		}
 
+		// Audit errors summarize the data issues that link to them
+		if errorEntry.Source == audit.ErrorSource {
+			issues, err := db.ListDataIssues(cmd.Context(), db.DataIssueFilter{ErrorID: errorID, Limit: 10})
+			if err != nil {
+				return fmt.Errorf("failed to get data issues: %w", err)
+			}
+			fmt.Printf("\nData issues:\n")
+			for _, issue := range issues {
+				fmt.Printf("  #%d %s %s: %s\n", issue.ID, issue.Ticker, issue.Kind, issue.Message)
+			}
+			fmt.Printf("  List all with: gofins audit issues --status all --error %d\n", errorID)
+		}
+
 		return nil
 	},
 }
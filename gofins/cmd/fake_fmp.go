@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/flocko-motion/gofins/pkg/fmp/fmptest"
+	"github.com/spf13/cobra"
+)
+
+var fakeFmpPort int
+var fakeFmpFixtures string
+
+var fakeFmpCmd = &cobra.Command{
+	Use:   "fake-fmp",
+	Short: "Run a fake FMP API backed by fixture files (for offline development)",
+	Long: `Serves the FMP endpoints used by the updaters from fixture files.
+Point any other command at it with:
+
+  FMP_BASE_URL=http://localhost:9090 FMP_API_KEY=fmptest-api-key gofins server
+
+Without --fixtures a small built-in data set is served (AAPL, MSFT, SAP.DE, ^GSPC, EURUSD).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		handler := fmptest.NewHandler(nil)
+		source := "built-in fixtures"
+		if fakeFmpFixtures != "" {
+			if _, err := os.Stat(fakeFmpFixtures); err != nil {
+				return fmt.Errorf("fixtures directory: %w", err)
+			}
+			handler = fmptest.NewHandler(os.DirFS(fakeFmpFixtures))
+			source = fakeFmpFixtures
+		}
+
+		addr := fmt.Sprintf(":%d", fakeFmpPort)
+		fmt.Printf("✓ Fake FMP API listening on %s (%s)\n", addr, source)
+		fmt.Printf("  FMP_BASE_URL=http://localhost:%d FMP_API_KEY=%s\n", fakeFmpPort, fmptest.APIKey)
+		return http.ListenAndServe(addr, handler)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fakeFmpCmd)
+
+	fakeFmpCmd.Flags().IntVar(&fakeFmpPort, "port", 9090, "Port to listen on")
+	fakeFmpCmd.Flags().StringVar(&fakeFmpFixtures, "fixtures", "",
+		"Fixture directory (layout: see package fmptest); default: built-in fixtures")
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/config"
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/mcp"
+	"github.com/spf13/cobra"
+)
+
+var mcpHTTPAddr string
+var mcpUser string
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Run the Model Context Protocol server (stdio, or streamable HTTP with --http)",
+	Long: `Exposes symbols, prices, analyses and the user's favorites, ratings and notes via MCP.
+
+stdio (default): all requests run as --user, or default_user from ~/.gofins/config.yaml.
+HTTP (--http :8081): POST JSON-RPC messages to /mcp. The user is taken from the
+X-Remote-User header, falling back to --user.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		if mcpHTTPAddr == "" {
+			return runMCPStdio(ctx)
+		}
+		return runMCPHTTP(ctx)
+	},
+}
+
+// runMCPStdio serves MCP on stdin/stdout. Stdout carries the protocol only,
+// so all other output (including stray prints from other packages) goes to stderr.
+func runMCPStdio(ctx context.Context) error {
+	protocolOut := os.Stdout
+	os.Stdout = os.Stderr
+
+	username := mcpUser
+	if username == "" {
+		var err error
+		username, err = config.GetDefaultUser()
+		if err != nil {
+			return fmt.Errorf("no --user given and no default user configured: %w", err)
+		}
+	}
+
+	if db.Db() == nil {
+		return fmt.Errorf("failed to connect to database")
+	}
+	userID, err := mcp.ResolveUser(ctx, username)
+	if err != nil {
+		return fmt.Errorf("failed to resolve user '%s': %w", username, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "✓ MCP server on stdio (user '%s')\n", username)
+	return mcp.NewServer().ServeStdio(ctx, userID, os.Stdin, protocolOut)
+}
+
+// runMCPHTTP serves the streamable HTTP transport on /mcp until interrupted
+func runMCPHTTP(ctx context.Context) error {
+	if db.Db() == nil {
+		return fmt.Errorf("failed to connect to database")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", mcp.NewServer().HTTPHandler(mcpUser))
+	server := &http.Server{Addr: mcpHTTPAddr, Handler: mux}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- server.ListenAndServe()
+	}()
+	if mcpUser != "" {
+		fmt.Printf("✓ MCP server listening on %s/mcp (default user '%s')\n", mcpHTTPAddr, mcpUser)
+	} else {
+		fmt.Printf("✓ MCP server listening on %s/mcp (X-Remote-User required)\n", mcpHTTPAddr)
+	}
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	fmt.Println("✓ MCP server stopped")
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+
+	mcpCmd.Flags().StringVar(&mcpHTTPAddr, "http", "",
+		"Serve streamable HTTP on this address (e.g., --http=:8081) instead of stdio")
+	mcpCmd.Flags().StringVar(&mcpUser, "user", "",
+		"User for all requests (stdio) or when no X-Remote-User header is sent (HTTP)")
+}
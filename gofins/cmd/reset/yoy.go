@@ -0,0 +1,88 @@
+package reset
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/calculator"
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	yoyTicker string
+	yoyWeeks  string
+)
+
+var yoyCmd = &cobra.Command{
+	Use:   "yoy",
+	Short: "Recompute YoY of stored weekly and monthly prices without refetching",
+	Long: `Matches every stored weekly and monthly period with its prior-year period again and rewrites
+yoy, yoy_tr and yoy_ref. Missing or shifted weeks fall back to the nearest week within 7 days.
+The total-return YoY compares the stored total-return indexes (close_tr) like the price updater;
+periods without one keep their stored value. Weeks are matched by the trading (52 weeks back) or ISO week
+convention, default from GOFINS_YOY_WEEKS; the price updater uses the same setting.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		weeks := calculator.DefaultWeekConvention()
+		if yoyWeeks != "" {
+			var err error
+			if weeks, err = calculator.ParseWeekConvention(yoyWeeks); err != nil {
+				return err
+			}
+		}
+
+		tickers := []string{yoyTicker}
+		if yoyTicker == "" {
+			var err error
+			if tickers, err = db.GetAllTickers(cmd.Context()); err != nil {
+				return fmt.Errorf("failed to get tickers: %w", err)
+			}
+		}
+		fmt.Printf("Recomputing YoY for %d symbols (%s weeks)...\n", len(tickers), weeks)
+
+		intervals := []types.PriceInterval{types.IntervalMonthly, types.IntervalWeekly}
+		changed := map[types.PriceInterval]int{}
+		updated, failed := 0, 0
+		for i, ticker := range tickers {
+			if i%100 == 0 && i > 0 {
+				fmt.Printf("Progress: %d/%d (updated: %d, failed: %d)\n", i, len(tickers), updated, failed)
+			}
+
+			tickerChanged := false
+			for _, interval := range intervals {
+				prices, err := db.GetPrices(ticker, time.Time{}, time.Now(), interval)
+				if err != nil {
+					_ = db.LogError(cmd.Context(), "reset.yoy", "database", "Failed to load prices of "+ticker, f.Ptr(err.Error()))
+					failed++
+					continue
+				}
+				count := calculator.RecomputeYoY(prices, calculator.NewAlignment(interval, weeks))
+				if count == 0 {
+					continue
+				}
+				if err := db.UpdatePriceYoY(prices, interval); err != nil {
+					_ = db.LogError(cmd.Context(), "reset.yoy", "database", "Failed to write YoY of "+ticker, f.Ptr(err.Error()))
+					failed++
+					continue
+				}
+				changed[interval] += count
+				tickerChanged = true
+			}
+			if tickerChanged {
+				updated++
+			}
+		}
+
+		fmt.Printf("\n✓ Complete: %d symbols updated (%d monthly, %d weekly periods changed), %d failed\n",
+			updated, changed[types.IntervalMonthly], changed[types.IntervalWeekly], failed)
+		return nil
+	},
+}
+
+func init() {
+	yoyCmd.Flags().StringVar(&yoyTicker, "ticker", "", "Only recompute this symbol")
+	yoyCmd.Flags().StringVar(&yoyWeeks, "weeks", "", "Week convention: trading or iso (default from GOFINS_YOY_WEEKS, else trading)")
+	Cmd.AddCommand(yoyCmd)
+}
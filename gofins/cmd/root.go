@@ -3,6 +3,8 @@ package cmd
 import (
 	"os"
 
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/fmp"
 	"github.com/spf13/cobra"
 )
 
@@ -11,6 +13,12 @@ var rootCmd = &cobra.Command{
 	Short: "FINS - Financial Intelligence System",
 }
 
+func init() {
+	// All commands share the FMP quota, its usage is stored in the database
+	fmp.SetQuotaStore(db.ApiUsage{})
+	cobra.OnFinalize(fmp.Shutdown)
+}
+
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
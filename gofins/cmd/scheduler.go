@@ -0,0 +1,8 @@
+package cmd
+
+import "github.com/flocko-motion/gofins/cmd/scheduler"
+
+func init() {
+	// Register the scheduler command and its subcommands
+	rootCmd.AddCommand(scheduler.Cmd)
+}
@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var unskip bool
+
+var triggerCmd = &cobra.Command{
+	Use:   "trigger [job]",
+	Short: "Run a job as soon as the scheduler is idle, even if paused",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sched, err := newScheduler()
+		if err != nil {
+			return err
+		}
+		if err := sched.Trigger(cmd.Context(), args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Triggered %s\n", args[0])
+		return nil
+	},
+}
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause [job]",
+	Short: "Suspend the scheduled runs of a job",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setPaused(cmd, args[0], true)
+	},
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume [job]",
+	Short: "Resume the scheduled runs of a paused job",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setPaused(cmd, args[0], false)
+	},
+}
+
+var skipCmd = &cobra.Command{
+	Use:   "skip [job]",
+	Short: "Skip the next scheduled run of a job",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sched, err := newScheduler()
+		if err != nil {
+			return err
+		}
+		if err := sched.SetSkipNext(cmd.Context(), args[0], !unskip); err != nil {
+			return err
+		}
+		if unskip {
+			fmt.Printf("✓ Next run of %s will not be skipped\n", args[0])
+		} else {
+			fmt.Printf("✓ Next run of %s will be skipped\n", args[0])
+		}
+		return nil
+	},
+}
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule [job] [cron expression]",
+	Short: "Override the schedule of a job, without expression restore the default",
+	Example: `  gofins scheduler schedule prices "CRON_TZ=America/New_York 30 17 * * MON-FRI"
+  gofins scheduler schedule prices`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sched, err := newScheduler()
+		if err != nil {
+			return err
+		}
+		expr := ""
+		if len(args) == 2 {
+			expr = args[1]
+		}
+		if err := sched.SetSchedule(cmd.Context(), args[0], expr); err != nil {
+			return err
+		}
+		job, err := sched.Job(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ %s runs on '%s'\n", job.Name, job.Schedule)
+		return nil
+	},
+}
+
+func setPaused(cmd *cobra.Command, job string, paused bool) error {
+	sched, err := newScheduler()
+	if err != nil {
+		return err
+	}
+	if err := sched.SetPaused(cmd.Context(), job, paused); err != nil {
+		return err
+	}
+	if paused {
+		fmt.Printf("✓ Paused %s\n", job)
+	} else {
+		fmt.Printf("✓ Resumed %s\n", job)
+	}
+	return nil
+}
+
+func init() {
+	skipCmd.Flags().BoolVar(&unskip, "undo", false, "Do not skip the next run after all")
+	Cmd.AddCommand(triggerCmd)
+	Cmd.AddCommand(pauseCmd)
+	Cmd.AddCommand(resumeCmd)
+	Cmd.AddCommand(skipCmd)
+	Cmd.AddCommand(scheduleCmd)
+}
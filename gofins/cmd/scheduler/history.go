@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"fmt"
+
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/spf13/cobra"
+)
+
+var historyLimit int
+
+var historyCmd = &cobra.Command{
+	Use:   "history [job]",
+	Short: "Show the latest updater runs, of all jobs or of one job",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sched, err := newScheduler()
+		if err != nil {
+			return err
+		}
+		job := ""
+		if len(args) == 1 {
+			job = args[0]
+		}
+		runs, err := sched.History(cmd.Context(), job, historyLimit)
+		if err != nil {
+			return fmt.Errorf("failed to get history: %w", err)
+		}
+		if len(runs) == 0 {
+			fmt.Println("No runs recorded")
+			return nil
+		}
+
+		for _, run := range runs {
+			duration := "-"
+			if run.FinishedAt != nil {
+				duration = f.DurationToString(run.FinishedAt.Sub(run.StartedAt))
+			}
+			fmt.Printf("%s  %-10s %-9s %-10s %s\n", run.StartedAt.Local().Format(timeFormat), run.Job, run.Trigger, run.Status, duration)
+			if run.Error != nil {
+				fmt.Printf("    %s\n", *run.Error)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	historyCmd.Flags().IntVar(&historyLimit, "limit", 20, "Number of runs to show")
+	Cmd.AddCommand(historyCmd)
+}
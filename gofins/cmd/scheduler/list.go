@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const timeFormat = "2006-01-02 15:04 MST"
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List updater jobs with schedule, next and last run",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sched, err := newScheduler()
+		if err != nil {
+			return err
+		}
+		jobs, err := sched.Jobs(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to list jobs: %w", err)
+		}
+
+		for _, job := range jobs {
+			var flags []string
+			if job.Schedule != job.DefaultSchedule {
+				flags = append(flags, "custom schedule")
+			}
+			if job.Paused {
+				flags = append(flags, "paused")
+			}
+			if job.SkipNext {
+				flags = append(flags, "skip next")
+			}
+			if job.TriggeredAt != nil {
+				flags = append(flags, "triggered")
+			}
+
+			fmt.Printf("%-10s %s", job.Name, job.Schedule)
+			if len(flags) > 0 {
+				fmt.Printf("  [%s]", strings.Join(flags, ", "))
+			}
+			fmt.Println()
+			if len(job.After) > 0 {
+				fmt.Printf("           after: %s\n", strings.Join(job.After, ", "))
+			}
+			if job.NextRun != nil {
+				fmt.Printf("           next:  %s\n", job.NextRun.Local().Format(timeFormat))
+			}
+			if job.LastRun != nil {
+				fmt.Printf("           last:  %s %s\n", job.LastRun.StartedAt.Local().Format(timeFormat), job.LastRun.Status)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	Cmd.AddCommand(listCmd)
+}
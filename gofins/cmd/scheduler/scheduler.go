@@ -0,0 +1,20 @@
+package scheduler
+
+import (
+	"github.com/flocko-motion/gofins/pkg/scheduler"
+	"github.com/flocko-motion/gofins/pkg/updater"
+	"github.com/spf13/cobra"
+)
+
+// Cmd is the parent command for managing the updater scheduler
+var Cmd = &cobra.Command{
+	Use:   "scheduler",
+	Short: "Inspect and control the updater schedules",
+	Long: `Inspect and control the updater jobs (symbols, profiles, quotes, prices, dedupe) and the price audit.
+Changes are stored in the database; a running server picks them up within 10 seconds.`,
+}
+
+// newScheduler returns a scheduler over the updater jobs, used to read and change their state
+func newScheduler() (*scheduler.Scheduler, error) {
+	return scheduler.New(updater.Jobs())
+}
@@ -8,15 +8,18 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/flocko-motion/gofins/pkg/analysis"
 	"github.com/flocko-motion/gofins/pkg/api"
 	"github.com/flocko-motion/gofins/pkg/db"
 	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/scheduler"
 	"github.com/flocko-motion/gofins/pkg/updater"
 	"github.com/spf13/cobra"
 )
 
 var noUpdates bool
 var devUser string
+var analysisWorkers int
 
 var serverCmd = &cobra.Command{
 	Use:   "server",
@@ -62,8 +65,19 @@ var serverCmd = &cobra.Command{
 		}
 		fmt.Println("✓ Database connected")
 
+		// Start analysis job queue (resumes jobs orphaned by a previous run)
+		queue := analysis.NewQueue(analysisWorkers)
+		go queue.Run(ctx)
+		fmt.Printf("✓ Analysis job queue running with %d workers\n", analysisWorkers)
+
+		// Updater scheduler (also serves the admin scheduler endpoints when updates are disabled)
+		sched, err := scheduler.New(updater.Jobs())
+		if err != nil {
+			return fmt.Errorf("failed to set up updater scheduler: %w", err)
+		}
+
 		// Start REST API server
-		apiServer := api.NewServer(db.Db(), 8080, devUser)
+		apiServer := api.NewServer(db.Db(), 8080, devUser, queue, sched)
 		go apiServer.Start(ctx)
 		if devUser != "" {
 			fmt.Printf("✓ REST API server listening on :8080 (DEV MODE - all requests as user '%s')\n", devUser)
@@ -74,7 +88,8 @@ var serverCmd = &cobra.Command{
 		// Start updaters only if --no-updates is not set
 		fmt.Println("\n=== Starting Services ===")
 		if !noUpdates {
-			go updater.RunAllUpdaters(ctx)
+			go sched.Run(ctx)
+			fmt.Println("✓ Updater scheduler running")
 		} else {
 			fmt.Println("⚠️  Updates disabled - working with existing data only")
 		}
@@ -102,4 +117,6 @@ func init() {
 		"Disable all data updates and work with existing data only")
 	serverCmd.Flags().StringVar(&devUser, "user", "",
 		"Development mode - override user for all requests (e.g., --user=alice)")
+	serverCmd.Flags().IntVar(&analysisWorkers, "analysis-workers", 2,
+		"Number of analysis jobs processed concurrently across all users")
 }
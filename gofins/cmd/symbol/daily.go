@@ -0,0 +1,66 @@
+package symbol
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dailyDays int
+)
+
+var dailyCmd = &cobra.Command{
+	Use:   "daily [ticker]",
+	Short: "Display daily price history with volume for a symbol",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ticker := args[0]
+
+		// Get the last N days (0 = from beginning to now)
+		to := time.Now()
+		from := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+		if dailyDays > 0 {
+			from = to.AddDate(0, 0, -dailyDays)
+		}
+
+		prices, err := db.GetDailyPrices(ticker, from, to)
+		if err != nil {
+			return fmt.Errorf("failed to get daily prices: %w", err)
+		}
+
+		if len(prices) == 0 {
+			fmt.Printf("No daily price data found for %s\n", ticker)
+			return nil
+		}
+
+		// Print header
+		fmt.Printf("%-12s %12s %12s %12s %12s %12s %14s\n", "Date", "Open", "High", "Low", "Close", "Adj Close", "Volume")
+		fmt.Println("------------------------------------------------------------------------------------------")
+
+		// Print prices
+		for _, price := range prices {
+			dateStr := price.Date.Format("2006-01-02")
+			adjCloseStr := "N/A"
+			if price.AdjClose != nil {
+				adjCloseStr = fmt.Sprintf("%.2f", *price.AdjClose)
+			}
+			volumeStr := "N/A"
+			if price.Volume != nil {
+				volumeStr = fmt.Sprintf("%d", *price.Volume)
+			}
+			fmt.Printf("%-12s %12.2f %12.2f %12.2f %12.2f %12s %14s\n",
+				dateStr, price.Open, price.High, price.Low, price.Close, adjCloseStr, volumeStr)
+		}
+
+		fmt.Printf("\nTotal: %d daily prices\n", len(prices))
+		return nil
+	},
+}
+
+func init() {
+	Cmd.AddCommand(dailyCmd)
+	dailyCmd.Flags().IntVar(&dailyDays, "days", 365, "Number of days to show (0 = full history)")
+}
@@ -1,23 +1,31 @@
 package update
 
 import (
-	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/flocko-motion/gofins/pkg/scheduler"
 	"github.com/flocko-motion/gofins/pkg/updater"
 	"github.com/spf13/cobra"
 )
 
 var allCmd = &cobra.Command{
 	Use:   "all",
-	Short: "Run all updaters in sequence (symbols -> profiles -> prices -> dedupe), repeat every 8h",
+	Short: "Run the updater scheduler in the foreground (symbols -> profiles -> quotes -> prices, dedupe)",
+	Long: `Runs the updaters on their schedules like the server does, until interrupted.
+Updaters that never ran or missed their schedule run right away. See 'gofins scheduler list'.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("Starting all updaters in continuous mode...")
-		fmt.Println("Order: symbols -> profiles -> prices -> dedupe")
-		fmt.Println("Cycle repeats every 8 hours")
-		fmt.Println()
-		ctx := context.Background()
-		updater.RunAllUpdaters(ctx)
+		sched, err := scheduler.New(updater.Jobs())
+		if err != nil {
+			return err
+		}
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		fmt.Println("Starting updater scheduler (Ctrl+C to stop)...")
+		sched.Run(ctx)
 		return nil
 	},
 }
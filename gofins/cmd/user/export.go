@@ -0,0 +1,82 @@
+package user
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/backup"
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportOutput string
+	exportFormat string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [username]",
+	Short: "Export a user's ratings, watchlists, analyses, journal, scoring profiles and portfolios",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		username := args[0]
+
+		if exportFormat != backup.FormatJSON && exportFormat != backup.FormatTarGz {
+			return fmt.Errorf("invalid format '%s' (must be 'json' or 'tar.gz')", exportFormat)
+		}
+
+		user, err := db.GetUser(cmd.Context(), username)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if user == nil {
+			return fmt.Errorf("user '%s' not found", username)
+		}
+
+		b, err := backup.Export(cmd.Context(), user)
+		if err != nil {
+			return err
+		}
+
+		output := exportOutput
+		if output == "" {
+			output = fmt.Sprintf("gofins-%s-%s.%s", user.Name, time.Now().Format("20060102"), exportFormat)
+		}
+
+		var w io.Writer = os.Stdout
+		if output != "-" {
+			file, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			defer file.Close()
+			w = file
+		}
+
+		if err := backup.Encode(w, b, exportFormat); err != nil {
+			return fmt.Errorf("failed to write backup: %w", err)
+		}
+		if output == "-" {
+			return nil
+		}
+
+		fmt.Printf("✓ Exported user '%s' to %s\n", user.Name, output)
+		fmt.Printf("  Ratings:           %d\n", len(b.Ratings))
+		fmt.Printf("  Watchlists:        %d\n", len(b.Watchlists))
+		fmt.Printf("  Analysis packages: %d\n", len(b.AnalysisPackages))
+		fmt.Printf("  Journal entries:   %d\n", len(b.Journal))
+		fmt.Printf("  Scoring profiles:  %d\n", len(b.ScoringProfiles))
+		fmt.Printf("  Portfolios:        %d\n", len(b.Portfolios))
+
+		return nil
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file, '-' for stdout (default: gofins-<user>-<date>.<format>)")
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", backup.FormatJSON, "Backup format: "+strings.Join([]string{backup.FormatJSON, backup.FormatTarGz}, " or "))
+	UserCmd.AddCommand(exportCmd)
+}
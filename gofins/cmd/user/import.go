@@ -0,0 +1,80 @@
+package user
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/flocko-motion/gofins/pkg/backup"
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/spf13/cobra"
+)
+
+var importPolicy string
+
+var importCmd = &cobra.Command{
+	Use:   "import [username] [file]",
+	Short: "Import a backup into a user's account (creates the user if needed)",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		username, path := args[0], args[1]
+
+		if !backup.IsValidPolicy(importPolicy) {
+			return fmt.Errorf("invalid policy '%s' (must be one of: %s)", importPolicy, strings.Join(backup.Policies, ", "))
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open backup: %w", err)
+		}
+		defer file.Close()
+
+		b, err := backup.Decode(file)
+		if err != nil {
+			return err
+		}
+
+		user, err := db.GetUser(cmd.Context(), username)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if user == nil {
+			user, err = db.CreateUser(cmd.Context(), username)
+			if err != nil {
+				return fmt.Errorf("failed to create user: %w", err)
+			}
+			fmt.Printf("✓ User '%s' created (ID: %s)\n", user.Name, user.ID)
+		}
+
+		report, err := backup.Import(cmd.Context(), user.ID, b, importPolicy)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("✓ Imported backup of '%s' (exported %s) into '%s' (policy: %s)\n",
+			b.User, b.ExportedAt.Format("2006-01-02 15:04:05"), user.Name, report.Policy)
+		fmt.Printf("  %-18s %7s %7s %7s\n", "", "ADDED", "UPDATED", "SKIPPED")
+		rows := []struct {
+			name   string
+			counts backup.ImportCounts
+		}{
+			{"Ratings", report.Ratings},
+			{"Favorites", report.Favorites},
+			{"Watchlist entries", report.Watchlists},
+			{"Analysis packages", report.AnalysisPackages},
+			{"Journal entries", report.Journal},
+			{"Scoring profiles", report.ScoringProfiles},
+			{"Portfolios", report.Portfolios},
+		}
+		for _, row := range rows {
+			fmt.Printf("  %-18s %7d %7d %7d\n", row.name, row.counts.Added, row.counts.Updated, row.counts.Skipped)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	importCmd.Flags().StringVarP(&importPolicy, "policy", "p", backup.PolicySkip, "Conflict policy: "+strings.Join(backup.Policies, ", "))
+	UserCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,40 @@
+package userflag
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flocko-motion/gofins/pkg/config"
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+// Add adds a persistent --user flag to cmd and returns a function that resolves the ID of
+// the given user, or of the configured default user if the flag isn't set
+func Add(cmd *cobra.Command, usage string) func(ctx context.Context) (uuid.UUID, error) {
+	var username string
+	cmd.PersistentFlags().StringVar(&username, "user", "", usage+" (default: default_user from ~/.gofins/config.yaml)")
+	return func(ctx context.Context) (uuid.UUID, error) {
+		return resolve(ctx, username)
+	}
+}
+
+func resolve(ctx context.Context, name string) (uuid.UUID, error) {
+	if name == "" {
+		var err error
+		name, err = config.GetDefaultUser()
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("no --user given and no default user configured: %w", err)
+		}
+	}
+
+	user, err := db.GetUser(ctx, name)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return uuid.Nil, fmt.Errorf("user '%s' not found", name)
+	}
+	return user.ID, nil
+}
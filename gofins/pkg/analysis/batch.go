@@ -5,141 +5,249 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"runtime"
 	"sync"
 	"time"
 
 	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/types"
 )
 
 // SymbolStats contains YoY statistics for a single symbol
 type SymbolStats struct {
-	Ticker string
-	Stats  Stats
+	Ticker  string
+	Stats   Stats
+	Beta    *BetaStats                // nil if no reference index was configured or too few aligned periods
+	Metrics *types.PerformanceMetrics // nil if too few periods
+	Rolling *types.RollingSummary     // nil unless rolling statistics are enabled and the series spans a window
 }
 
-// AnalyzeBatch performs YoY analysis on multiple symbols using batch query
-// Returns statistics for each symbol that has YoY data
+// Progress is a snapshot of a running batch analysis
+type Progress struct {
+	Processed  int            // Symbols processed so far, including rejected ones
+	Total      int            // Symbols to process
+	Results    int            // Symbols with YoY data
+	Rejections map[string]int // Rejected symbols by reason
+}
+
+// Defaults of the batch pipeline, used when the config leaves the setting at zero
+const (
+	DefaultChunkSize       = 500 // Tickers whose prices are read per query
+	DefaultInsertBatchSize = 500 // Results written per COPY
+)
+
+// symbolTask is a symbol whose prices passed the rejection checks and wait for analysis
+type symbolTask struct {
+	ticker string
+	prices []types.PriceData
+}
+
+// AnalyzeBatch performs YoY analysis on multiple symbols as a pipeline: prices are read in
+// chunks of config.ChunkSize tickers, analyzed by config.Workers workers and, with SaveToDB,
+// written in batches of config.InsertBatchSize results. Only one or two chunks of prices are
+// held in memory at a time. Plots are not generated here, see GeneratePlots.
+// Returns statistics for each symbol that has YoY data. Progress is reported every few seconds
+// and once at the end through config.OnProgress; if ctx is cancelled the analysis stops early
+// and returns ctx.Err().
 func AnalyzeBatch(ctx context.Context, config AnalysisPackageConfig) ([]SymbolStats, error) {
-	logf("%s Starting batch analysis for %d symbols\n", config.PackageID, len(config.Tickers))
-	// Fetch all prices in a single batch query
-	pricesMap, err := db.GetPricesBatch(config.Tickers, config.TimeFrom, config.TimeTo, config.Interval)
-	if err != nil {
-		return nil, err
+	workers := config.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	chunkSize := config.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	insertBatchSize := config.InsertBatchSize
+	if insertBatchSize <= 0 {
+		insertBatchSize = DefaultInsertBatchSize
+	}
+	logf("%s Starting batch analysis for %d symbols (%d workers, chunks of %d)\n", config.PackageID, len(config.Tickers), workers, chunkSize)
+
+	// Fetch reference index prices once for beta/correlation/alpha, on the same return basis as the symbols
+	var indexPrices []types.PriceData
+	if config.ReferenceIndex != nil {
+		prices, err := db.GetPrices(*config.ReferenceIndex, config.TimeFrom, config.TimeTo, config.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get reference index prices for %s: %w", *config.ReferenceIndex, err)
+		}
+		indexPrices = WithReturnBasis(prices, config.ReturnBasis)
+		if len(indexPrices) == 0 {
+			return nil, fmt.Errorf("no price data for reference index %s", *config.ReferenceIndex)
+		}
+		logf("%s Loaded %d reference index prices for %s\n", config.PackageID, len(indexPrices), *config.ReferenceIndex)
+	}
+
+	// The pipeline stops on cancellation of ctx and on errors of any stage
+	pipelineCtx, stopPipeline := context.WithCancel(ctx)
+	defer stopPipeline()
+	var pipelineErr error
+	var errOnce sync.Once
+	fail := func(err error) {
+		errOnce.Do(func() {
+			pipelineErr = err
+			stopPipeline()
+		})
 	}
 
-	// Analyze each symbol in parallel
 	var mu sync.Mutex
-	var wg sync.WaitGroup
 	results := make([]SymbolStats, 0, len(config.Tickers))
 	processed := 0
 	totalTickers := len(config.Tickers)
+	rejectionReasons := make(map[string]int)
+
+	snapshot := func() Progress {
+		mu.Lock()
+		defer mu.Unlock()
+		progress := Progress{Processed: processed, Total: totalTickers, Results: len(results)}
+		progress.Rejections = make(map[string]int, len(rejectionReasons))
+		for reason, count := range rejectionReasons {
+			progress.Rejections[reason] = count
+		}
+		return progress
+	}
 
 	// Start a progress reporter goroutine
 	progressTicker := time.NewTicker(2 * time.Second)
 	defer progressTicker.Stop()
+	done := make(chan struct{})
+	defer close(done)
 
 	go func() {
-		for range progressTicker.C {
-			mu.Lock()
-			currentProcessed := processed
-			currentResults := len(results)
-			mu.Unlock()
+		for {
+			select {
+			case <-done:
+				return
+			case <-progressTicker.C:
+			}
 
-			if currentProcessed > 0 {
+			progress := snapshot()
+			if progress.Processed > 0 {
 				logf("%s Progress: %d/%d processed, %d results (%.1f%%)\n",
 					config.PackageID,
-					currentProcessed, totalTickers, currentResults, float64(currentProcessed)/float64(totalTickers)*100)
-
-				// Update package status in database with current progress
-				db.UpdateAnalysisPackageStatus(ctx, config.UserID, config.PackageID, "processing", currentResults)
+					progress.Processed, progress.Total, progress.Results, float64(progress.Processed)/float64(progress.Total)*100)
+			}
+			if config.OnProgress != nil {
+				config.OnProgress(progress)
 			}
 		}
 	}()
 
-	// Track rejection reasons
-	rejectionReasons := make(map[string]int)
-	var rejectionMu sync.Mutex
-
-	for _, ticker := range config.Tickers {
-		ticker := ticker // Capture for goroutine
-		prices, ok := pricesMap[ticker]
-
-		// Check rejection reasons
-		var rejected bool
-		var reason string
-
-		if !ok {
-			rejected = true
-			reason = "no_price_data"
-		} else if len(prices) == 0 {
-			rejected = true
-			reason = "empty_price_data"
-		} else if prices[0].Date.After(config.TimeFrom.AddDate(1, 0, 0)) {
-			rejected = true
-			reason = "insufficient_history"
-		}
+	tasks := make(chan symbolTask, workers*2)
+	analyzed := make(chan SymbolStats, workers*2)
 
-		if rejected {
-			rejectionMu.Lock()
-			rejectionReasons[reason]++
-			rejectionMu.Unlock()
-
-			mu.Lock()
-			processed++
-			mu.Unlock()
-			continue
-		}
-
-		wg.Add(1)
+	// Stage 2: workers analyze symbols
+	var workerWg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		workerWg.Add(1)
 		go func() {
-			defer wg.Done()
-
-			stats := AnalyzeYoY(prices, config.HistConfig)
-
-			// Only include symbols with YoY data
-			if stats.Count > 0 {
-				if config.PathPlots != "" {
-					if err = PlotChart(ChartOptions{
-						TimeFrom:   config.TimeFrom,
-						TimeTo:     config.TimeTo,
-						Ticker:     ticker,
-						Prices:     prices,
-						Stats:      stats,
-						OutputPath: filepath.Join(config.PathPlots, fmt.Sprintf("%s_%s.png", ticker, PlotTypeChart)),
-						LimitY:     true, // Default to limiting Y-axis
-					}); err != nil {
-						logf("%s ERROR: Failed to generate plot: %v\n", config.PackageID, err)
+			defer workerWg.Done()
+			for task := range tasks {
+				if pipelineCtx.Err() != nil {
+					continue // Drain
+				}
+				stats := AnalyzeYoYWithOutliers(task.prices, config.HistConfig, config.Outliers)
+				if stats.Count > 0 {
+					var beta *BetaStats
+					if indexPrices != nil {
+						beta = CalculateBeta(task.prices, indexPrices, config.Interval)
+					}
+					var rolling *types.RollingSummary
+					if config.RollingWindowMonths > 0 {
+						if series := RollingYoYStats(task.prices, config.RollingWindowMonths, 1); series != nil {
+							rolling = &series.RollingSummary
+						}
 					}
-					if err = PlotHistogram(ticker, stats, filepath.Join(config.PathPlots, fmt.Sprintf("%s_%s.png", ticker, PlotTypeHistogram))); err != nil {
-						logf("%s ERROR: Failed to generate histogram: %v\n", config.PackageID, err)
+					analyzed <- SymbolStats{
+						Ticker:  task.ticker,
+						Stats:   stats,
+						Beta:    beta,
+						Metrics: CalculateMetrics(task.prices, config.Interval, config.RiskFreeRate),
+						Rolling: rolling,
 					}
+				} else {
+					mu.Lock()
+					processed++
+					mu.Unlock()
 				}
+			}
+		}()
+	}
+
+	// Stage 3: a single writer collects results and saves them in batches
+	var writerWg sync.WaitGroup
+	writerWg.Add(1)
+	go func() {
+		defer writerWg.Done()
+		batch := make([]types.AnalysisResult, 0, insertBatchSize)
+		flush := func() {
+			if len(batch) == 0 || pipelineCtx.Err() != nil {
+				return
+			}
+			if err := db.SaveAnalysisResults(pipelineCtx, config.UserID, config.PackageID, batch); err != nil {
+				fail(fmt.Errorf("failed to save results: %w", err))
+			}
+			batch = batch[:0]
+		}
 
-				// Save to database immediately if requested
-				if config.SaveToDB {
-					histogramJSON, _ := json.Marshal(stats.Histogram)
-					db.SaveAnalysisResult(context.Background(),
-						config.UserID, config.PackageID, ticker,
-						stats.Count, stats.Mean, stats.StdDev, stats.Variance,
-						stats.Min, stats.Max, histogramJSON,
-					)
+		for stats := range analyzed {
+			if config.SaveToDB {
+				batch = append(batch, analysisResultFromStats(config.PackageID, stats))
+				if len(batch) >= insertBatchSize {
+					flush()
 				}
+			}
+			mu.Lock()
+			results = append(results, stats)
+			processed++
+			mu.Unlock()
+		}
+		flush()
+	}()
 
+	// Stage 1: read prices chunk by chunk and hand symbols to the workers
+	for _, chunk := range chunkTickers(config.Tickers, chunkSize) {
+		if pipelineCtx.Err() != nil {
+			break
+		}
+		pricesMap, err := db.GetPricesBatch(chunk, config.TimeFrom, config.TimeTo, config.Interval)
+		if err != nil {
+			fail(fmt.Errorf("failed to get prices: %w", err))
+			break
+		}
+
+		for _, ticker := range chunk {
+			prices, ok := pricesMap[ticker]
+			if reason := rejectionReason(prices, ok, config.TimeFrom); reason != "" {
 				mu.Lock()
-				results = append(results, SymbolStats{
-					Ticker: ticker,
-					Stats:  stats,
-				})
+				rejectionReasons[reason]++
+				processed++
 				mu.Unlock()
+				continue
 			}
 
-			mu.Lock()
-			processed++
-			mu.Unlock()
-		}()
+			select {
+			case tasks <- symbolTask{ticker: ticker, prices: WithReturnBasis(prices, config.ReturnBasis)}:
+			case <-pipelineCtx.Done():
+			}
+		}
 	}
 
-	wg.Wait()
+	close(tasks)
+	workerWg.Wait()
+	close(analyzed)
+	writerWg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		logf("%s Batch analysis cancelled after %d/%d symbols\n", config.PackageID, snapshot().Processed, len(config.Tickers))
+		return results, err
+	}
+	if pipelineErr != nil {
+		return results, pipelineErr
+	}
+	if config.OnProgress != nil {
+		config.OnProgress(snapshot())
+	}
 
 	logf("%s Batch analysis complete: %d/%d symbols with YoY data\n", config.PackageID, len(results), len(config.Tickers))
 
@@ -162,3 +270,120 @@ func AnalyzeBatch(ctx context.Context, config AnalysisPackageConfig) ([]SymbolSt
 
 	return results, nil
 }
+
+// GeneratePlots renders chart and histogram plots for the given tickers into config.PathPlots.
+// It is a separate stage after AnalyzeBatch that re-reads prices chunk by chunk; plot errors
+// are logged and skipped. Stops early if ctx is cancelled.
+func GeneratePlots(ctx context.Context, config AnalysisPackageConfig, tickers []string) error {
+	workers := config.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	chunkSize := config.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	logf("%s Generating plots for %d symbols\n", config.PackageID, len(tickers))
+
+	tasks := make(chan symbolTask, workers*2)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range tasks {
+				if ctx.Err() != nil {
+					continue
+				}
+				stats := AnalyzeYoYWithOutliers(task.prices, config.HistConfig, config.Outliers)
+				if err := PlotChart(ChartOptions{
+					TimeFrom:   config.TimeFrom,
+					TimeTo:     config.TimeTo,
+					Ticker:     task.ticker,
+					Prices:     task.prices,
+					Stats:      stats,
+					OutputPath: filepath.Join(config.PathPlots, fmt.Sprintf("%s_%s.png", task.ticker, PlotTypeChart)),
+					LimitY:     true, // Default to limiting Y-axis
+				}); err != nil {
+					logf("%s ERROR: Failed to generate plot: %v\n", config.PackageID, err)
+				}
+				if err := PlotHistogram(task.ticker, stats, filepath.Join(config.PathPlots, fmt.Sprintf("%s_%s.png", task.ticker, PlotTypeHistogram))); err != nil {
+					logf("%s ERROR: Failed to generate histogram: %v\n", config.PackageID, err)
+				}
+			}
+		}()
+	}
+
+	var err error
+	for _, chunk := range chunkTickers(tickers, chunkSize) {
+		if ctx.Err() != nil {
+			break
+		}
+		var pricesMap map[string][]types.PriceData
+		pricesMap, err = db.GetPricesBatch(chunk, config.TimeFrom, config.TimeTo, config.Interval)
+		if err != nil {
+			err = fmt.Errorf("failed to get prices: %w", err)
+			break
+		}
+		for _, ticker := range chunk {
+			if prices := pricesMap[ticker]; len(prices) > 0 {
+				tasks <- symbolTask{ticker: ticker, prices: WithReturnBasis(prices, config.ReturnBasis)}
+			}
+		}
+	}
+	close(tasks)
+	wg.Wait()
+
+	if err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// rejectionReason returns why a symbol cannot be analyzed, or "" if its prices are usable.
+// ok reports whether any prices were found for the symbol.
+func rejectionReason(prices []types.PriceData, ok bool, timeFrom time.Time) string {
+	switch {
+	case !ok:
+		return "no_price_data"
+	case len(prices) == 0:
+		return "empty_price_data"
+	case prices[0].Date.After(timeFrom.AddDate(1, 0, 0)):
+		return "insufficient_history"
+	}
+	return ""
+}
+
+// chunkTickers splits tickers into consecutive chunks of at most size tickers
+func chunkTickers(tickers []string, size int) [][]string {
+	var chunks [][]string
+	for start := 0; start < len(tickers); start += size {
+		chunks = append(chunks, tickers[start:min(start+size, len(tickers))])
+	}
+	return chunks
+}
+
+// analysisResultFromStats converts the statistics of a symbol into a result row of a package
+func analysisResultFromStats(packageID string, stats SymbolStats) types.AnalysisResult {
+	histogramJSON, _ := json.Marshal(stats.Stats.Histogram)
+	result := types.AnalysisResult{
+		PackageID:       packageID,
+		Ticker:          stats.Ticker,
+		Count:           stats.Stats.Count,
+		Mean:            stats.Stats.Mean,
+		StdDev:          stats.Stats.StdDev,
+		Variance:        stats.Stats.Variance,
+		Min:             stats.Stats.Min,
+		Max:             stats.Stats.Max,
+		Metrics:         stats.Metrics,
+		Rolling:         stats.Rolling,
+		Histogram:       histogramJSON,
+		OutliersRemoved: stats.Stats.OutliersRemoved,
+	}
+	if stats.Beta != nil {
+		result.Beta = &stats.Beta.Beta
+		result.Correlation = &stats.Beta.Correlation
+		result.Alpha = &stats.Beta.Alpha
+	}
+	return result
+}
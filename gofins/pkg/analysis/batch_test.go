@@ -0,0 +1,76 @@
+package analysis
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+func TestChunkTickers(t *testing.T) {
+	tickers := []string{"A", "B", "C", "D", "E"}
+
+	got := chunkTickers(tickers, 2)
+	want := [][]string{{"A", "B"}, {"C", "D"}, {"E"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("chunkTickers(5, 2) = %v, want %v", got, want)
+	}
+	if got := chunkTickers(tickers, 10); len(got) != 1 || len(got[0]) != 5 {
+		t.Errorf("chunkTickers(5, 10) = %v, want one chunk", got)
+	}
+	if got := chunkTickers(nil, 3); len(got) != 0 {
+		t.Errorf("chunkTickers(nil) = %v, want none", got)
+	}
+}
+
+func TestRejectionReason(t *testing.T) {
+	from := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	early := []types.PriceData{{Date: from.AddDate(0, 6, 0)}}
+	late := []types.PriceData{{Date: from.AddDate(2, 0, 0)}}
+
+	tests := []struct {
+		name   string
+		prices []types.PriceData
+		ok     bool
+		want   string
+	}{
+		{"missing", nil, false, "no_price_data"},
+		{"empty", []types.PriceData{}, true, "empty_price_data"},
+		{"starts too late", late, true, "insufficient_history"},
+		{"usable", early, true, ""},
+	}
+	for _, tt := range tests {
+		if got := rejectionReason(tt.prices, tt.ok, from); got != tt.want {
+			t.Errorf("%s: rejectionReason = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAnalysisResultFromStats(t *testing.T) {
+	stats := SymbolStats{
+		Ticker: "AAPL",
+		Stats: Stats{
+			Count: 10, Mean: 12, StdDev: 3, Variance: 9, Min: -5, Max: 30,
+			Histogram: []HistogramBin{{Min: 0, Max: 10, Count: 4}},
+		},
+		Beta: &BetaStats{Beta: 1.2, Correlation: 0.8, Alpha: 2.5},
+	}
+
+	result := analysisResultFromStats("pkg", stats)
+	if result.PackageID != "pkg" || result.Ticker != "AAPL" || result.Count != 10 || result.Mean != 12 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if result.Beta == nil || *result.Beta != 1.2 || *result.Correlation != 0.8 || *result.Alpha != 2.5 {
+		t.Errorf("beta fields not copied: %+v", result)
+	}
+	var bins []HistogramBin
+	if err := json.Unmarshal(result.Histogram, &bins); err != nil || len(bins) != 1 || bins[0].Count != 4 {
+		t.Errorf("histogram = %s (%v), want one bin with count 4", result.Histogram, err)
+	}
+
+	if result := analysisResultFromStats("pkg", SymbolStats{Ticker: "X"}); result.Beta != nil {
+		t.Error("expected nil beta without reference index")
+	}
+}
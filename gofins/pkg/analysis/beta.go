@@ -0,0 +1,96 @@
+package analysis
+
+import (
+	"math"
+
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+// minBetaPeriods is the minimum number of aligned return periods required to report beta
+const minBetaPeriods = 12
+
+// BetaStats describes how a symbol moves relative to a reference index
+type BetaStats struct {
+	Beta        float64
+	Correlation float64
+	Alpha       float64 // Annualized excess return over beta * index return, in percent
+	Count       int     // Number of aligned return periods
+}
+
+// PeriodsPerYear returns the number of price periods per year for an interval
+func PeriodsPerYear(interval types.PriceInterval) float64 {
+	if interval == types.IntervalMonthly {
+		return 12
+	}
+	return 52
+}
+
+// CalculateBeta computes beta, correlation and alpha of a symbol against a reference index.
+// Returns are close-to-close between consecutive symbol periods; a period only counts if the
+// index has a close on both of its dates, so gaps in either series don't skew the result.
+// Returns nil if there are too few aligned periods or the index has no variance.
+func CalculateBeta(prices, index []types.PriceData, interval types.PriceInterval) *BetaStats {
+	indexClose := make(map[string]float64, len(index))
+	for _, p := range index {
+		if p.Close > 0 {
+			indexClose[p.Date.Format("2006-01-02")] = p.Close
+		}
+	}
+
+	symbolReturns := make([]float64, 0, len(prices))
+	indexReturns := make([]float64, 0, len(prices))
+	for i := 1; i < len(prices); i++ {
+		prev, cur := prices[i-1], prices[i]
+		if prev.Close <= 0 || cur.Close <= 0 {
+			continue
+		}
+		indexPrev, ok := indexClose[prev.Date.Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		indexCur, ok := indexClose[cur.Date.Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		symbolReturns = append(symbolReturns, cur.Close/prev.Close-1)
+		indexReturns = append(indexReturns, indexCur/indexPrev-1)
+	}
+
+	n := len(symbolReturns)
+	if n < minBetaPeriods {
+		return nil
+	}
+
+	meanSymbol, meanIndex := 0.0, 0.0
+	for i := 0; i < n; i++ {
+		meanSymbol += symbolReturns[i]
+		meanIndex += indexReturns[i]
+	}
+	meanSymbol /= float64(n)
+	meanIndex /= float64(n)
+
+	covariance, varSymbol, varIndex := 0.0, 0.0, 0.0
+	for i := 0; i < n; i++ {
+		ds := symbolReturns[i] - meanSymbol
+		di := indexReturns[i] - meanIndex
+		covariance += ds * di
+		varSymbol += ds * ds
+		varIndex += di * di
+	}
+	if varIndex == 0 {
+		return nil
+	}
+
+	beta := covariance / varIndex
+	correlation := 0.0
+	if varSymbol > 0 {
+		correlation = covariance / math.Sqrt(varSymbol*varIndex)
+	}
+
+	return &BetaStats{
+		Beta:        beta,
+		Correlation: correlation,
+		Alpha:       (meanSymbol - beta*meanIndex) * PeriodsPerYear(interval) * 100,
+		Count:       n,
+	}
+}
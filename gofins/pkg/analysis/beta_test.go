@@ -0,0 +1,105 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+func makeMonthlySeries(closes []float64) []types.PriceData {
+	start := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	prices := make([]types.PriceData, len(closes))
+	for i, c := range closes {
+		prices[i] = types.PriceData{Date: start.AddDate(0, i, 0), Close: c}
+	}
+	return prices
+}
+
+func TestCalculateBeta(t *testing.T) {
+	// Index alternates +2% / -1%, symbol moves exactly twice as much
+	indexCloses := []float64{100}
+	symbolCloses := []float64{50}
+	for i := 0; i < 24; i++ {
+		r := 0.02
+		if i%2 == 1 {
+			r = -0.01
+		}
+		indexCloses = append(indexCloses, indexCloses[len(indexCloses)-1]*(1+r))
+		symbolCloses = append(symbolCloses, symbolCloses[len(symbolCloses)-1]*(1+2*r))
+	}
+
+	stats := CalculateBeta(makeMonthlySeries(symbolCloses), makeMonthlySeries(indexCloses), types.IntervalMonthly)
+	if stats == nil {
+		t.Fatal("expected beta stats, got nil")
+	}
+	if math.Abs(stats.Beta-2) > 1e-9 {
+		t.Errorf("Beta = %v, want 2", stats.Beta)
+	}
+	if math.Abs(stats.Correlation-1) > 1e-9 {
+		t.Errorf("Correlation = %v, want 1", stats.Correlation)
+	}
+	if math.Abs(stats.Alpha) > 1e-9 {
+		t.Errorf("Alpha = %v, want 0", stats.Alpha)
+	}
+	if stats.Count != 24 {
+		t.Errorf("Count = %d, want 24", stats.Count)
+	}
+}
+
+func TestCalculateBetaSkipsUnalignedPeriods(t *testing.T) {
+	closes := make([]float64, 20)
+	for i := range closes {
+		closes[i] = 100 + float64(i%3)
+	}
+	symbol := makeMonthlySeries(closes)
+	// Index only covers the first 10 months -> 9 aligned returns, below the minimum
+	index := makeMonthlySeries(closes[:10])
+
+	if stats := CalculateBeta(symbol, index, types.IntervalMonthly); stats != nil {
+		t.Errorf("expected nil for insufficient overlap, got %+v", stats)
+	}
+}
+
+func TestCalculateBetaTotalReturnBasis(t *testing.T) {
+	// On the total-return basis the symbol moves exactly twice as much as the index. The index
+	// pays a dividend every other month, so its price closes lag its total-return index.
+	indexTR := []float64{100}
+	symbolTR := []float64{50}
+	for i := 0; i < 24; i++ {
+		r := 0.02
+		if i%2 == 1 {
+			r = -0.01
+		}
+		indexTR = append(indexTR, indexTR[len(indexTR)-1]*(1+r))
+		symbolTR = append(symbolTR, symbolTR[len(symbolTR)-1]*(1+2*r))
+	}
+	index := makeMonthlySeries(indexTR)
+	symbol := makeMonthlySeries(symbolTR)
+	for i := range index {
+		closeTR := index[i].Close
+		index[i].CloseTR = &closeTR
+		index[i].Close = closeTR * math.Pow(0.99, float64(i/2))
+		symbolCloseTR := symbol[i].Close
+		symbol[i].CloseTR = &symbolCloseTR
+	}
+
+	symbolTotalReturn := WithReturnBasis(symbol, types.ReturnBasisTotalReturn)
+	stats := CalculateBeta(symbolTotalReturn, WithReturnBasis(index, types.ReturnBasisTotalReturn), types.IntervalMonthly)
+	if stats == nil {
+		t.Fatal("expected beta stats, got nil")
+	}
+	if math.Abs(stats.Beta-2) > 1e-9 {
+		t.Errorf("Beta = %v, want 2", stats.Beta)
+	}
+	if math.Abs(stats.Correlation-1) > 1e-9 {
+		t.Errorf("Correlation = %v, want 1", stats.Correlation)
+	}
+
+	// Against the index price closes the dividends distort the result
+	mixed := CalculateBeta(symbolTotalReturn, index, types.IntervalMonthly)
+	if mixed == nil || math.Abs(mixed.Beta-2) < 1e-3 && math.Abs(mixed.Alpha) < 1e-3 {
+		t.Errorf("expected a different beta or alpha against price closes, got %+v", mixed)
+	}
+}
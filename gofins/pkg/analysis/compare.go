@@ -0,0 +1,100 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+// ComparePackages diffs the results of two packages: tickers entering (only in other) and
+// leaving (only in base) the universe, and rank and statistic changes of the tickers in both.
+// Both packages are ranked independently with ScoreResults under the given profile.
+func ComparePackages(baseID string, base []types.AnalysisResult, otherID string, other []types.AnalysisResult, profile types.ScoringProfile) types.PackageComparison {
+	baseScored := ScoreResults(base, profile)
+	otherScored := ScoreResults(other, profile)
+
+	baseByTicker := make(map[string]types.ScoredResult, len(baseScored))
+	for _, r := range baseScored {
+		baseByTicker[r.Ticker] = r
+	}
+	otherTickers := make(map[string]bool, len(otherScored))
+	for _, r := range otherScored {
+		otherTickers[r.Ticker] = true
+	}
+
+	comparison := types.PackageComparison{
+		BasePackageID:  baseID,
+		OtherPackageID: otherID,
+		Profile:        profile,
+		Entered:        []types.RankedTicker{},
+		Left:           []types.RankedTicker{},
+		Changes:        []types.ResultChange{},
+	}
+
+	// Scored results are ordered by rank, so all lists come out ordered by rank too
+	for _, o := range otherScored {
+		b, ok := baseByTicker[o.Ticker]
+		if !ok {
+			comparison.Entered = append(comparison.Entered, types.RankedTicker{Ticker: o.Ticker, Rank: o.Rank})
+			continue
+		}
+		comparison.Changes = append(comparison.Changes, types.ResultChange{
+			Ticker:     o.Ticker,
+			RankBase:   b.Rank,
+			RankOther:  o.Rank,
+			RankChange: b.Rank - o.Rank,
+			Mean:       valueDelta(b.Mean, o.Mean),
+			StdDev:     valueDelta(b.StdDev, o.StdDev),
+			Min:        valueDelta(b.Min, o.Min),
+			Max:        valueDelta(b.Max, o.Max),
+		})
+	}
+	for _, b := range baseScored {
+		if !otherTickers[b.Ticker] {
+			comparison.Left = append(comparison.Left, types.RankedTicker{Ticker: b.Ticker, Rank: b.Rank})
+		}
+	}
+
+	summary := types.ComparisonStats{
+		BaseCount:  len(base),
+		OtherCount: len(other),
+		Common:     len(comparison.Changes),
+		Entered:    len(comparison.Entered),
+		Left:       len(comparison.Left),
+	}
+	for _, c := range comparison.Changes {
+		switch {
+		case c.RankChange > 0:
+			summary.MovedUp++
+		case c.RankChange < 0:
+			summary.MovedDown++
+		}
+		summary.MeanDelta += c.Mean.Delta
+		summary.StdDevDelta += c.StdDev.Delta
+	}
+	if summary.Common > 0 {
+		summary.MeanDelta /= float64(summary.Common)
+		summary.StdDevDelta /= float64(summary.Common)
+	}
+	comparison.Summary = summary
+
+	return comparison
+}
+
+// SortChangesByRankChange orders changes by the size of their rank change, largest first
+func SortChangesByRankChange(changes []types.ResultChange) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		return abs(changes[i].RankChange) > abs(changes[j].RankChange)
+	})
+}
+
+func valueDelta(base, other float64) types.ValueDelta {
+	return types.ValueDelta{Base: base, Other: other, Delta: other - base}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
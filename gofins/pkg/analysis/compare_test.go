@@ -0,0 +1,66 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+func TestComparePackages(t *testing.T) {
+	profile := types.ScoringProfile{WeightMean: 1, Normalization: types.NormalizationRank}
+	base := []types.AnalysisResult{
+		{Ticker: "AAA", Mean: 10, StdDev: 5, Min: -2, Max: 20},
+		{Ticker: "BBB", Mean: 8, StdDev: 4},
+		{Ticker: "OLD", Mean: 1},
+	}
+	other := []types.AnalysisResult{
+		{Ticker: "AAA", Mean: 6, StdDev: 7, Min: -4, Max: 18},
+		{Ticker: "BBB", Mean: 9, StdDev: 4},
+		{Ticker: "NEW", Mean: 3},
+	}
+
+	comparison := ComparePackages("base", base, "other", other, profile)
+
+	if len(comparison.Entered) != 1 || comparison.Entered[0] != (types.RankedTicker{Ticker: "NEW", Rank: 3}) {
+		t.Errorf("Entered = %+v, want NEW at rank 3", comparison.Entered)
+	}
+	if len(comparison.Left) != 1 || comparison.Left[0] != (types.RankedTicker{Ticker: "OLD", Rank: 3}) {
+		t.Errorf("Left = %+v, want OLD at rank 3", comparison.Left)
+	}
+	if len(comparison.Changes) != 2 {
+		t.Fatalf("Changes = %+v, want 2", comparison.Changes)
+	}
+
+	// Ordered by rank in the other package: BBB moved up to 1, AAA down to 2
+	bbb, aaa := comparison.Changes[0], comparison.Changes[1]
+	if bbb.Ticker != "BBB" || bbb.RankBase != 2 || bbb.RankOther != 1 || bbb.RankChange != 1 {
+		t.Errorf("BBB change = %+v", bbb)
+	}
+	if aaa.Ticker != "AAA" || aaa.RankChange != -1 {
+		t.Errorf("AAA change = %+v", aaa)
+	}
+	if aaa.Mean != (types.ValueDelta{Base: 10, Other: 6, Delta: -4}) || aaa.StdDev.Delta != 2 || aaa.Min.Delta != -2 || aaa.Max.Delta != -2 {
+		t.Errorf("AAA deltas = %+v", aaa)
+	}
+
+	summary := comparison.Summary
+	if summary.Common != 2 || summary.Entered != 1 || summary.Left != 1 || summary.MovedUp != 1 || summary.MovedDown != 1 {
+		t.Errorf("Summary = %+v", summary)
+	}
+	if math.Abs(summary.MeanDelta-(-1.5)) > 1e-9 || math.Abs(summary.StdDevDelta-1) > 1e-9 {
+		t.Errorf("Summary deltas = %v / %v, want -1.5 / 1", summary.MeanDelta, summary.StdDevDelta)
+	}
+}
+
+func TestSortChangesByRankChange(t *testing.T) {
+	changes := []types.ResultChange{
+		{Ticker: "A", RankChange: 1},
+		{Ticker: "B", RankChange: -5},
+		{Ticker: "C", RankChange: 3},
+	}
+	SortChangesByRankChange(changes)
+	if changes[0].Ticker != "B" || changes[1].Ticker != "C" || changes[2].Ticker != "A" {
+		t.Errorf("order = %s, %s, %s, want B, C, A", changes[0].Ticker, changes[1].Ticker, changes[2].Ticker)
+	}
+}
@@ -0,0 +1,161 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+// minMetricsPeriods is the minimum number of period returns required to report metrics
+const minMetricsPeriods = 12
+
+// CalculateMetrics computes risk/return metrics of a price series sorted by date.
+// Return-based metrics (CAGR, drawdown, volatility, Sharpe, Sortino) use closes, the
+// positive share and percentile bands use the YoY values of the series as given; pass
+// prices through WithReturnBasis to compute both on the total-return basis.
+// riskFreeRate is the annual risk-free rate in percent. Returns nil if there are too few periods.
+func CalculateMetrics(prices []types.PriceData, interval types.PriceInterval, riskFreeRate float64) *types.PerformanceMetrics {
+	series := make([]types.PriceData, 0, len(prices))
+	for _, p := range prices {
+		if p.Close > 0 {
+			series = append(series, p)
+		}
+	}
+	if len(series)-1 < minMetricsPeriods {
+		return nil
+	}
+
+	periodsPerYear := PeriodsPerYear(interval)
+	riskFreePerPeriod := math.Pow(1+riskFreeRate/100, 1/periodsPerYear) - 1
+
+	returns := make([]float64, len(series)-1)
+	for i := 1; i < len(series); i++ {
+		returns[i-1] = series[i].Close/series[i-1].Close - 1
+	}
+
+	metrics := &types.PerformanceMetrics{
+		Periods:      len(returns),
+		RiskFreeRate: riskFreeRate,
+	}
+
+	first, last := series[0], series[len(series)-1]
+	years := last.Date.Sub(first.Date).Hours() / 24 / 365.25
+	if years >= 1 {
+		metrics.CAGR = f.Ptr((math.Pow(last.Close/first.Close, 1/years) - 1) * 100)
+	}
+
+	applyMaxDrawdown(series, metrics)
+
+	// Volatility is the plain standard deviation of returns, downside deviation only
+	// counts shortfalls below the risk-free rate (over all periods, not just the negative ones)
+	n := float64(len(returns))
+	mean, meanExcess := 0.0, 0.0
+	for _, r := range returns {
+		mean += r
+		meanExcess += r - riskFreePerPeriod
+	}
+	mean /= n
+	meanExcess /= n
+
+	sumSquaredDiff, sumSquaredShortfall := 0.0, 0.0
+	for _, r := range returns {
+		sumSquaredDiff += (r - mean) * (r - mean)
+		if shortfall := r - riskFreePerPeriod; shortfall < 0 {
+			sumSquaredShortfall += shortfall * shortfall
+		}
+	}
+	annualize := math.Sqrt(periodsPerYear)
+	volatility := math.Sqrt(sumSquaredDiff/n) * annualize
+	downside := math.Sqrt(sumSquaredShortfall/n) * annualize
+	annualExcess := meanExcess * periodsPerYear
+
+	metrics.Volatility = volatility * 100
+	metrics.DownsideDeviation = downside * 100
+	if volatility > 0 {
+		metrics.Sharpe = f.Ptr(annualExcess / volatility)
+	}
+	if downside > 0 {
+		metrics.Sortino = f.Ptr(annualExcess / downside)
+	}
+
+	var yoy []float64
+	for _, p := range series {
+		if p.YoY != nil && !math.IsNaN(*p.YoY) && !math.IsInf(*p.YoY, 0) {
+			yoy = append(yoy, *p.YoY)
+		}
+	}
+	if len(yoy) > 0 {
+		positive := 0
+		for _, v := range yoy {
+			if v > 0 {
+				positive++
+			}
+		}
+		metrics.PositiveYoY = f.Ptr(float64(positive) / float64(len(yoy)) * 100)
+		metrics.YoYPercentiles = CalculatePercentileBands(yoy)
+	}
+
+	return metrics
+}
+
+// applyMaxDrawdown finds the largest peak-to-trough decline of the series and how long it
+// lasted from the peak until the close regained the peak level (or until the last date)
+func applyMaxDrawdown(series []types.PriceData, metrics *types.PerformanceMetrics) {
+	peak := 0
+	maxDrawdown := 0.0
+	peakIdx, troughIdx := -1, -1
+	for i, p := range series {
+		if p.Close > series[peak].Close {
+			peak = i
+			continue
+		}
+		if drawdown := 1 - p.Close/series[peak].Close; drawdown > maxDrawdown {
+			maxDrawdown = drawdown
+			peakIdx, troughIdx = peak, i
+		}
+	}
+	if peakIdx < 0 {
+		return
+	}
+
+	end := series[len(series)-1].Date
+	for _, p := range series[troughIdx+1:] {
+		if p.Close >= series[peakIdx].Close {
+			end = p.Date
+			metrics.Recovered = true
+			break
+		}
+	}
+
+	metrics.MaxDrawdown = maxDrawdown * 100
+	metrics.MaxDrawdownDays = int(math.Round(end.Sub(series[peakIdx].Date).Hours() / 24))
+	metrics.MaxDrawdownPeak = f.Ptr(series[peakIdx].Date)
+	metrics.MaxDrawdownTrough = f.Ptr(series[troughIdx].Date)
+}
+
+// CalculatePercentileBands returns the 5th, 25th, 50th, 75th and 95th percentile of values.
+// values must not be empty; it is not modified.
+func CalculatePercentileBands(values []float64) *types.PercentileBands {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return &types.PercentileBands{
+		P5:  percentile(sorted, 0.05),
+		P25: percentile(sorted, 0.25),
+		P50: percentile(sorted, 0.50),
+		P75: percentile(sorted, 0.75),
+		P95: percentile(sorted, 0.95),
+	}
+}
+
+// percentile linearly interpolates the q-quantile (0..1) of sorted values
+func percentile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
@@ -0,0 +1,139 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+func TestCalculateMetricsSteadyGrowth(t *testing.T) {
+	// 1% per month for two years: no drawdown, no volatility
+	closes := []float64{100}
+	for i := 0; i < 24; i++ {
+		closes = append(closes, closes[len(closes)-1]*1.01)
+	}
+
+	metrics := CalculateMetrics(makeMonthlySeries(closes), types.IntervalMonthly, 0)
+	if metrics == nil {
+		t.Fatal("expected metrics, got nil")
+	}
+	if metrics.Periods != 24 {
+		t.Errorf("Periods = %d, want 24", metrics.Periods)
+	}
+	if metrics.CAGR == nil || math.Abs(*metrics.CAGR-12.68) > 0.05 {
+		t.Errorf("CAGR = %v, want ~12.68", metrics.CAGR)
+	}
+	if metrics.MaxDrawdown != 0 || metrics.MaxDrawdownPeak != nil {
+		t.Errorf("MaxDrawdown = %v (peak %v), want none", metrics.MaxDrawdown, metrics.MaxDrawdownPeak)
+	}
+	if metrics.Sharpe != nil {
+		t.Errorf("Sharpe = %v, want nil without volatility", *metrics.Sharpe)
+	}
+	if metrics.Sortino != nil {
+		t.Errorf("Sortino = %v, want nil without downside", *metrics.Sortino)
+	}
+}
+
+func TestCalculateMetricsDrawdown(t *testing.T) {
+	closes := []float64{100, 110, 120, 90, 60, 80, 100, 125, 130, 120, 140, 150, 160, 170}
+	prices := makeMonthlySeries(closes)
+
+	metrics := CalculateMetrics(prices, types.IntervalMonthly, 2)
+	if metrics == nil {
+		t.Fatal("expected metrics, got nil")
+	}
+	if math.Abs(metrics.MaxDrawdown-50) > 1e-9 {
+		t.Errorf("MaxDrawdown = %v, want 50", metrics.MaxDrawdown)
+	}
+	if !metrics.MaxDrawdownPeak.Equal(prices[2].Date) || !metrics.MaxDrawdownTrough.Equal(prices[4].Date) {
+		t.Errorf("drawdown %v -> %v, want %v -> %v", metrics.MaxDrawdownPeak, metrics.MaxDrawdownTrough, prices[2].Date, prices[4].Date)
+	}
+	// Recovered in month 7 (125 >= 120)
+	if !metrics.Recovered {
+		t.Error("expected drawdown to be recovered")
+	}
+	wantDays := int(prices[7].Date.Sub(prices[2].Date).Hours() / 24)
+	if metrics.MaxDrawdownDays != wantDays {
+		t.Errorf("MaxDrawdownDays = %d, want %d", metrics.MaxDrawdownDays, wantDays)
+	}
+	if metrics.Sharpe == nil || metrics.Sortino == nil {
+		t.Fatal("expected Sharpe and Sortino")
+	}
+	if *metrics.Sortino <= *metrics.Sharpe {
+		t.Errorf("Sortino %v should exceed Sharpe %v for a mostly rising series", *metrics.Sortino, *metrics.Sharpe)
+	}
+	if metrics.DownsideDeviation <= 0 || metrics.DownsideDeviation >= metrics.Volatility {
+		t.Errorf("DownsideDeviation = %v, want between 0 and volatility %v", metrics.DownsideDeviation, metrics.Volatility)
+	}
+}
+
+func TestCalculateMetricsYoY(t *testing.T) {
+	prices := makeMonthlySeries([]float64{10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22})
+	yoy := []float64{-10, -5, 0, 5, 10, 15, 20, 25, 30, 35, 40}
+	for i, v := range yoy {
+		prices[i+2].YoY = f.Ptr(v)
+	}
+
+	metrics := CalculateMetrics(prices, types.IntervalMonthly, 0)
+	if metrics == nil {
+		t.Fatal("expected metrics, got nil")
+	}
+	if metrics.PositiveYoY == nil || math.Abs(*metrics.PositiveYoY-8.0/11*100) > 1e-9 {
+		t.Errorf("PositiveYoY = %v, want %v", metrics.PositiveYoY, 8.0/11*100)
+	}
+	bands := metrics.YoYPercentiles
+	if bands == nil {
+		t.Fatal("expected percentile bands")
+	}
+	if bands.P50 != 15 || bands.P25 != 2.5 || bands.P75 != 27.5 {
+		t.Errorf("bands = %+v, want P25 2.5, P50 15, P75 27.5", *bands)
+	}
+	if math.Abs(bands.P5-(-7.5)) > 1e-9 || math.Abs(bands.P95-37.5) > 1e-9 {
+		t.Errorf("bands = %+v, want P5 -7.5, P95 37.5", *bands)
+	}
+}
+
+func TestCalculateMetricsTotalReturnBasis(t *testing.T) {
+	// A dividend payer whose price goes sideways and pays 1% each quarter
+	closes := []float64{100}
+	for i := 1; i <= 24; i++ {
+		closes = append(closes, 100+float64(i%3)*2)
+	}
+	prices := makeMonthlySeries(closes)
+	reinvested := 1.0
+	for i := range prices {
+		if i > 0 && i%3 == 0 {
+			reinvested *= 1.01
+		}
+		prices[i].CloseTR = f.Ptr(prices[i].Close * reinvested)
+	}
+
+	priceMetrics := CalculateMetrics(WithReturnBasis(prices, types.ReturnBasisPrice), types.IntervalMonthly, 0)
+	trMetrics := CalculateMetrics(WithReturnBasis(prices, types.ReturnBasisTotalReturn), types.IntervalMonthly, 0)
+	if priceMetrics == nil || trMetrics == nil {
+		t.Fatal("expected metrics on both bases")
+	}
+	if priceMetrics.CAGR == nil || math.Abs(*priceMetrics.CAGR) > 1e-9 {
+		t.Errorf("price CAGR = %v, want 0", priceMetrics.CAGR)
+	}
+	if trMetrics.CAGR == nil || math.Abs(*trMetrics.CAGR-4.06) > 0.01 {
+		t.Errorf("total-return CAGR = %v, want ~4.06", trMetrics.CAGR)
+	}
+	if trMetrics.MaxDrawdown >= priceMetrics.MaxDrawdown {
+		t.Errorf("total-return drawdown %v should be below the price drawdown %v", trMetrics.MaxDrawdown, priceMetrics.MaxDrawdown)
+	}
+	if priceMetrics.Sharpe == nil || trMetrics.Sharpe == nil || *trMetrics.Sharpe <= *priceMetrics.Sharpe {
+		t.Errorf("total-return Sharpe %v should exceed the price Sharpe %v", trMetrics.Sharpe, priceMetrics.Sharpe)
+	}
+	if trMetrics.Volatility == priceMetrics.Volatility {
+		t.Errorf("volatility %v is the same on both bases", trMetrics.Volatility)
+	}
+}
+
+func TestCalculateMetricsTooShort(t *testing.T) {
+	if metrics := CalculateMetrics(makeMonthlySeries([]float64{1, 2, 3}), types.IntervalMonthly, 0); metrics != nil {
+		t.Errorf("expected nil for short series, got %+v", metrics)
+	}
+}
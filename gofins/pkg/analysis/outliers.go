@@ -1,46 +1,153 @@
 package analysis
 
 import (
+	"fmt"
 	"math"
+	"slices"
 	"sort"
+	"strings"
+
+	"github.com/flocko-motion/gofins/pkg/types"
 )
 
-// BalancedYoYOutlierRemoval demonstrates the recommended approach for YoY data.
-// This combines multiple strategies for robust outlier handling.
-func BalancedYoYOutlierRemoval(yoyPercentages []float64) []float64 {
-	// Strategy 1: First remove impossible values (e.g., > 10000% or < -100%)
-	// YoY can't be less than -100% (complete loss) and values > 10000% are likely errors
-	filtered := RemoveOutliersAbsolute(yoyPercentages, -100.0, 10000.0)
+// Default parameters of the outlier strategies (see types.OutlierConfig)
+const (
+	defaultOutlierAbsMin      = -100.0  // YoY can't be less than -100% (complete loss)
+	defaultOutlierAbsMax      = 10000.0 // Values above are likely errors
+	conservativeOutlierAbsMax = 50000.0
+	defaultIQRMultiplier      = 1.5
+	conservativeIQRMultiplier = 3.0
+	defaultOutlierStdDevs     = 3.0
+	defaultOutlierPercentile  = 90
+	defaultWinsorLow          = 5
+	defaultWinsorHigh         = 95
+)
 
-	// Strategy 2: Then use IQR method to remove statistical outliers
-	// Using multiplier=1.5 for moderate outlier removal
-	// This handles cases where prices near zero cause extreme but "valid" percentages
-	filtered = RemoveOutliersIQR(filtered, 1.5)
+// outlierStrategies are the valid values of types.OutlierConfig.Strategy
+var outlierStrategies = []string{
+	types.OutlierBalanced, types.OutlierConservative, types.OutlierIQR, types.OutlierStdDev,
+	types.OutlierPercentile, types.OutlierWinsorize, types.OutlierNone,
+}
 
+// ValidateOutlierConfig checks the strategy and the ranges of its parameters
+func ValidateOutlierConfig(config types.OutlierConfig) error {
+	if config.Strategy != "" && !slices.Contains(outlierStrategies, config.Strategy) {
+		return fmt.Errorf("invalid outlier strategy '%s' (must be one of %s)", config.Strategy, strings.Join(outlierStrategies, ", "))
+	}
+	if config.IQRMultiplier < 0 {
+		return fmt.Errorf("invalid IQR multiplier %v (must be positive)", config.IQRMultiplier)
+	}
+	if config.StdDevs < 0 {
+		return fmt.Errorf("invalid number of standard deviations %v (must be positive)", config.StdDevs)
+	}
+	if config.AbsMin != nil && config.AbsMax != nil && *config.AbsMin >= *config.AbsMax {
+		return fmt.Errorf("invalid absolute bounds: min %v must be below max %v", *config.AbsMin, *config.AbsMax)
+	}
+	if config.Percentile < 0 || config.Percentile >= 100 {
+		return fmt.Errorf("invalid percentile %d (must be between 1 and 99)", config.Percentile)
+	}
+	if low, high := winsorBounds(config); config.WinsorLow < 0 || config.WinsorHigh < 0 || high > 100 || low >= high {
+		return fmt.Errorf("invalid winsor percentiles %d/%d (need 0 <= low < high <= 100)", low, high)
+	}
+	return nil
+}
+
+// winsorBounds returns the winsorize percentiles, each defaulting on its own if zero
+func winsorBounds(config types.OutlierConfig) (low, high int) {
+	low, high = config.WinsorLow, config.WinsorHigh
+	if low == 0 {
+		low = defaultWinsorLow
+	}
+	if high == 0 {
+		high = defaultWinsorHigh
+	}
+	return low, high
+}
+
+// RemoveYoYOutliers filters YoY percentages with the configured strategy. Returns the values to
+// analyze and how many values were removed, or capped in the case of winsorize.
+func RemoveYoYOutliers(yoyPercentages []float64, config types.OutlierConfig) ([]float64, int) {
+	strategy := config.Strategy
+	if strategy == "" {
+		strategy = types.OutlierBalanced
+	}
+	if strategy == types.OutlierNone {
+		return yoyPercentages, 0
+	}
+
+	// Impossible values go first, whatever the strategy
+	absMin, absMax := defaultOutlierAbsMin, defaultOutlierAbsMax
+	if strategy == types.OutlierConservative {
+		absMax = conservativeOutlierAbsMax
+	}
+	if config.AbsMin != nil {
+		absMin = *config.AbsMin
+	}
+	if config.AbsMax != nil {
+		absMax = *config.AbsMax
+	}
+	filtered := RemoveOutliersAbsolute(yoyPercentages, absMin, absMax)
+
+	switch strategy {
+	case types.OutlierBalanced, types.OutlierConservative, types.OutlierIQR:
+		multiplier := config.IQRMultiplier
+		if multiplier == 0 {
+			multiplier = defaultIQRMultiplier
+			if strategy == types.OutlierConservative {
+				multiplier = conservativeIQRMultiplier
+			}
+		}
+		filtered = RemoveOutliersIQR(filtered, multiplier)
+	case types.OutlierStdDev:
+		stdDevs := config.StdDevs
+		if stdDevs == 0 {
+			stdDevs = defaultOutlierStdDevs
+		}
+		filtered = RemoveOutliersStdDev(filtered, stdDevs)
+	case types.OutlierPercentile:
+		percentile := config.Percentile
+		if percentile == 0 {
+			percentile = defaultOutlierPercentile
+		}
+		filtered = RemoveOutliers(filtered, percentile)
+	case types.OutlierWinsorize:
+		low, high := winsorBounds(config)
+		removed := len(yoyPercentages) - len(filtered)
+		winsorized := WinsorizeOutliers(filtered, low, high)
+		for i := range winsorized {
+			if winsorized[i] != filtered[i] {
+				removed++
+			}
+		}
+		return winsorized, removed
+	}
+
+	return filtered, len(yoyPercentages) - len(filtered)
+}
+
+// BalancedYoYOutlierRemoval demonstrates the recommended approach for YoY data.
+// This combines multiple strategies for robust outlier handling: impossible values
+// (< -100% or > 10000%) are removed first, then statistical outliers by IQR (multiplier 1.5),
+// which handles cases where prices near zero cause extreme but "valid" percentages.
+func BalancedYoYOutlierRemoval(yoyPercentages []float64) []float64 {
+	filtered, _ := RemoveYoYOutliers(yoyPercentages, types.OutlierConfig{Strategy: types.OutlierBalanced})
 	return filtered
 }
 
 // ConservativeYoYOutlierRemoval uses a more conservative approach.
-// Only removes the most extreme outliers.
+// Only removes the most extreme outliers (wider absolute bounds, IQR multiplier 3).
 func ConservativeYoYOutlierRemoval(yoyPercentages []float64) []float64 {
-	// Use absolute bounds with wider range
-	filtered := RemoveOutliersAbsolute(yoyPercentages, -100.0, 50000.0)
-
-	// Use IQR with higher multiplier (only extreme outliers)
-	filtered = RemoveOutliersIQR(filtered, 3.0)
-
+	filtered, _ := RemoveYoYOutliers(yoyPercentages, types.OutlierConfig{Strategy: types.OutlierConservative})
 	return filtered
 }
 
 // WinsorizedYoYData caps extreme values instead of removing them.
 // This preserves data points while limiting their impact on statistics.
 // Good when you want to keep all data points but reduce outlier influence.
+// Impossible values are removed first, then values are winsorized at the 5th and 95th percentiles.
 func WinsorizedYoYData(yoyPercentages []float64) []float64 {
-	// First apply absolute bounds
-	filtered := RemoveOutliersAbsolute(yoyPercentages, -100.0, 10000.0)
-
-	// Then winsorize at 5th and 95th percentiles
-	return WinsorizeOutliers(filtered, 5, 95)
+	filtered, _ := RemoveYoYOutliers(yoyPercentages, types.OutlierConfig{Strategy: types.OutlierWinsorize})
+	return filtered
 }
 
 // RemoveOutliers removes the specified percentile of values from both ends of the array.
@@ -1,7 +1,11 @@
 package analysis
 
 import (
+	"slices"
 	"testing"
+
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
 )
 
 func TestOutlierMethods(t *testing.T) {
@@ -129,3 +133,112 @@ func TestWinsorization(t *testing.T) {
 
 	t.Logf("Original max: 100, Winsorized max: %f", maxVal)
 }
+
+func TestRemoveYoYOutliersStrategies(t *testing.T) {
+	values := []float64{-150, 5, 8, 10, 12, 15, 18, 20, 25, 300, 20000}
+
+	cases := []struct {
+		name    string
+		config  types.OutlierConfig
+		removed int
+	}{
+		{"default is balanced", types.OutlierConfig{}, 3}, // -150 and 20000 by bounds, 300 by IQR
+		{"balanced", types.OutlierConfig{Strategy: types.OutlierBalanced}, 3},
+		{"conservative keeps moderate outliers", types.OutlierConfig{Strategy: types.OutlierConservative}, 3},
+		{"none", types.OutlierConfig{Strategy: types.OutlierNone}, 0},
+		{"custom bounds", types.OutlierConfig{Strategy: types.OutlierIQR, IQRMultiplier: 100, AbsMax: f.Ptr(1000.0)}, 2},
+		{"stddev", types.OutlierConfig{Strategy: types.OutlierStdDev, StdDevs: 2}, 3},
+	}
+	for _, c := range cases {
+		kept, removed := RemoveYoYOutliers(values, c.config)
+		if removed != c.removed {
+			t.Errorf("%s: removed %d values, want %d (kept %v)", c.name, removed, c.removed, kept)
+		}
+		if len(kept)+removed != len(values) {
+			t.Errorf("%s: %d kept + %d removed != %d values", c.name, len(kept), removed, len(values))
+		}
+	}
+
+	// Same result as the named helper
+	balanced, _ := RemoveYoYOutliers(values, types.OutlierConfig{})
+	if len(balanced) != len(BalancedYoYOutlierRemoval(values)) {
+		t.Error("default strategy differs from BalancedYoYOutlierRemoval")
+	}
+}
+
+func TestRemoveYoYOutliersWinsorizeCountsCapped(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 100}
+	kept, removed := RemoveYoYOutliers(values, types.OutlierConfig{Strategy: types.OutlierWinsorize, WinsorLow: 10, WinsorHigh: 90})
+	if len(kept) != len(values) {
+		t.Fatalf("winsorize should keep all values, got %d", len(kept))
+	}
+	// The 10th percentile is the lowest value itself, so only 100 gets capped
+	if removed != 1 {
+		t.Errorf("expected one capped value, got %d", removed)
+	}
+}
+
+func TestRemoveYoYOutliersWinsorizeSingleBound(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}
+	lowOnly, _ := RemoveYoYOutliers(values, types.OutlierConfig{Strategy: types.OutlierWinsorize, WinsorLow: 20})
+	if !slices.Equal(lowOnly, WinsorizeOutliers(values, 20, defaultWinsorHigh)) {
+		t.Errorf("winsor_low alone should keep the default high bound, got %v", lowOnly)
+	}
+	highOnly, _ := RemoveYoYOutliers(values, types.OutlierConfig{Strategy: types.OutlierWinsorize, WinsorHigh: 80})
+	if !slices.Equal(highOnly, WinsorizeOutliers(values, defaultWinsorLow, 80)) {
+		t.Errorf("winsor_high alone should keep the default low bound, got %v", highOnly)
+	}
+	if slices.Equal(lowOnly, WinsorizeOutliers(values, defaultWinsorLow, defaultWinsorHigh)) {
+		t.Error("winsor_low was ignored")
+	}
+}
+
+func TestAnalyzeYoYWithOutliersPercentileFallback(t *testing.T) {
+	prices := make([]types.PriceData, 0, 20)
+	for i := 1; i <= 20; i++ {
+		yoy := float64(i)
+		prices = append(prices, types.PriceData{YoY: &yoy})
+	}
+	hist := HistogramConfig{NumBins: 10, Min: 0, Max: 20, Percentile: 80}
+
+	stats := AnalyzeYoYWithOutliers(prices, hist, types.OutlierConfig{Strategy: types.OutlierPercentile})
+	if stats.OutliersRemoved != 4 || stats.Count != 16 {
+		t.Errorf("expected the histogram percentile 80 to trim 2 values per end, got %d removed, count %d", stats.OutliersRemoved, stats.Count)
+	}
+
+	stats = AnalyzeYoYWithOutliers(prices, hist, types.OutlierConfig{Strategy: types.OutlierNone})
+	if stats.OutliersRemoved != 0 || stats.Count != 20 {
+		t.Errorf("strategy none should keep all values, got %d removed, count %d", stats.OutliersRemoved, stats.Count)
+	}
+}
+
+func TestValidateOutlierConfig(t *testing.T) {
+	valid := []types.OutlierConfig{
+		{},
+		{Strategy: types.OutlierStdDev, StdDevs: 2.5},
+		{Strategy: types.OutlierWinsorize, WinsorLow: 1, WinsorHigh: 99},
+		{Strategy: types.OutlierWinsorize, WinsorLow: 20},
+		{Strategy: types.OutlierIQR, AbsMin: f.Ptr(-50.0), AbsMax: f.Ptr(500.0)},
+	}
+	for _, config := range valid {
+		if err := ValidateOutlierConfig(config); err != nil {
+			t.Errorf("%+v: unexpected error %v", config, err)
+		}
+	}
+
+	invalid := []types.OutlierConfig{
+		{Strategy: "median"},
+		{Strategy: types.OutlierIQR, IQRMultiplier: -1},
+		{Strategy: types.OutlierPercentile, Percentile: 100},
+		{Strategy: types.OutlierWinsorize, WinsorLow: 90, WinsorHigh: 10},
+		{Strategy: types.OutlierWinsorize, WinsorLow: 50, WinsorHigh: 50},
+		{Strategy: types.OutlierWinsorize, WinsorLow: 96}, // above the default high of 95
+		{Strategy: types.OutlierWinsorize, WinsorHigh: 3}, // below the default low of 5
+		{AbsMin: f.Ptr(10.0), AbsMax: f.Ptr(5.0)},
+	}
+	for _, config := range invalid {
+		if err := ValidateOutlierConfig(config); err == nil {
+			t.Errorf("%+v: expected an error", config)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/flocko-motion/gofins/pkg/db"
@@ -27,10 +28,31 @@ type AnalysisPackageConfig struct {
 	TimeTo       time.Time
 	HistConfig   HistogramConfig
 	McapMin      *int64
+	McapMax      *int64
 	InceptionMax *time.Time
-	Tickers      []string
-	PathPlots    string
-	SaveToDB     bool // If true, save results to database during batch analysis
+	// Filters select the universe and include/exclude symbols by sector, exchange etc.
+	Filters types.AnalysisFilters
+	// ReferenceIndex is the index ticker (e.g. ^GSPC) used for beta/correlation/alpha, nil to skip
+	ReferenceIndex *string
+	// ReturnBasis selects price-only or total-return YoY (empty = price-only)
+	ReturnBasis types.ReturnBasis
+	// RiskFreeRate is the annual risk-free rate in percent used for Sharpe/Sortino
+	RiskFreeRate float64
+	// RollingWindowMonths enables rolling YoY statistics per result over windows of this length (0 = off)
+	RollingWindowMonths int
+	// Outliers selects how YoY outliers are removed before the statistics are computed (zero = balanced)
+	Outliers  types.OutlierConfig
+	Tickers   []string
+	PathPlots string // Plots are generated after the analysis if set
+	SaveToDB  bool   // If true, save results to database during batch analysis
+	// Workers is the number of symbols analyzed concurrently (0 = number of CPUs)
+	Workers int
+	// ChunkSize is the number of tickers whose prices are read per query (0 = DefaultChunkSize)
+	ChunkSize int
+	// InsertBatchSize is the number of results saved per COPY (0 = DefaultInsertBatchSize)
+	InsertBatchSize int
+	// OnProgress is called periodically and at the end of a batch analysis, nil to skip
+	OnProgress func(Progress)
 }
 
 // AnalysisResult represents a single symbol's analysis result
@@ -63,39 +85,75 @@ const (
 	PlotTypeHistogram PlotType = "histogram"
 )
 
-// CreatePackage creates a new analysis package and processes all symbols
-func CreatePackage(ctx context.Context, database *db.DB, config AnalysisPackageConfig) (string, error) {
+// CreatePackage creates a new analysis package and queues a job to process all symbols.
+// The job is picked up by a running Queue.
+func CreatePackage(ctx context.Context, config AnalysisPackageConfig) (string, error) {
 	// Generate package ID
 	config.PackageID = uuid.New().String()
 	config.PathPlots = PathPlots(config.PackageID)
 
 	// Create package metadata
 	pkg := &types.AnalysisPackage{
-		ID:           config.PackageID,
-		Name:         config.Name,
-		CreatedAt:    time.Now(),
-		Interval:     string(config.Interval),
-		TimeFrom:     config.TimeFrom,
-		TimeTo:       config.TimeTo,
-		HistBins:     config.HistConfig.NumBins,
-		HistMin:      config.HistConfig.Min,
-		HistMax:      config.HistConfig.Max,
-		UserID:       config.UserID,
-		McapMin:      config.McapMin,
-		InceptionMax: config.InceptionMax,
-		Status:       "processing",
+		ID:                  config.PackageID,
+		Name:                config.Name,
+		CreatedAt:           time.Now(),
+		Interval:            string(config.Interval),
+		TimeFrom:            config.TimeFrom,
+		TimeTo:              config.TimeTo,
+		HistBins:            config.HistConfig.NumBins,
+		HistMin:             config.HistConfig.Min,
+		HistMax:             config.HistConfig.Max,
+		UserID:              config.UserID,
+		McapMin:             config.McapMin,
+		McapMax:             config.McapMax,
+		InceptionMax:        config.InceptionMax,
+		Filters:             config.Filters,
+		ReferenceIndex:      config.ReferenceIndex,
+		ReturnBasis:         string(config.ReturnBasis),
+		RiskFreeRate:        config.RiskFreeRate,
+		RollingWindowMonths: config.RollingWindowMonths,
+		Outliers:            config.Outliers,
+		Status:              "processing",
 	}
 
 	if err := db.CreateAnalysisPackage(ctx, pkg); err != nil {
 		return "", err
 	}
 
-	go processPackage(ctx, config)
+	if err := db.CreateAnalysisJob(ctx, config.UserID, config.PackageID); err != nil {
+		db.UpdateAnalysisPackageStatus(ctx, config.UserID, config.PackageID, types.JobFailed, 0)
+		return "", fmt.Errorf("failed to queue analysis job: %w", err)
+	}
 
 	return config.PackageID, nil
 }
 
-func processPackage(ctx context.Context, config AnalysisPackageConfig) {
+// ConfigFromPackage rebuilds the processing config of a stored package, e.g. to resume its job
+func ConfigFromPackage(pkg types.AnalysisPackage) AnalysisPackageConfig {
+	return AnalysisPackageConfig{
+		PackageID:           pkg.ID,
+		Name:                pkg.Name,
+		UserID:              pkg.UserID,
+		Interval:            types.PriceInterval(pkg.Interval),
+		TimeFrom:            pkg.TimeFrom,
+		TimeTo:              pkg.TimeTo,
+		HistConfig:          HistogramConfig{NumBins: pkg.HistBins, Min: pkg.HistMin, Max: pkg.HistMax},
+		McapMin:             pkg.McapMin,
+		McapMax:             pkg.McapMax,
+		InceptionMax:        pkg.InceptionMax,
+		Filters:             pkg.Filters,
+		ReferenceIndex:      pkg.ReferenceIndex,
+		ReturnBasis:         types.ReturnBasis(pkg.ReturnBasis),
+		RiskFreeRate:        pkg.RiskFreeRate,
+		RollingWindowMonths: pkg.RollingWindowMonths,
+		Outliers:            pkg.Outliers,
+		PathPlots:           PathPlots(pkg.ID),
+	}
+}
+
+// processPackage selects the symbols of a package and analyzes them, saving results to the
+// database. Returns the number of results; the caller owns the package and job status.
+func processPackage(ctx context.Context, config AnalysisPackageConfig) (int, error) {
 	var err error
 	logf("Starting package processing: %s (ID: %s)\n", config.Name, config.PackageID)
 	logf("%s config raw: %+v\n", config.PackageID, config)
@@ -109,16 +167,29 @@ func processPackage(ctx context.Context, config AnalysisPackageConfig) {
 	if config.McapMin != nil {
 		logf("%s Market cap filter: >= %d\n", config.PackageID, *config.McapMin)
 	}
+	if config.McapMax != nil {
+		logf("%s Market cap filter: <= %d\n", config.PackageID, *config.McapMax)
+	}
+	logf("%s Filters: %+v\n", config.PackageID, config.Filters)
 	if config.InceptionMax != nil {
 		logf("%s Inception filter: <= %s\n", config.PackageID, config.InceptionMax.Format("2006-01-02"))
 	}
+	if config.ReferenceIndex != nil {
+		logf("%s Reference index: %s\n", config.PackageID, *config.ReferenceIndex)
+	}
+	if config.ReturnBasis != "" {
+		logf("%s Return basis: %s\n", config.PackageID, config.ReturnBasis)
+	}
+	if config.RollingWindowMonths > 0 {
+		logf("%s Rolling window: %d months\n", config.PackageID, config.RollingWindowMonths)
+	}
+	logf("%s Outliers: %+v\n", config.PackageID, config.Outliers)
 
 	logf("%s Fetching filtered tickers...\n", config.PackageID)
-	config.Tickers, err = db.GetFilteredTickers(ctx, config.McapMin, config.InceptionMax)
+	config.Tickers, err = db.GetFilteredTickers(ctx, config.UserID, config.McapMin, config.McapMax, config.InceptionMax, config.Filters)
 	if err != nil {
 		logf("ERROR: Failed to get filtered tickers: %v\n", err)
-		db.UpdateAnalysisPackageStatus(ctx, config.UserID, config.PackageID, "failed", 0)
-		return
+		return 0, fmt.Errorf("failed to get filtered tickers: %w", err)
 	}
 
 	logf("%s Found %d tickers to analyze\n", config.PackageID, len(config.Tickers))
@@ -127,18 +198,26 @@ func processPackage(ctx context.Context, config AnalysisPackageConfig) {
 		logf("%s First %d tickers: %v\n", config.PackageID, sampleSize, config.Tickers[:sampleSize])
 	} else {
 		logf("%s WARNING: No tickers found, nothing to analyze\n", config.PackageID)
-		db.UpdateAnalysisPackageStatus(ctx, config.UserID, config.PackageID, "ready", 0)
-		return
+		return 0, nil
 	}
 
 	logf("%s Starting batch analysis of %d symbols...\n", config.PackageID, len(config.Tickers))
 	config.SaveToDB = true // Enable database saving
+	var lastProgress Progress
+	var progressMu sync.Mutex
+	if onProgress := config.OnProgress; onProgress != nil {
+		config.OnProgress = func(progress Progress) {
+			progressMu.Lock()
+			lastProgress = progress
+			progressMu.Unlock()
+			onProgress(progress)
+		}
+	}
 	startTime := time.Now()
 	results, err := AnalyzeBatch(ctx, config)
 	if err != nil {
 		logf("%s ERROR: Batch analysis failed: %v\n", config.PackageID, err)
-		db.UpdateAnalysisPackageStatus(ctx, config.UserID, config.PackageID, "failed", 0)
-		return
+		return len(results), err
 	}
 	elapsed := time.Since(startTime)
 
@@ -147,7 +226,44 @@ func processPackage(ctx context.Context, config AnalysisPackageConfig) {
 		logf("%s Throughput: %.1f symbols/sec\n", config.PackageID, float64(len(results))/elapsed.Seconds())
 	}
 
+	if config.PathPlots != "" && len(results) > 0 {
+		tickers := make([]string, len(results))
+		for i, result := range results {
+			tickers[i] = result.Ticker
+		}
+		// Keep reporting the final progress while plotting, it doubles as the job heartbeat
+		done := make(chan struct{})
+		if config.OnProgress != nil {
+			go func() {
+				ticker := time.NewTicker(2 * time.Second)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+						progressMu.Lock()
+						progress := lastProgress
+						progressMu.Unlock()
+						config.OnProgress(progress)
+					}
+				}
+			}()
+		}
+		startTime = time.Now()
+		err := GeneratePlots(ctx, config, tickers)
+		close(done)
+		if err != nil {
+			if ctx.Err() != nil {
+				return len(results), err
+			}
+			// Results are complete, missing plots only show up as missing images
+			logf("%s ERROR: Plot generation failed: %v\n", config.PackageID, err)
+		} else {
+			logf("%s Plots generated in %v\n", config.PackageID, time.Since(startTime))
+		}
+	}
+
 	logf("%s Package processing complete: %d results\n", config.PackageID, len(results))
-	db.UpdateAnalysisPackageStatus(ctx, config.UserID, config.PackageID, "ready", len(results))
-	logf("%s Package %s is now ready\n", config.PackageID, config.PackageID)
+	return len(results), nil
 }
@@ -0,0 +1,304 @@
+package analysis
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/events"
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+)
+
+const (
+	// queuePollInterval is how often the queue looks for new and orphaned jobs without being notified
+	queuePollInterval = 10 * time.Second
+	// orphanTimeout is how long a running job may go without a heartbeat before it counts as orphaned.
+	// Running jobs report progress every 2 seconds and send a heartbeat every heartbeatInterval.
+	orphanTimeout = 30 * time.Second
+	// heartbeatInterval is how often a running job refreshes its heartbeat, also while it reports no progress
+	heartbeatInterval = 10 * time.Second
+	// maxJobAttempts is how often an orphaned job is restarted before it fails
+	maxJobAttempts = 3
+)
+
+// ErrJobNotCancellable is returned by Cancel if the package has no queued or running job
+var ErrJobNotCancellable = errors.New("analysis job is not queued or running")
+
+// Queue runs the analysis jobs persisted in the database with bounded concurrency across all users.
+// Jobs whose process died (no heartbeat) are resumed from scratch or failed after maxJobAttempts.
+type Queue struct {
+	workers       int
+	wake          chan struct{}
+	orphanTimeout time.Duration
+
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc // Running jobs of this process by package ID
+}
+
+// NewQueue creates a queue that runs at most workers jobs at a time
+func NewQueue(workers int) *Queue {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Queue{
+		workers:       workers,
+		wake:          make(chan struct{}, 1),
+		orphanTimeout: orphanTimeout,
+		cancels:       make(map[string]context.CancelFunc),
+	}
+}
+
+// Submit creates an analysis package with a queued job and wakes up the queue
+func (q *Queue) Submit(ctx context.Context, config AnalysisPackageConfig) (string, error) {
+	packageID, err := CreatePackage(ctx, config)
+	if err != nil {
+		return "", err
+	}
+	q.notify()
+	return packageID, nil
+}
+
+// Cancel stops a queued or running job of a user and marks its package as cancelled.
+// Results computed so far are kept.
+func (q *Queue) Cancel(ctx context.Context, userID uuid.UUID, packageID string) (*types.AnalysisJob, error) {
+	cancelled, err := db.CancelAnalysisJob(ctx, userID, packageID)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, ErrJobNotCancellable
+	}
+
+	// Jobs running in this process stop right away, others notice on their next progress update
+	q.mu.Lock()
+	if cancel, ok := q.cancels[packageID]; ok {
+		cancel()
+	}
+	q.mu.Unlock()
+
+	job, err := db.GetAnalysisJob(ctx, userID, packageID)
+	if err != nil || job == nil {
+		return nil, err
+	}
+	if err := db.UpdateAnalysisPackageStatus(ctx, userID, packageID, types.JobCancelled, job.Results); err != nil {
+		return nil, err
+	}
+	logf("%s Job cancelled\n", packageID)
+	events.PublishUser(userID, events.TypeAnalysisFinished, job)
+	return job, nil
+}
+
+// Run processes queued jobs until ctx is cancelled. Jobs interrupted by shutdown stay
+// running in the database and are resumed by the next process once their heartbeat is stale.
+func (q *Queue) Run(ctx context.Context) {
+	logf("Job queue started with %d workers\n", q.workers)
+	ticker := time.NewTicker(queuePollInterval)
+	defer ticker.Stop()
+
+	slots := make(chan struct{}, q.workers)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		q.recoverOrphans(ctx)
+		q.claimJobs(ctx, slots, &wg)
+
+		select {
+		case <-ctx.Done():
+			logf("Job queue stopping\n")
+			return
+		case <-q.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// notify wakes up the run loop without blocking
+func (q *Queue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// claimJobs starts queued jobs while worker slots are free
+func (q *Queue) claimJobs(ctx context.Context, slots chan struct{}, wg *sync.WaitGroup) {
+	for {
+		select {
+		case slots <- struct{}{}:
+		default:
+			return
+		}
+
+		job, err := db.ClaimAnalysisJob(ctx)
+		if err != nil || job == nil {
+			<-slots
+			if err != nil && ctx.Err() == nil {
+				_ = db.LogError(ctx, "analysis.queue", "database", "Failed to claim analysis job", f.Ptr(err.Error()))
+			}
+			return
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				<-slots
+				q.notify()
+			}()
+			q.runJob(ctx, job)
+		}()
+	}
+}
+
+// recoverOrphans requeues or fails running jobs without a recent heartbeat.
+// Jobs running in this process are never orphaned, even if their heartbeat is late.
+func (q *Queue) recoverOrphans(ctx context.Context) {
+	q.mu.Lock()
+	running := make([]string, 0, len(q.cancels))
+	for packageID := range q.cancels {
+		running = append(running, packageID)
+	}
+	q.mu.Unlock()
+
+	requeued, failed, err := db.RecoverOrphanedAnalysisJobs(ctx, time.Now().Add(-q.orphanTimeout), maxJobAttempts, running)
+	if err != nil {
+		if ctx.Err() == nil {
+			_ = db.LogError(ctx, "analysis.queue", "database", "Failed to recover orphaned analysis jobs", f.Ptr(err.Error()))
+		}
+		return
+	}
+	if requeued > 0 || failed > 0 {
+		logf("Orphaned jobs: %d requeued, %d failed\n", requeued, failed)
+	}
+}
+
+// runJob processes the package of a claimed job and records the outcome
+func (q *Queue) runJob(ctx context.Context, job *types.AnalysisJob) {
+	packageID := job.PackageID
+	logf("%s Job started (attempt %d)\n", packageID, job.Attempts)
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	q.mu.Lock()
+	q.cancels[packageID] = cancel
+	q.mu.Unlock()
+	defer func() {
+		q.mu.Lock()
+		delete(q.cancels, packageID)
+		q.mu.Unlock()
+	}()
+	go heartbeat(jobCtx, packageID, cancel)
+
+	pkg, err := db.GetAnalysisPackage(ctx, job.UserID, packageID)
+	if err == nil && pkg == nil {
+		err = fmt.Errorf("package not found")
+	}
+	if err != nil {
+		q.finishJob(ctx, job, 0, err)
+		return
+	}
+
+	// A previous attempt may have saved some results already
+	if err := db.DeleteAnalysisResults(ctx, packageID); err != nil {
+		q.finishJob(ctx, job, 0, err)
+		return
+	}
+
+	// Full job record (timestamps) as the base of progress events
+	if stored, err := db.GetAnalysisJob(ctx, job.UserID, packageID); err == nil && stored != nil {
+		job = stored
+	}
+
+	config := ConfigFromPackage(*pkg)
+	config.OnProgress = func(progress Progress) {
+		state, err := db.UpdateAnalysisJobProgress(ctx, packageID, progress.Processed, progress.Total, progress.Results, progress.Rejections)
+		if err == sql.ErrNoRows || (err == nil && state != types.JobRunning) {
+			// Cancelled by another process or package deleted
+			cancel()
+			return
+		}
+		if err == nil {
+			db.UpdateAnalysisPackageStatus(ctx, job.UserID, packageID, "processing", progress.Results)
+			events.PublishUser(job.UserID, events.TypeAnalysisProgress, jobWithProgress(*job, progress))
+		}
+	}
+
+	results, err := processPackage(jobCtx, config)
+	if ctx.Err() != nil {
+		// Shutdown: leave the job running so it is resumed after restart
+		logf("%s Job interrupted by shutdown\n", packageID)
+		return
+	}
+	if jobCtx.Err() != nil {
+		logf("%s Job stopped after cancellation (%d results kept)\n", packageID, results)
+		return
+	}
+	q.finishJob(ctx, job, results, err)
+}
+
+// heartbeat refreshes the heartbeat of a running job every heartbeatInterval until ctx is done,
+// so slow steps without progress updates (deleting old results, selecting the symbols, loading
+// the reference index) don't make the job look orphaned. Cancels the job if it was cancelled
+// by another process or its package was deleted.
+func heartbeat(ctx context.Context, packageID string, cancel context.CancelFunc) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		state, err := db.TouchAnalysisJob(ctx, packageID)
+		if err == sql.ErrNoRows || (err == nil && state != types.JobRunning) {
+			cancel()
+			return
+		}
+	}
+}
+
+// finishJob moves a job to ready or failed and updates its package accordingly.
+// Nothing changes if the job was cancelled in the meantime.
+func (q *Queue) finishJob(ctx context.Context, job *types.AnalysisJob, results int, jobErr error) {
+	state := types.JobReady
+	var errMsg *string
+	if jobErr != nil {
+		state = types.JobFailed
+		errMsg = f.Ptr(jobErr.Error())
+		_ = db.LogError(ctx, "analysis.queue", "job", "Analysis job failed", f.Ptr(job.PackageID+": "+jobErr.Error()))
+	}
+
+	finished, err := db.FinishAnalysisJob(ctx, job.PackageID, state, errMsg)
+	if err != nil {
+		_ = db.LogError(ctx, "analysis.queue", "database", "Failed to finish analysis job", f.Ptr(err.Error()))
+		return
+	}
+	if !finished {
+		return
+	}
+	db.UpdateAnalysisPackageStatus(ctx, job.UserID, job.PackageID, state, results)
+	logf("%s Job %s with %d results\n", job.PackageID, state, results)
+
+	if finishedJob, err := db.GetAnalysisJob(ctx, job.UserID, job.PackageID); err == nil && finishedJob != nil {
+		events.PublishUser(job.UserID, events.TypeAnalysisFinished, finishedJob)
+	}
+}
+
+// jobWithProgress returns a running job with the given progress, as stored by the last progress update
+func jobWithProgress(job types.AnalysisJob, progress Progress) types.AnalysisJob {
+	now := time.Now()
+	job.Processed = progress.Processed
+	job.Total = progress.Total
+	job.Results = progress.Results
+	job.Rejections = progress.Rejections
+	job.HeartbeatAt = &now
+	return job
+}
@@ -0,0 +1,110 @@
+package analysis
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newQueueTestJob submits a package for a new user and claims its job, as a worker would.
+// The queue counts every running job as orphaned. Requires a disposable database
+// (DB_NAME containing "test"), since the claim takes the oldest queued job.
+func newQueueTestJob(t *testing.T) (*Queue, *types.AnalysisJob) {
+	if !strings.Contains(os.Getenv("DB_NAME"), "test") {
+		t.Skip("Requires a disposable database: run with DB_NAME=<name containing 'test'>")
+	}
+	if db.Db() == nil {
+		t.Skip("Database not available")
+	}
+	ctx := context.Background()
+
+	user, err := db.CreateUser(ctx, "queue-test-"+uuid.NewString())
+	require.NoError(t, err)
+	q := NewQueue(1)
+	q.orphanTimeout = -time.Hour
+	packageID, err := q.Submit(ctx, AnalysisPackageConfig{
+		Name:       "queue test",
+		UserID:     user.ID,
+		Interval:   types.IntervalMonthly,
+		TimeFrom:   time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		TimeTo:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		HistConfig: HistogramConfig{NumBins: 10, Min: -50, Max: 50},
+		Filters:    types.AnalysisFilters{Universe: types.UniverseTickers, Tickers: []string{"AAPL"}},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.DeleteAnalysisPackage(ctx, user.ID, packageID)
+		_ = db.DeleteUser(ctx, user.Name)
+	})
+
+	job, err := db.ClaimAnalysisJob(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	require.Equal(t, packageID, job.PackageID)
+	return q, job
+}
+
+func jobState(t *testing.T, job *types.AnalysisJob) string {
+	stored, err := db.GetAnalysisJob(context.Background(), job.UserID, job.PackageID)
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	return stored.State
+}
+
+func TestQueueRecoverOrphans(t *testing.T) {
+	q, job := newQueueTestJob(t)
+	ctx := context.Background()
+
+	// Still running in this process: not orphaned, however late its heartbeat
+	q.cancels[job.PackageID] = func() {}
+	q.recoverOrphans(ctx)
+	assert.Equal(t, types.JobRunning, jobState(t, job))
+
+	// Its process died: requeued until the last attempt, then failed with its package
+	delete(q.cancels, job.PackageID)
+	for attempt := 1; attempt < maxJobAttempts; attempt++ {
+		q.recoverOrphans(ctx)
+		assert.Equal(t, types.JobQueued, jobState(t, job), "attempt %d", attempt)
+		claimed, err := db.ClaimAnalysisJob(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+		assert.Equal(t, attempt+1, claimed.Attempts)
+	}
+	q.recoverOrphans(ctx)
+	assert.Equal(t, types.JobFailed, jobState(t, job))
+	pkg, err := db.GetAnalysisPackage(ctx, job.UserID, job.PackageID)
+	require.NoError(t, err)
+	assert.Equal(t, types.JobFailed, pkg.Status)
+}
+
+func TestQueueCancel(t *testing.T) {
+	q, job := newQueueTestJob(t)
+	ctx := context.Background()
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	q.cancels[job.PackageID] = cancel
+
+	cancelled, err := q.Cancel(ctx, job.UserID, job.PackageID)
+	require.NoError(t, err)
+	assert.Equal(t, types.JobCancelled, cancelled.State)
+	assert.Error(t, jobCtx.Err(), "the running job is stopped")
+	pkg, err := db.GetAnalysisPackage(ctx, job.UserID, job.PackageID)
+	require.NoError(t, err)
+	assert.Equal(t, types.JobCancelled, pkg.Status)
+
+	// Cancelled jobs are neither cancelled again nor recovered as orphans
+	_, err = q.Cancel(ctx, job.UserID, job.PackageID)
+	assert.ErrorIs(t, err, ErrJobNotCancellable)
+	delete(q.cancels, job.PackageID)
+	q.recoverOrphans(ctx)
+	assert.Equal(t, types.JobCancelled, jobState(t, job))
+}
@@ -0,0 +1,146 @@
+package analysis
+
+import (
+	"math"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+const (
+	// DefaultRollingWindowMonths is the window of the rolling YoY series unless requested otherwise
+	DefaultRollingWindowMonths = 36
+	// RegimeShiftThreshold is how many pooled window standard deviations the rolling mean must
+	// move against the preceding non-overlapping window to count as a regime shift
+	RegimeShiftThreshold = 1.0
+)
+
+// RollingYoYStats computes YoY statistics over windows of windowMonths, stepped by stepMonths,
+// from a weekly or monthly series sorted by date. The first window starts at the first YoY value,
+// so every window is covered by data. Unlike AnalyzeYoY no outliers are removed: within a short
+// window an outlier is part of the regime. Returns nil if no window has at least two values.
+func RollingYoYStats(prices []types.PriceData, windowMonths, stepMonths int) *types.RollingYoY {
+	if windowMonths <= 0 || stepMonths <= 0 {
+		return nil
+	}
+
+	var dates []time.Time
+	var values []float64
+	for _, p := range prices {
+		if p.YoY != nil && !math.IsNaN(*p.YoY) && !math.IsInf(*p.YoY, 0) {
+			dates = append(dates, p.Date)
+			values = append(values, *p.YoY)
+		}
+	}
+	if len(values) < 2 {
+		return nil
+	}
+
+	rolling := &types.RollingYoY{StepMonths: stepMonths, Points: []types.RollingPoint{}}
+	steps := []int{} // Step index of each point, to find the window one window length earlier
+	base := dates[0]
+	lo := 0
+	for k := 0; ; k++ {
+		// Offsets from the base date instead of repeated AddDate avoid month-end drift
+		end := base.AddDate(0, windowMonths+k*stepMonths, 0)
+		if end.After(dates[len(dates)-1]) {
+			break
+		}
+		start := end.AddDate(0, -windowMonths, 0)
+		for lo < len(dates) && !dates[lo].After(start) {
+			lo++
+		}
+		hi := lo
+		for hi < len(dates) && !dates[hi].After(end) {
+			hi++
+		}
+		if hi-lo < 2 {
+			continue
+		}
+
+		point := types.RollingPoint{Date: end, Count: hi - lo}
+		point.Mean, point.StdDev, point.Min, point.Max = describe(values[lo:hi])
+		rolling.Points = append(rolling.Points, point)
+		steps = append(steps, k)
+	}
+	if len(rolling.Points) == 0 {
+		return nil
+	}
+
+	rolling.RollingSummary = summarizeRolling(rolling.Points, steps, windowMonths, stepMonths)
+	return rolling
+}
+
+// summarizeRolling condenses rolling points and detects regime shifts. steps holds the step
+// index of each point; a window length corresponds to windowMonths/stepMonths steps (rounded up).
+func summarizeRolling(points []types.RollingPoint, steps []int, windowMonths, stepMonths int) types.RollingSummary {
+	means := make([]float64, len(points))
+	for i, p := range points {
+		means[i] = p.Mean
+	}
+	_, meanStdDev, meanMin, meanMax := describe(means)
+
+	last := points[len(points)-1]
+	summary := types.RollingSummary{
+		WindowMonths: windowMonths,
+		Windows:      len(points),
+		MeanMin:      meanMin,
+		MeanMax:      meanMax,
+		MeanStdDev:   meanStdDev,
+		LastMean:     last.Mean,
+		LastStdDev:   last.StdDev,
+		Shifts:       []types.RegimeShift{},
+	}
+
+	pointAtStep := make(map[int]int, len(steps))
+	for i, k := range steps {
+		pointAtStep[k] = i
+	}
+	lag := (windowMonths + stepMonths - 1) / stepMonths
+
+	// Consecutive flagged windows moving in the same direction are one shift,
+	// reported at the window where the change is largest
+	var current *types.RegimeShift
+	for i, k := range steps {
+		j, ok := pointAtStep[k-lag]
+		if !ok {
+			current = nil
+			continue
+		}
+		before, after := points[j], points[i]
+		change := after.Mean - before.Mean
+		pooled := math.Sqrt((before.StdDev*before.StdDev + after.StdDev*after.StdDev) / 2)
+		if pooled == 0 || math.Abs(change) <= RegimeShiftThreshold*pooled {
+			current = nil
+			continue
+		}
+
+		shift := types.RegimeShift{Date: after.Date, MeanBefore: before.Mean, MeanAfter: after.Mean, Change: change}
+		if current != nil && (current.Change > 0) == (change > 0) {
+			if math.Abs(change) > math.Abs(current.Change) {
+				*current = shift
+			}
+			continue
+		}
+		summary.Shifts = append(summary.Shifts, shift)
+		current = &summary.Shifts[len(summary.Shifts)-1]
+	}
+
+	return summary
+}
+
+// describe returns mean, population standard deviation, min and max of non-empty values
+func describe(values []float64) (mean, stddev, minimum, maximum float64) {
+	minimum, maximum = values[0], values[0]
+	for _, v := range values {
+		mean += v
+		minimum = math.Min(minimum, v)
+		maximum = math.Max(maximum, v)
+	}
+	mean /= float64(len(values))
+	for _, v := range values {
+		stddev += (v - mean) * (v - mean)
+	}
+	stddev = math.Sqrt(stddev / float64(len(values)))
+	return mean, stddev, minimum, maximum
+}
@@ -0,0 +1,95 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+// makeYoYSeries builds a monthly series with the given YoY values
+func makeYoYSeries(yoy []float64) []types.PriceData {
+	prices := makeMonthlySeries(make([]float64, len(yoy)))
+	for i, v := range yoy {
+		prices[i].YoY = f.Ptr(v)
+	}
+	return prices
+}
+
+func TestRollingYoYStatsWindows(t *testing.T) {
+	yoy := make([]float64, 36)
+	for i := range yoy {
+		yoy[i] = float64(i)
+	}
+
+	rolling := RollingYoYStats(makeYoYSeries(yoy), 12, 6)
+	if rolling == nil {
+		t.Fatal("expected rolling stats, got nil")
+	}
+	// Windows end 12, 18, 24, 30 months after the first value (month 36 is past the data)
+	if len(rolling.Points) != 4 {
+		t.Fatalf("got %d windows, want 4", len(rolling.Points))
+	}
+	first := rolling.Points[0]
+	if first.Count != 12 || first.Min != 1 || first.Max != 12 || first.Mean != 6.5 {
+		t.Errorf("first window = %+v, want values 1..12", first)
+	}
+	if rolling.LastMean != 24.5 || rolling.WindowMonths != 12 || rolling.StepMonths != 6 {
+		t.Errorf("summary = %+v", rolling.RollingSummary)
+	}
+	if rolling.MeanMin != 6.5 || rolling.MeanMax != 24.5 {
+		t.Errorf("mean range = %v..%v, want 6.5..24.5", rolling.MeanMin, rolling.MeanMax)
+	}
+}
+
+func TestRollingYoYStatsDetectsRegimeShift(t *testing.T) {
+	// Five years around 10%, then five years around 30%
+	var yoy []float64
+	for i := 0; i < 120; i++ {
+		level := 10.0
+		if i >= 60 {
+			level = 30
+		}
+		yoy = append(yoy, level+float64(i%3-1)*2)
+	}
+
+	rolling := RollingYoYStats(makeYoYSeries(yoy), 12, 1)
+	if rolling == nil {
+		t.Fatal("expected rolling stats, got nil")
+	}
+	if len(rolling.Shifts) != 1 {
+		t.Fatalf("got %d shifts, want 1: %+v", len(rolling.Shifts), rolling.Shifts)
+	}
+	shift := rolling.Shifts[0]
+	if math.Abs(shift.Change-20) > 1 || math.Abs(shift.MeanBefore-10) > 1 || math.Abs(shift.MeanAfter-30) > 1 {
+		t.Errorf("shift = %+v, want ~10 -> ~30", shift)
+	}
+	if rolling.MeanStdDev < 5 {
+		t.Errorf("MeanStdDev = %v, expected a wide spread of rolling means", rolling.MeanStdDev)
+	}
+}
+
+func TestRollingYoYStatsStable(t *testing.T) {
+	var yoy []float64
+	for i := 0; i < 120; i++ {
+		yoy = append(yoy, 10+float64(i%3-1)*2)
+	}
+
+	rolling := RollingYoYStats(makeYoYSeries(yoy), 36, 1)
+	if rolling == nil {
+		t.Fatal("expected rolling stats, got nil")
+	}
+	if len(rolling.Shifts) != 0 {
+		t.Errorf("got shifts %+v for a stable series", rolling.Shifts)
+	}
+	if rolling.MeanStdDev > 0.5 {
+		t.Errorf("MeanStdDev = %v, want close to 0", rolling.MeanStdDev)
+	}
+}
+
+func TestRollingYoYStatsTooShort(t *testing.T) {
+	if rolling := RollingYoYStats(makeYoYSeries([]float64{1, 2, 3}), 12, 1); rolling != nil {
+		t.Errorf("expected nil for a series shorter than the window, got %+v", rolling)
+	}
+}
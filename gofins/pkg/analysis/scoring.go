@@ -0,0 +1,157 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+// DefaultScoringProfile rewards high mean and low volatility equally
+var DefaultScoringProfile = types.ScoringProfile{
+	Name:          "default",
+	WeightMean:    1,
+	WeightStdDev:  1,
+	Normalization: types.NormalizationZScore,
+}
+
+// scoreComponent extracts one metric from a result. Inverted components are "lower is better"
+// (stddev, beta), so their normalized value is negated/mirrored before weighting.
+type scoreComponent struct {
+	value    func(r types.AnalysisResult) *float64
+	inverted bool
+}
+
+var (
+	componentMean   = scoreComponent{value: func(r types.AnalysisResult) *float64 { return &r.Mean }}
+	componentStdDev = scoreComponent{value: func(r types.AnalysisResult) *float64 { return &r.StdDev }, inverted: true}
+	componentMin    = scoreComponent{value: func(r types.AnalysisResult) *float64 { return &r.Min }}
+	componentMax    = scoreComponent{value: func(r types.AnalysisResult) *float64 { return &r.Max }}
+	componentBeta   = scoreComponent{value: func(r types.AnalysisResult) *float64 { return r.Beta }, inverted: true}
+)
+
+// ScoreResults computes a weighted score for each result and returns them ranked best first.
+// Each component is normalized across the whole package so that higher is better:
+// mean, min and max as-is, stddev and beta inverted. Results missing a component (e.g. no beta)
+// get a neutral value for it. Ties are broken by ticker to keep pagination stable.
+func ScoreResults(results []types.AnalysisResult, profile types.ScoringProfile) []types.ScoredResult {
+	normalize := normalizeZScore
+	if profile.Normalization == types.NormalizationRank {
+		normalize = normalizeRank
+	}
+
+	mean := normalize(results, componentMean)
+	stddev := normalize(results, componentStdDev)
+	minimum := normalize(results, componentMin)
+	maximum := normalize(results, componentMax)
+	beta := normalize(results, componentBeta)
+
+	scored := make([]types.ScoredResult, len(results))
+	for i, r := range results {
+		c := types.ScoreContributions{
+			Mean:   profile.WeightMean * mean[i],
+			StdDev: profile.WeightStdDev * stddev[i],
+			Min:    profile.WeightMin * minimum[i],
+			Max:    profile.WeightMax * maximum[i],
+			Beta:   profile.WeightBeta * beta[i],
+		}
+		scored[i] = types.ScoredResult{
+			AnalysisResult: r,
+			Score:          c.Mean + c.StdDev + c.Min + c.Max + c.Beta,
+			Contributions:  c,
+		}
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].Score != scored[j].Score {
+			return scored[i].Score > scored[j].Score
+		}
+		return scored[i].Ticker < scored[j].Ticker
+	})
+	for i := range scored {
+		scored[i].Rank = i + 1
+	}
+
+	return scored
+}
+
+// normalizeZScore returns (v - mean) / stddev per result, 0 for missing values or no variance
+func normalizeZScore(results []types.AnalysisResult, component scoreComponent) []float64 {
+	normalized := make([]float64, len(results))
+
+	sum, n := 0.0, 0
+	for _, r := range results {
+		if v := component.value(r); v != nil {
+			sum += *v
+			n++
+		}
+	}
+	if n == 0 {
+		return normalized
+	}
+	mean := sum / float64(n)
+
+	sumSquaredDiff := 0.0
+	for _, r := range results {
+		if v := component.value(r); v != nil {
+			sumSquaredDiff += (*v - mean) * (*v - mean)
+		}
+	}
+	stddev := math.Sqrt(sumSquaredDiff / float64(n))
+	if stddev == 0 {
+		return normalized
+	}
+
+	for i, r := range results {
+		if v := component.value(r); v != nil {
+			z := (*v - mean) / stddev
+			if component.inverted {
+				z = -z
+			}
+			normalized[i] = z
+		}
+	}
+	return normalized
+}
+
+// normalizeRank returns the rank percentile in [0, 1] per result (ties share their average rank),
+// 0.5 for missing values
+func normalizeRank(results []types.AnalysisResult, component scoreComponent) []float64 {
+	normalized := make([]float64, len(results))
+	for i := range normalized {
+		normalized[i] = 0.5
+	}
+
+	indices := make([]int, 0, len(results))
+	for i, r := range results {
+		if component.value(r) != nil {
+			indices = append(indices, i)
+		}
+	}
+	n := len(indices)
+	if n < 2 {
+		return normalized
+	}
+
+	sort.Slice(indices, func(a, b int) bool {
+		return *component.value(results[indices[a]]) < *component.value(results[indices[b]])
+	})
+
+	for start := 0; start < n; {
+		end := start + 1
+		value := *component.value(results[indices[start]])
+		for end < n && *component.value(results[indices[end]]) == value {
+			end++
+		}
+		averageRank := float64(start+end-1) / 2
+		percentile := averageRank / float64(n-1)
+		if component.inverted {
+			percentile = 1 - percentile
+		}
+		for k := start; k < end; k++ {
+			normalized[indices[k]] = percentile
+		}
+		start = end
+	}
+	return normalized
+}
@@ -0,0 +1,57 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+func TestScoreResultsZScore(t *testing.T) {
+	results := []types.AnalysisResult{
+		{Ticker: "LOW", Mean: 2, StdDev: 10},
+		{Ticker: "MID", Mean: 6, StdDev: 10},
+		{Ticker: "HIGH", Mean: 10, StdDev: 10},
+	}
+	profile := types.ScoringProfile{WeightMean: 1, WeightStdDev: 1, Normalization: types.NormalizationZScore}
+
+	scored := ScoreResults(results, profile)
+
+	if scored[0].Ticker != "HIGH" || scored[1].Ticker != "MID" || scored[2].Ticker != "LOW" {
+		t.Fatalf("unexpected order: %s, %s, %s", scored[0].Ticker, scored[1].Ticker, scored[2].Ticker)
+	}
+	if scored[0].Rank != 1 || scored[2].Rank != 3 {
+		t.Errorf("unexpected ranks: %d, %d", scored[0].Rank, scored[2].Rank)
+	}
+	// Equal stddev has no variance and must not contribute
+	if scored[0].Contributions.StdDev != 0 {
+		t.Errorf("StdDev contribution = %v, want 0", scored[0].Contributions.StdDev)
+	}
+	if math.Abs(scored[1].Score) > 1e-9 {
+		t.Errorf("MID score = %v, want 0", scored[1].Score)
+	}
+}
+
+func TestScoreResultsRankInvertsStdDevAndBeta(t *testing.T) {
+	results := []types.AnalysisResult{
+		{Ticker: "CALM", Mean: 5, StdDev: 5, Beta: f.Ptr(0.5)},
+		{Ticker: "WILD", Mean: 5, StdDev: 50, Beta: f.Ptr(2.0)},
+		{Ticker: "NOBETA", Mean: 5, StdDev: 20},
+	}
+	profile := types.ScoringProfile{WeightStdDev: 1, WeightBeta: 1, Normalization: types.NormalizationRank}
+
+	scored := ScoreResults(results, profile)
+
+	if scored[0].Ticker != "CALM" {
+		t.Errorf("expected CALM first, got %s", scored[0].Ticker)
+	}
+	for _, s := range scored {
+		if s.Ticker == "NOBETA" && s.Contributions.Beta != 0.5 {
+			t.Errorf("missing beta should be neutral 0.5, got %v", s.Contributions.Beta)
+		}
+		if s.Ticker == "CALM" && s.Contributions.StdDev != 1 {
+			t.Errorf("lowest stddev should rank 1.0, got %v", s.Contributions.StdDev)
+		}
+	}
+}
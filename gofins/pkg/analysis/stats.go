@@ -14,6 +14,7 @@ type Stats struct {
 	Max             float64
 	Histogram       []HistogramBin
 	HistogramConfig HistogramConfig
+	OutliersRemoved int // Values removed or capped before the calculation (see AnalyzeYoYWithOutliers)
 }
 
 // Calculate performs all statistical calculations in a single pass
@@ -71,7 +72,7 @@ type HistogramConfig struct {
 	NumBins    int     // Number of bins
 	Min        float64 // Minimum value (values below go to first bin)
 	Max        float64 // Maximum value (values above go to last bin)
-	Percentile int     // which percentile to use for outlier removal
+	Percentile int     // Central percentile kept by the percentile outlier strategy if the package sets none
 }
 
 // HistogramBin represents a single bin in a histogram
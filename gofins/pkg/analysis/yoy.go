@@ -5,8 +5,15 @@ import (
 )
 
 // AnalyzeYoY performs statistical analysis on Year-over-Year data from price data
-// Only processes entries that have a non-nil YoY value
+// Only processes entries that have a non-nil YoY value; outliers are removed with the balanced strategy
 func AnalyzeYoY(prices []types.PriceData, histConfig HistogramConfig) Stats {
+	return AnalyzeYoYWithOutliers(prices, histConfig, types.OutlierConfig{})
+}
+
+// AnalyzeYoYWithOutliers is AnalyzeYoY with a selectable outlier strategy. The percentile strategy
+// falls back to histConfig.Percentile if the config sets none. Stats.OutliersRemoved reports how
+// many values the strategy removed or capped.
+func AnalyzeYoYWithOutliers(prices []types.PriceData, histConfig HistogramConfig, outliers types.OutlierConfig) Stats {
 	// Extract non-nil YoY values
 	yoyValues := make([]float64, 0, len(prices))
 	for _, p := range prices {
@@ -15,5 +22,33 @@ func AnalyzeYoY(prices []types.PriceData, histConfig HistogramConfig) Stats {
 		}
 	}
 
-	return Calculate(BalancedYoYOutlierRemoval(yoyValues), histConfig)
+	if outliers.Strategy == types.OutlierPercentile && outliers.Percentile == 0 {
+		outliers.Percentile = histConfig.Percentile
+	}
+	filtered, removed := RemoveYoYOutliers(yoyValues, outliers)
+	stats := Calculate(filtered, histConfig)
+	stats.OutliersRemoved = removed
+	return stats
+}
+
+// WithReturnBasis returns prices on the given return basis; the input is not modified.
+// For the total-return basis YoY is replaced by YoYTR and the bars are scaled to the
+// total-return index, so return-based metrics reinvest dividends too. Periods without
+// a total-return index are left out.
+func WithReturnBasis(prices []types.PriceData, basis types.ReturnBasis) []types.PriceData {
+	if basis != types.ReturnBasisTotalReturn {
+		return prices
+	}
+
+	selected := make([]types.PriceData, 0, len(prices))
+	for _, p := range prices {
+		if p.CloseTR == nil || p.Close <= 0 {
+			continue
+		}
+		factor := *p.CloseTR / p.Close
+		p.Open, p.High, p.Low, p.Avg, p.Close = p.Open*factor, p.High*factor, p.Low*factor, p.Avg*factor, *p.CloseTR
+		p.YoY = p.YoYTR
+		selected = append(selected, p)
+	}
+	return selected
 }
@@ -0,0 +1,38 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+func TestWithReturnBasis(t *testing.T) {
+	prices := []types.PriceData{
+		{Open: 40, Close: 50, YoY: f.Ptr(10.0), YoYTR: f.Ptr(12.5), CloseTR: f.Ptr(60.0)},
+		{Open: 50, Close: 55, YoY: f.Ptr(-5.0), YoYTR: nil},
+	}
+
+	t.Run("price basis keeps YoY", func(t *testing.T) {
+		selected := WithReturnBasis(prices, types.ReturnBasisPrice)
+		if *selected[0].YoY != 10.0 || *selected[1].YoY != -5.0 {
+			t.Errorf("price basis changed YoY: %v, %v", *selected[0].YoY, *selected[1].YoY)
+		}
+	})
+
+	t.Run("total return basis uses YoYTR", func(t *testing.T) {
+		selected := WithReturnBasis(prices, types.ReturnBasisTotalReturn)
+		if selected[0].YoY == nil || *selected[0].YoY != 12.5 {
+			t.Errorf("expected YoY 12.5, got %v", selected[0].YoY)
+		}
+		if selected[0].Close != 60 || selected[0].Open != 48 {
+			t.Errorf("expected the bar scaled to the total-return index, got open %v close %v", selected[0].Open, selected[0].Close)
+		}
+		if len(selected) != 1 {
+			t.Errorf("expected the period without total-return index to be left out, got %d periods", len(selected))
+		}
+		if *prices[0].YoY != 10.0 || prices[0].Close != 50 {
+			t.Errorf("input was modified: %v, %v", *prices[0].YoY, prices[0].Close)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"os"
 	"strings"
@@ -117,6 +118,11 @@ func (s *Server) handleDeleteAnalysis(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	userID := getUserID(r)
+	// Stop a running job right away instead of waiting for it to notice the deleted package
+	if _, err := s.queue.Cancel(r.Context(), userID, packageID); err != nil && !errors.Is(err, analysis.ErrJobNotCancellable) {
+		http.Error(w, "Failed to cancel analysis: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 	err := analysis.DeletePackage(r.Context(), userID, packageID)
 	if err != nil {
 		http.Error(w, "Failed to delete analysis: "+err.Error(), http.StatusInternalServerError)
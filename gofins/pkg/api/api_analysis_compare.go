@@ -0,0 +1,61 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/flocko-motion/gofins/pkg/analysis"
+	"github.com/flocko-motion/gofins/pkg/db"
+)
+
+// handleCompareAnalyses diffs the results of two analysis packages of the current user
+// GET /api/analysis/compare?base={id}&other={id}&sort=rank|rank_change&profile={uuid}&w_mean=1&...
+// Tickers are ranked per package with the selected scoring profile (see /scores).
+func (s *Server) handleCompareAnalyses(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	baseID := query.Get("base")
+	otherID := query.Get("other")
+	if baseID == "" || otherID == "" {
+		http.Error(w, "Parameters base and other (package IDs) required", http.StatusBadRequest)
+		return
+	}
+	sortBy := query.Get("sort")
+	if sortBy != "" && sortBy != "rank" && sortBy != "rank_change" {
+		http.Error(w, "Invalid sort (must be 'rank' or 'rank_change')", http.StatusBadRequest)
+		return
+	}
+
+	userID := getUserID(r)
+	profile, ok := scoringProfileFromQuery(w, r, userID)
+	if !ok {
+		return
+	}
+
+	base, err := db.GetAnalysisResults(r.Context(), userID, baseID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Base analysis not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to get base results: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	other, err := db.GetAnalysisResults(r.Context(), userID, otherID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Other analysis not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to get other results: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	comparison := analysis.ComparePackages(baseID, base, otherID, other, profile)
+	if sortBy == "rank_change" {
+		analysis.SortChangesByRankChange(comparison.Changes)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comparison)
+}
@@ -4,24 +4,55 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/flocko-motion/gofins/pkg/analysis"
 	"github.com/flocko-motion/gofins/pkg/db"
 	"github.com/flocko-motion/gofins/pkg/f"
 	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
 )
 
 type CreateAnalysisRequest struct {
-	Name         string  `json:"name"`
-	Interval     string  `json:"interval"`  // "weekly" or "monthly"
-	TimeFrom     string  `json:"time_from"` // YYYY, YYYY-MM or YYYY-MM-DD
-	TimeTo       string  `json:"time_to"`   // YYYY, YYYY-MM or YYYY-MM-DD
-	HistBins     int     `json:"hist_bins"`
-	HistMin      float64 `json:"hist_min"`
-	HistMax      float64 `json:"hist_max"`
-	McapMin      *string `json:"mcap_min"`      // e.g., "1B", "500M"
-	InceptionMax *string `json:"inception_max"` // YYYY, YYYY-MM or YYYY-MM-DD
+	Name           string          `json:"name"`
+	Interval       string          `json:"interval"`  // "weekly" or "monthly"
+	TimeFrom       string          `json:"time_from"` // YYYY, YYYY-MM or YYYY-MM-DD
+	TimeTo         string          `json:"time_to"`   // YYYY, YYYY-MM or YYYY-MM-DD
+	HistBins       int             `json:"hist_bins"`
+	HistMin        float64         `json:"hist_min"`
+	HistMax        float64         `json:"hist_max"`
+	McapMin        *string         `json:"mcap_min"`        // e.g., "1B", "500M"
+	McapMax        *string         `json:"mcap_max"`        // e.g., "10B"
+	InceptionMax   *string         `json:"inception_max"`   // YYYY, YYYY-MM or YYYY-MM-DD
+	ReferenceIndex *string         `json:"reference_index"` // Index ticker for beta/correlation/alpha, e.g. "^GSPC"
+	ReturnBasis    *string         `json:"return_basis"`    // "price" or "total_return"
+	RiskFreeRate   *float64        `json:"risk_free_rate"`  // Annual, in percent
+	RollingWindow  int             `json:"rolling_window"`  // Months of rolling YoY statistics per result, 0 = off
+	Outliers       *OutlierRequest `json:"outliers"`        // Outlier strategy, default: balanced
+
+	// Symbol selection, persisted with the package
+	Universe    string                `json:"universe"`     // "all" (default), "favorites", "watchlist" or "tickers"
+	Tickers     []string              `json:"tickers"`      // Explicit universe, implies universe "tickers"
+	WatchlistID *uuid.UUID            `json:"watchlist_id"` // Watchlist of the user, implies universe "watchlist"
+	Sectors     types.IncludeExclude  `json:"sectors"`
+	Industries  types.IncludeExclude  `json:"industries"`
+	Countries   types.IncludeExclude  `json:"countries"`
+	Exchanges   *types.IncludeExclude `json:"exchanges"` // Default for universe "all": exclude OTC exchanges
+	Currencies  types.IncludeExclude  `json:"currencies"`
+	Types       types.IncludeExclude  `json:"types"`
+}
+
+// OutlierRequest selects the outlier strategy of a package, zero parameters use the strategy defaults
+type OutlierRequest struct {
+	Strategy      string   `json:"strategy"` // balanced, conservative, iqr, stddev, percentile, winsorize or none
+	IQRMultiplier float64  `json:"iqr_multiplier"`
+	StdDevs       float64  `json:"stddevs"`
+	AbsMin        *float64 `json:"abs_min"`
+	AbsMax        *float64 `json:"abs_max"`
+	Percentile    int      `json:"percentile"`
+	WinsorLow     int      `json:"winsor_low"`
+	WinsorHigh    int      `json:"winsor_high"`
 }
 
 type CreateAnalysisResponse struct {
@@ -91,7 +122,39 @@ func (s *Server) handleCreateAnalysis(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse mcap_min with default
+	filters, err := parseAnalysisFilters(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// Favorites and watchlists are resolved to their tickers now and stored with the filters,
+	// so re-runs and resumed jobs analyze the same symbols after the list changes
+	switch filters.Universe {
+	case types.UniverseWatchlist:
+		watchlist, err := db.GetWatchlist(r.Context(), getUserID(r), *filters.Watchlist)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if watchlist == nil {
+			http.Error(w, "watchlist not found", http.StatusNotFound)
+			return
+		}
+		for _, entry := range watchlist.Entries {
+			filters.Tickers = append(filters.Tickers, entry.Ticker)
+		}
+	case types.UniverseFavorites:
+		if filters.Tickers, err = db.GetFavorites(r.Context(), getUserID(r)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if filters.Universe != types.UniverseAll && len(filters.Tickers) == 0 {
+		http.Error(w, "the selected universe has no symbols", http.StatusBadRequest)
+		return
+	}
+
+	// Parse mcap_min with default (only for the full universe, explicit selections are taken as is)
 	var mcapMin *int64
 	if req.McapMin != nil && *req.McapMin != "" {
 		parsed, err := f.ParseMarketCap(*req.McapMin)
@@ -100,11 +163,26 @@ func (s *Server) handleCreateAnalysis(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		mcapMin = &parsed
-	} else {
+	} else if filters.Universe == types.UniverseAll {
 		defaultMcap := int64(100_000_000)
 		mcapMin = &defaultMcap
 	}
 
+	// Parse optional mcap_max
+	var mcapMax *int64
+	if req.McapMax != nil && *req.McapMax != "" {
+		parsed, err := f.ParseMarketCap(*req.McapMax)
+		if err != nil {
+			http.Error(w, "Invalid mcap_max format: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if mcapMin != nil && parsed < *mcapMin {
+			http.Error(w, "Invalid mcap_max (must not be below mcap_min)", http.StatusBadRequest)
+			return
+		}
+		mcapMax = &parsed
+	}
+
 	// Parse optional inception_max
 	var inceptionMax *time.Time
 	if req.InceptionMax != nil && *req.InceptionMax != "" {
@@ -116,6 +194,69 @@ func (s *Server) handleCreateAnalysis(w http.ResponseWriter, r *http.Request) {
 		inceptionMax = &parsed
 	}
 
+	// Parse optional reference_index (must be a known symbol of type index)
+	var referenceIndex *string
+	if req.ReferenceIndex != nil && *req.ReferenceIndex != "" {
+		symbol, err := db.GetSymbol(r.Context(), *req.ReferenceIndex)
+		if err != nil {
+			http.Error(w, "Failed to look up reference_index: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if symbol == nil || symbol.Type == nil || *symbol.Type != types.TypeIndex {
+			http.Error(w, "Invalid reference_index (must be a symbol of type 'index')", http.StatusBadRequest)
+			return
+		}
+		referenceIndex = &symbol.Ticker
+	}
+
+	// Parse return_basis with default
+	returnBasis := types.ReturnBasisPrice
+	if req.ReturnBasis != nil && *req.ReturnBasis != "" {
+		returnBasis = types.ReturnBasis(*req.ReturnBasis)
+		if returnBasis != types.ReturnBasisPrice && returnBasis != types.ReturnBasisTotalReturn {
+			http.Error(w, "Invalid return_basis (must be 'price' or 'total_return')", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Parse optional risk_free_rate (default 0)
+	riskFreeRate := 0.0
+	if req.RiskFreeRate != nil {
+		riskFreeRate = *req.RiskFreeRate
+		if riskFreeRate <= -100 || riskFreeRate >= 100 {
+			http.Error(w, "Invalid risk_free_rate (must be an annual rate in percent between -100 and 100)", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Parse optional rolling_window (default off)
+	if req.RollingWindow != 0 && (req.RollingWindow < 12 || req.RollingWindow > 240) {
+		http.Error(w, "Invalid rolling_window (must be 0 or between 12 and 240 months)", http.StatusBadRequest)
+		return
+	}
+
+	// Parse optional outliers (default balanced)
+	outliers := types.OutlierConfig{Strategy: types.OutlierBalanced}
+	if req.Outliers != nil {
+		outliers = types.OutlierConfig{
+			Strategy:      strings.ToLower(strings.TrimSpace(req.Outliers.Strategy)),
+			IQRMultiplier: req.Outliers.IQRMultiplier,
+			StdDevs:       req.Outliers.StdDevs,
+			AbsMin:        req.Outliers.AbsMin,
+			AbsMax:        req.Outliers.AbsMax,
+			Percentile:    req.Outliers.Percentile,
+			WinsorLow:     req.Outliers.WinsorLow,
+			WinsorHigh:    req.Outliers.WinsorHigh,
+		}
+		if outliers.Strategy == "" {
+			outliers.Strategy = types.OutlierBalanced
+		}
+		if err := analysis.ValidateOutlierConfig(outliers); err != nil {
+			http.Error(w, "Invalid outliers: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Use defaults for histogram config if not provided
 	histBins := req.HistBins
 	if histBins == 0 {
@@ -131,18 +272,26 @@ func (s *Server) handleCreateAnalysis(w http.ResponseWriter, r *http.Request) {
 
 	// Create analysis package
 	config := analysis.AnalysisPackageConfig{
-		Name:         req.Name,
-		Interval:     interval,
-		TimeFrom:     timeFrom,
-		TimeTo:       timeTo,
-		HistConfig:   analysis.HistogramConfig{NumBins: histBins, Min: histMin, Max: histMax},
-		McapMin:      mcapMin,
-		InceptionMax: inceptionMax,
+		Name:                req.Name,
+		UserID:              getUserID(r),
+		Interval:            interval,
+		TimeFrom:            timeFrom,
+		TimeTo:              timeTo,
+		HistConfig:          analysis.HistogramConfig{NumBins: histBins, Min: histMin, Max: histMax},
+		McapMin:             mcapMin,
+		McapMax:             mcapMax,
+		InceptionMax:        inceptionMax,
+		Filters:             filters,
+		ReferenceIndex:      referenceIndex,
+		ReturnBasis:         returnBasis,
+		RiskFreeRate:        riskFreeRate,
+		RollingWindowMonths: req.RollingWindow,
+		Outliers:            outliers,
 	}
 
 	fmt.Printf("[API] Creating analysis package with config: %+v\n", config)
 
-	packageID, err := analysis.CreatePackage(r.Context(), s.db, config)
+	packageID, err := s.queue.Submit(r.Context(), config)
 	if err != nil {
 		_ = db.LogError(r.Context(), "api.analysis", "database", "Failed to create analysis package", f.Ptr(err.Error()))
 		http.Error(w, "Failed to create package: "+err.Error(), http.StatusInternalServerError)
@@ -153,7 +302,7 @@ func (s *Server) handleCreateAnalysis(w http.ResponseWriter, r *http.Request) {
 
 	response := CreateAnalysisResponse{
 		PackageID: packageID,
-		Status:    "processing",
+		Status:    types.JobQueued,
 	}
 
 	fmt.Printf("[API] Sending response: %+v\n", response)
@@ -163,3 +312,58 @@ func (s *Server) handleCreateAnalysis(w http.ResponseWriter, r *http.Request) {
 		_ = db.LogError(r.Context(), "api.analysis", "encoding", "Failed to encode response", f.Ptr(err.Error()))
 	}
 }
+
+// parseAnalysisFilters validates the symbol selection of a create request and applies defaults
+func parseAnalysisFilters(req CreateAnalysisRequest) (types.AnalysisFilters, error) {
+	filters := types.AnalysisFilters{
+		Universe:   req.Universe,
+		Sectors:    req.Sectors,
+		Industries: req.Industries,
+		Countries:  req.Countries,
+		Currencies: req.Currencies,
+		Types:      req.Types,
+	}
+
+	if filters.Universe == "" {
+		filters.Universe = types.UniverseAll
+		if len(req.Tickers) > 0 {
+			filters.Universe = types.UniverseTickers
+		} else if req.WatchlistID != nil {
+			filters.Universe = types.UniverseWatchlist
+		}
+	}
+	if req.WatchlistID != nil && filters.Universe != types.UniverseWatchlist {
+		return filters, fmt.Errorf("watchlist_id requires universe 'watchlist'")
+	}
+	switch filters.Universe {
+	case types.UniverseAll, types.UniverseFavorites, types.UniverseWatchlist:
+		if len(req.Tickers) > 0 {
+			return filters, fmt.Errorf("tickers require universe 'tickers'")
+		}
+		if filters.Universe == types.UniverseWatchlist {
+			if req.WatchlistID == nil {
+				return filters, fmt.Errorf("universe 'watchlist' requires watchlist_id")
+			}
+			filters.Watchlist = req.WatchlistID
+		}
+	case types.UniverseTickers:
+		for _, ticker := range req.Tickers {
+			if ticker = strings.ToUpper(strings.TrimSpace(ticker)); ticker != "" {
+				filters.Tickers = append(filters.Tickers, ticker)
+			}
+		}
+		if len(filters.Tickers) == 0 {
+			return filters, fmt.Errorf("universe 'tickers' requires a non-empty tickers list")
+		}
+	default:
+		return filters, fmt.Errorf("invalid universe (must be 'all', 'favorites', 'watchlist' or 'tickers')")
+	}
+
+	if req.Exchanges != nil {
+		filters.Exchanges = *req.Exchanges
+	} else if filters.Universe == types.UniverseAll {
+		filters.Exchanges = types.IncludeExclude{Exclude: types.OTCExchanges}
+	}
+
+	return filters, nil
+}
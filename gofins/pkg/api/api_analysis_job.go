@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/flocko-motion/gofins/pkg/analysis"
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/go-chi/chi/v5"
+)
+
+// handleCancelAnalysis stops the queued or running job of an analysis package
+// POST /api/analysis/{id}/cancel
+func (s *Server) handleCancelAnalysis(w http.ResponseWriter, r *http.Request) {
+	packageID := chi.URLParam(r, "id")
+	if packageID == "" {
+		http.Error(w, "Package ID required", http.StatusBadRequest)
+		return
+	}
+
+	userID := getUserID(r)
+	job, err := db.GetAnalysisJob(r.Context(), userID, packageID)
+	if err != nil {
+		http.Error(w, "Failed to get analysis job: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if job == nil {
+		http.Error(w, "Analysis job not found", http.StatusNotFound)
+		return
+	}
+
+	job, err = s.queue.Cancel(r.Context(), userID, packageID)
+	if errors.Is(err, analysis.ErrJobNotCancellable) {
+		http.Error(w, "Analysis job is not queued or running", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to cancel analysis: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// handleAnalysisProgress returns the job state and progress of an analysis package
+// GET /api/analysis/{id}/progress
+func (s *Server) handleAnalysisProgress(w http.ResponseWriter, r *http.Request) {
+	packageID := chi.URLParam(r, "id")
+	if packageID == "" {
+		http.Error(w, "Package ID required", http.StatusBadRequest)
+		return
+	}
+
+	job, err := db.GetAnalysisJob(r.Context(), getUserID(r), packageID)
+	if err != nil {
+		http.Error(w, "Failed to get analysis job: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if job == nil {
+		http.Error(w, "Analysis job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
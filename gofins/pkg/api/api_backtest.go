@@ -0,0 +1,169 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/backtest"
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type CreateBacktestRequest struct {
+	Name      string                  `json:"name"`
+	Holdings  []types.BacktestHolding `json:"holdings"`  // [{ "ticker": "AAPL", "weight": 0.6 }, ...]
+	TopN      *BacktestTopNRequest    `json:"top_n"`     // Alternative to holdings
+	Rebalance string                  `json:"rebalance"` // "monthly" (default), "quarterly" or "yearly"
+	Interval  string                  `json:"interval"`  // "monthly" (default) or "weekly"
+	Start     string                  `json:"start"`     // YYYY, YYYY-MM or YYYY-MM-DD
+	End       string                  `json:"end"`       // YYYY, YYYY-MM or YYYY-MM-DD
+	Benchmark *string                 `json:"benchmark"` // Index ticker, e.g. "^GSPC"
+}
+
+type BacktestTopNRequest struct {
+	PackageID string     `json:"package_id"`
+	N         int        `json:"n"`
+	ProfileID *uuid.UUID `json:"profile_id"`
+}
+
+// handleBacktests handles the collection of backtests
+// GET  /api/backtests - List backtests of the current user (without results)
+// POST /api/backtests - Run a backtest and store it
+func (s *Server) handleBacktests(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		backtests, err := db.ListBacktests(r.Context(), userID)
+		if err != nil {
+			http.Error(w, "Failed to list backtests: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(backtests)
+
+	case http.MethodPost:
+		var req CreateBacktestRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		config, err := parseBacktestRequest(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		created, err := backtest.Create(r.Context(), userID, strings.TrimSpace(req.Name), config)
+		switch {
+		case errors.Is(err, backtest.ErrPackageNotFound), errors.Is(err, backtest.ErrProfileNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, backtest.ErrInvalidBenchmark), errors.Is(err, backtest.ErrNoHoldings), errors.Is(err, backtest.ErrNoPrices):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			_ = db.LogError(r.Context(), "api.backtest", "backtest", "Failed to run backtest", f.Ptr(err.Error()))
+			http.Error(w, "Failed to run backtest: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(created)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleBacktest handles a single backtest
+// GET    /api/backtests/{id}
+// DELETE /api/backtests/{id}
+func (s *Server) handleBacktest(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+	backtestID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid backtest ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		stored, err := db.GetBacktest(r.Context(), userID, backtestID)
+		if err != nil {
+			http.Error(w, "Failed to get backtest: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if stored == nil {
+			http.Error(w, "Backtest not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stored)
+
+	case http.MethodDelete:
+		deleted, err := db.DeleteBacktest(r.Context(), userID, backtestID)
+		if err != nil {
+			http.Error(w, "Failed to delete backtest: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !deleted {
+			http.Error(w, "Backtest not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// parseBacktestRequest applies the defaults of a create request and validates it
+func parseBacktestRequest(req CreateBacktestRequest) (types.BacktestConfig, error) {
+	config := types.BacktestConfig{
+		Holdings:  req.Holdings,
+		Rebalance: req.Rebalance,
+		Interval:  types.PriceInterval(req.Interval),
+	}
+	if config.Rebalance == "" {
+		config.Rebalance = types.RebalanceMonthly
+	}
+	if config.Interval == "" {
+		config.Interval = types.IntervalMonthly
+	}
+	if req.TopN != nil {
+		config.TopN = &types.BacktestTopN{
+			PackageID: req.TopN.PackageID,
+			N:         req.TopN.N,
+			ProfileID: req.TopN.ProfileID,
+		}
+	}
+	if req.Benchmark != nil && *req.Benchmark != "" {
+		config.Benchmark = f.Ptr(strings.ToUpper(*req.Benchmark))
+	}
+
+	if req.Start == "" {
+		return config, fmt.Errorf("start is required")
+	}
+	start, err := f.ParseDate(req.Start)
+	if err != nil {
+		return config, fmt.Errorf("invalid start: %w", err)
+	}
+	config.Start = start
+
+	config.End = time.Now().UTC().Truncate(24 * time.Hour)
+	if req.End != "" {
+		if config.End, err = f.ParseDate(req.End); err != nil {
+			return config, fmt.Errorf("invalid end: %w", err)
+		}
+	}
+
+	return config, backtest.Validate(config)
+}
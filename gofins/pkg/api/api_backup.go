@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/backup"
+	"github.com/flocko-motion/gofins/pkg/db"
+)
+
+// maxBackupUploadSize limits the size of an uploaded backup (256 MB)
+const maxBackupUploadSize = 256 << 20
+
+// handleBackupExport returns all data of the current user as a downloadable backup
+// GET /api/backup/export?format=json|tar.gz (default json)
+func (s *Server) handleBackupExport(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = backup.FormatJSON
+	}
+	if format != backup.FormatJSON && format != backup.FormatTarGz {
+		http.Error(w, "Invalid format (must be 'json' or 'tar.gz')", http.StatusBadRequest)
+		return
+	}
+
+	user, err := db.GetUserByID(r.Context(), getUserID(r))
+	if err != nil || user == nil {
+		http.Error(w, "Failed to get user", http.StatusInternalServerError)
+		return
+	}
+
+	b, err := backup.Export(r.Context(), user)
+	if err != nil {
+		http.Error(w, "Failed to export backup: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	contentType := "application/json"
+	if format == backup.FormatTarGz {
+		contentType = "application/gzip"
+	}
+	filename := fmt.Sprintf("gofins-%s-%s.%s", user.Name, time.Now().Format("20060102"), format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if err := backup.Encode(w, b, format); err != nil {
+		fmt.Printf("[API] Failed to write backup for '%s': %v\n", user.Name, err)
+	}
+}
+
+// handleBackupImport restores a backup (JSON or tar.gz) into the current user's account
+// POST /api/backup/import?policy=skip|overwrite|merge (default skip)
+func (s *Server) handleBackupImport(w http.ResponseWriter, r *http.Request) {
+	policy := r.URL.Query().Get("policy")
+	if policy == "" {
+		policy = backup.PolicySkip
+	}
+	if !backup.IsValidPolicy(policy) {
+		http.Error(w, "Invalid policy (must be 'skip', 'overwrite' or 'merge')", http.StatusBadRequest)
+		return
+	}
+
+	b, err := backup.Decode(http.MaxBytesReader(w, r.Body, maxBackupUploadSize))
+	if err != nil {
+		http.Error(w, "Invalid backup: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := backup.Import(r.Context(), getUserID(r), b, policy)
+	if err != nil {
+		http.Error(w, "Failed to import backup: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
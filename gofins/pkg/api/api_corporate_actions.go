@@ -0,0 +1,38 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/go-chi/chi/v5"
+)
+
+// handleGetCorporateActions returns the stored dividend and split history of a symbol
+// GET /api/symbol/{ticker}/corporate-actions
+func (s *Server) handleGetCorporateActions(w http.ResponseWriter, r *http.Request) {
+	ticker := chi.URLParam(r, "ticker")
+	if ticker == "" {
+		http.Error(w, "ticker required", http.StatusBadRequest)
+		return
+	}
+
+	dividends, err := db.GetDividends(ticker)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	splits, err := db.GetSplits(ticker)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"ticker":    ticker,
+		"dividends": dividends,
+		"splits":    splits,
+	})
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/go-chi/chi/v5"
+)
+
+// DataIssueResponse is a data issue with the error logged by the audit run that found it
+type DataIssueResponse struct {
+	types.DataIssue
+	Error *db.ErrorEntry `json:"error"`
+}
+
+// handleDataIssues lists the findings of the price audit, most recently seen first
+// GET /api/data-issues?ticker=AAPL&kind=gap&status=open&errorId=12&limit=100
+func (s *Server) handleDataIssues(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := db.DataIssueFilter{
+		Ticker: query.Get("ticker"),
+		Kind:   query.Get("kind"),
+		Status: types.IssueOpen,
+		Limit:  100,
+	}
+
+	switch status := query.Get("status"); status {
+	case "":
+	case "all":
+		filter.Status = ""
+	case types.IssueOpen, types.IssueResolved, types.IssueIgnored:
+		filter.Status = status
+	default:
+		http.Error(w, "Invalid status, must be open, resolved, ignored or all", http.StatusBadRequest)
+		return
+	}
+	if errorIDStr := query.Get("errorId"); errorIDStr != "" {
+		errorID, err := strconv.Atoi(errorIDStr)
+		if err != nil || errorID <= 0 {
+			http.Error(w, "Invalid errorId", http.StatusBadRequest)
+			return
+		}
+		filter.ErrorID = errorID
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	issues, err := db.ListDataIssues(r.Context(), filter)
+	if err != nil {
+		http.Error(w, "Failed to list data issues: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(issues)
+}
+
+// handleDataIssueSummary returns the number of data issues by kind and status
+// GET /api/data-issues/summary
+func (s *Server) handleDataIssueSummary(w http.ResponseWriter, r *http.Request) {
+	counts, err := db.CountDataIssues(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to count data issues: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(counts)
+}
+
+// handleDataIssue returns a data issue with its linked error
+// GET /api/data-issues/{id}
+func (s *Server) handleDataIssue(w http.ResponseWriter, r *http.Request) {
+	s.respondDataIssue(w, r)
+}
+
+// handleDataIssueIgnore ignores an open issue, so later audits keep it ignored, or reopens an ignored one
+// POST   /api/data-issues/{id}/ignore
+// DELETE /api/data-issues/{id}/ignore
+func (s *Server) handleDataIssueIgnore(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid issue ID", http.StatusBadRequest)
+		return
+	}
+
+	status := types.IssueIgnored
+	if r.Method == http.MethodDelete {
+		status = types.IssueOpen
+	}
+	updated, err := db.SetDataIssueStatus(r.Context(), id, status)
+	if err != nil {
+		http.Error(w, "Failed to update data issue: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !updated {
+		issue, err := db.GetDataIssue(r.Context(), id)
+		if err == nil && issue != nil {
+			http.Error(w, "Data issue is resolved", http.StatusConflict)
+			return
+		}
+		http.Error(w, "Data issue not found", http.StatusNotFound)
+		return
+	}
+	s.respondDataIssue(w, r)
+}
+
+func (s *Server) respondDataIssue(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid issue ID", http.StatusBadRequest)
+		return
+	}
+
+	issue, err := db.GetDataIssue(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Failed to get data issue: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if issue == nil {
+		http.Error(w, "Data issue not found", http.StatusNotFound)
+		return
+	}
+
+	response := DataIssueResponse{DataIssue: *issue}
+	if issue.ErrorID != nil {
+		// The error may have been cleared, then the link is gone as well
+		if entry, err := db.GetErrorByID(r.Context(), *issue.ErrorID); err == nil {
+			response.Error = entry
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
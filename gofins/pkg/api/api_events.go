@@ -0,0 +1,65 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/events"
+)
+
+// eventsKeepAlive is the interval of comment lines that keep idle connections open through proxies
+const eventsKeepAlive = 15 * time.Second
+
+// handleEvents streams live events as Server-Sent Events: analysis progress of the current
+// user and, for admins, updater job events
+// GET /api/events
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	userID := getUserID(r)
+	user, err := db.GetUserByID(r.Context(), userID)
+	if err != nil || user == nil {
+		http.Error(w, "Authentication error", http.StatusInternalServerError)
+		return
+	}
+
+	ch, unsubscribe := events.Subscribe(userID, user.IsAdmin)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable proxy buffering (nginx)
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case event, ok := <-ch:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/go-chi/chi/v5"
+)
+
+// JournalTickersRequest is the body for linking/unlinking tickers
+type JournalTickersRequest struct {
+	Tickers []string `json:"tickers"`
+}
+
+// handleJournal handles the journal collection
+// GET  /api/journal?type=idea&tag=macro&ticker=AAPL&from=2024&to=2024-12 - List entries (all filters optional)
+// POST /api/journal - Create entry
+func (s *Server) handleJournal(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		from, to, err := parseDateRange(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		entries, err := db.ListJournalEntries(r.Context(), userID, db.JournalFilter{
+			Type:   r.URL.Query().Get("type"),
+			Tag:    r.URL.Query().Get("tag"),
+			Ticker: r.URL.Query().Get("ticker"),
+			From:   from,
+			To:     to,
+		})
+		if err != nil {
+			http.Error(w, "Failed to list journal: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(entries)
+
+	case http.MethodPost:
+		entry, ok := decodeJournalEntry(w, r)
+		if !ok {
+			return
+		}
+		created, err := db.CreateJournalEntry(r.Context(), userID, entry)
+		if err != nil {
+			http.Error(w, "Failed to create journal entry: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(created)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleJournalEntry handles a single journal entry
+// GET    /api/journal/{id}
+// PUT    /api/journal/{id} - Replace entry (tickers are only replaced if given)
+// DELETE /api/journal/{id}
+func (s *Server) handleJournalEntry(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid journal entry ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		entry, err := db.GetJournalEntry(r.Context(), userID, id)
+		if err != nil {
+			http.Error(w, "Failed to get journal entry: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if entry == nil {
+			http.Error(w, "Journal entry not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(entry)
+
+	case http.MethodPut:
+		entry, ok := decodeJournalEntry(w, r)
+		if !ok {
+			return
+		}
+		entry.ID = id
+		updated, err := db.UpdateJournalEntry(r.Context(), userID, entry)
+		if err != nil {
+			http.Error(w, "Failed to update journal entry: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if updated == nil {
+			http.Error(w, "Journal entry not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(updated)
+
+	case http.MethodDelete:
+		deleted, err := db.DeleteJournalEntry(r.Context(), userID, id)
+		if err != nil {
+			http.Error(w, "Failed to delete journal entry: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !deleted {
+			http.Error(w, "Journal entry not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleJournalTickers links or unlinks tickers of a journal entry
+// POST   /api/journal/{id}/tickers - Link tickers
+// DELETE /api/journal/{id}/tickers - Unlink tickers
+func (s *Server) handleJournalTickers(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid journal entry ID", http.StatusBadRequest)
+		return
+	}
+
+	var req JournalTickersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Tickers) == 0 {
+		http.Error(w, "tickers required", http.StatusBadRequest)
+		return
+	}
+
+	var entry *types.JournalEntry
+	switch r.Method {
+	case http.MethodPost:
+		entry, err = db.LinkJournalTickers(r.Context(), userID, id, req.Tickers)
+	case http.MethodDelete:
+		entry, err = db.UnlinkJournalTickers(r.Context(), userID, id, req.Tickers)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update journal tickers: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if entry == nil {
+		http.Error(w, "Journal entry not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+}
+
+// handleJournalByTicker returns all journal entries linked to a ticker
+// GET /api/journal/ticker/{ticker}
+func (s *Server) handleJournalByTicker(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+	ticker := chi.URLParam(r, "ticker")
+	if ticker == "" {
+		http.Error(w, "ticker required", http.StatusBadRequest)
+		return
+	}
+
+	entries, err := db.ListJournalEntriesByTicker(r.Context(), userID, ticker)
+	if err != nil {
+		http.Error(w, "Failed to list journal: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// handleTimeline returns ratings and journal entries merged into one timeline, newest first
+// GET /api/timeline?kind=rating|journal&ticker=AAPL&from=2024&to=2024-12&limit=100 (all filters optional)
+func (s *Server) handleTimeline(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit := 0
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		limit, err = parsePositiveIntParam(limitParam, 0)
+		if err != nil {
+			http.Error(w, "Invalid limit: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	timeline, err := db.GetTimeline(r.Context(), userID, db.TimelineFilter{
+		Kind:   r.URL.Query().Get("kind"),
+		Ticker: r.URL.Query().Get("ticker"),
+		From:   from,
+		To:     to,
+		Limit:  limit,
+	})
+	if err != nil {
+		http.Error(w, "Failed to get timeline: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(timeline)
+}
+
+// decodeJournalEntry reads and validates a journal entry from the request body.
+// Writes the error response and returns false if the body is invalid.
+func decodeJournalEntry(w http.ResponseWriter, r *http.Request) (types.JournalEntry, bool) {
+	var entry types.JournalEntry
+	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return entry, false
+	}
+	if entry.Title == "" {
+		http.Error(w, "Title is required", http.StatusBadRequest)
+		return entry, false
+	}
+	if entry.Type == "" {
+		entry.Type = types.JournalTypeNote
+	}
+	if !types.IsValidJournalType(entry.Type) {
+		http.Error(w, "Invalid type (must be one of: "+strings.Join(types.JournalTypes, ", ")+")", http.StatusBadRequest)
+		return entry, false
+	}
+	return entry, true
+}
+
+// parseDateRange parses the optional from/to query parameters (YYYY, YYYY-MM or YYYY-MM-DD).
+// to is returned as the exclusive end of its period, so to=2024-12 includes all of December.
+func parseDateRange(r *http.Request) (*time.Time, *time.Time, error) {
+	var from, to *time.Time
+	if v := r.URL.Query().Get("from"); v != "" {
+		parsed, err := f.ParseDate(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		from = &parsed
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		parsed, err := f.ParseDateEnd(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		to = &parsed
+	}
+	return from, to, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
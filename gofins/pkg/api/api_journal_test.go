@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDateRangeToMonth(t *testing.T) {
+	r := httptest.NewRequest("GET", "/api/journal?from=2024&to=2024-12", nil)
+	from, to, err := parseDateRange(r)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), *from)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), *to, "to is the exclusive end of the month")
+}
+
+func TestParseDateRangeToDayAndYear(t *testing.T) {
+	_, to, err := parseDateRange(httptest.NewRequest("GET", "/api/timeline?to=2024-02-28", nil))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), *to)
+
+	_, to, err = parseDateRange(httptest.NewRequest("GET", "/api/timeline?to=2024", nil))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), *to)
+
+	_, _, err = parseDateRange(httptest.NewRequest("GET", "/api/timeline?to=24-12", nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,14 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/flocko-motion/gofins/pkg/ratelimit"
+)
+
+// handleMetrics exposes the request counters and quotas of the API rate limiters in the Prometheus text format
+// GET /api/metrics
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	ratelimit.WriteMetrics(w)
+}
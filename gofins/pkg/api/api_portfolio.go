@@ -0,0 +1,340 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/portfolio"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// maxTransactionsUploadSize limits the size of an uploaded broker CSV export (10 MB)
+const maxTransactionsUploadSize = 10 << 20
+
+type PortfolioRequest struct {
+	Name      string `json:"name"`
+	CostBasis string `json:"cost_basis"` // "fifo" (default) or "average"
+}
+
+type TransactionRequest struct {
+	Ticker   string   `json:"ticker"`
+	Type     string   `json:"type"` // "buy", "sell" or "dividend"
+	Date     string   `json:"date"` // YYYY-MM-DD
+	Quantity float64  `json:"quantity"`
+	Price    float64  `json:"price"`    // Per share, in currency
+	Amount   float64  `json:"amount"`   // Dividend cash, in currency
+	Fee      float64  `json:"fee"`      // In currency
+	Currency string   `json:"currency"` // Default "USD"
+	FxRate   *float64 `json:"fx_rate"`  // USD per unit of currency, looked up for the date if omitted
+	Note     *string  `json:"note"`
+}
+
+// ImportTransactionsResponse reports the outcome of a CSV import
+type ImportTransactionsResponse struct {
+	Imported int                     `json:"imported"`
+	Errors   []portfolio.ImportError `json:"errors"`
+}
+
+// handlePortfolios handles the collection of portfolios
+// GET  /api/portfolios - List portfolios of the current user
+// POST /api/portfolios - Create a portfolio
+func (s *Server) handlePortfolios(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		portfolios, err := db.ListPortfolios(r.Context(), userID)
+		if err != nil {
+			http.Error(w, "Failed to list portfolios: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(portfolios)
+
+	case http.MethodPost:
+		req, ok := decodePortfolioRequest(w, r)
+		if !ok {
+			return
+		}
+		created, err := db.CreatePortfolio(r.Context(), userID, req.Name, req.CostBasis)
+		if err != nil {
+			http.Error(w, "Failed to create portfolio: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(created)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handlePortfolio handles a single portfolio
+// GET    /api/portfolios/{id}
+// PUT    /api/portfolios/{id}
+// DELETE /api/portfolios/{id} - Deletes the portfolio with all its transactions
+func (s *Server) handlePortfolio(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+	portfolioID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid portfolio ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		stored, ok := getPortfolio(w, r)
+		if !ok {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stored)
+
+	case http.MethodPut:
+		req, ok := decodePortfolioRequest(w, r)
+		if !ok {
+			return
+		}
+		updated, err := db.UpdatePortfolio(r.Context(), userID, types.Portfolio{
+			ID:        portfolioID,
+			Name:      req.Name,
+			CostBasis: req.CostBasis,
+		})
+		if err != nil {
+			http.Error(w, "Failed to update portfolio: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if updated == nil {
+			http.Error(w, "Portfolio not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(updated)
+
+	case http.MethodDelete:
+		deleted, err := db.DeletePortfolio(r.Context(), userID, portfolioID)
+		if err != nil {
+			http.Error(w, "Failed to delete portfolio: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !deleted {
+			http.Error(w, "Portfolio not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handlePortfolioTransactions handles the transactions of a portfolio
+// GET  /api/portfolios/{id}/transactions - List transactions ordered by date
+// POST /api/portfolios/{id}/transactions - Add a transaction
+func (s *Server) handlePortfolioTransactions(w http.ResponseWriter, r *http.Request) {
+	stored, ok := getPortfolio(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		transactions, err := db.ListPortfolioTransactions(r.Context(), stored.ID)
+		if err != nil {
+			http.Error(w, "Failed to list transactions: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(transactions)
+
+	case http.MethodPost:
+		var req TransactionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		date, err := f.ParseDate(req.Date)
+		if err != nil {
+			http.Error(w, "Invalid date: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		tx := types.Transaction{
+			Ticker:   req.Ticker,
+			Type:     strings.ToLower(req.Type),
+			Date:     date,
+			Quantity: req.Quantity,
+			Price:    req.Price,
+			Amount:   req.Amount,
+			Fee:      req.Fee,
+			Currency: req.Currency,
+			Note:     req.Note,
+		}
+		if req.FxRate != nil {
+			if *req.FxRate <= 0 {
+				http.Error(w, "fx_rate must be positive", http.StatusBadRequest)
+				return
+			}
+			tx.FxRate = *req.FxRate
+		}
+
+		created, ok := addTransactions(w, r, stored, []types.Transaction{tx})
+		if !ok {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(created[0])
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handlePortfolioTransaction deletes a transaction of a portfolio
+// DELETE /api/portfolios/{id}/transactions/{txid}
+func (s *Server) handlePortfolioTransaction(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := uuid.Parse(chi.URLParam(r, "txid"))
+	if err != nil {
+		http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
+		return
+	}
+	stored, ok := getPortfolio(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := portfolio.DeleteTransaction(r.Context(), stored, transactionID)
+	if errors.Is(err, portfolio.ErrOversold) {
+		http.Error(w, "Cannot delete transaction: "+err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to delete transaction: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePortfolioImport imports transactions from a broker CSV export sent as request body.
+// The import is all or nothing: if any line is invalid, nothing is stored and the errors are returned with 422.
+// POST /api/portfolios/{id}/import
+func (s *Server) handlePortfolioImport(w http.ResponseWriter, r *http.Request) {
+	stored, ok := getPortfolio(w, r)
+	if !ok {
+		return
+	}
+
+	transactions, importErrors, err := portfolio.ParseCSV(http.MaxBytesReader(w, r.Body, maxTransactionsUploadSize))
+	if err != nil {
+		http.Error(w, "Invalid CSV: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(importErrors) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(ImportTransactionsResponse{Errors: importErrors})
+		return
+	}
+	if len(transactions) == 0 {
+		http.Error(w, "CSV contains no transactions", http.StatusBadRequest)
+		return
+	}
+
+	created, ok := addTransactions(w, r, stored, transactions)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ImportTransactionsResponse{Imported: len(created), Errors: importErrors})
+}
+
+// handlePortfolioPositions returns the positions of a portfolio valued at current prices
+// GET /api/portfolios/{id}/positions?method=fifo|average (default: the portfolio's cost basis method)
+func (s *Server) handlePortfolioPositions(w http.ResponseWriter, r *http.Request) {
+	stored, ok := getPortfolio(w, r)
+	if !ok {
+		return
+	}
+	method := r.URL.Query().Get("method")
+	if method != "" && !types.IsValidCostBasis(method) {
+		http.Error(w, "Invalid method (must be 'fifo' or 'average')", http.StatusBadRequest)
+		return
+	}
+
+	valuation, err := portfolio.Valuate(r.Context(), stored, method)
+	if err != nil {
+		http.Error(w, "Failed to calculate positions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(valuation)
+}
+
+// getPortfolio loads the portfolio {id} of the current user.
+// Writes the error response and returns false if the ID is invalid or the portfolio doesn't exist.
+func getPortfolio(w http.ResponseWriter, r *http.Request) (*types.Portfolio, bool) {
+	portfolioID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid portfolio ID", http.StatusBadRequest)
+		return nil, false
+	}
+	stored, err := db.GetPortfolio(r.Context(), getUserID(r), portfolioID)
+	if err != nil {
+		http.Error(w, "Failed to get portfolio: "+err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if stored == nil {
+		http.Error(w, "Portfolio not found", http.StatusNotFound)
+		return nil, false
+	}
+	return stored, true
+}
+
+// addTransactions stores transactions and maps their validation errors to 400.
+// Writes the error response and returns false on failure.
+func addTransactions(w http.ResponseWriter, r *http.Request, stored *types.Portfolio, transactions []types.Transaction) ([]types.Transaction, bool) {
+	created, err := portfolio.AddTransactions(r.Context(), stored, transactions)
+	switch {
+	case errors.Is(err, portfolio.ErrInvalidTransaction), errors.Is(err, portfolio.ErrNoFxRate), errors.Is(err, portfolio.ErrOversold):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	case err != nil:
+		http.Error(w, "Failed to add transactions: "+err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return created, true
+}
+
+// decodePortfolioRequest reads and validates a portfolio from the request body.
+// Writes the error response and returns false if the body is invalid.
+func decodePortfolioRequest(w http.ResponseWriter, r *http.Request) (PortfolioRequest, bool) {
+	var req PortfolioRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return req, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return req, false
+	}
+	if req.CostBasis == "" {
+		req.CostBasis = types.CostBasisFIFO
+	}
+	if !types.IsValidCostBasis(req.CostBasis) {
+		http.Error(w, "Invalid cost_basis (must be 'fifo' or 'average')", http.StatusBadRequest)
+		return req, false
+	}
+	return req, true
+}
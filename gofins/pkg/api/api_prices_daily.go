@@ -0,0 +1,49 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/go-chi/chi/v5"
+)
+
+func (s *Server) handleGetDailyPrices(w http.ResponseWriter, r *http.Request) {
+	ticker := chi.URLParam(r, "ticker")
+	if ticker == "" {
+		http.Error(w, "ticker required", http.StatusBadRequest)
+		return
+	}
+
+	fromParam, toParam, err := parseDateRange(r)
+	if err != nil {
+		http.Error(w, "Invalid date: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Default to last year
+	to := time.Now()
+	if toParam != nil {
+		to = toParam.AddDate(0, 0, -1) // GetDailyPrices includes the end date
+	}
+	from := to.AddDate(-1, 0, 0)
+	if fromParam != nil {
+		from = *fromParam
+	}
+
+	prices, err := db.GetDailyPrices(ticker, from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"ticker": ticker,
+		"from":   from,
+		"to":     to,
+		"count":  len(prices),
+		"prices": prices,
+	})
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/flocko-motion/gofins/pkg/scheduler"
+	"github.com/go-chi/chi/v5"
+)
+
+type SetScheduleRequest struct {
+	Schedule string `json:"schedule"` // Cron expression, empty restores the default
+}
+
+// handleSchedulerJobs lists the updater jobs with their schedule, state, next and last run
+// GET /api/scheduler/jobs
+func (s *Server) handleSchedulerJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, err := s.scheduler.Jobs(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to list jobs: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jobs)
+}
+
+// handleSchedulerJob returns a single updater job
+// GET /api/scheduler/jobs/{name}
+func (s *Server) handleSchedulerJob(w http.ResponseWriter, r *http.Request) {
+	s.respondSchedulerJob(w, r, http.StatusOK)
+}
+
+// handleSchedulerTrigger runs a job as soon as the scheduler is idle, even if it is paused
+// POST /api/scheduler/jobs/{name}/trigger
+func (s *Server) handleSchedulerTrigger(w http.ResponseWriter, r *http.Request) {
+	if err := s.scheduler.Trigger(r.Context(), chi.URLParam(r, "name")); err != nil {
+		respondSchedulerError(w, err)
+		return
+	}
+	s.respondSchedulerJob(w, r, http.StatusAccepted)
+}
+
+// handleSchedulerPause suspends or resumes the scheduled runs of a job
+// POST /api/scheduler/jobs/{name}/pause
+// POST /api/scheduler/jobs/{name}/resume
+func (s *Server) handleSchedulerPause(w http.ResponseWriter, r *http.Request) {
+	paused := !strings.HasSuffix(r.URL.Path, "/resume")
+	if err := s.scheduler.SetPaused(r.Context(), chi.URLParam(r, "name"), paused); err != nil {
+		respondSchedulerError(w, err)
+		return
+	}
+	s.respondSchedulerJob(w, r, http.StatusOK)
+}
+
+// handleSchedulerSkip marks or unmarks the next scheduled run of a job to be skipped
+// POST   /api/scheduler/jobs/{name}/skip
+// DELETE /api/scheduler/jobs/{name}/skip
+func (s *Server) handleSchedulerSkip(w http.ResponseWriter, r *http.Request) {
+	if err := s.scheduler.SetSkipNext(r.Context(), chi.URLParam(r, "name"), r.Method == http.MethodPost); err != nil {
+		respondSchedulerError(w, err)
+		return
+	}
+	s.respondSchedulerJob(w, r, http.StatusOK)
+}
+
+// handleSchedulerSchedule overrides the cron schedule of a job
+// PUT /api/scheduler/jobs/{name}/schedule
+func (s *Server) handleSchedulerSchedule(w http.ResponseWriter, r *http.Request) {
+	var req SetScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := s.scheduler.SetSchedule(r.Context(), chi.URLParam(r, "name"), strings.TrimSpace(req.Schedule)); err != nil {
+		respondSchedulerError(w, err)
+		return
+	}
+	s.respondSchedulerJob(w, r, http.StatusOK)
+}
+
+// handleSchedulerRuns returns the run history, newest first
+// GET /api/scheduler/runs?job=prices&limit=50
+func (s *Server) handleSchedulerRuns(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	runs, err := s.scheduler.History(r.Context(), r.URL.Query().Get("job"), limit)
+	if err != nil {
+		respondSchedulerError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(runs)
+}
+
+func (s *Server) respondSchedulerJob(w http.ResponseWriter, r *http.Request, status int) {
+	job, err := s.scheduler.Job(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		respondSchedulerError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(job)
+}
+
+func respondSchedulerError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, scheduler.ErrUnknownJob):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, scheduler.ErrInvalidSchedule):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
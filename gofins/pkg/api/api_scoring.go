@@ -0,0 +1,276 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/flocko-motion/gofins/pkg/analysis"
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultScoresPageSize = 50
+	maxScoresPageSize     = 500
+)
+
+// ScoresResponse is a page of ranked analysis results
+type ScoresResponse struct {
+	Total    int                  `json:"total"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"page_size"`
+	Profile  types.ScoringProfile `json:"profile"`
+	Results  []types.ScoredResult `json:"results"`
+}
+
+// handleAnalysisScores ranks the results of a package by a weighted score
+// GET /api/analysis/{id}/scores?profile={uuid}&w_mean=1&w_stddev=1&w_min=0&w_max=0&w_beta=0&normalization=zscore&page=1&page_size=50
+// Weights and normalization given as query parameters override the selected profile.
+func (s *Server) handleAnalysisScores(w http.ResponseWriter, r *http.Request) {
+	packageID := chi.URLParam(r, "id")
+	if packageID == "" {
+		http.Error(w, "Package ID required", http.StatusBadRequest)
+		return
+	}
+	userID := getUserID(r)
+	query := r.URL.Query()
+
+	profile, ok := scoringProfileFromQuery(w, r, userID)
+	if !ok {
+		return
+	}
+
+	page, err := parsePositiveIntParam(query.Get("page"), 1)
+	if err != nil {
+		http.Error(w, "Invalid page: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	pageSize, err := parsePositiveIntParam(query.Get("page_size"), defaultScoresPageSize)
+	if err != nil {
+		http.Error(w, "Invalid page_size: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	pageSize = min(pageSize, maxScoresPageSize)
+
+	results, err := db.GetAnalysisResults(r.Context(), userID, packageID)
+	if err != nil {
+		http.Error(w, "Failed to get results: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	scored := analysis.ScoreResults(results, profile)
+	start := min((page-1)*pageSize, len(scored))
+	end := min(start+pageSize, len(scored))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ScoresResponse{
+		Total:    len(scored),
+		Page:     page,
+		PageSize: pageSize,
+		Profile:  profile,
+		Results:  scored[start:end],
+	})
+}
+
+// scoringProfileFromQuery selects the scoring profile of a request: the stored profile given as
+// ?profile={uuid} or the default, overridden by w_* weights and normalization parameters.
+// Writes an error response and returns false if a parameter is invalid.
+func scoringProfileFromQuery(w http.ResponseWriter, r *http.Request, userID uuid.UUID) (types.ScoringProfile, bool) {
+	query := r.URL.Query()
+
+	profile := analysis.DefaultScoringProfile
+	if profileParam := query.Get("profile"); profileParam != "" {
+		profileID, err := uuid.Parse(profileParam)
+		if err != nil {
+			http.Error(w, "Invalid profile ID", http.StatusBadRequest)
+			return profile, false
+		}
+		stored, err := db.GetScoringProfile(r.Context(), userID, profileID)
+		if err != nil {
+			http.Error(w, "Failed to get scoring profile: "+err.Error(), http.StatusInternalServerError)
+			return profile, false
+		}
+		if stored == nil {
+			http.Error(w, "Scoring profile not found", http.StatusNotFound)
+			return profile, false
+		}
+		profile = *stored
+	}
+
+	weights := []struct {
+		param  string
+		target *float64
+	}{
+		{"w_mean", &profile.WeightMean},
+		{"w_stddev", &profile.WeightStdDev},
+		{"w_min", &profile.WeightMin},
+		{"w_max", &profile.WeightMax},
+		{"w_beta", &profile.WeightBeta},
+	}
+	for _, weight := range weights {
+		if value := query.Get(weight.param); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				http.Error(w, "Invalid "+weight.param+": "+err.Error(), http.StatusBadRequest)
+				return profile, false
+			}
+			if math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+				http.Error(w, "Invalid "+weight.param+": must be a finite number", http.StatusBadRequest)
+				return profile, false
+			}
+			*weight.target = parsed
+		}
+	}
+	if normalization := query.Get("normalization"); normalization != "" {
+		profile.Normalization = normalization
+	}
+	if !isValidNormalization(profile.Normalization) {
+		http.Error(w, "Invalid normalization (must be 'zscore' or 'rank')", http.StatusBadRequest)
+		return profile, false
+	}
+	return profile, true
+}
+
+// handleScoringProfiles handles the collection of scoring profiles
+// GET  /api/scoring-profiles - List profiles of the current user
+// POST /api/scoring-profiles - Create a profile
+func (s *Server) handleScoringProfiles(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		profiles, err := db.ListScoringProfiles(r.Context(), userID)
+		if err != nil {
+			http.Error(w, "Failed to list scoring profiles: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(profiles)
+
+	case http.MethodPost:
+		profile, ok := decodeScoringProfile(w, r)
+		if !ok {
+			return
+		}
+		created, err := db.CreateScoringProfile(r.Context(), userID, profile)
+		if err != nil {
+			if db.IsUniqueViolation(err) {
+				http.Error(w, "A scoring profile named '"+profile.Name+"' already exists", http.StatusConflict)
+				return
+			}
+			http.Error(w, "Failed to create scoring profile: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(created)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleScoringProfile handles a single scoring profile
+// GET    /api/scoring-profiles/{id}
+// PUT    /api/scoring-profiles/{id}
+// DELETE /api/scoring-profiles/{id}
+func (s *Server) handleScoringProfile(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+	profileID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid profile ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		profile, err := db.GetScoringProfile(r.Context(), userID, profileID)
+		if err != nil {
+			http.Error(w, "Failed to get scoring profile: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if profile == nil {
+			http.Error(w, "Scoring profile not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(profile)
+
+	case http.MethodPut:
+		profile, ok := decodeScoringProfile(w, r)
+		if !ok {
+			return
+		}
+		profile.ID = profileID
+		updated, err := db.UpdateScoringProfile(r.Context(), userID, profile)
+		if err != nil {
+			if db.IsUniqueViolation(err) {
+				http.Error(w, "A scoring profile named '"+profile.Name+"' already exists", http.StatusConflict)
+				return
+			}
+			http.Error(w, "Failed to update scoring profile: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if updated == nil {
+			http.Error(w, "Scoring profile not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(updated)
+
+	case http.MethodDelete:
+		if err := db.DeleteScoringProfile(r.Context(), userID, profileID); err != nil {
+			http.Error(w, "Failed to delete scoring profile: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// decodeScoringProfile reads and validates a scoring profile from the request body.
+// Writes the error response and returns false if the body is invalid.
+func decodeScoringProfile(w http.ResponseWriter, r *http.Request) (types.ScoringProfile, bool) {
+	var profile types.ScoringProfile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return profile, false
+	}
+	if profile.Name == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return profile, false
+	}
+	if profile.Normalization == "" {
+		profile.Normalization = types.NormalizationZScore
+	}
+	if !isValidNormalization(profile.Normalization) {
+		http.Error(w, "Invalid normalization (must be 'zscore' or 'rank')", http.StatusBadRequest)
+		return profile, false
+	}
+	return profile, true
+}
+
+func isValidNormalization(normalization string) bool {
+	return normalization == types.NormalizationZScore || normalization == types.NormalizationRank
+}
+
+// parsePositiveIntParam parses an optional positive integer query parameter
+func parsePositiveIntParam(value string, defaultValue int) (int, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if parsed < 1 {
+		return 0, strconv.ErrRange
+	}
+	return parsed, nil
+}
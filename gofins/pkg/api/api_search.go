@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// SearchResponse groups full-text search hits by kind
+type SearchResponse struct {
+	Query  string              `json:"query"`
+	Total  int                 `json:"total"`
+	Groups []types.SearchGroup `json:"groups"`
+}
+
+// handleSearch runs a full-text search over symbols and the user's own rating notes and journal
+// GET /api/search?q=cloud+software&kinds=symbol,rating,journal&limit=20
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "q required", http.StatusBadRequest)
+		return
+	}
+
+	limit, err := parsePositiveIntParam(r.URL.Query().Get("limit"), defaultSearchLimit)
+	if err != nil {
+		http.Error(w, "Invalid limit: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit = min(limit, maxSearchLimit)
+
+	kinds := types.SearchKinds
+	if kindsParam := r.URL.Query().Get("kinds"); kindsParam != "" {
+		kinds = strings.Split(kindsParam, ",")
+		for _, kind := range kinds {
+			if !containsString(types.SearchKinds, kind) {
+				http.Error(w, "Invalid kind: "+kind, http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	response := SearchResponse{Query: query, Groups: []types.SearchGroup{}}
+	for _, kind := range types.SearchKinds {
+		if !containsString(kinds, kind) {
+			continue
+		}
+
+		var hits []types.SearchHit
+		switch kind {
+		case types.SearchKindSymbol:
+			hits, err = db.SearchSymbols(r.Context(), query, limit)
+		case types.SearchKindRating:
+			hits, err = db.SearchRatingNotes(r.Context(), userID, query, limit)
+		case types.SearchKindJournal:
+			hits, err = db.SearchJournal(r.Context(), userID, query, limit)
+		}
+		if err != nil {
+			http.Error(w, "Search failed: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if len(hits) > 0 {
+			response.Groups = append(response.Groups, types.SearchGroup{Kind: kind, Count: len(hits), Hits: hits})
+			response.Total += len(hits)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
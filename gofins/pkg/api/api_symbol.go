@@ -3,13 +3,23 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/flocko-motion/gofins/pkg/analysis"
 	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/types"
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
+// symbolResponse is a symbol together with performance metrics over its full price history
+type symbolResponse struct {
+	*types.Symbol
+	Metrics *types.PerformanceMetrics `json:"metrics"` // nil if the history is too short
+}
+
 func (s *Server) handleGetSymbol(w http.ResponseWriter, r *http.Request) {
 	ticker := chi.URLParam(r, "ticker")
 	if ticker == "" {
@@ -27,9 +37,113 @@ func (s *Server) handleGetSymbol(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "symbol not found", http.StatusNotFound)
 		return
 	}
+	if userID := s.currentUserID(r); userID != uuid.Nil {
+		if symbol.IsFavorite, err = db.IsFavorite(r.Context(), userID, symbol.Ticker); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	query := r.URL.Query()
+	interval, returnBasis, ok := seriesParams(w, r)
+	if !ok {
+		return
+	}
+	riskFreeRate := 0.0
+	if value := query.Get("risk_free_rate"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			http.Error(w, "Invalid risk_free_rate: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		riskFreeRate = parsed
+	}
+	// Metrics over the full stored history
+	prices, err := db.GetPrices(symbol.Ticker, time.Time{}, time.Now(), interval)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	prices = analysis.WithReturnBasis(prices, returnBasis)
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(symbol)
+	json.NewEncoder(w).Encode(symbolResponse{
+		Symbol:  symbol,
+		Metrics: analysis.CalculateMetrics(prices, interval, riskFreeRate),
+	})
+}
+
+// handleSymbolRolling returns rolling-window YoY statistics of a symbol over its full price history
+// GET /api/symbol/{ticker}/rolling?interval=monthly&window=36&step=1&return_basis=price
+func (s *Server) handleSymbolRolling(w http.ResponseWriter, r *http.Request) {
+	ticker := strings.TrimSpace(chi.URLParam(r, "ticker"))
+	if ticker == "" {
+		http.Error(w, "ticker required", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	interval, returnBasis, ok := seriesParams(w, r)
+	if !ok {
+		return
+	}
+	window, err := parsePositiveIntParam(query.Get("window"), analysis.DefaultRollingWindowMonths)
+	if err != nil || window < 12 || window > 240 {
+		http.Error(w, "Invalid window (must be between 12 and 240 months)", http.StatusBadRequest)
+		return
+	}
+	step, err := parsePositiveIntParam(query.Get("step"), 1)
+	if err != nil || step > 12 {
+		http.Error(w, "Invalid step (must be between 1 and 12 months)", http.StatusBadRequest)
+		return
+	}
+
+	symbol, err := db.GetSymbol(r.Context(), ticker)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if symbol == nil {
+		http.Error(w, "symbol not found", http.StatusNotFound)
+		return
+	}
+
+	prices, err := db.GetPrices(symbol.Ticker, time.Time{}, time.Now(), interval)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rolling := analysis.RollingYoYStats(analysis.WithReturnBasis(prices, returnBasis), window, step)
+	if rolling == nil {
+		http.Error(w, "Not enough YoY data for the rolling window", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rolling)
+}
+
+// seriesParams parses the optional interval (default monthly) and return_basis (default price)
+// query parameters. Writes an error response and returns false if one is invalid.
+func seriesParams(w http.ResponseWriter, r *http.Request) (types.PriceInterval, types.ReturnBasis, bool) {
+	query := r.URL.Query()
+	interval := types.IntervalMonthly
+	if value := query.Get("interval"); value != "" {
+		interval = types.PriceInterval(value)
+		if interval != types.IntervalMonthly && interval != types.IntervalWeekly {
+			http.Error(w, "Invalid interval (must be 'weekly' or 'monthly')", http.StatusBadRequest)
+			return "", "", false
+		}
+	}
+	returnBasis := types.ReturnBasisPrice
+	if value := query.Get("return_basis"); value != "" {
+		returnBasis = types.ReturnBasis(value)
+		if returnBasis != types.ReturnBasisPrice && returnBasis != types.ReturnBasisTotalReturn {
+			http.Error(w, "Invalid return_basis (must be 'price' or 'total_return')", http.StatusBadRequest)
+			return "", "", false
+		}
+	}
+	return interval, returnBasis, true
 }
 
 func (s *Server) handleSymbolChartRoute(w http.ResponseWriter, r *http.Request) {
@@ -7,8 +7,8 @@ import (
 )
 
 func (s *Server) handleListActiveSymbols(w http.ResponseWriter, r *http.Request) {
-	// Get all active symbols
-	symbols, err := db.GetActiveSymbols()
+	// Get all active symbols, favorites are marked if the user is known
+	symbols, err := db.GetActiveSymbols(s.currentUserID(r))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -8,7 +8,7 @@ import (
 
 func (s *Server) handleListFavoriteSymbols(w http.ResponseWriter, r *http.Request) {
 	// Get favorite symbols (filtered in SQL)
-	symbols, err := db.GetFavoriteSymbols()
+	symbols, err := db.GetFavoriteSymbols(getUserID(r))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -0,0 +1,334 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type WatchlistRequest struct {
+	Name    string   `json:"name"`
+	Tickers []string `json:"tickers"` // Initial entries on create, ignored on rename
+}
+
+type WatchlistEntryRequest struct {
+	Ticker      string   `json:"ticker"`
+	Note        *string  `json:"note"`
+	TargetPrice *float64 `json:"target_price"` // In USD
+}
+
+// WatchlistEntriesRequest is the body for adding and removing entries.
+// Tickers is a shorthand for entries without note and target price.
+type WatchlistEntriesRequest struct {
+	Entries []WatchlistEntryRequest `json:"entries"`
+	Tickers []string                `json:"tickers"`
+}
+
+// handleWatchlists handles the collection of watchlists
+// GET  /api/watchlists - List watchlists of the current user, the favorites list first
+// POST /api/watchlists - Create a watchlist, optionally with initial tickers
+func (s *Server) handleWatchlists(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		watchlists, err := db.ListWatchlists(r.Context(), userID)
+		if err != nil {
+			http.Error(w, "Failed to list watchlists: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(watchlists)
+
+	case http.MethodPost:
+		req, ok := decodeWatchlistRequest(w, r)
+		if !ok || !checkWatchlistName(w, r, req.Name) {
+			return
+		}
+		created, err := db.CreateWatchlist(r.Context(), userID, req.Name)
+		if err != nil {
+			http.Error(w, "Failed to create watchlist: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		entries := make([]types.WatchlistEntry, 0, len(req.Tickers))
+		for _, ticker := range normalizeTickers(req.Tickers) {
+			entries = append(entries, types.WatchlistEntry{Ticker: ticker})
+		}
+		if _, err := db.AddWatchlistEntries(r.Context(), created.ID, entries); err != nil {
+			http.Error(w, "Failed to add tickers: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		stored, err := db.GetWatchlist(r.Context(), userID, created.ID)
+		if err != nil {
+			http.Error(w, "Failed to get watchlist: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(stored)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleWatchlist handles a single watchlist
+// GET    /api/watchlists/{id} - Watchlist with its entries in list order
+// PUT    /api/watchlists/{id} - Rename
+// DELETE /api/watchlists/{id} - Deletes the watchlist with its entries, not allowed for the favorites list
+func (s *Server) handleWatchlist(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+	stored, ok := getWatchlist(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stored)
+
+	case http.MethodPut:
+		req, ok := decodeWatchlistRequest(w, r)
+		if !ok {
+			return
+		}
+		if req.Name != stored.Name && !checkWatchlistName(w, r, req.Name) {
+			return
+		}
+		renamed, err := db.RenameWatchlist(r.Context(), userID, stored.ID, req.Name)
+		if err != nil {
+			http.Error(w, "Failed to rename watchlist: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if renamed == nil {
+			http.Error(w, "Watchlist not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(renamed)
+
+	case http.MethodDelete:
+		if stored.IsDefault {
+			http.Error(w, "The favorites watchlist can't be deleted", http.StatusConflict)
+			return
+		}
+		if _, err := db.DeleteWatchlist(r.Context(), userID, stored.ID); err != nil {
+			http.Error(w, "Failed to delete watchlist: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleWatchlistEntries adds or removes entries in bulk and returns the updated watchlist
+// POST   /api/watchlists/{id}/entries - Append entries, tickers already on the list are left unchanged
+// DELETE /api/watchlists/{id}/entries - Remove tickers
+func (s *Server) handleWatchlistEntries(w http.ResponseWriter, r *http.Request) {
+	stored, ok := getWatchlist(w, r)
+	if !ok {
+		return
+	}
+
+	var req WatchlistEntriesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		entries := make([]types.WatchlistEntry, 0, len(req.Entries)+len(req.Tickers))
+		for _, e := range req.Entries {
+			entry, ok := watchlistEntryFromRequest(w, e)
+			if !ok {
+				return
+			}
+			entries = append(entries, entry)
+		}
+		for _, ticker := range normalizeTickers(req.Tickers) {
+			entries = append(entries, types.WatchlistEntry{Ticker: ticker})
+		}
+		if len(entries) == 0 {
+			http.Error(w, "entries or tickers required", http.StatusBadRequest)
+			return
+		}
+		if _, err := db.AddWatchlistEntries(r.Context(), stored.ID, entries); err != nil {
+			http.Error(w, "Failed to add entries: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+	case http.MethodDelete:
+		tickers := normalizeTickers(req.Tickers)
+		for _, e := range req.Entries {
+			tickers = append(tickers, normalizeTickers([]string{e.Ticker})...)
+		}
+		if len(tickers) == 0 {
+			http.Error(w, "tickers required", http.StatusBadRequest)
+			return
+		}
+		if _, err := db.RemoveWatchlistEntries(r.Context(), stored.ID, tickers); err != nil {
+			http.Error(w, "Failed to remove entries: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	respondWatchlist(w, r, stored.ID)
+}
+
+// handleWatchlistEntry replaces note and target price of an entry
+// PUT /api/watchlists/{id}/entries/{ticker}
+func (s *Server) handleWatchlistEntry(w http.ResponseWriter, r *http.Request) {
+	stored, ok := getWatchlist(w, r)
+	if !ok {
+		return
+	}
+
+	var req WatchlistEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Ticker = chi.URLParam(r, "ticker")
+	entry, ok := watchlistEntryFromRequest(w, req)
+	if !ok {
+		return
+	}
+
+	updated, err := db.UpdateWatchlistEntry(r.Context(), stored.ID, entry)
+	if err != nil {
+		http.Error(w, "Failed to update entry: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !updated {
+		http.Error(w, "Ticker not on watchlist", http.StatusNotFound)
+		return
+	}
+	respondWatchlist(w, r, stored.ID)
+}
+
+// handleWatchlistOrder moves the given tickers to the top of the list in the given order
+// PUT /api/watchlists/{id}/order
+func (s *Server) handleWatchlistOrder(w http.ResponseWriter, r *http.Request) {
+	stored, ok := getWatchlist(w, r)
+	if !ok {
+		return
+	}
+
+	var req WatchlistEntriesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := db.ReorderWatchlist(r.Context(), stored.ID, normalizeTickers(req.Tickers)); err != nil {
+		http.Error(w, "Failed to reorder watchlist: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondWatchlist(w, r, stored.ID)
+}
+
+// getWatchlist loads the watchlist {id} of the current user with its entries.
+// Writes the error response and returns false if the ID is invalid or the watchlist doesn't exist.
+func getWatchlist(w http.ResponseWriter, r *http.Request) (*types.Watchlist, bool) {
+	watchlistID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid watchlist ID", http.StatusBadRequest)
+		return nil, false
+	}
+	stored, err := db.GetWatchlist(r.Context(), getUserID(r), watchlistID)
+	if err != nil {
+		http.Error(w, "Failed to get watchlist: "+err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if stored == nil {
+		http.Error(w, "Watchlist not found", http.StatusNotFound)
+		return nil, false
+	}
+	return stored, true
+}
+
+// respondWatchlist writes the current state of a watchlist after a change
+func respondWatchlist(w http.ResponseWriter, r *http.Request, watchlistID uuid.UUID) {
+	stored, err := db.GetWatchlist(r.Context(), getUserID(r), watchlistID)
+	if err != nil {
+		http.Error(w, "Failed to get watchlist: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stored)
+}
+
+// decodeWatchlistRequest reads and validates a watchlist from the request body.
+// Writes the error response and returns false if the body is invalid.
+func decodeWatchlistRequest(w http.ResponseWriter, r *http.Request) (WatchlistRequest, bool) {
+	var req WatchlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return req, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return req, false
+	}
+	return req, true
+}
+
+// checkWatchlistName rejects names already used by another watchlist of the user with 409.
+// Writes the error response and returns false if the name is taken.
+func checkWatchlistName(w http.ResponseWriter, r *http.Request, name string) bool {
+	watchlists, err := db.ListWatchlists(r.Context(), getUserID(r))
+	if err != nil {
+		http.Error(w, "Failed to list watchlists: "+err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	for _, existing := range watchlists {
+		if existing.Name == name {
+			http.Error(w, "A watchlist named '"+name+"' already exists", http.StatusConflict)
+			return false
+		}
+	}
+	return true
+}
+
+// watchlistEntryFromRequest validates an entry of a request.
+// Writes the error response and returns false if the entry is invalid.
+func watchlistEntryFromRequest(w http.ResponseWriter, req WatchlistEntryRequest) (types.WatchlistEntry, bool) {
+	entry := types.WatchlistEntry{
+		Ticker:      strings.ToUpper(strings.TrimSpace(req.Ticker)),
+		Note:        req.Note,
+		TargetPrice: req.TargetPrice,
+	}
+	if entry.Ticker == "" {
+		http.Error(w, "ticker required", http.StatusBadRequest)
+		return entry, false
+	}
+	if entry.TargetPrice != nil && *entry.TargetPrice <= 0 {
+		http.Error(w, "target_price must be positive", http.StatusBadRequest)
+		return entry, false
+	}
+	return entry, true
+}
+
+// normalizeTickers upper-cases tickers and drops empty ones
+func normalizeTickers(tickers []string) []string {
+	normalized := make([]string, 0, len(tickers))
+	for _, ticker := range tickers {
+		if ticker = strings.ToUpper(strings.TrimSpace(ticker)); ticker != "" {
+			normalized = append(normalized, ticker)
+		}
+	}
+	return normalized
+}
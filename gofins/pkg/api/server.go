@@ -6,21 +6,27 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/flocko-motion/gofins/pkg/analysis"
 	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/scheduler"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
 type Server struct {
-	db      *db.DB
-	server  *http.Server
-	devUser string // If set, all requests use this user (dev mode)
+	db        *db.DB
+	server    *http.Server
+	devUser   string               // If set, all requests use this user (dev mode)
+	queue     *analysis.Queue      // Runs analysis jobs
+	scheduler *scheduler.Scheduler // Runs the updaters
 }
 
-func NewServer(database *db.DB, port int, devUser string) *Server {
+func NewServer(database *db.DB, port int, devUser string, queue *analysis.Queue, sched *scheduler.Scheduler) *Server {
 	s := &Server{
-		db:      database,
-		devUser: devUser,
+		db:        database,
+		devUser:   devUser,
+		queue:     queue,
+		scheduler: sched,
 	}
 
 	r := chi.NewRouter()
@@ -42,10 +48,13 @@ func NewServer(database *db.DB, port int, devUser string) *Server {
 		r.Get("/symbol/{ticker}", s.handleGetSymbol)
 		r.Get("/symbol/{ticker}/chart", s.handleSymbolChartRoute)
 		r.Get("/symbol/{ticker}/histogram", s.handleSymbolHistogramRoute)
+		r.Get("/symbol/{ticker}/corporate-actions", s.handleGetCorporateActions)
+		r.Get("/symbol/{ticker}/rolling", s.handleSymbolRolling)
 
 		// Prices (public)
 		r.Get("/prices/monthly/{ticker}", s.handleGetMonthlyPrices)
 		r.Get("/prices/weekly/{ticker}", s.handleGetWeeklyPrices)
+		r.Get("/prices/daily/{ticker}", s.handleGetDailyPrices)
 
 		// Admin-only routes (require admin user from config)
 		r.Group(func(r chi.Router) {
@@ -55,6 +64,27 @@ func NewServer(database *db.DB, port int, devUser string) *Server {
 			// Errors
 			r.Get("/errors", s.handleListErrors)
 			r.Delete("/errors", s.handleClearErrors)
+
+			// Updater scheduler
+			r.Get("/scheduler/jobs", s.handleSchedulerJobs)
+			r.Get("/scheduler/jobs/{name}", s.handleSchedulerJob)
+			r.Post("/scheduler/jobs/{name}/trigger", s.handleSchedulerTrigger)
+			r.Post("/scheduler/jobs/{name}/pause", s.handleSchedulerPause)
+			r.Post("/scheduler/jobs/{name}/resume", s.handleSchedulerPause)
+			r.Post("/scheduler/jobs/{name}/skip", s.handleSchedulerSkip)
+			r.Delete("/scheduler/jobs/{name}/skip", s.handleSchedulerSkip)
+			r.Put("/scheduler/jobs/{name}/schedule", s.handleSchedulerSchedule)
+			r.Get("/scheduler/runs", s.handleSchedulerRuns)
+
+			// Price data audit
+			r.Get("/data-issues", s.handleDataIssues)
+			r.Get("/data-issues/summary", s.handleDataIssueSummary)
+			r.Get("/data-issues/{id}", s.handleDataIssue)
+			r.Post("/data-issues/{id}/ignore", s.handleDataIssueIgnore)
+			r.Delete("/data-issues/{id}/ignore", s.handleDataIssueIgnore)
+
+			// Rate limiter metrics (Prometheus)
+			r.Get("/metrics", s.handleMetrics)
 		})
 
 		// User-specific routes (require user context)
@@ -64,18 +94,61 @@ func NewServer(database *db.DB, port int, devUser string) *Server {
 			// User info
 			r.Get("/user", s.handleGetCurrentUser)
 
+			// Live events (Server-Sent Events)
+			r.Get("/events", s.handleEvents)
+
 			// Analyses
 			r.Get("/analyses", s.handleAnalyses)
 			r.Post("/analyses", s.handleAnalyses)
+			r.Get("/analysis/compare", s.handleCompareAnalyses)
 			r.Get("/analysis/{id}", s.handleGetAnalysis)
 			r.Put("/analysis/{id}", s.handleUpdateAnalysis)
 			r.Delete("/analysis/{id}", s.handleDeleteAnalysis)
+			r.Post("/analysis/{id}/cancel", s.handleCancelAnalysis)
+			r.Get("/analysis/{id}/progress", s.handleAnalysisProgress)
 			r.Get("/analysis/{id}/results", s.handleAnalysisResults)
+			r.Get("/analysis/{id}/scores", s.handleAnalysisScores)
 			r.Get("/analysis/{id}/profile/{ticker}", s.handleSymbolProfile)
 			r.Get("/analysis/{id}/chart/{ticker}", s.handleAnalysisChart)
 			r.Get("/analysis/{id}/histogram/{ticker}", s.handleAnalysisHistogram)
 
-			// Favorites
+			// Scoring profiles
+			r.Get("/scoring-profiles", s.handleScoringProfiles)
+			r.Post("/scoring-profiles", s.handleScoringProfiles)
+			r.Get("/scoring-profiles/{id}", s.handleScoringProfile)
+			r.Put("/scoring-profiles/{id}", s.handleScoringProfile)
+			r.Delete("/scoring-profiles/{id}", s.handleScoringProfile)
+
+			// Backtests
+			r.Get("/backtests", s.handleBacktests)
+			r.Post("/backtests", s.handleBacktests)
+			r.Get("/backtests/{id}", s.handleBacktest)
+			r.Delete("/backtests/{id}", s.handleBacktest)
+
+			// Portfolios
+			r.Get("/portfolios", s.handlePortfolios)
+			r.Post("/portfolios", s.handlePortfolios)
+			r.Get("/portfolios/{id}", s.handlePortfolio)
+			r.Put("/portfolios/{id}", s.handlePortfolio)
+			r.Delete("/portfolios/{id}", s.handlePortfolio)
+			r.Get("/portfolios/{id}/transactions", s.handlePortfolioTransactions)
+			r.Post("/portfolios/{id}/transactions", s.handlePortfolioTransactions)
+			r.Delete("/portfolios/{id}/transactions/{txid}", s.handlePortfolioTransaction)
+			r.Post("/portfolios/{id}/import", s.handlePortfolioImport)
+			r.Get("/portfolios/{id}/positions", s.handlePortfolioPositions)
+
+			// Watchlists
+			r.Get("/watchlists", s.handleWatchlists)
+			r.Post("/watchlists", s.handleWatchlists)
+			r.Get("/watchlists/{id}", s.handleWatchlist)
+			r.Put("/watchlists/{id}", s.handleWatchlist)
+			r.Delete("/watchlists/{id}", s.handleWatchlist)
+			r.Post("/watchlists/{id}/entries", s.handleWatchlistEntries)
+			r.Delete("/watchlists/{id}/entries", s.handleWatchlistEntries)
+			r.Put("/watchlists/{id}/entries/{ticker}", s.handleWatchlistEntry)
+			r.Put("/watchlists/{id}/order", s.handleWatchlistOrder)
+
+			// Favorites (the default watchlist)
 			r.Get("/symbols/favorites", s.handleListFavoriteSymbols)
 			r.Get("/favorites", s.handleFavorites)
 			r.Post("/favorites/{ticker}", s.handleFavorites)
@@ -89,6 +162,24 @@ func NewServer(database *db.DB, port int, devUser string) *Server {
 
 			// Notes
 			r.Get("/notes", s.handleListNotes)
+
+			// Journal
+			r.Get("/journal", s.handleJournal)
+			r.Post("/journal", s.handleJournal)
+			r.Get("/journal/ticker/{ticker}", s.handleJournalByTicker)
+			r.Get("/journal/{id}", s.handleJournalEntry)
+			r.Put("/journal/{id}", s.handleJournalEntry)
+			r.Delete("/journal/{id}", s.handleJournalEntry)
+			r.Post("/journal/{id}/tickers", s.handleJournalTickers)
+			r.Delete("/journal/{id}/tickers", s.handleJournalTickers)
+			r.Get("/timeline", s.handleTimeline)
+
+			// Search
+			r.Get("/search", s.handleSearch)
+
+			// Backup
+			r.Get("/backup/export", s.handleBackupExport)
+			r.Post("/backup/import", s.handleBackupImport)
 		})
 	})
 
@@ -68,6 +68,23 @@ func getUserID(r *http.Request) uuid.UUID {
 	return userID
 }
 
+// currentUserID resolves the user of a request on public routes, which don't run userMiddleware.
+// Returns uuid.Nil if no user is authenticated or the user doesn't exist yet; users are not created here.
+func (s *Server) currentUserID(r *http.Request) uuid.UUID {
+	username := s.devUser
+	if username == "" {
+		username = r.Header.Get("X-Remote-User")
+	}
+	if username == "" {
+		return uuid.Nil
+	}
+	user, err := db.GetUser(r.Context(), username)
+	if err != nil || user == nil {
+		return uuid.Nil
+	}
+	return user.ID
+}
+
 // adminOnlyMiddleware restricts access to admin users (checks is_admin from database)
 func (s *Server) adminOnlyMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,188 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/forex"
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+// ErrorSource is the source of the errors logged by audit runs; new data issues link to them
+const ErrorSource = "audit.prices"
+
+func logf(format string, args ...interface{}) {
+	fmt.Printf("[AUDIT] "+format, args...)
+}
+
+// Options of an audit run
+type Options struct {
+	Ticker string // Audit a single symbol, all symbols with prices if empty
+	Config Config
+}
+
+// Summary is the outcome of an audit run
+type Summary struct {
+	StartedAt time.Time
+	Symbols   int            // Audited symbols
+	Failed    int            // Symbols that couldn't be audited
+	Found     map[string]int // Issues found by kind, including known ones
+	New       int            // Issues that weren't open before the run
+	Resolved  int            // Open issues that weren't found anymore
+	ErrorID   *int           // Error logged for the new issues, nil if there are none
+}
+
+// Run audits the stored prices and records the findings as data issues. Open issues that aren't
+// found anymore are resolved, unless some symbols failed. New issues are summarized in an error
+// of ErrorSource, which the issues link to.
+func Run(ctx context.Context, opts Options) (*Summary, error) {
+	summary := &Summary{
+		StartedAt: time.Now().Truncate(time.Microsecond), // Compared with timestamps stored by Postgres
+		Found:     map[string]int{},
+	}
+
+	tickers := []string{opts.Ticker}
+	if opts.Ticker == "" {
+		var err error
+		if tickers, err = db.GetTickersWithPrices(ctx, math.MaxInt32); err != nil {
+			return nil, err
+		}
+	}
+	logf("Auditing prices of %d symbols\n", len(tickers))
+
+	for i, ticker := range tickers {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		if i%1000 == 0 && i > 0 {
+			logf("Progress: %d/%d (%d issues, %d new, %d failed)\n", i, len(tickers), summary.total(), summary.New, summary.Failed)
+		}
+
+		issues, err := auditSymbol(ctx, ticker, opts.Config, summary.StartedAt)
+		if err == nil {
+			var added int
+			added, err = db.PutDataIssues(ctx, issues, summary.StartedAt)
+			summary.New += added
+		}
+		if err != nil {
+			_ = db.LogError(ctx, ErrorSource, "database", "Failed to audit "+ticker, f.Ptr(err.Error()))
+			summary.Failed++
+			continue
+		}
+		summary.Symbols++
+		for _, issue := range issues {
+			summary.Found[issue.Kind]++
+		}
+	}
+
+	// Issues of failed symbols weren't checked again, they are resolved by the next complete run
+	if summary.Failed == 0 {
+		resolved, err := db.ResolveDataIssues(ctx, opts.Ticker, summary.StartedAt)
+		if err != nil {
+			return summary, fmt.Errorf("failed to resolve data issues: %w", err)
+		}
+		summary.Resolved = resolved
+	}
+
+	if summary.New > 0 {
+		message := fmt.Sprintf("Price audit found %d new data issues", summary.New)
+		errorID, err := db.LogErrorWithID(ctx, ErrorSource, "data_quality", message, f.Ptr(summary.describeFound()))
+		if err != nil {
+			return summary, fmt.Errorf("failed to log audit error: %w", err)
+		}
+		if _, err := db.LinkDataIssuesToError(ctx, errorID, summary.StartedAt); err != nil {
+			return summary, fmt.Errorf("failed to link data issues: %w", err)
+		}
+		summary.ErrorID = &errorID
+	}
+
+	logf("Audited %d symbols: %d issues (%d new, %d resolved), %d failed\n",
+		summary.Symbols, summary.total(), summary.New, summary.Resolved, summary.Failed)
+	return summary, nil
+}
+
+// RunOnce audits all symbols with the default config, as a scheduler job
+func RunOnce(ctx context.Context) error {
+	_, err := Run(ctx, Options{Config: DefaultConfig()})
+	return err
+}
+
+func (s *Summary) total() int {
+	total := 0
+	for _, count := range s.Found {
+		total += count
+	}
+	return total
+}
+
+// describeFound lists the issues found by kind, e.g. "gap: 12, split_jump: 3"
+func (s *Summary) describeFound() string {
+	kinds := make([]string, 0, len(s.Found))
+	for kind := range s.Found {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	parts := make([]string, len(kinds))
+	for i, kind := range kinds {
+		parts[i] = fmt.Sprintf("%s: %d", kind, s.Found[kind])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// auditSymbol checks the weekly and monthly prices and the update state of a symbol
+func auditSymbol(ctx context.Context, ticker string, cfg Config, now time.Time) ([]types.DataIssue, error) {
+	symbol, err := db.GetSymbol(ctx, ticker)
+	if err != nil {
+		return nil, err
+	}
+	if symbol == nil {
+		return nil, nil // Removed since the tickers were listed
+	}
+	splits, err := db.GetSplits(ticker)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get splits: %w", err)
+	}
+	currency := f.MaybeToString(symbol.Currency, "")
+
+	var issues []types.DataIssue
+	for _, interval := range []types.PriceInterval{types.IntervalMonthly, types.IntervalWeekly} {
+		prices, err := db.GetPrices(ticker, time.Time{}, now, interval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s prices: %w", interval, err)
+		}
+		issues = append(issues, CheckSeries(Series{
+			Ticker:   ticker,
+			Interval: interval,
+			Prices:   prices,
+			Splits:   splits,
+			Currency: currency,
+			UsdRate:  usdRate(currency),
+		}, cfg)...)
+	}
+	if issue := CheckStale(*symbol, now, cfg); issue != nil {
+		issues = append(issues, *issue)
+	}
+	return issues, nil
+}
+
+// usdRate returns the forex rate lookup the price updater converts a currency with,
+// nil for USD and if the forex series isn't available
+func usdRate(currency string) func(time.Time) (float64, error) {
+	if currency == "" || currency == "USD" {
+		return nil
+	}
+	ts, err := forex.GetCachedForex(currency)
+	if err != nil {
+		return nil
+	}
+	return func(date time.Time) (float64, error) {
+		return forex.ConvertToUsdWithTimeSeries(1.0, ts, date)
+	}
+}
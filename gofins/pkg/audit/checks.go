@@ -0,0 +1,351 @@
+// Package audit checks the stored price series for data errors: missing and duplicate periods,
+// non-positive closes, jumps by a split ratio (likely unadjusted splits), USD closes that don't
+// match the original close at the forex rate and symbols whose prices aren't updated anymore.
+// Findings are stored as data issues, see Run.
+package audit
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/calculator"
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+const dateFormat = "2006-01-02"
+
+// Config holds the thresholds of the checks
+type Config struct {
+	AllowedWeeklyGap  int           // Missing weeks in a row that aren't reported, e.g. exchanges closed for a holiday week
+	AllowedMonthlyGap int           // Missing months in a row that aren't reported
+	JumpFactor        float64       // Minimum close-to-close change (as a factor) compared with split ratios
+	SplitTolerance    float64       // Maximum relative distance of a jump to a split ratio
+	CurrencyTolerance float64       // Maximum relative deviation of the USD close from the converted original close
+	StaleAfter        time.Duration // Age of the last price update after which an actively trading symbol is stale
+}
+
+// DefaultConfig returns the thresholds used by the scheduled audit
+func DefaultConfig() Config {
+	return Config{
+		AllowedWeeklyGap:  1,
+		AllowedMonthlyGap: 0,
+		JumpFactor:        1.8,
+		SplitTolerance:    0.05,
+		CurrencyTolerance: 0.02,
+		StaleAfter:        45 * 24 * time.Hour, // The price updater refreshes every 30 days
+	}
+}
+
+// splitRatios are the common split ratios jumps are compared with when no split is recorded
+var splitRatios = []float64{2, 3, 4, 5, 6, 8, 10, 15, 20, 25, 30, 50, 100}
+
+// Series is a stored price series with the data needed to check it
+type Series struct {
+	Ticker   string
+	Interval types.PriceInterval
+	Prices   []types.PriceData // Oldest first
+	Splits   []types.Split     // Recorded splits of the symbol
+	Currency string            // Currency of the original closes, empty if unknown
+	// UsdRate returns the USD value of one unit of Currency as used for a period, nil if unknown
+	UsdRate func(date time.Time) (float64, error)
+}
+
+// period is a week or month of a series with its stored rows
+type period struct {
+	start time.Time
+	rows  []types.PriceData
+}
+
+// last returns the row used for the period, the latest one if there are duplicates
+func (p period) last() types.PriceData {
+	return p.rows[len(p.rows)-1]
+}
+
+// CheckSeries returns the issues of a weekly or monthly price series
+func CheckSeries(s Series, cfg Config) []types.DataIssue {
+	prices := slices.Clone(s.Prices)
+	sort.SliceStable(prices, func(i, j int) bool { return prices[i].Date.Before(prices[j].Date) })
+	s.Prices = prices
+
+	periods := s.periods()
+	var issues []types.DataIssue
+	issues = append(issues, checkDuplicates(s, periods)...)
+	issues = append(issues, checkGaps(s, periods, cfg)...)
+	issues = append(issues, checkCloses(s)...)
+	issues = append(issues, checkJumps(s, periods, cfg)...)
+	issues = append(issues, checkCurrency(s, cfg)...)
+	return issues
+}
+
+// CheckStale returns an issue if the prices of an actively trading symbol weren't updated within
+// StaleAfter, nil otherwise. Symbols without a price update yet are pending, not stale.
+func CheckStale(symbol types.Symbol, now time.Time, cfg Config) *types.DataIssue {
+	if symbol.IsActivelyTrading == nil || !*symbol.IsActivelyTrading || symbol.LastPriceUpdate == nil {
+		return nil
+	}
+	if !slices.Contains(types.PriceUpdateTypes, f.MaybeToString(symbol.Type, "")) {
+		return nil
+	}
+	age := now.Sub(*symbol.LastPriceUpdate)
+	if age <= cfg.StaleAfter {
+		return nil
+	}
+
+	return &types.DataIssue{
+		Ticker:   symbol.Ticker,
+		Kind:     types.IssueStale,
+		Severity: types.SeverityWarning,
+		Message: fmt.Sprintf("Prices last updated on %s, %d days ago",
+			symbol.LastPriceUpdate.Format(dateFormat), int(age.Hours()/24)),
+		Details: symbol.LastPriceStatus,
+	}
+}
+
+func (s Series) issue(kind, severity string, date time.Time, message string) types.DataIssue {
+	return types.DataIssue{
+		Ticker:   s.Ticker,
+		Interval: s.Interval,
+		Kind:     kind,
+		Period:   &date,
+		Severity: severity,
+		Message:  message,
+	}
+}
+
+func (s Series) periodStart(date time.Time) time.Time {
+	if s.Interval == types.IntervalWeekly {
+		return calculator.StartOfWeek(date)
+	}
+	return calculator.StartOfMonth(date)
+}
+
+func (s Series) nextPeriod(start time.Time) time.Time {
+	if s.Interval == types.IntervalWeekly {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+func (s Series) previousPeriod(start time.Time) time.Time {
+	if s.Interval == types.IntervalWeekly {
+		return start.AddDate(0, 0, -7)
+	}
+	return start.AddDate(0, -1, 0)
+}
+
+// missingBetween returns the number of periods between two period starts
+func (s Series) missingBetween(from, to time.Time) int {
+	if s.Interval == types.IntervalWeekly {
+		return int(math.Round(to.Sub(from).Hours()/(24*7))) - 1
+	}
+	return (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) - 1
+}
+
+// periods groups the rows by week or month
+func (s Series) periods() []period {
+	var periods []period
+	for _, p := range s.Prices {
+		start := s.periodStart(p.Date)
+		if n := len(periods); n > 0 && periods[n-1].start.Equal(start) {
+			periods[n-1].rows = append(periods[n-1].rows, p)
+			continue
+		}
+		periods = append(periods, period{start: start, rows: []types.PriceData{p}})
+	}
+	return periods
+}
+
+// origClose returns the close in the original currency
+func origClose(p types.PriceData) float64 {
+	if p.CloseOrig != nil {
+		return *p.CloseOrig
+	}
+	return p.Close
+}
+
+// runs returns the index ranges [from, to] of consecutive rows for which bad is true
+func runs(n int, bad func(i int) bool) [][2]int {
+	var ranges [][2]int
+	for i := 0; i < n; i++ {
+		if !bad(i) {
+			continue
+		}
+		if k := len(ranges); k > 0 && ranges[k-1][1] == i-1 {
+			ranges[k-1][1] = i
+			continue
+		}
+		ranges = append(ranges, [2]int{i, i})
+	}
+	return ranges
+}
+
+// describeRun returns "on <date>" for a single row or "in <n> <interval> periods from <date> to <date>"
+func (s Series) describeRun(r [2]int) string {
+	from, to := s.Prices[r[0]].Date, s.Prices[r[1]].Date
+	if r[0] == r[1] {
+		return "on " + from.Format(dateFormat)
+	}
+	return fmt.Sprintf("in %d %s periods from %s to %s", r[1]-r[0]+1, s.Interval, from.Format(dateFormat), to.Format(dateFormat))
+}
+
+// checkDuplicates reports periods with more than one row, e.g. a week stored under a Monday and a Sunday label
+func checkDuplicates(s Series, periods []period) []types.DataIssue {
+	var issues []types.DataIssue
+	for _, p := range periods {
+		if len(p.rows) < 2 {
+			continue
+		}
+		dates := make([]string, len(p.rows))
+		for i, row := range p.rows {
+			dates[i] = row.Date.Format(dateFormat)
+		}
+		issue := s.issue(types.IssueDuplicatePeriod, types.SeverityError, p.start,
+			fmt.Sprintf("%d rows for the %s period of %s", len(p.rows), s.Interval, p.start.Format(dateFormat)))
+		issue.Details = f.Ptr("Dates: " + strings.Join(dates, ", "))
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// checkGaps reports periods missing between the first and the last stored period
+func checkGaps(s Series, periods []period, cfg Config) []types.DataIssue {
+	allowed := cfg.AllowedMonthlyGap
+	if s.Interval == types.IntervalWeekly {
+		allowed = cfg.AllowedWeeklyGap
+	}
+
+	var issues []types.DataIssue
+	for i := 1; i < len(periods); i++ {
+		prev, cur := periods[i-1].start, periods[i].start
+		missing := s.missingBetween(prev, cur)
+		if missing <= allowed {
+			continue
+		}
+		issues = append(issues, s.issue(types.IssueGap, types.SeverityWarning, s.nextPeriod(prev),
+			fmt.Sprintf("%d %s periods missing between %s and %s", missing, s.Interval, prev.Format(dateFormat), cur.Format(dateFormat))))
+	}
+	return issues
+}
+
+// checkCloses reports rows with a close of zero or below, in USD or in the original currency
+func checkCloses(s Series) []types.DataIssue {
+	bad := func(i int) bool {
+		p := s.Prices[i]
+		return p.Close <= 0 || (p.CloseOrig != nil && *p.CloseOrig <= 0)
+	}
+
+	var issues []types.DataIssue
+	for _, r := range runs(len(s.Prices), bad) {
+		first := s.Prices[r[0]]
+		issues = append(issues, s.issue(types.IssueNonPositiveClose, types.SeverityError, first.Date,
+			fmt.Sprintf("Close is zero or negative %s", s.describeRun(r))))
+	}
+	return issues
+}
+
+// checkJumps reports close-to-close changes by a split ratio. A jump matching a recorded split
+// around its date is an unadjusted split; one matching a common ratio without a recorded split
+// may be a missing split record or a real move.
+func checkJumps(s Series, periods []period, cfg Config) []types.DataIssue {
+	var issues []types.DataIssue
+	for i := 1; i < len(periods); i++ {
+		prev, cur := periods[i-1].last(), periods[i].last()
+		prevClose, curClose := origClose(prev), origClose(cur)
+		if prevClose <= 0 || curClose <= 0 {
+			continue
+		}
+		ratio := curClose / prevClose
+		factor, direction := ratio, "rose"
+		if ratio < 1 {
+			factor, direction = 1/ratio, "fell"
+		}
+		if factor < cfg.JumpFactor {
+			continue
+		}
+		change := fmt.Sprintf("Close %s %.2fx from %s to %s", direction, factor, prev.Date.Format(dateFormat), cur.Date.Format(dateFormat))
+
+		// Splits recorded from one period before to one period after the jump
+		windowFrom := s.previousPeriod(periods[i-1].start)
+		windowTo := s.nextPeriod(s.nextPeriod(periods[i].start))
+		if split, found := matchingSplit(s.Splits, ratio, windowFrom, windowTo, cfg.SplitTolerance); found {
+			issues = append(issues, s.issue(types.IssueSplitJump, types.SeverityError, cur.Date,
+				fmt.Sprintf("%s, matching the unadjusted %g:%g split on %s", change, split.Numerator, split.Denominator, split.Date.Format(dateFormat))))
+			continue
+		}
+		if splitRatio, found := matchingRatio(factor, cfg.SplitTolerance); found {
+			issues = append(issues, s.issue(types.IssueSplitJump, types.SeverityWarning, cur.Date,
+				fmt.Sprintf("%s, a split ratio of %g, but no split is recorded", change, splitRatio)))
+		}
+	}
+	return issues
+}
+
+// matchingSplit returns a recorded split in [from, to) whose ratio explains the close ratio:
+// a 4:1 split divides the price by 4, a 1:10 reverse split multiplies it by 10
+func matchingSplit(splits []types.Split, ratio float64, from, to time.Time, tolerance float64) (types.Split, bool) {
+	for _, split := range splits {
+		if split.Ratio() <= 0 || split.Date.Before(from) || !split.Date.Before(to) {
+			continue
+		}
+		if math.Abs(ratio*split.Ratio()-1) <= tolerance {
+			return split, true
+		}
+	}
+	return types.Split{}, false
+}
+
+// matchingRatio returns the common split ratio within tolerance of factor
+func matchingRatio(factor, tolerance float64) (float64, bool) {
+	for _, ratio := range splitRatios {
+		if math.Abs(factor/ratio-1) <= tolerance {
+			return ratio, true
+		}
+	}
+	return 0, false
+}
+
+// checkCurrency reports rows whose USD close deviates from the original close converted at the
+// forex rate of the period, the rate the price updater converts with. Rows without an original
+// close or a forex rate can't be checked.
+func checkCurrency(s Series, cfg Config) []types.DataIssue {
+	if s.Currency == "" || (s.Currency != "USD" && s.UsdRate == nil) {
+		return nil
+	}
+
+	deviations := make([]float64, len(s.Prices))
+	bad := func(i int) bool {
+		p := s.Prices[i]
+		if p.CloseOrig == nil || *p.CloseOrig <= 0 || p.Close <= 0 {
+			return false
+		}
+		rate := 1.0
+		if s.Currency != "USD" {
+			var err error
+			if rate, err = s.UsdRate(p.Date); err != nil || rate <= 0 {
+				return false
+			}
+		}
+		deviations[i] = p.Close/(*p.CloseOrig*rate) - 1
+		return math.Abs(deviations[i]) > cfg.CurrencyTolerance
+	}
+
+	var issues []types.DataIssue
+	for _, r := range runs(len(s.Prices), bad) {
+		worst := 0.0
+		for i := r[0]; i <= r[1]; i++ {
+			worst = math.Max(worst, math.Abs(deviations[i]))
+		}
+		first := s.Prices[r[0]]
+		issue := s.issue(types.IssueCurrencyMismatch, types.SeverityError, first.Date,
+			fmt.Sprintf("USD close deviates up to %.1f%% from the %s close at the forex rate %s", worst*100, s.Currency, s.describeRun(r)))
+		issue.Details = f.Ptr(fmt.Sprintf("%s: close %.4f USD, close_orig %.4f %s",
+			first.Date.Format(dateFormat), first.Close, *first.CloseOrig, s.Currency))
+		issues = append(issues, issue)
+	}
+	return issues
+}
@@ -0,0 +1,166 @@
+package audit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func day(value string) time.Time {
+	date, err := time.Parse(dateFormat, value)
+	if err != nil {
+		panic(err)
+	}
+	return date
+}
+
+// weekly returns weekly prices from the Monday start, one close per week
+func weekly(start string, closes ...float64) []types.PriceData {
+	prices := make([]types.PriceData, len(closes))
+	for i, closing := range closes {
+		prices[i] = types.PriceData{Date: day(start).AddDate(0, 0, 7*i), Close: closing}
+	}
+	return prices
+}
+
+func kinds(issues []types.DataIssue) []string {
+	result := make([]string, len(issues))
+	for i, issue := range issues {
+		result[i] = issue.Kind
+	}
+	return result
+}
+
+func TestCheckSeriesClean(t *testing.T) {
+	s := Series{Ticker: "TEST", Interval: types.IntervalWeekly, Prices: weekly("2024-01-01", 100, 102, 99, 101, 150)}
+	assert.Empty(t, CheckSeries(s, DefaultConfig()))
+}
+
+func TestCheckGapsAndDuplicates(t *testing.T) {
+	prices := weekly("2024-01-01", 100, 101, 102, 103, 104, 105)
+	prices = append(prices[:2], prices[4:]...)                                    // Weeks of Jan 15 and 22 missing
+	prices = append(prices, types.PriceData{Date: day("2024-02-11"), Close: 106}) // Sunday label of the week of Feb 5
+
+	issues := CheckSeries(Series{Ticker: "TEST", Interval: types.IntervalWeekly, Prices: prices}, DefaultConfig())
+	require.Equal(t, []string{types.IssueDuplicatePeriod, types.IssueGap}, kinds(issues))
+	assert.Equal(t, day("2024-02-05"), *issues[0].Period)
+	assert.Equal(t, "Dates: 2024-02-05, 2024-02-11", *issues[0].Details)
+	assert.Equal(t, day("2024-01-15"), *issues[1].Period)
+	assert.Equal(t, "2 weekly periods missing between 2024-01-08 and 2024-01-29", issues[1].Message)
+
+	// A single missing week is allowed by default (holiday weeks), a single missing month isn't
+	single := weekly("2024-01-01", 100, 101, 102)
+	single = append(single[:1], single[2:]...)
+	assert.Empty(t, CheckSeries(Series{Interval: types.IntervalWeekly, Prices: single}, DefaultConfig()))
+
+	monthly := []types.PriceData{{Date: day("2024-01-01"), Close: 1}, {Date: day("2024-03-01"), Close: 1}}
+	issues = CheckSeries(Series{Interval: types.IntervalMonthly, Prices: monthly}, DefaultConfig())
+	require.Equal(t, []string{types.IssueGap}, kinds(issues))
+	assert.Equal(t, day("2024-02-01"), *issues[0].Period)
+}
+
+func TestCheckCloses(t *testing.T) {
+	prices := weekly("2024-01-01", 100, 0, -1, 100, 100)
+	prices[4].CloseOrig = f.Ptr(0.0)
+
+	issues := CheckSeries(Series{Interval: types.IntervalWeekly, Prices: prices}, DefaultConfig())
+	require.Equal(t, []string{types.IssueNonPositiveClose, types.IssueNonPositiveClose}, kinds(issues))
+	assert.Equal(t, "Close is zero or negative in 2 weekly periods from 2024-01-08 to 2024-01-15", issues[0].Message)
+	assert.Equal(t, "Close is zero or negative on 2024-01-29", issues[1].Message)
+	assert.Equal(t, types.SeverityError, issues[0].Severity)
+}
+
+func TestCheckJumps(t *testing.T) {
+	// 4:1 split on 2020-08-31 left unadjusted: the close falls by 4
+	prices := weekly("2020-08-17", 500, 504, 126, 128)
+	splits := []types.Split{{SymbolTicker: "AAPL", Date: day("2020-08-31"), Numerator: 4, Denominator: 1}}
+
+	issues := CheckSeries(Series{Ticker: "AAPL", Interval: types.IntervalWeekly, Prices: prices, Splits: splits}, DefaultConfig())
+	require.Equal(t, []string{types.IssueSplitJump}, kinds(issues))
+	assert.Equal(t, types.SeverityError, issues[0].Severity)
+	assert.Equal(t, day("2020-08-31"), *issues[0].Period)
+	assert.Equal(t, "Close fell 4.00x from 2020-08-24 to 2020-08-31, matching the unadjusted 4:1 split on 2020-08-31", issues[0].Message)
+
+	// 1:10 reverse split: the close rises by 10
+	splits = []types.Split{{Date: day("2020-09-01"), Numerator: 1, Denominator: 10}}
+	issues = CheckSeries(Series{Interval: types.IntervalWeekly, Prices: weekly("2020-08-17", 1, 1, 10.2), Splits: splits}, DefaultConfig())
+	require.Len(t, issues, 1)
+	assert.Equal(t, types.SeverityError, issues[0].Severity)
+
+	// Split ratio without a recorded split: warning
+	issues = CheckSeries(Series{Interval: types.IntervalWeekly, Prices: weekly("2020-08-17", 30, 10)}, DefaultConfig())
+	require.Len(t, issues, 1)
+	assert.Equal(t, types.SeverityWarning, issues[0].Severity)
+	assert.Contains(t, issues[0].Message, "a split ratio of 3, but no split is recorded")
+
+	// Large moves that aren't split ratios are left alone
+	assert.Empty(t, CheckSeries(Series{Interval: types.IntervalWeekly, Prices: weekly("2020-08-17", 10, 27)}, DefaultConfig()))
+
+	// Jumps are compared in the original currency
+	prices = weekly("2020-08-17", 10, 20)
+	prices[0].CloseOrig, prices[1].CloseOrig = f.Ptr(10.0), f.Ptr(10.5)
+	assert.Empty(t, CheckSeries(Series{Interval: types.IntervalWeekly, Prices: prices}, DefaultConfig()))
+}
+
+func TestCheckCurrency(t *testing.T) {
+	prices := weekly("2024-01-01", 110, 110, 100, 100, 110)
+	for i := range prices {
+		prices[i].CloseOrig = f.Ptr(100.0)
+	}
+	rates := map[time.Time]float64{day("2024-01-01"): 1.1, day("2024-01-08"): 1.1, day("2024-01-15"): 1.1, day("2024-01-22"): 1.1}
+	usdRate := func(date time.Time) (float64, error) {
+		if rate, exists := rates[date]; exists {
+			return rate, nil
+		}
+		return 0, errors.New("no forex data")
+	}
+
+	issues := CheckSeries(Series{Interval: types.IntervalWeekly, Prices: prices, Currency: "EUR", UsdRate: usdRate}, DefaultConfig())
+	require.Equal(t, []string{types.IssueCurrencyMismatch}, kinds(issues), "the week without a rate can't be checked")
+	assert.Equal(t, day("2024-01-15"), *issues[0].Period)
+	assert.Equal(t, "USD close deviates up to 9.1% from the EUR close at the forex rate in 2 weekly periods from 2024-01-15 to 2024-01-22", issues[0].Message)
+
+	// USD symbols must store the same close twice
+	prices = weekly("2024-01-01", 100, 100)
+	prices[1].CloseOrig = f.Ptr(95.0)
+	issues = CheckSeries(Series{Interval: types.IntervalWeekly, Prices: prices, Currency: "USD"}, DefaultConfig())
+	require.Equal(t, []string{types.IssueCurrencyMismatch}, kinds(issues))
+
+	// Without a forex series nothing is checked
+	prices = weekly("2024-01-01", 100)
+	prices[0].CloseOrig = f.Ptr(1.0)
+	assert.Empty(t, CheckSeries(Series{Interval: types.IntervalWeekly, Prices: prices, Currency: "EUR"}, DefaultConfig()))
+}
+
+func TestCheckStale(t *testing.T) {
+	now := day("2024-06-01")
+	symbol := types.Symbol{
+		Ticker:            "TEST",
+		Type:              f.Ptr(types.TypeStock),
+		IsActivelyTrading: f.Ptr(true),
+		LastPriceUpdate:   f.Ptr(day("2024-04-01")),
+	}
+
+	issue := CheckStale(symbol, now, DefaultConfig())
+	require.NotNil(t, issue)
+	assert.Equal(t, types.IssueStale, issue.Kind)
+	assert.Nil(t, issue.Period)
+	assert.Equal(t, "Prices last updated on 2024-04-01, 61 days ago", issue.Message)
+
+	recent := symbol
+	recent.LastPriceUpdate = f.Ptr(day("2024-05-01"))
+	assert.Nil(t, CheckStale(recent, now, DefaultConfig()))
+
+	delisted := symbol
+	delisted.IsActivelyTrading = f.Ptr(false)
+	assert.Nil(t, CheckStale(delisted, now, DefaultConfig()))
+
+	pending := symbol
+	pending.LastPriceUpdate = nil
+	assert.Nil(t, CheckStale(pending, now, DefaultConfig()))
+}
@@ -0,0 +1,329 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/analysis"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+)
+
+// StartValue is the portfolio value at the first period of a backtest
+const StartValue = 100.0
+
+// MaxHoldings limits the number of holdings of a backtest (fixed or top N)
+const MaxHoldings = 500
+
+var (
+	// ErrNoHoldings is returned if a top-N selection yields no tickers
+	ErrNoHoldings = errors.New("backtest has no holdings")
+	// ErrNoPrices is returned if none of the holdings has prices in the backtest range
+	ErrNoPrices = errors.New("no prices for any holding in the backtest range")
+)
+
+// Validate checks a config before it is run
+func Validate(config types.BacktestConfig) error {
+	if len(config.Holdings) > 0 && config.TopN != nil {
+		return fmt.Errorf("holdings and topN are mutually exclusive")
+	}
+	if len(config.Holdings) == 0 && config.TopN == nil {
+		return fmt.Errorf("holdings or topN required")
+	}
+	if len(config.Holdings) > MaxHoldings {
+		return fmt.Errorf("too many holdings (max %d)", MaxHoldings)
+	}
+	for _, h := range config.Holdings {
+		if strings.TrimSpace(h.Ticker) == "" {
+			return fmt.Errorf("holding without ticker")
+		}
+		if h.Weight <= 0 || math.IsNaN(h.Weight) || math.IsInf(h.Weight, 0) {
+			return fmt.Errorf("invalid weight %v for %s (must be positive)", h.Weight, h.Ticker)
+		}
+	}
+	if config.TopN != nil {
+		if _, err := uuid.Parse(config.TopN.PackageID); err != nil {
+			return fmt.Errorf("invalid topN package ID '%s'", config.TopN.PackageID)
+		}
+		if config.TopN.N < 1 || config.TopN.N > MaxHoldings {
+			return fmt.Errorf("invalid topN count %d (must be between 1 and %d)", config.TopN.N, MaxHoldings)
+		}
+	}
+	switch config.Rebalance {
+	case types.RebalanceMonthly, types.RebalanceQuarterly, types.RebalanceYearly:
+	default:
+		return fmt.Errorf("invalid rebalance schedule '%s' (must be 'monthly', 'quarterly' or 'yearly')", config.Rebalance)
+	}
+	if config.Interval != types.IntervalWeekly && config.Interval != types.IntervalMonthly {
+		return fmt.Errorf("invalid interval '%s' (must be 'weekly' or 'monthly')", config.Interval)
+	}
+	if !config.End.After(config.Start) {
+		return fmt.Errorf("end must be after start")
+	}
+	return nil
+}
+
+// Simulate runs a backtest of holdings over prices (ticker -> series sorted by date, USD closes).
+// The portfolio starts at StartValue fully invested and is rebalanced to the target weights in the
+// first period of every month, quarter or year. Holdings without a price in a rebalance period are
+// sold at their last price and their weight is spread over the others; if none has a price, the
+// portfolio is held in cash until the next rebalance. Cash earns nothing.
+func Simulate(config types.BacktestConfig, holdings []types.BacktestHolding, prices map[string][]types.PriceData, benchmark []types.PriceData) (*types.BacktestResult, error) {
+	holdings = normalizeHoldings(holdings)
+	if len(holdings) == 0 {
+		return nil, ErrNoHoldings
+	}
+
+	result := &types.BacktestResult{
+		Holdings: holdings,
+		Missing:  []string{},
+	}
+	series := make(map[string][]types.PriceData, len(holdings))
+	for _, h := range holdings {
+		if s := inRange(prices[h.Ticker], config.Start, config.End); len(s) > 0 {
+			series[h.Ticker] = s
+		} else {
+			result.Missing = append(result.Missing, h.Ticker)
+		}
+	}
+	if len(series) == 0 {
+		return nil, ErrNoPrices
+	}
+
+	portfolio := newPortfolio(holdings, series)
+	bench := newBenchmark(inRange(benchmark, config.Start, config.End))
+	period := -1
+	for i, date := range portfolio.dates {
+		quoted := portfolio.advance(date)
+		value := portfolio.value()
+
+		if key := rebalanceKey(date, config.Rebalance); key != period {
+			period = key
+			rebalance := portfolio.rebalance(date, value, quoted)
+			if i == 0 {
+				rebalance.Turnover = 0 // Initial allocation
+			}
+			result.Turnover += rebalance.Turnover
+			result.Rebalances = append(result.Rebalances, rebalance)
+		}
+
+		result.Equity = append(result.Equity, types.EquityPoint{
+			Date:      date,
+			Value:     value,
+			Benchmark: bench.advance(date, value),
+		})
+	}
+
+	first, last := result.Equity[0].Date, result.Equity[len(result.Equity)-1].Date
+	if years := last.Sub(first).Hours() / 24 / 365.25; years > 0 {
+		result.AnnualTurnover = result.Turnover / years
+	}
+
+	equity := make([]types.PriceData, len(result.Equity))
+	for i, point := range result.Equity {
+		equity[i] = types.PriceData{Date: point.Date, Close: point.Value}
+	}
+	result.Metrics = analysis.CalculateMetrics(equity, config.Interval, 0)
+	if len(bench.series) > 0 {
+		result.Benchmark = analysis.CalculateMetrics(closesOnly(bench.series), config.Interval, 0)
+	}
+
+	return result, nil
+}
+
+// normalizeHoldings upper-cases tickers, merges duplicates and scales the weights to sum up to 1
+func normalizeHoldings(holdings []types.BacktestHolding) []types.BacktestHolding {
+	index := make(map[string]int, len(holdings))
+	merged := make([]types.BacktestHolding, 0, len(holdings))
+	total := 0.0
+	for _, h := range holdings {
+		ticker := strings.ToUpper(strings.TrimSpace(h.Ticker))
+		if ticker == "" || h.Weight <= 0 {
+			continue
+		}
+		total += h.Weight
+		if i, ok := index[ticker]; ok {
+			merged[i].Weight += h.Weight
+			continue
+		}
+		index[ticker] = len(merged)
+		merged = append(merged, types.BacktestHolding{Ticker: ticker, Weight: h.Weight})
+	}
+	for i := range merged {
+		merged[i].Weight /= total
+	}
+	return merged
+}
+
+// inRange returns the prices between from and to (inclusive) with a positive close
+func inRange(prices []types.PriceData, from, to time.Time) []types.PriceData {
+	var filtered []types.PriceData
+	for _, p := range prices {
+		if p.Close > 0 && !p.Date.Before(from) && !p.Date.After(to) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// closesOnly strips a series down to dates and closes, so metrics don't pick up stored YoY values
+func closesOnly(prices []types.PriceData) []types.PriceData {
+	series := make([]types.PriceData, len(prices))
+	for i, p := range prices {
+		series[i] = types.PriceData{Date: p.Date, Close: p.Close}
+	}
+	return series
+}
+
+// rebalanceKey identifies the rebalancing period a date falls into
+func rebalanceKey(date time.Time, schedule string) int {
+	switch schedule {
+	case types.RebalanceYearly:
+		return date.Year()
+	case types.RebalanceQuarterly:
+		return date.Year()*4 + (int(date.Month())-1)/3
+	default:
+		return date.Year()*12 + int(date.Month()) - 1
+	}
+}
+
+// portfolio steps through the union of the holdings' price dates, tracking the last close of every
+// holding and the units held
+type portfolio struct {
+	holdings []types.BacktestHolding
+	series   map[string][]types.PriceData
+	dates    []time.Time
+	cursor   map[string]int
+	last     map[string]float64
+	units    map[string]float64
+	cash     float64
+}
+
+func newPortfolio(holdings []types.BacktestHolding, series map[string][]types.PriceData) *portfolio {
+	seen := make(map[int64]bool)
+	var dates []time.Time
+	for _, s := range series {
+		for _, p := range s {
+			if !seen[p.Date.Unix()] {
+				seen[p.Date.Unix()] = true
+				dates = append(dates, p.Date)
+			}
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	return &portfolio{
+		holdings: holdings,
+		series:   series,
+		dates:    dates,
+		cursor:   make(map[string]int, len(series)),
+		last:     make(map[string]float64, len(series)),
+		units:    make(map[string]float64, len(series)),
+		cash:     StartValue,
+	}
+}
+
+// advance moves all holdings to date and returns the tickers that have a price on that date
+func (p *portfolio) advance(date time.Time) map[string]bool {
+	quoted := make(map[string]bool, len(p.series))
+	for ticker, s := range p.series {
+		i := p.cursor[ticker]
+		for ; i < len(s) && !s[i].Date.After(date); i++ {
+			p.last[ticker] = s[i].Close
+			if s[i].Date.Equal(date) {
+				quoted[ticker] = true
+			}
+		}
+		p.cursor[ticker] = i
+	}
+	return quoted
+}
+
+// value returns the current portfolio value at the last known closes
+func (p *portfolio) value() float64 {
+	value := p.cash
+	for _, h := range p.holdings {
+		value += p.units[h.Ticker] * p.last[h.Ticker]
+	}
+	return value
+}
+
+// rebalance trades the portfolio to the target weights of the quoted holdings
+func (p *portfolio) rebalance(date time.Time, value float64, quoted map[string]bool) types.BacktestRebalance {
+	before := make(map[string]float64, len(p.units))
+	for ticker, units := range p.units {
+		before[ticker] = units * p.last[ticker] / value
+	}
+	cashBefore := p.cash / value
+
+	total := 0.0
+	for _, h := range p.holdings {
+		if quoted[h.Ticker] {
+			total += h.Weight
+		}
+	}
+
+	rebalance := types.BacktestRebalance{
+		Date:     date,
+		Value:    value,
+		Holdings: []types.BacktestPosition{},
+	}
+	p.units = make(map[string]float64, len(p.holdings))
+	p.cash = 0
+	if total == 0 {
+		p.cash = value
+		rebalance.Cash = 1
+	}
+
+	traded := math.Abs(rebalance.Cash - cashBefore)
+	for _, h := range p.holdings {
+		weight := 0.0
+		if quoted[h.Ticker] {
+			weight = h.Weight / total
+			price := p.last[h.Ticker]
+			p.units[h.Ticker] = value * weight / price
+			rebalance.Holdings = append(rebalance.Holdings, types.BacktestPosition{
+				Ticker: h.Ticker,
+				Weight: weight,
+				Price:  price,
+				Units:  p.units[h.Ticker],
+			})
+		}
+		traded += math.Abs(weight - before[h.Ticker])
+	}
+	rebalance.Turnover = traded / 2 * 100
+
+	return rebalance
+}
+
+// benchmark follows an index series alongside the portfolio, scaled to the portfolio value at the
+// first date the index has a price
+type benchmark struct {
+	series []types.PriceData
+	cursor int
+	last   float64
+	scale  float64
+}
+
+func newBenchmark(series []types.PriceData) *benchmark {
+	return &benchmark{series: series}
+}
+
+// advance moves the benchmark to date and returns its scaled value, nil before its first price
+func (b *benchmark) advance(date time.Time, portfolioValue float64) *float64 {
+	for ; b.cursor < len(b.series) && !b.series[b.cursor].Date.After(date); b.cursor++ {
+		b.last = b.series[b.cursor].Close
+		if b.scale == 0 {
+			b.scale = portfolioValue / b.last
+		}
+	}
+	if b.scale == 0 {
+		return nil
+	}
+	value := b.last * b.scale
+	return &value
+}
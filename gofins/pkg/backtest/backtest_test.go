@@ -0,0 +1,196 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+// monthly builds a monthly series starting January 2020
+func monthly(ticker string, closes ...float64) []types.PriceData {
+	series := make([]types.PriceData, len(closes))
+	for i, c := range closes {
+		series[i] = types.PriceData{
+			Date:         time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, i, 0),
+			Close:        c,
+			SymbolTicker: ticker,
+		}
+	}
+	return series
+}
+
+func testConfig(rebalance string) types.BacktestConfig {
+	return types.BacktestConfig{
+		Rebalance: rebalance,
+		Interval:  types.IntervalMonthly,
+		Start:     time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+		End:       time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestSimulateBuyAndHoldSingleTicker(t *testing.T) {
+	prices := map[string][]types.PriceData{"AAA": monthly("AAA", 10, 11, 12, 9, 15)}
+	result, err := Simulate(testConfig(types.RebalanceYearly), []types.BacktestHolding{{Ticker: "aaa", Weight: 3}}, prices, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []float64{100, 110, 120, 90, 150}
+	if len(result.Equity) != len(want) {
+		t.Fatalf("got %d equity points, want %d", len(result.Equity), len(want))
+	}
+	for i, point := range result.Equity {
+		if !approx(point.Value, want[i]) {
+			t.Errorf("equity[%d] = %v, want %v", i, point.Value, want[i])
+		}
+	}
+	if len(result.Rebalances) != 1 || result.Turnover != 0 {
+		t.Errorf("expected only the initial allocation, got %d rebalances with turnover %v", len(result.Rebalances), result.Turnover)
+	}
+	if result.Holdings[0].Ticker != "AAA" || result.Holdings[0].Weight != 1 {
+		t.Errorf("holdings not normalized: %+v", result.Holdings)
+	}
+}
+
+func TestSimulateRebalancesToTargetWeights(t *testing.T) {
+	// A doubles in the first month, B stays flat: the monthly rebalance sells A back to 50%
+	prices := map[string][]types.PriceData{
+		"A": monthly("A", 10, 20, 20),
+		"B": monthly("B", 10, 10, 10),
+	}
+	holdings := []types.BacktestHolding{{Ticker: "A", Weight: 1}, {Ticker: "B", Weight: 1}}
+	result, err := Simulate(testConfig(types.RebalanceMonthly), holdings, prices, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !approx(result.Equity[1].Value, 150) || !approx(result.Equity[2].Value, 150) {
+		t.Errorf("equity = %v, %v, want 150, 150", result.Equity[1].Value, result.Equity[2].Value)
+	}
+	if len(result.Rebalances) != 3 {
+		t.Fatalf("got %d rebalances, want 3", len(result.Rebalances))
+	}
+	// Before the second rebalance A is 2/3 of the portfolio: one-way turnover 1/6
+	if got := result.Rebalances[1].Turnover; !approx(got, 100.0/6) {
+		t.Errorf("turnover = %v, want %v", got, 100.0/6)
+	}
+	if got := result.Rebalances[2].Turnover; !approx(got, 0) {
+		t.Errorf("turnover without drift = %v, want 0", got)
+	}
+	for _, position := range result.Rebalances[1].Holdings {
+		if !approx(position.Weight, 0.5) {
+			t.Errorf("%s weight = %v, want 0.5", position.Ticker, position.Weight)
+		}
+	}
+}
+
+func TestSimulateQuarterlyDrift(t *testing.T) {
+	prices := map[string][]types.PriceData{
+		"A": monthly("A", 10, 20, 20, 20),
+		"B": monthly("B", 10, 10, 10, 10),
+	}
+	holdings := []types.BacktestHolding{{Ticker: "A", Weight: 1}, {Ticker: "B", Weight: 1}}
+	result, err := Simulate(testConfig(types.RebalanceQuarterly), holdings, prices, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Rebalanced in January and April only
+	if len(result.Rebalances) != 2 || result.Rebalances[1].Date.Month() != time.April {
+		t.Fatalf("unexpected rebalances: %+v", result.Rebalances)
+	}
+}
+
+func TestSimulateMissingAndDelistedHoldings(t *testing.T) {
+	// B stops trading after two months, C never has prices in the range
+	prices := map[string][]types.PriceData{
+		"A": monthly("A", 10, 10, 10, 10),
+		"B": monthly("B", 10, 5),
+	}
+	holdings := []types.BacktestHolding{{Ticker: "A", Weight: 1}, {Ticker: "B", Weight: 1}, {Ticker: "C", Weight: 2}}
+	result, err := Simulate(testConfig(types.RebalanceMonthly), holdings, prices, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Missing) != 1 || result.Missing[0] != "C" {
+		t.Errorf("missing = %v, want [C]", result.Missing)
+	}
+	// C's weight goes to A and B, then B is sold at its last price and A holds everything
+	if !approx(result.Equity[1].Value, 75) || !approx(result.Equity[3].Value, 75) {
+		t.Errorf("equity = %+v", result.Equity)
+	}
+	last := result.Rebalances[len(result.Rebalances)-1]
+	if len(last.Holdings) != 1 || last.Holdings[0].Ticker != "A" || !approx(last.Holdings[0].Weight, 1) {
+		t.Errorf("last rebalance holdings = %+v, want all in A", last.Holdings)
+	}
+}
+
+func TestSimulateBenchmarkAndMetrics(t *testing.T) {
+	closes := make([]float64, 25)
+	index := make([]float64, 25)
+	for i := range closes {
+		closes[i] = 100 * math.Pow(1.01, float64(i))
+		index[i] = 50 * math.Pow(1.005, float64(i))
+	}
+	prices := map[string][]types.PriceData{"A": monthly("A", closes...)}
+	result, err := Simulate(testConfig(types.RebalanceYearly), []types.BacktestHolding{{Ticker: "A", Weight: 1}}, prices, monthly("^IDX", index...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Metrics == nil || result.Metrics.CAGR == nil || math.Abs(*result.Metrics.CAGR-12.68) > 0.05 {
+		t.Errorf("portfolio CAGR = %v, want ~12.68", result.Metrics)
+	}
+	if result.Benchmark == nil || result.Benchmark.CAGR == nil || math.Abs(*result.Benchmark.CAGR-6.17) > 0.05 {
+		t.Errorf("benchmark CAGR = %v, want ~6.17", result.Benchmark)
+	}
+	// The benchmark curve starts at the portfolio value
+	if b := result.Equity[0].Benchmark; b == nil || !approx(*b, StartValue) {
+		t.Errorf("benchmark start = %v, want %v", b, StartValue)
+	}
+}
+
+func TestSimulateNoPrices(t *testing.T) {
+	_, err := Simulate(testConfig(types.RebalanceMonthly), []types.BacktestHolding{{Ticker: "A", Weight: 1}}, nil, nil)
+	if err != ErrNoPrices {
+		t.Errorf("err = %v, want ErrNoPrices", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := testConfig(types.RebalanceQuarterly)
+	valid.Holdings = []types.BacktestHolding{{Ticker: "AAPL", Weight: 0.6}, {Ticker: "MSFT", Weight: 0.4}}
+	if err := Validate(valid); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	invalid := map[string]func(*types.BacktestConfig){
+		"no holdings":      func(c *types.BacktestConfig) { c.Holdings = nil },
+		"both selections":  func(c *types.BacktestConfig) { c.TopN = &types.BacktestTopN{PackageID: "x", N: 5} },
+		"negative weight":  func(c *types.BacktestConfig) { c.Holdings[0].Weight = -1 },
+		"bad schedule":     func(c *types.BacktestConfig) { c.Rebalance = "weekly" },
+		"bad interval":     func(c *types.BacktestConfig) { c.Interval = "daily" },
+		"end before start": func(c *types.BacktestConfig) { c.End = c.Start },
+		"bad package id":   func(c *types.BacktestConfig) { c.Holdings = nil; c.TopN = &types.BacktestTopN{PackageID: "x", N: 5} },
+		"top n out of range": func(c *types.BacktestConfig) {
+			c.Holdings = nil
+			c.TopN = &types.BacktestTopN{PackageID: "6f1c2b9e-3a4d-4e5f-8a7b-1c2d3e4f5a6b", N: 0}
+		},
+	}
+	for name, mutate := range invalid {
+		config := valid
+		config.Holdings = append([]types.BacktestHolding(nil), valid.Holdings...)
+		config.Benchmark = f.Ptr("^GSPC")
+		mutate(&config)
+		if err := Validate(config); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package backtest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/analysis"
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrPackageNotFound is returned if the top-N package doesn't exist or belongs to another user
+	ErrPackageNotFound = errors.New("analysis package not found")
+	// ErrProfileNotFound is returned if the top-N scoring profile doesn't exist
+	ErrProfileNotFound = errors.New("scoring profile not found")
+	// ErrInvalidBenchmark is returned if the benchmark is not a known index
+	ErrInvalidBenchmark = errors.New("benchmark must be a symbol of type 'index'")
+)
+
+// Run resolves the holdings of a config, loads their prices and simulates the backtest.
+// The config must have passed Validate.
+func Run(ctx context.Context, userID uuid.UUID, config types.BacktestConfig) (*types.BacktestResult, error) {
+	holdings := config.Holdings
+	if config.TopN != nil {
+		var err error
+		holdings, err = topHoldings(ctx, userID, *config.TopN)
+		if err != nil {
+			return nil, err
+		}
+	}
+	holdings = normalizeHoldings(holdings)
+	if len(holdings) == 0 {
+		return nil, ErrNoHoldings
+	}
+
+	tickers := make([]string, len(holdings))
+	for i, h := range holdings {
+		tickers[i] = h.Ticker
+	}
+	prices, err := db.GetPricesBatch(tickers, config.Start, config.End, config.Interval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prices: %w", err)
+	}
+
+	var benchmark []types.PriceData
+	if config.Benchmark != nil {
+		symbol, err := db.GetSymbol(ctx, *config.Benchmark)
+		if err != nil {
+			return nil, err
+		}
+		if symbol == nil || symbol.Type == nil || *symbol.Type != types.TypeIndex {
+			return nil, ErrInvalidBenchmark
+		}
+		benchmark, err = db.GetPrices(symbol.Ticker, config.Start, config.End, config.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get benchmark prices: %w", err)
+		}
+	}
+
+	return Simulate(config, holdings, prices, benchmark)
+}
+
+// topHoldings ranks the results of a package and weights the best N equally.
+// The ranking uses the package results as stored, i.e. with hindsight of the package's time range.
+func topHoldings(ctx context.Context, userID uuid.UUID, topN types.BacktestTopN) ([]types.BacktestHolding, error) {
+	profile := analysis.DefaultScoringProfile
+	if topN.ProfileID != nil {
+		stored, err := db.GetScoringProfile(ctx, userID, *topN.ProfileID)
+		if err != nil {
+			return nil, err
+		}
+		if stored == nil {
+			return nil, ErrProfileNotFound
+		}
+		profile = *stored
+	}
+
+	results, err := db.GetAnalysisResults(ctx, userID, topN.PackageID)
+	if err == sql.ErrNoRows {
+		return nil, ErrPackageNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get package results: %w", err)
+	}
+
+	scored := analysis.ScoreResults(results, profile)
+	n := min(topN.N, len(scored))
+	holdings := make([]types.BacktestHolding, n)
+	for i := range holdings {
+		holdings[i] = types.BacktestHolding{Ticker: scored[i].Ticker, Weight: 1 / float64(n)}
+	}
+	return holdings, nil
+}
+
+// Create runs a backtest and stores it for the user. An empty name is derived from the config.
+func Create(ctx context.Context, userID uuid.UUID, name string, config types.BacktestConfig) (*types.Backtest, error) {
+	result, err := Run(ctx, userID, config)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = defaultName(config)
+	}
+
+	backtest := &types.Backtest{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      name,
+		Config:    config,
+		Result:    result,
+		CreatedAt: time.Now(),
+	}
+	if err := db.CreateBacktest(ctx, backtest); err != nil {
+		return nil, err
+	}
+	return backtest, nil
+}
+
+// defaultName describes the selection, schedule and range of a config, e.g. "Top 10, quarterly, 2015-2024"
+func defaultName(config types.BacktestConfig) string {
+	selection := fmt.Sprintf("%d holdings", len(config.Holdings))
+	if config.TopN != nil {
+		selection = fmt.Sprintf("Top %d", config.TopN.N)
+	}
+	return fmt.Sprintf("%s, %s, %d-%d", selection, config.Rebalance, config.Start.Year(), config.End.Year())
+}
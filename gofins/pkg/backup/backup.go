@@ -0,0 +1,348 @@
+// Package backup exports a user's own data (ratings, watchlists, analysis packages,
+// journal, scoring profiles and portfolios) to a versioned JSON document and imports it again.
+// Market data is not part of a backup - it can always be rebuilt by the updaters.
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+)
+
+// FormatVersion is the version written to new backups. Import rejects newer versions.
+// Version 2 added portfolios, version 3 replaced favorites by all watchlists.
+const FormatVersion = 3
+
+// File formats
+const (
+	FormatJSON  = "json"
+	FormatTarGz = "tar.gz"
+)
+
+// archiveEntryName is the name of the JSON document inside a tar.gz backup
+const archiveEntryName = "backup.json"
+
+// Backup is the complete exported data of one user
+type Backup struct {
+	Version          int                    `json:"version"`
+	ExportedAt       time.Time              `json:"exportedAt"`
+	User             string                 `json:"user"`
+	Ratings          []db.UserRating        `json:"ratings"`             // Full history, not only the latest rating per ticker
+	Favorites        []db.Favorite          `json:"favorites,omitempty"` // The favorites watchlist before version 3
+	Watchlists       []Watchlist            `json:"watchlists"`          // Since version 3
+	AnalysisPackages []AnalysisPackage      `json:"analysisPackages"`
+	Journal          []types.JournalEntry   `json:"journal"`
+	ScoringProfiles  []types.ScoringProfile `json:"scoringProfiles"`
+	Portfolios       []Portfolio            `json:"portfolios"` // Since version 2
+}
+
+// Watchlist is a watchlist with its entries in list order
+type Watchlist struct {
+	Name      string           `json:"name"`
+	IsDefault bool             `json:"isDefault"` // The favorites list
+	CreatedAt time.Time        `json:"createdAt"`
+	Entries   []WatchlistEntry `json:"entries"`
+}
+
+// WatchlistEntry is a ticker on a watchlist with the user's note and target price
+type WatchlistEntry struct {
+	Ticker      string    `json:"ticker"`
+	Note        *string   `json:"note"`
+	TargetPrice *float64  `json:"targetPrice"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Portfolio is a portfolio together with its transactions
+type Portfolio struct {
+	ID           uuid.UUID           `json:"id"`
+	Name         string              `json:"name"`
+	CostBasis    string              `json:"costBasis"`
+	CreatedAt    time.Time           `json:"createdAt"`
+	Transactions []types.Transaction `json:"transactions"`
+}
+
+// AnalysisPackage is a package definition together with its computed results
+type AnalysisPackage struct {
+	ID             string                `json:"id"`
+	Name           string                `json:"name"`
+	CreatedAt      time.Time             `json:"createdAt"`
+	Interval       string                `json:"interval"`
+	TimeFrom       time.Time             `json:"timeFrom"`
+	TimeTo         time.Time             `json:"timeTo"`
+	HistBins       int                   `json:"histBins"`
+	HistMin        float64               `json:"histMin"`
+	HistMax        float64               `json:"histMax"`
+	McapMin        *int64                `json:"mcapMin"`
+	McapMax        *int64                `json:"mcapMax"`
+	InceptionMax   *time.Time            `json:"inceptionMax"`
+	Filters        types.AnalysisFilters `json:"filters"`
+	ReferenceIndex *string               `json:"referenceIndex"`
+	ReturnBasis    string                `json:"returnBasis"`
+	RiskFreeRate   float64               `json:"riskFreeRate"`
+	RollingWindow  int                   `json:"rollingWindowMonths"`
+	Outliers       types.OutlierConfig   `json:"outliers"`
+	Status         string                `json:"status"`
+	SymbolCount    int                   `json:"symbolCount"`
+	Results        []AnalysisResult      `json:"results"`
+}
+
+// AnalysisResult is one stored result of a package, including its histogram
+type AnalysisResult struct {
+	Ticker          string                    `json:"ticker"`
+	Count           int                       `json:"count"`
+	Mean            float64                   `json:"mean"`
+	StdDev          float64                   `json:"stddev"`
+	Variance        float64                   `json:"variance"`
+	Min             float64                   `json:"min"`
+	Max             float64                   `json:"max"`
+	Beta            *float64                  `json:"beta"`
+	Correlation     *float64                  `json:"correlation"`
+	Alpha           *float64                  `json:"alpha"`
+	Metrics         *types.PerformanceMetrics `json:"metrics"`
+	Rolling         *types.RollingSummary     `json:"rolling"`
+	OutliersRemoved int                       `json:"outliersRemoved"`
+	Histogram       json.RawMessage           `json:"histogram"`
+}
+
+// Export collects all data owned by a user
+func Export(ctx context.Context, user *types.User) (*Backup, error) {
+	ratings, err := db.GetAllRatings(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export ratings: %w", err)
+	}
+
+	watchlists, err := exportWatchlists(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export watchlists: %w", err)
+	}
+
+	packages, err := db.ListAnalysisPackages(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export analysis packages: %w", err)
+	}
+	exportedPackages := make([]AnalysisPackage, 0, len(packages))
+	for _, pkg := range packages {
+		results, err := db.GetAnalysisResultsWithHistogram(ctx, pkg.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export results of package %s: %w", pkg.ID, err)
+		}
+		exportedPackages = append(exportedPackages, packageToBackup(pkg, results))
+	}
+
+	journal, err := db.ListJournalEntries(ctx, user.ID, db.JournalFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export journal: %w", err)
+	}
+
+	profiles, err := db.ListScoringProfiles(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export scoring profiles: %w", err)
+	}
+
+	portfolios, err := db.ListPortfolios(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export portfolios: %w", err)
+	}
+	exportedPortfolios := make([]Portfolio, 0, len(portfolios))
+	for _, p := range portfolios {
+		transactions, err := db.ListPortfolioTransactions(ctx, p.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export transactions of portfolio %s: %w", p.Name, err)
+		}
+		exportedPortfolios = append(exportedPortfolios, Portfolio{
+			ID:           p.ID,
+			Name:         p.Name,
+			CostBasis:    p.CostBasis,
+			CreatedAt:    p.CreatedAt,
+			Transactions: transactions,
+		})
+	}
+
+	return &Backup{
+		Version:          FormatVersion,
+		ExportedAt:       time.Now().UTC(),
+		User:             user.Name,
+		Ratings:          ratings,
+		Watchlists:       watchlists,
+		AnalysisPackages: exportedPackages,
+		Journal:          journal,
+		ScoringProfiles:  profiles,
+		Portfolios:       exportedPortfolios,
+	}, nil
+}
+
+func exportWatchlists(ctx context.Context, userID uuid.UUID) ([]Watchlist, error) {
+	watchlists, err := db.ListWatchlists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	exported := make([]Watchlist, 0, len(watchlists))
+	for _, w := range watchlists {
+		watchlist, err := db.GetWatchlist(ctx, userID, w.ID)
+		if err != nil {
+			return nil, err
+		}
+		if watchlist == nil {
+			continue // Deleted meanwhile
+		}
+		entries := make([]WatchlistEntry, len(watchlist.Entries))
+		for i, e := range watchlist.Entries {
+			entries[i] = WatchlistEntry{
+				Ticker:      e.Ticker,
+				Note:        e.Note,
+				TargetPrice: e.TargetPrice,
+				CreatedAt:   e.CreatedAt,
+			}
+		}
+		exported = append(exported, Watchlist{
+			Name:      watchlist.Name,
+			IsDefault: watchlist.IsDefault,
+			CreatedAt: watchlist.CreatedAt,
+			Entries:   entries,
+		})
+	}
+	return exported, nil
+}
+
+func packageToBackup(pkg types.AnalysisPackage, results []types.AnalysisResult) AnalysisPackage {
+	exported := AnalysisPackage{
+		ID:             pkg.ID,
+		Name:           pkg.Name,
+		CreatedAt:      pkg.CreatedAt,
+		Interval:       pkg.Interval,
+		TimeFrom:       pkg.TimeFrom,
+		TimeTo:         pkg.TimeTo,
+		HistBins:       pkg.HistBins,
+		HistMin:        pkg.HistMin,
+		HistMax:        pkg.HistMax,
+		McapMin:        pkg.McapMin,
+		McapMax:        pkg.McapMax,
+		InceptionMax:   pkg.InceptionMax,
+		Filters:        pkg.Filters,
+		ReferenceIndex: pkg.ReferenceIndex,
+		ReturnBasis:    pkg.ReturnBasis,
+		RiskFreeRate:   pkg.RiskFreeRate,
+		RollingWindow:  pkg.RollingWindowMonths,
+		Outliers:       pkg.Outliers,
+		Status:         pkg.Status,
+		SymbolCount:    pkg.SymbolCount,
+		Results:        make([]AnalysisResult, len(results)),
+	}
+	for i, r := range results {
+		exported.Results[i] = AnalysisResult{
+			Ticker:          r.Ticker,
+			Count:           r.Count,
+			Mean:            r.Mean,
+			StdDev:          r.StdDev,
+			Variance:        r.Variance,
+			Min:             r.Min,
+			Max:             r.Max,
+			Beta:            r.Beta,
+			Correlation:     r.Correlation,
+			Alpha:           r.Alpha,
+			Metrics:         r.Metrics,
+			Rolling:         r.Rolling,
+			OutliersRemoved: r.OutliersRemoved,
+			Histogram:       r.Histogram,
+		}
+	}
+	return exported
+}
+
+// Encode writes a backup as plain JSON or as a gzipped tarball containing backup.json
+func Encode(w io.Writer, b *Backup, format string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatJSON:
+		_, err = w.Write(data)
+		return err
+
+	case FormatTarGz:
+		gz := gzip.NewWriter(w)
+		tw := tar.NewWriter(gz)
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    archiveEntryName,
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: b.ExportedAt,
+		}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+		if err := tw.Close(); err != nil {
+			return err
+		}
+		return gz.Close()
+
+	default:
+		return fmt.Errorf("unknown backup format '%s' (must be '%s' or '%s')", format, FormatJSON, FormatTarGz)
+	}
+}
+
+// Decode reads a backup written by Encode. Gzipped tarballs are detected automatically.
+func Decode(r io.Reader) (*Backup, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	var data []byte
+	if bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		data, err = readArchive(br)
+	} else {
+		data, err = io.ReadAll(br)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var b Backup
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("invalid backup: %w", err)
+	}
+	if b.Version < 1 || b.Version > FormatVersion {
+		return nil, fmt.Errorf("unsupported backup version %d (supported: 1-%d)", b.Version, FormatVersion)
+	}
+	return &b, nil
+}
+
+// readArchive returns the content of backup.json from a gzipped tarball
+func readArchive(r io.Reader) ([]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("archive contains no %s", archiveEntryName)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Name == archiveEntryName {
+			return io.ReadAll(tr)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+func testBackup() *Backup {
+	notes := "strong moat"
+	target := 150.0
+	return &Backup{
+		Version:    FormatVersion,
+		ExportedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		User:       "alice",
+		Ratings: []db.UserRating{
+			{Ticker: "AAPL", Rating: 4, Notes: &notes, CreatedAt: time.Date(2024, 4, 1, 9, 30, 0, 0, time.UTC)},
+		},
+		Watchlists: []Watchlist{
+			{Name: "Favorites", IsDefault: true, Entries: []WatchlistEntry{{Ticker: "MSFT"}}},
+			{Name: "Semis", Entries: []WatchlistEntry{{Ticker: "NVDA", Note: &notes, TargetPrice: &target}, {Ticker: "AMD"}}},
+		},
+		AnalysisPackages: []AnalysisPackage{{
+			ID:      "5b0c5f39-7c5e-4a45-9f43-1d2b4cb4fd2e",
+			Name:    "Tech",
+			Results: []AnalysisResult{{Ticker: "AAPL", Mean: 12.5, Histogram: []byte(`{"bins":[1,2]}`)}},
+		}},
+		Portfolios: []Portfolio{{
+			Name:      "Depot",
+			CostBasis: types.CostBasisFIFO,
+			Transactions: []types.Transaction{
+				{Ticker: "AAPL", Type: types.TransactionBuy, Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Quantity: 10, Price: 170, Currency: "USD", FxRate: 1},
+			},
+		}},
+	}
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatTarGz} {
+		var buf bytes.Buffer
+		if err := Encode(&buf, testBackup(), format); err != nil {
+			t.Fatalf("%s: encode failed: %v", format, err)
+		}
+
+		decoded, err := Decode(&buf)
+		if err != nil {
+			t.Fatalf("%s: decode failed: %v", format, err)
+		}
+		if decoded.User != "alice" || len(decoded.Ratings) != 1 || *decoded.Ratings[0].Notes != "strong moat" {
+			t.Errorf("%s: unexpected backup: %+v", format, decoded)
+		}
+		var histogram bytes.Buffer
+		if err := json.Compact(&histogram, decoded.AnalysisPackages[0].Results[0].Histogram); err != nil || histogram.String() != `{"bins":[1,2]}` {
+			t.Errorf("%s: histogram = %s (%v)", format, histogram.String(), err)
+		}
+		if len(decoded.Watchlists) != 2 || decoded.Watchlists[1].Entries[1].Ticker != "AMD" || *decoded.Watchlists[1].Entries[0].TargetPrice != 150 {
+			t.Errorf("%s: watchlists = %+v", format, decoded.Watchlists)
+		}
+		if len(decoded.Portfolios) != 1 || len(decoded.Portfolios[0].Transactions) != 1 || decoded.Portfolios[0].Transactions[0].Quantity != 10 {
+			t.Errorf("%s: portfolios = %+v", format, decoded.Portfolios)
+		}
+	}
+}
+
+func TestDecodeRejectsNewerVersion(t *testing.T) {
+	if _, err := Decode(bytes.NewBufferString(`{"version": 99}`)); err == nil {
+		t.Error("expected error for unsupported version")
+	}
+}
+
+func TestDecodeVersion1(t *testing.T) {
+	decoded, err := Decode(bytes.NewBufferString(`{"version": 1, "user": "alice", "favorites": [{"ticker": "MSFT"}]}`))
+	if err != nil {
+		t.Fatalf("version 1 backups must still be accepted: %v", err)
+	}
+	if len(decoded.Favorites) != 1 || decoded.Watchlists != nil || decoded.Portfolios != nil {
+		t.Errorf("unexpected backup: %+v", decoded)
+	}
+}
+
+func TestMergeJournalEntries(t *testing.T) {
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	local := types.JournalEntry{ID: 7, Content: "local", Type: "note", Tags: []string{"macro"}, Tickers: []string{"AAPL"}, UpdatedAt: older}
+	backup := types.JournalEntry{Content: "backup", Type: "idea", Tags: []string{"ai", "macro"}, Tickers: []string{"NVDA"}, UpdatedAt: newer}
+
+	merged := mergeJournalEntries(local, backup)
+	if merged.ID != 7 || merged.Content != "backup" || merged.Type != "idea" {
+		t.Errorf("newer backup content should win: %+v", merged)
+	}
+	if !reflect.DeepEqual(merged.Tags, []string{"ai", "macro"}) {
+		t.Errorf("tags = %v", merged.Tags)
+	}
+	if !reflect.DeepEqual(merged.Tickers, []string{"AAPL", "NVDA"}) {
+		t.Errorf("tickers = %v", merged.Tickers)
+	}
+
+	merged = mergeJournalEntries(backup, local)
+	if merged.Content != "backup" {
+		t.Errorf("older content must not win, got %q", merged.Content)
+	}
+}
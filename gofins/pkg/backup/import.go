@@ -0,0 +1,635 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/portfolio"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+)
+
+// Conflict policies for items that exist both in the backup and in the database
+const (
+	PolicySkip      = "skip"      // Keep the existing item, only add missing ones
+	PolicyOverwrite = "overwrite" // Replace the existing item with the backup version
+	PolicyMerge     = "merge"     // Combine both (union of tickers/tags, newer content wins)
+)
+
+// Policies lists all valid conflict policies
+var Policies = []string{PolicySkip, PolicyOverwrite, PolicyMerge}
+
+// IsValidPolicy checks if a conflict policy is supported
+func IsValidPolicy(policy string) bool {
+	for _, p := range Policies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportCounts counts what happened to the items of one kind
+type ImportCounts struct {
+	Added   int `json:"added"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+}
+
+// ImportReport summarizes an import
+type ImportReport struct {
+	Policy           string       `json:"policy"`
+	Ratings          ImportCounts `json:"ratings"`
+	Favorites        ImportCounts `json:"favorites"`  // Backups before version 3
+	Watchlists       ImportCounts `json:"watchlists"` // Counts watchlist entries
+	AnalysisPackages ImportCounts `json:"analysisPackages"`
+	Journal          ImportCounts `json:"journal"`
+	ScoringProfiles  ImportCounts `json:"scoringProfiles"`
+	Portfolios       ImportCounts `json:"portfolios"`
+}
+
+// Import restores a backup into the account of userID.
+// Items are matched as follows: ratings by ticker and timestamp, favorites by ticker, watchlists by name
+// (the favorites list by being the default) and their entries by ticker,
+// analysis packages by ID, journal entries by title and creation time, scoring profiles and portfolios
+// by name, portfolio transactions by ticker, type, date and creation time.
+func Import(ctx context.Context, userID uuid.UUID, b *Backup, policy string) (*ImportReport, error) {
+	if !IsValidPolicy(policy) {
+		return nil, fmt.Errorf("invalid policy '%s' (must be one of: %s)", policy, strings.Join(Policies, ", "))
+	}
+
+	report := &ImportReport{Policy: policy}
+	if err := importRatings(ctx, userID, b.Ratings, policy, &report.Ratings); err != nil {
+		return report, fmt.Errorf("failed to import ratings: %w", err)
+	}
+	if err := importFavorites(ctx, userID, b.Favorites, &report.Favorites); err != nil {
+		return report, fmt.Errorf("failed to import favorites: %w", err)
+	}
+	if err := importWatchlists(ctx, userID, b.Watchlists, policy, &report.Watchlists); err != nil {
+		return report, fmt.Errorf("failed to import watchlists: %w", err)
+	}
+	if err := importPackages(ctx, userID, b.AnalysisPackages, policy, &report.AnalysisPackages); err != nil {
+		return report, fmt.Errorf("failed to import analysis packages: %w", err)
+	}
+	if err := importJournal(ctx, userID, b.Journal, policy, &report.Journal); err != nil {
+		return report, fmt.Errorf("failed to import journal: %w", err)
+	}
+	if err := importScoringProfiles(ctx, userID, b.ScoringProfiles, policy, &report.ScoringProfiles); err != nil {
+		return report, fmt.Errorf("failed to import scoring profiles: %w", err)
+	}
+	if err := importPortfolios(ctx, userID, b.Portfolios, policy, &report.Portfolios); err != nil {
+		return report, fmt.Errorf("failed to import portfolios: %w", err)
+	}
+	return report, nil
+}
+
+// timeKey identifies a timestamp at database (microsecond) precision
+func timeKey(t time.Time) string {
+	return t.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+}
+
+func importRatings(ctx context.Context, userID uuid.UUID, ratings []db.UserRating, policy string, counts *ImportCounts) error {
+	existing, err := db.GetAllRatings(ctx, userID)
+	if err != nil {
+		return err
+	}
+	byKey := make(map[string]db.UserRating, len(existing))
+	for _, r := range existing {
+		byKey[r.Ticker+"|"+timeKey(r.CreatedAt)] = r
+	}
+
+	for _, rating := range ratings {
+		rating.Ticker = strings.ToUpper(rating.Ticker)
+		local, exists := byKey[rating.Ticker+"|"+timeKey(rating.CreatedAt)]
+		if !exists {
+			if _, err := db.ImportRating(ctx, userID, rating); err != nil {
+				return err
+			}
+			counts.Added++
+			continue
+		}
+
+		switch {
+		case policy == PolicyOverwrite && !sameRating(local, rating):
+		case policy == PolicyMerge && local.Notes == nil && rating.Notes != nil:
+			// Merge keeps the local rating and only fills in missing notes
+			rating.Rating = local.Rating
+		default:
+			counts.Skipped++
+			continue
+		}
+		if _, err := db.UpdateRatingAt(ctx, userID, rating); err != nil {
+			return err
+		}
+		counts.Updated++
+	}
+	return nil
+}
+
+func sameRating(a, b db.UserRating) bool {
+	if a.Rating != b.Rating || (a.Notes == nil) != (b.Notes == nil) {
+		return false
+	}
+	return a.Notes == nil || *a.Notes == *b.Notes
+}
+
+// importFavorites adds missing favorites. Favorites carry no data, so all policies behave the same.
+func importFavorites(ctx context.Context, userID uuid.UUID, favorites []db.Favorite, counts *ImportCounts) error {
+	for _, favorite := range favorites {
+		favorite.Ticker = strings.ToUpper(favorite.Ticker)
+		added, err := db.ImportFavorite(ctx, userID, favorite)
+		if err != nil {
+			return err
+		}
+		if added {
+			counts.Added++
+		} else {
+			counts.Skipped++
+		}
+	}
+	return nil
+}
+
+// importWatchlists creates missing watchlists and adds missing entries to existing ones. Overwrite also
+// replaces note and target price of existing entries and restores the order of the backup,
+// merge only fills in missing notes and target prices.
+func importWatchlists(ctx context.Context, userID uuid.UUID, watchlists []Watchlist, policy string, counts *ImportCounts) error {
+	existing, err := db.ListWatchlists(ctx, userID)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]uuid.UUID, len(existing))
+	for _, w := range existing {
+		byName[w.Name] = w.ID
+	}
+
+	for _, w := range watchlists {
+		entries := make([]types.WatchlistEntry, len(w.Entries))
+		for i, e := range w.Entries {
+			entries[i] = types.WatchlistEntry{
+				Ticker:      strings.ToUpper(e.Ticker),
+				Note:        e.Note,
+				TargetPrice: e.TargetPrice,
+				CreatedAt:   e.CreatedAt,
+			}
+		}
+
+		id, exists := byName[w.Name]
+		if w.IsDefault {
+			if id, err = db.DefaultWatchlistID(ctx, userID); err != nil {
+				return err
+			}
+			exists = true
+		}
+		if !exists {
+			created, err := db.CreateWatchlist(ctx, userID, w.Name)
+			if err != nil {
+				return err
+			}
+			added, err := db.AddWatchlistEntries(ctx, created.ID, entries)
+			if err != nil {
+				return err
+			}
+			byName[w.Name] = created.ID
+			counts.Added += added
+			continue
+		}
+
+		if err := mergeWatchlist(ctx, userID, id, entries, policy, counts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeWatchlist imports the entries of a backup watchlist into an existing one
+func mergeWatchlist(ctx context.Context, userID, watchlistID uuid.UUID, entries []types.WatchlistEntry, policy string, counts *ImportCounts) error {
+	local, err := db.GetWatchlist(ctx, userID, watchlistID)
+	if err != nil || local == nil {
+		return err
+	}
+	byTicker := make(map[string]types.WatchlistEntry, len(local.Entries))
+	for _, e := range local.Entries {
+		byTicker[e.Ticker] = e
+	}
+
+	var missing []types.WatchlistEntry
+	for _, entry := range entries {
+		localEntry, exists := byTicker[entry.Ticker]
+		if !exists {
+			missing = append(missing, entry)
+			continue
+		}
+
+		updated := localEntry
+		switch policy {
+		case PolicyOverwrite:
+			updated.Note, updated.TargetPrice = entry.Note, entry.TargetPrice
+		case PolicyMerge:
+			if updated.Note == nil {
+				updated.Note = entry.Note
+			}
+			if updated.TargetPrice == nil {
+				updated.TargetPrice = entry.TargetPrice
+			}
+		}
+		if sameEntry(localEntry, updated) {
+			counts.Skipped++
+			continue
+		}
+		if _, err := db.UpdateWatchlistEntry(ctx, watchlistID, updated); err != nil {
+			return err
+		}
+		counts.Updated++
+	}
+
+	added, err := db.AddWatchlistEntries(ctx, watchlistID, missing)
+	if err != nil {
+		return err
+	}
+	counts.Added += added
+	counts.Skipped += len(missing) - added
+
+	if policy != PolicyOverwrite {
+		return nil
+	}
+	order := make([]string, len(entries))
+	for i, e := range entries {
+		order[i] = e.Ticker
+	}
+	return db.ReorderWatchlist(ctx, watchlistID, order)
+}
+
+func sameEntry(a, b types.WatchlistEntry) bool {
+	sameNote := (a.Note == nil) == (b.Note == nil) && (a.Note == nil || *a.Note == *b.Note)
+	sameTarget := (a.TargetPrice == nil) == (b.TargetPrice == nil) && (a.TargetPrice == nil || *a.TargetPrice == *b.TargetPrice)
+	return sameNote && sameTarget
+}
+
+func importPackages(ctx context.Context, userID uuid.UUID, packages []AnalysisPackage, policy string, counts *ImportCounts) error {
+	for _, pkg := range packages {
+		if _, err := uuid.Parse(pkg.ID); err != nil {
+			pkg.ID = uuid.New().String()
+		}
+
+		owner, err := db.GetAnalysisPackageOwner(ctx, pkg.ID)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case owner == nil:
+			// not present yet
+		case *owner != userID:
+			// Package IDs are global - a copy owned by another user gets a fresh ID
+			pkg.ID = uuid.New().String()
+		case policy == PolicySkip:
+			counts.Skipped++
+			continue
+		case policy == PolicyOverwrite:
+			if err := db.DeleteAnalysisPackage(ctx, userID, pkg.ID); err != nil {
+				return err
+			}
+			if err := createPackage(ctx, userID, pkg); err != nil {
+				return err
+			}
+			counts.Updated++
+			continue
+		case policy == PolicyMerge:
+			added, err := addMissingResults(ctx, userID, pkg)
+			if err != nil {
+				return err
+			}
+			if added > 0 {
+				counts.Updated++
+			} else {
+				counts.Skipped++
+			}
+			continue
+		}
+
+		if err := createPackage(ctx, userID, pkg); err != nil {
+			return err
+		}
+		counts.Added++
+	}
+	return nil
+}
+
+// createPackage inserts a package definition with all its results
+func createPackage(ctx context.Context, userID uuid.UUID, pkg AnalysisPackage) error {
+	status := pkg.Status
+	if status == "processing" {
+		// The analysis that was running at export time will never finish here
+		status = "failed"
+	}
+
+	if err := db.CreateAnalysisPackage(ctx, &types.AnalysisPackage{
+		ID:                  pkg.ID,
+		Name:                pkg.Name,
+		CreatedAt:           pkg.CreatedAt,
+		Interval:            pkg.Interval,
+		TimeFrom:            pkg.TimeFrom,
+		TimeTo:              pkg.TimeTo,
+		HistBins:            pkg.HistBins,
+		HistMin:             pkg.HistMin,
+		HistMax:             pkg.HistMax,
+		McapMin:             pkg.McapMin,
+		McapMax:             pkg.McapMax,
+		InceptionMax:        pkg.InceptionMax,
+		Filters:             pkg.Filters,
+		ReferenceIndex:      pkg.ReferenceIndex,
+		ReturnBasis:         pkg.ReturnBasis,
+		RiskFreeRate:        pkg.RiskFreeRate,
+		RollingWindowMonths: pkg.RollingWindow,
+		Outliers:            pkg.Outliers,
+		Status:              status,
+		UserID:              userID,
+	}); err != nil {
+		return err
+	}
+
+	for _, result := range pkg.Results {
+		if err := saveResult(ctx, userID, pkg.ID, result); err != nil {
+			return err
+		}
+	}
+
+	return db.UpdateAnalysisPackageStatus(ctx, userID, pkg.ID, status, len(pkg.Results))
+}
+
+// addMissingResults adds results for tickers the local package doesn't have yet
+func addMissingResults(ctx context.Context, userID uuid.UUID, pkg AnalysisPackage) (int, error) {
+	local, err := db.GetAnalysisResultsWithHistogram(ctx, pkg.ID)
+	if err != nil {
+		return 0, err
+	}
+	have := make(map[string]bool, len(local))
+	for _, r := range local {
+		have[r.Ticker] = true
+	}
+
+	added := 0
+	for _, result := range pkg.Results {
+		if have[result.Ticker] {
+			continue
+		}
+		if err := saveResult(ctx, userID, pkg.ID, result); err != nil {
+			return 0, err
+		}
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+
+	current, err := db.GetAnalysisPackage(ctx, userID, pkg.ID)
+	if err != nil || current == nil {
+		return added, err
+	}
+	return added, db.UpdateAnalysisPackageStatus(ctx, userID, pkg.ID, current.Status, len(local)+added)
+}
+
+func saveResult(ctx context.Context, userID uuid.UUID, packageID string, result AnalysisResult) error {
+	return db.SaveAnalysisResult(ctx, userID, types.AnalysisResult{
+		PackageID:       packageID,
+		Ticker:          result.Ticker,
+		Count:           result.Count,
+		Mean:            result.Mean,
+		StdDev:          result.StdDev,
+		Variance:        result.Variance,
+		Min:             result.Min,
+		Max:             result.Max,
+		Beta:            result.Beta,
+		Correlation:     result.Correlation,
+		Alpha:           result.Alpha,
+		Metrics:         result.Metrics,
+		Rolling:         result.Rolling,
+		OutliersRemoved: result.OutliersRemoved,
+	}, result.Histogram)
+}
+
+func importJournal(ctx context.Context, userID uuid.UUID, entries []types.JournalEntry, policy string, counts *ImportCounts) error {
+	existing, err := db.ListJournalEntries(ctx, userID, db.JournalFilter{})
+	if err != nil {
+		return err
+	}
+	byKey := make(map[string]types.JournalEntry, len(existing))
+	for _, e := range existing {
+		byKey[e.Title+"|"+timeKey(e.CreatedAt)] = e
+	}
+
+	for _, entry := range entries {
+		if entry.Type == "" || !types.IsValidJournalType(entry.Type) {
+			entry.Type = types.JournalTypeNote
+		}
+
+		local, exists := byKey[entry.Title+"|"+timeKey(entry.CreatedAt)]
+		if !exists {
+			if _, err := db.ImportJournalEntry(ctx, userID, entry); err != nil {
+				return err
+			}
+			counts.Added++
+			continue
+		}
+
+		var updated types.JournalEntry
+		switch policy {
+		case PolicyOverwrite:
+			updated = entry
+		case PolicyMerge:
+			updated = mergeJournalEntries(local, entry)
+		default:
+			counts.Skipped++
+			continue
+		}
+		updated.ID = local.ID
+		if _, err := db.UpdateJournalEntry(ctx, userID, updated); err != nil {
+			return err
+		}
+		counts.Updated++
+	}
+	return nil
+}
+
+// mergeJournalEntries combines a local entry with its backup version:
+// content and type are taken from whichever was updated last, tags and tickers are united.
+func mergeJournalEntries(local, backup types.JournalEntry) types.JournalEntry {
+	merged := local
+	if backup.UpdatedAt.After(local.UpdatedAt) {
+		merged.Content = backup.Content
+		merged.Type = backup.Type
+	}
+	merged.Tags = unionStrings(local.Tags, backup.Tags)
+	merged.Tickers = unionStrings(local.Tickers, backup.Tickers)
+	return merged
+}
+
+// unionStrings returns the sorted union of two string sets
+func unionStrings(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	result := []string{}
+	for _, s := range append(append([]string{}, a...), b...) {
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func importScoringProfiles(ctx context.Context, userID uuid.UUID, profiles []types.ScoringProfile, policy string, counts *ImportCounts) error {
+	existing, err := db.ListScoringProfiles(ctx, userID)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]types.ScoringProfile, len(existing))
+	for _, p := range existing {
+		byName[p.Name] = p
+	}
+
+	for _, profile := range profiles {
+		if profile.Normalization != types.NormalizationRank {
+			// Unknown normalizations fall back to the default
+			profile.Normalization = types.NormalizationZScore
+		}
+
+		local, exists := byName[profile.Name]
+		if !exists {
+			if _, err := db.CreateScoringProfile(ctx, userID, profile); err != nil {
+				return err
+			}
+			counts.Added++
+			continue
+		}
+
+		if policy == PolicySkip || (policy == PolicyMerge && !profile.UpdatedAt.After(local.UpdatedAt)) {
+			counts.Skipped++
+			continue
+		}
+		profile.ID = local.ID
+		if _, err := db.UpdateScoringProfile(ctx, userID, profile); err != nil {
+			return err
+		}
+		counts.Updated++
+	}
+	return nil
+}
+
+// importPortfolios creates missing portfolios. Skip keeps existing ones, overwrite replaces their cost basis
+// method and transactions, merge adds the missing transactions unless they would oversell a position.
+// Portfolio and transaction IDs are global, so imported ones always get fresh IDs.
+func importPortfolios(ctx context.Context, userID uuid.UUID, portfolios []Portfolio, policy string, counts *ImportCounts) error {
+	existing, err := db.ListPortfolios(ctx, userID)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]types.Portfolio, len(existing))
+	for _, p := range existing {
+		byName[p.Name] = p
+	}
+
+	for _, p := range portfolios {
+		if !types.IsValidCostBasis(p.CostBasis) {
+			p.CostBasis = types.CostBasisFIFO
+		}
+
+		local, exists := byName[p.Name]
+		if !exists {
+			imported := types.Portfolio{
+				ID:        uuid.New(),
+				UserID:    userID,
+				Name:      p.Name,
+				CostBasis: p.CostBasis,
+				CreatedAt: p.CreatedAt,
+			}
+			if err := db.ImportPortfolio(ctx, imported, importedTransactions(imported.ID, p.Transactions)); err != nil {
+				return err
+			}
+			counts.Added++
+			continue
+		}
+
+		switch policy {
+		case PolicyOverwrite:
+			local.CostBasis = p.CostBasis
+			if _, err := db.UpdatePortfolio(ctx, userID, local); err != nil {
+				return err
+			}
+			if err := db.ReplacePortfolioTransactions(ctx, local.ID, importedTransactions(local.ID, p.Transactions)); err != nil {
+				return err
+			}
+			counts.Updated++
+		case PolicyMerge:
+			added, err := addMissingTransactions(ctx, local, p.Transactions)
+			if err != nil {
+				return err
+			}
+			if added > 0 {
+				counts.Updated++
+			} else {
+				counts.Skipped++
+			}
+		default:
+			counts.Skipped++
+		}
+	}
+	return nil
+}
+
+// importedTransactions assigns the transactions of a backup to a portfolio with fresh IDs
+func importedTransactions(portfolioID uuid.UUID, transactions []types.Transaction) []types.Transaction {
+	imported := make([]types.Transaction, len(transactions))
+	for i, tx := range transactions {
+		tx.ID = uuid.New()
+		tx.PortfolioID = portfolioID
+		imported[i] = tx
+	}
+	return imported
+}
+
+func transactionKey(tx types.Transaction) string {
+	return tx.Ticker + "|" + tx.Type + "|" + tx.Date.Format("2006-01-02") + "|" + timeKey(tx.CreatedAt)
+}
+
+// addMissingTransactions adds the transactions the local portfolio doesn't have yet. Nothing is added
+// if they would oversell a position together with the local transactions.
+func addMissingTransactions(ctx context.Context, local types.Portfolio, transactions []types.Transaction) (int, error) {
+	existing, err := db.ListPortfolioTransactions(ctx, local.ID)
+	if err != nil {
+		return 0, err
+	}
+	have := make(map[string]bool, len(existing))
+	for _, tx := range existing {
+		have[transactionKey(tx)] = true
+	}
+
+	var missing []types.Transaction
+	for _, tx := range transactions {
+		if !have[transactionKey(tx)] {
+			missing = append(missing, tx)
+		}
+	}
+	if len(missing) == 0 {
+		return 0, nil
+	}
+
+	missing = importedTransactions(local.ID, missing)
+	err = db.AddPortfolioTransactions(ctx, local.ID, missing, func(existing []types.Transaction) error {
+		_, err := portfolio.Positions(append(existing, missing...), local.CostBasis)
+		return err
+	})
+	if errors.Is(err, portfolio.ErrOversold) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(missing), nil
+}
@@ -0,0 +1,256 @@
+package calculator
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+// WeekConvention selects the prior-year reference period of weekly prices
+type WeekConvention string
+
+const (
+	// WeeksTrading compares with the week 52 weeks (364 days) earlier: the same number of weeks back,
+	// drifting a day or two against the calendar each year
+	WeeksTrading WeekConvention = "trading"
+	// WeeksISO compares with the same ISO week number of the previous ISO year (week 53 with week 52)
+	WeeksISO WeekConvention = "iso"
+)
+
+// WeekConventionEnv selects the week convention of the price updater and `gofins reset yoy`
+const WeekConventionEnv = "GOFINS_YOY_WEEKS"
+
+// Maximum distance between the ideal and the chosen reference period. A missing or shifted
+// weekly reference falls back to the nearest week; months only match the same month.
+const (
+	WeeklyYoYTolerance  = 7 * 24 * time.Hour
+	MonthlyYoYTolerance = 15 * 24 * time.Hour
+)
+
+// ParseWeekConvention parses "trading" or "iso"
+func ParseWeekConvention(value string) (WeekConvention, error) {
+	switch convention := WeekConvention(value); convention {
+	case WeeksTrading, WeeksISO:
+		return convention, nil
+	default:
+		return "", fmt.Errorf("invalid week convention %q, must be %q or %q", value, WeeksTrading, WeeksISO)
+	}
+}
+
+// DefaultWeekConvention returns the convention set in GOFINS_YOY_WEEKS, trading weeks if unset or invalid
+func DefaultWeekConvention() WeekConvention {
+	if convention, err := ParseWeekConvention(os.Getenv(WeekConventionEnv)); err == nil {
+		return convention
+	}
+	return WeeksTrading
+}
+
+// Alignment matches the periods of a price series to their prior-year reference periods
+type Alignment struct {
+	Interval  types.PriceInterval
+	Weeks     WeekConvention // Weekly series only
+	Tolerance time.Duration
+}
+
+// NewAlignment returns the alignment of an interval with the default tolerance
+func NewAlignment(interval types.PriceInterval, weeks WeekConvention) Alignment {
+	tolerance := MonthlyYoYTolerance
+	if interval == types.IntervalWeekly {
+		tolerance = WeeklyYoYTolerance
+	}
+	return Alignment{Interval: interval, Weeks: weeks, Tolerance: tolerance}
+}
+
+// Target returns the ideal date of the prior-year reference period of a period
+func (a Alignment) Target(date time.Time) time.Time {
+	if a.Interval != types.IntervalWeekly {
+		return date.AddDate(-1, 0, 0)
+	}
+	if a.Weeks != WeeksISO {
+		return date.AddDate(0, 0, -364)
+	}
+
+	year, week := date.ISOWeek()
+	if week == 53 && isoWeeksInYear(year-1) < 53 {
+		week = 52
+	}
+	offset := (int(date.Weekday()) + 6) % 7 // Days since Monday, for labels that aren't Mondays
+	return isoWeekStart(year-1, week, date.Location()).AddDate(0, 0, offset)
+}
+
+// ReferenceFor returns the index of the reference period of date among earlier (oldest first),
+// -1 if none lies within the tolerance of the target. Ties go to the earlier period.
+func (a Alignment) ReferenceFor(date time.Time, earlier []time.Time) int {
+	target := a.Target(date)
+	next := sort.Search(len(earlier), func(k int) bool { return !earlier[k].Before(target) })
+
+	best := -1
+	var bestDistance time.Duration
+	for _, k := range []int{next - 1, next} {
+		if k < 0 || k >= len(earlier) || !earlier[k].Before(date) {
+			continue
+		}
+		distance := earlier[k].Sub(target)
+		if distance < 0 {
+			distance = -distance
+		}
+		if distance <= a.Tolerance && (best < 0 || distance < bestDistance) {
+			best, bestDistance = k, distance
+		}
+	}
+	return best
+}
+
+// References returns the index of the reference period of each period (oldest first), -1 if none
+func (a Alignment) References(dates []time.Time) []int {
+	refs := make([]int, len(dates))
+	for i, date := range dates {
+		refs[i] = a.ReferenceFor(date, dates[:i])
+	}
+	return refs
+}
+
+// isoWeekStart returns the Monday of an ISO week
+func isoWeekStart(year, week int, loc *time.Location) time.Time {
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc) // Always in week 1
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+	return monday.AddDate(0, 0, (week-1)*7)
+}
+
+// isoWeeksInYear returns 52 or 53
+func isoWeeksInYear(year int) int {
+	_, week := time.Date(year, time.December, 28, 0, 0, 0, 0, time.UTC).ISOWeek() // Always in the last week
+	return week
+}
+
+// percentChange returns the change from ref to value in percent, nil if ref isn't positive
+func percentChange(value, ref float64) *float64 {
+	if ref <= 0 {
+		return nil
+	}
+	change := (value - ref) / ref * 100
+	return &change
+}
+
+// periodDates returns the dates of a price series
+func periodDates(prices []types.PriceData) []time.Time {
+	dates := make([]time.Time, len(prices))
+	for i, p := range prices {
+		dates[i] = p.Date
+	}
+	return dates
+}
+
+// applyYoY sets YoY, YoYTR and YoYRef of periods (oldest first) whose Close and CloseTR are in
+// the original currency.
+func applyYoY(periods []types.PriceData, a Alignment) {
+	for i, ref := range a.References(periodDates(periods)) {
+		if ref < 0 {
+			continue
+		}
+		periods[i].YoY = percentChange(periods[i].Close, periods[ref].Close)
+		if periods[i].YoY == nil {
+			continue
+		}
+		refDate := periods[ref].Date
+		periods[i].YoYRef = &refDate
+		if periods[i].CloseTR != nil && periods[ref].CloseTR != nil {
+			periods[i].YoYTR = percentChange(*periods[i].CloseTR, *periods[ref].CloseTR)
+		}
+	}
+}
+
+// SetYoYFromStored sets YoY, YoYTR and YoYRef of a new period from stored earlier periods
+// (oldest first), comparing in the original currency like applyYoY
+func SetYoYFromStored(period *types.PriceData, stored []types.PriceData, a Alignment) {
+	ref := a.ReferenceFor(period.Date, periodDates(stored))
+	if ref < 0 {
+		return
+	}
+	yoy := percentChange(originalClose(*period), originalClose(stored[ref]))
+	if yoy == nil {
+		return
+	}
+	refDate := stored[ref].Date
+	period.YoY, period.YoYRef = yoy, &refDate
+	closeTR, refCloseTR := originalCloseTR(*period), originalCloseTR(stored[ref])
+	if closeTR != nil && refCloseTR != nil {
+		period.YoYTR = percentChange(*closeTR, *refCloseTR)
+	}
+}
+
+// CarryTotalReturn sets CloseTR of a new period from the total-return to close ratio of the
+// stored previous period, leaving it unknown if that period has none
+func CarryTotalReturn(period *types.PriceData, previous types.PriceData) {
+	if previous.CloseTR == nil || previous.Close <= 0 {
+		return
+	}
+	closeTR := period.Close * *previous.CloseTR / previous.Close
+	period.CloseTR = &closeTR
+}
+
+// originalClose returns the close of a stored period in the original currency
+func originalClose(p types.PriceData) float64 {
+	if p.CloseOrig != nil {
+		return *p.CloseOrig
+	}
+	return p.Close
+}
+
+// originalCloseTR returns the total-return index of a stored period in the original currency,
+// nil if unknown. It is stored USD converted at the rate of the close.
+func originalCloseTR(p types.PriceData) *float64 {
+	if p.CloseTR == nil || p.CloseOrig == nil || p.Close <= 0 {
+		return p.CloseTR
+	}
+	closeTR := *p.CloseTR * *p.CloseOrig / p.Close
+	return &closeTR
+}
+
+// RecomputeYoY recomputes YoY, YoYTR and YoYRef of a stored price series (oldest first) in place
+// and returns the number of changed periods. Closes and total-return indexes are compared in the
+// original currency like applyYoY; periods without a stored CloseTR keep their YoYTR unless they
+// lost their reference.
+func RecomputeYoY(prices []types.PriceData, a Alignment) int {
+	changed := 0
+	for i, ref := range a.References(periodDates(prices)) {
+		p := prices[i]
+		var yoy, yoyTR *float64
+		var yoyRef *time.Time
+		if ref >= 0 {
+			yoy = percentChange(originalClose(p), originalClose(prices[ref]))
+		}
+		if yoy != nil {
+			refDate := prices[ref].Date
+			yoyRef = &refDate
+			yoyTR = p.YoYTR
+			closeTR, refCloseTR := originalCloseTR(p), originalCloseTR(prices[ref])
+			if closeTR != nil && refCloseTR != nil {
+				yoyTR = percentChange(*closeTR, *refCloseTR)
+			}
+		}
+
+		if !equalFloat(p.YoY, yoy) || !equalFloat(p.YoYTR, yoyTR) || !equalTime(p.YoYRef, yoyRef) {
+			changed++
+		}
+		prices[i].YoY, prices[i].YoYTR, prices[i].YoYRef = yoy, yoyTR, yoyRef
+	}
+	return changed
+}
+
+func equalFloat(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,138 @@
+package calculator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/fmp"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlignmentTarget(t *testing.T) {
+	trading := NewAlignment(types.IntervalWeekly, WeeksTrading)
+	iso := NewAlignment(types.IntervalWeekly, WeeksISO)
+	monthly := NewAlignment(types.IntervalMonthly, WeeksTrading)
+
+	// 2021-W01 starts on 2021-01-04: 52 weeks back is 2020-W02, the same ISO week 2020-W01
+	assert.Equal(t, day("2020-01-06"), trading.Target(day("2021-01-04")))
+	assert.Equal(t, day("2019-12-30"), iso.Target(day("2021-01-04")))
+
+	// 2020-W53 has no counterpart in 2019 (52 weeks): compare with 2019-W52
+	assert.Equal(t, day("2019-12-23"), iso.Target(day("2020-12-28")))
+
+	assert.Equal(t, day("2023-03-01"), monthly.Target(day("2024-03-01")))
+}
+
+func TestAlignmentReferenceFor(t *testing.T) {
+	weekly := NewAlignment(types.IntervalWeekly, WeeksTrading)
+	stored := []time.Time{day("2023-02-27"), day("2023-03-13"), day("2023-03-20")}
+
+	// Exact week (2023-03-06) missing: falls back to the nearest week, ties go to the earlier one
+	assert.Equal(t, 0, weekly.ReferenceFor(day("2024-03-04"), stored))
+	// Shifted label (Sunday instead of Monday) still matches
+	assert.Equal(t, 0, weekly.ReferenceFor(day("2024-03-11"), []time.Time{day("2023-03-12")}))
+	// Nothing within the tolerance
+	assert.Equal(t, -1, weekly.ReferenceFor(day("2024-05-06"), stored))
+	// Never the period itself or a later one
+	assert.Equal(t, -1, weekly.ReferenceFor(day("2023-03-20"), stored))
+}
+
+func TestConvertPricesWeeklyYoYWithGap(t *testing.T) {
+	// Weekly closes for two years with the week of 2020-03-09 missing
+	var prices []fmp.PriceDataRaw
+	for date := day("2020-01-06"); date.Before(day("2021-12-31")); date = date.AddDate(0, 0, 7) {
+		if date.Equal(day("2020-03-09")) {
+			continue
+		}
+		prices = append(prices, bar(date.Format("2006-01-02"), 100+float64(date.YearDay())))
+	}
+
+	_, weekly := ConvertPrices(prices, nil, nil, "TEST", nil, WeeksTrading)
+	missing := 0
+	for _, p := range weekly {
+		if p.Date.Year() == 2021 && p.YoY == nil {
+			missing++
+		}
+	}
+	assert.Zero(t, missing, "every 2021 week has a YoY")
+
+	// The week after the gap compares with the nearest earlier week
+	for _, p := range weekly {
+		if p.Date.Equal(day("2021-03-08")) {
+			require.NotNil(t, p.YoYRef)
+			assert.Equal(t, day("2020-03-02"), *p.YoYRef)
+		}
+	}
+}
+
+func TestRecomputeYoY(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+	staleYoY := 99.0
+	prices := []types.PriceData{
+		{Date: day("2023-01-01"), Close: 50, CloseOrig: value(100), CloseTR: value(5)},
+		{Date: day("2023-02-01"), Close: 55, CloseOrig: value(110)},
+		{Date: day("2024-01-01"), Close: 60, CloseOrig: value(120), CloseTR: value(6.5), YoYTR: &staleYoY},
+		{Date: day("2024-02-01"), Close: 55, CloseOrig: value(110), YoY: &staleYoY, YoYTR: &staleYoY},
+	}
+
+	changed := RecomputeYoY(prices, NewAlignment(types.IntervalMonthly, WeeksTrading))
+	assert.Equal(t, 2, changed)
+
+	// Original currency closes and total-return indexes are compared
+	require.NotNil(t, prices[2].YoY)
+	assert.InDelta(t, 20, *prices[2].YoY, 1e-9)
+	assert.Equal(t, day("2023-01-01"), *prices[2].YoYRef)
+	assert.InDelta(t, 30, *prices[2].YoYTR, 1e-9)
+
+	// Without a total-return index the stored total-return YoY is kept
+	require.NotNil(t, prices[3].YoY)
+	assert.InDelta(t, 0, *prices[3].YoY, 1e-9)
+	assert.Equal(t, staleYoY, *prices[3].YoYTR)
+
+	assert.Nil(t, prices[0].YoY)
+	assert.Nil(t, prices[0].YoYRef)
+}
+
+func TestRecomputeYoYMatchesConvertPrices(t *testing.T) {
+	// Two years of daily closes with quarterly dividends
+	var prices []fmp.PriceDataRaw
+	var dividends []types.Dividend
+	for date := day("2022-01-03"); date.Before(day("2023-12-30")); date = date.AddDate(0, 0, 1) {
+		if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+			continue
+		}
+		prices = append(prices, bar(date.Format("2006-01-02"), 100+float64(date.YearDay()%90)))
+		if date.Day() == 15 && date.Month()%3 == 0 {
+			dividends = append(dividends, types.Dividend{Date: date, Dividend: 1, AdjDividend: 1})
+		}
+	}
+	monthly, weekly := ConvertPrices(prices, nil, dividends, "TEST", nil, WeeksTrading)
+
+	for _, series := range []struct {
+		prices   []types.PriceData
+		interval types.PriceInterval
+	}{{monthly, types.IntervalMonthly}, {weekly, types.IntervalWeekly}} {
+		// Stored like a foreign currency listing: USD converted at a changing rate
+		stored := make([]types.PriceData, len(series.prices))
+		for i, p := range series.prices {
+			rate := 1 + float64(i%5)/10
+			close, closeTR := p.Close*rate, *p.CloseTR*rate
+			stored[i] = types.PriceData{Date: p.Date, Close: close, CloseOrig: &series.prices[i].Close, CloseTR: &closeTR}
+		}
+
+		RecomputeYoY(stored, NewAlignment(series.interval, WeeksTrading))
+		for i, p := range series.prices {
+			if p.YoY == nil {
+				assert.Nil(t, stored[i].YoY, p.Date)
+				continue
+			}
+			require.NotNil(t, stored[i].YoY, p.Date)
+			require.NotNil(t, stored[i].YoYTR, p.Date)
+			assert.InDelta(t, *p.YoY, *stored[i].YoY, 1e-9, p.Date)
+			assert.InDelta(t, *p.YoYTR, *stored[i].YoYTR, 1e-9, p.Date)
+			assert.Equal(t, *p.YoYRef, *stored[i].YoYRef, p.Date)
+		}
+	}
+}
@@ -1,21 +1,30 @@
 package calculator
 
 import (
-	"fmt"
 	"time"
 
+	"github.com/flocko-motion/gofins/pkg/calendar"
 	"github.com/flocko-motion/gofins/pkg/fmp"
 	"github.com/flocko-motion/gofins/pkg/types"
 )
 
 // ConvertPrices converts daily prices to monthly and weekly aggregated data
-func ConvertPrices(dailyPrices []fmp.PriceDataRaw, ticker string) (monthly, weekly []types.PriceData) {
+// dailyPrices (the dividend-adjusted history) are the basis of the closes and the price YoY.
+// The total-return YoY reinvests the dividends (oldest first) in raw, the history as traded;
+// a nil raw uses dailyPrices. Pass nil dividends if the dividend history is unknown to leave
+// YoYTR and CloseTR empty, or an empty slice for non-payers.
+// Periods consist of the trading days of cal: bars on days the exchange was closed (stale
+// provider prints on weekends and holidays) are skipped. A nil cal keeps every bar.
+// Each period's YoY compares with its prior-year period; weeks are matched by the given convention.
+func ConvertPrices(dailyPrices, raw []fmp.PriceDataRaw, dividends []types.Dividend, ticker string, cal *calendar.Calendar, weeks WeekConvention) (monthly, weekly []types.PriceData) {
 	if len(dailyPrices) == 0 {
 		return nil, nil
 	}
+	if raw == nil {
+		raw = dailyPrices
+	}
 
-	monthlyYoY := make(map[string]float64) // Store monthly closes for YoY calculation
-	weeklyYoY := make(map[string]float64)  // Store weekly closes for YoY calculation
+	totalReturn := totalReturnByDate(raw, dividends)
 
 	var currentMonth, currentWeek time.Time
 	var monthData, weekData aggregator
@@ -25,48 +34,94 @@ func ConvertPrices(dailyPrices []fmp.PriceDataRaw, ticker string) (monthly, week
 		if err != nil {
 			continue
 		}
+		if cal != nil && !cal.IsTradingDay(date) {
+			continue
+		}
 
 		// Monthly aggregation
 		monthStart := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
 		if currentMonth.IsZero() || !monthStart.Equal(currentMonth) {
 			if !currentMonth.IsZero() {
-				monthly = append(monthly, monthData.toMonthly(currentMonth, ticker, monthlyYoY))
+				monthly = append(monthly, monthData.toPriceData(currentMonth, ticker))
 			}
 			currentMonth = monthStart
 			monthData = aggregator{}
 		}
+		tr, hasTR := totalReturn[daily.Date]
 		monthData.add(daily.Open, daily.High, daily.Low, daily.Close)
+		if hasTR {
+			monthData.setTotalReturn(tr)
+		}
 
 		// Weekly aggregation (Monday-Sunday)
 		weekStart := StartOfWeek(date)
 		if currentWeek.IsZero() || !weekStart.Equal(currentWeek) {
 			if !currentWeek.IsZero() {
-				weekly = append(weekly, weekData.toWeekly(currentWeek, ticker, weeklyYoY))
+				weekly = append(weekly, weekData.toPriceData(currentWeek, ticker))
 			}
 			currentWeek = weekStart
 			weekData = aggregator{}
 		}
 		weekData.add(daily.Open, daily.High, daily.Low, daily.Close)
+		if hasTR {
+			weekData.setTotalReturn(tr)
+		}
 	}
 
 	// Flush last periods
 	if !currentMonth.IsZero() {
-		monthly = append(monthly, monthData.toMonthly(currentMonth, ticker, monthlyYoY))
+		monthly = append(monthly, monthData.toPriceData(currentMonth, ticker))
 	}
 	if !currentWeek.IsZero() {
-		weekly = append(weekly, weekData.toWeekly(currentWeek, ticker, weeklyYoY))
+		weekly = append(weekly, weekData.toPriceData(currentWeek, ticker))
 	}
 
+	applyYoY(monthly, NewAlignment(types.IntervalMonthly, weeks))
+	applyYoY(weekly, NewAlignment(types.IntervalWeekly, weeks))
 	return monthly, weekly
 }
 
+// ConvertDailyPrices converts raw daily prices (oldest first) to daily bars
+// The adjusted close is back-adjusted for dividends, so it equals the close on the latest bar.
+// It is left nil if dividends is nil (dividend history unknown).
+func ConvertDailyPrices(dailyPrices []fmp.PriceDataRaw, dividends []types.Dividend, ticker string) []types.DailyPrice {
+	totalReturn := totalReturnIndex(dailyPrices, dividends)
+
+	daily := make([]types.DailyPrice, 0, len(dailyPrices))
+	for i, raw := range dailyPrices {
+		date, err := time.Parse("2006-01-02", raw.Date)
+		if err != nil {
+			continue
+		}
+
+		volume := raw.Volume
+		bar := types.DailyPrice{
+			Date:         date,
+			Open:         raw.Open,
+			High:         raw.High,
+			Low:          raw.Low,
+			Close:        raw.Close,
+			Volume:       &volume,
+			SymbolTicker: ticker,
+		}
+		last := len(totalReturn) - 1
+		if totalReturn != nil && totalReturn[last] > 0 {
+			adjClose := totalReturn[i] * dailyPrices[last].Close / totalReturn[last]
+			bar.AdjClose = &adjClose
+		}
+		daily = append(daily, bar)
+	}
+	return daily
+}
+
 type aggregator struct {
-	open     float64
-	high     float64
-	low      float64
-	closeSum float64
-	count    int
-	close    float64
+	open        float64
+	high        float64
+	low         float64
+	closeSum    float64
+	count       int
+	close       float64
+	totalReturn *float64 // Total-return index at the last close, nil if unknown
 }
 
 func (a *aggregator) add(open, high, low, close float64) {
@@ -87,57 +142,19 @@ func (a *aggregator) add(open, high, low, close float64) {
 	a.count++
 }
 
-func (a *aggregator) toMonthly(date time.Time, ticker string, yoyMap map[string]float64) types.PriceData {
-	avg := a.closeSum / float64(a.count)
-
-	// Calculate YoY
-	var yoy *float64
-	key := fmt.Sprintf("%s-%d-%02d", ticker, date.Year(), date.Month())
-	lastYearKey := fmt.Sprintf("%s-%d-%02d", ticker, date.Year()-1, date.Month())
-
-	if lastYearClose, exists := yoyMap[lastYearKey]; exists && lastYearClose > 0 {
-		yoyValue := ((a.close - lastYearClose) / lastYearClose) * 100
-		yoy = &yoyValue
-	}
-	yoyMap[key] = a.close
-
-	return types.PriceData{
-		Date:         date,
-		Open:         a.open,
-		High:         a.high,
-		Low:          a.low,
-		Avg:          avg,
-		Close:        a.close,
-		YoY:          yoy,
-		SymbolTicker: ticker,
-	}
+func (a *aggregator) setTotalReturn(value float64) {
+	a.totalReturn = &value
 }
 
-func (a *aggregator) toWeekly(date time.Time, ticker string, yoyMap map[string]float64) types.PriceData {
-	avg := a.closeSum / float64(a.count)
-
-	// Calculate YoY (52 weeks ago)
-	var yoy *float64
-	key := fmt.Sprintf("%s-%d-%02d-%02d", ticker, date.Year(), date.Month(), date.Day())
-
-	// Find the date 52 weeks ago
-	yearAgo := date.AddDate(0, 0, -364) // 52 weeks = 364 days
-	lastYearKey := fmt.Sprintf("%s-%d-%02d-%02d", ticker, yearAgo.Year(), yearAgo.Month(), yearAgo.Day())
-
-	if lastYearClose, exists := yoyMap[lastYearKey]; exists && lastYearClose > 0 {
-		yoyValue := ((a.close - lastYearClose) / lastYearClose) * 100
-		yoy = &yoyValue
-	}
-	yoyMap[key] = a.close
-
+func (a *aggregator) toPriceData(date time.Time, ticker string) types.PriceData {
 	return types.PriceData{
 		Date:         date,
 		Open:         a.open,
 		High:         a.high,
 		Low:          a.low,
-		Avg:          avg,
+		Avg:          a.closeSum / float64(a.count),
 		Close:        a.close,
-		YoY:          yoy,
+		CloseTR:      a.totalReturn,
 		SymbolTicker: ticker,
 	}
 }
@@ -164,16 +181,6 @@ func StartOfDay(date time.Time) time.Time {
 	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
 }
 
-// IsStartOfWeek returns true if the given date is a Monday (start of week)
-func IsStartOfWeek(date time.Time) bool {
-	return date.Weekday() == time.Monday
-}
-
-// IsStartOfMonth returns true if the given date is the 1st of the month
-func IsStartOfMonth(date time.Time) bool {
-	return date.Day() == 1
-}
-
 func Yesterday() time.Time {
 	return StartOfDay(time.Now().AddDate(0, 0, -1))
 }
@@ -0,0 +1,39 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/flocko-motion/gofins/pkg/calendar"
+	"github.com/flocko-motion/gofins/pkg/fmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertPricesTradingDays(t *testing.T) {
+	prices := []fmp.PriceDataRaw{
+		bar("2024-05-24", 100), // Friday
+		bar("2024-05-25", 90),  // Saturday: stale print
+		bar("2024-05-27", 90),  // Memorial Day: stale print
+		bar("2024-05-28", 102), // Tuesday, first session of the week
+		bar("2024-05-31", 104),
+		bar("2024-06-01", 80), // Saturday June 1st: must not open June
+		bar("2024-06-03", 105),
+	}
+
+	monthly, weekly := ConvertPrices(prices, nil, nil, "TEST", calendar.US(), WeeksTrading)
+	require.Len(t, monthly, 2)
+	assert.Equal(t, day("2024-05-01"), monthly[0].Date)
+	assert.InDelta(t, 104, monthly[0].Close, 1e-9)
+	assert.InDelta(t, 100, monthly[0].Low, 1e-9, "weekend and holiday prints are skipped")
+	assert.Equal(t, day("2024-06-01"), monthly[1].Date)
+	assert.InDelta(t, 105, monthly[1].Open, 1e-9)
+
+	require.Len(t, weekly, 3)
+	assert.Equal(t, day("2024-05-27"), weekly[1].Date, "weeks keep their Monday label")
+	assert.InDelta(t, 102, weekly[1].Open, 1e-9)
+	assert.InDelta(t, 102, weekly[1].Low, 1e-9)
+
+	// Without a calendar every bar counts
+	monthly, _ = ConvertPrices(prices, nil, nil, "TEST", nil, WeeksTrading)
+	assert.InDelta(t, 80, monthly[1].Open, 1e-9)
+}
@@ -0,0 +1,103 @@
+package calculator
+
+import (
+	"math"
+
+	"github.com/flocko-motion/gofins/pkg/fmp"
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+// AdjustSplits back-adjusts daily prices for splits the provider hasn't applied yet.
+// A split counts as unapplied if the close-to-close move across its date is closer to
+// the split ratio than to no move at all. Prices must be sorted by date (oldest first).
+// Returns the input unchanged if all splits are already reflected in the prices.
+func AdjustSplits(dailyPrices []fmp.PriceDataRaw, splits []types.Split) []fmp.PriceDataRaw {
+	adjusted := dailyPrices
+	copied := false
+
+	for _, split := range splits {
+		ratio := split.Ratio()
+		if ratio == 0 || ratio == 1 {
+			continue
+		}
+		splitDate := split.Date.Format("2006-01-02")
+
+		// First bar on or after the split date
+		k := 0
+		for k < len(adjusted) && adjusted[k].Date < splitDate {
+			k++
+		}
+		if k == 0 || k == len(adjusted) || adjusted[k].Close <= 0 || adjusted[k-1].Close <= 0 {
+			continue
+		}
+
+		jump := math.Log(adjusted[k-1].Close / adjusted[k].Close)
+		if math.Abs(jump-math.Log(ratio)) >= math.Abs(jump) {
+			continue // Already back-adjusted
+		}
+
+		if !copied {
+			adjusted = append([]fmp.PriceDataRaw(nil), dailyPrices...)
+			copied = true
+		}
+		for i := 0; i < k; i++ {
+			adjusted[i].Open /= ratio
+			adjusted[i].High /= ratio
+			adjusted[i].Low /= ratio
+			adjusted[i].Close /= ratio
+			adjusted[i].Volume = int64(float64(adjusted[i].Volume) * ratio)
+		}
+	}
+
+	return adjusted
+}
+
+// totalReturnIndex returns the value of a position with dividends reinvested on the
+// ex-date, starting at the first close. Dividends are matched to the first bar on or
+// after their ex-date. Prices and dividends must be sorted by date (oldest first).
+// Returns nil if dividends is nil (dividend history unknown).
+func totalReturnIndex(dailyPrices []fmp.PriceDataRaw, dividends []types.Dividend) []float64 {
+	if dividends == nil || len(dailyPrices) == 0 {
+		return nil
+	}
+
+	index := make([]float64, len(dailyPrices))
+	index[0] = dailyPrices[0].Close
+
+	d := 0
+	firstDate := dailyPrices[0].Date
+	for d < len(dividends) && dividends[d].Date.Format("2006-01-02") <= firstDate {
+		d++ // Paid before the first bar
+	}
+
+	for i := 1; i < len(dailyPrices); i++ {
+		var dividend float64
+		for d < len(dividends) && dividends[d].Date.Format("2006-01-02") <= dailyPrices[i].Date {
+			dividend += dividends[d].AdjDividend
+			d++
+		}
+
+		prevClose := dailyPrices[i-1].Close
+		if prevClose <= 0 {
+			index[i] = index[i-1]
+			continue
+		}
+		index[i] = index[i-1] * (dailyPrices[i].Close + dividend) / prevClose
+	}
+
+	return index
+}
+
+// totalReturnByDate returns the total-return index of dailyPrices keyed by date (YYYY-MM-DD),
+// nil if dividends is nil
+func totalReturnByDate(dailyPrices []fmp.PriceDataRaw, dividends []types.Dividend) map[string]float64 {
+	index := totalReturnIndex(dailyPrices, dividends)
+	if index == nil {
+		return nil
+	}
+	byDate := make(map[string]float64, len(index))
+	for i, value := range index {
+		byDate[dailyPrices[i].Date] = value
+	}
+	return byDate
+}
@@ -0,0 +1,100 @@
+package calculator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/fmp"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bar(date string, close float64) fmp.PriceDataRaw {
+	return fmp.PriceDataRaw{Date: date, Open: close, High: close, Low: close, Close: close, Volume: 100}
+}
+
+func day(date string) time.Time {
+	t, _ := time.Parse("2006-01-02", date)
+	return t
+}
+
+func TestAdjustSplitsUnapplied(t *testing.T) {
+	prices := []fmp.PriceDataRaw{bar("2020-08-27", 400), bar("2020-08-28", 404), bar("2020-08-31", 102)}
+	splits := []types.Split{{Date: day("2020-08-31"), Numerator: 4, Denominator: 1}}
+
+	adjusted := AdjustSplits(prices, splits)
+	assert.InDelta(t, 100, adjusted[0].Close, 1e-9)
+	assert.InDelta(t, 101, adjusted[1].Close, 1e-9)
+	assert.InDelta(t, 102, adjusted[2].Close, 1e-9)
+	assert.Equal(t, int64(400), adjusted[0].Volume)
+	assert.InDelta(t, 400, prices[0].Close, 1e-9, "input is not modified")
+}
+
+func TestAdjustSplitsAlreadyApplied(t *testing.T) {
+	prices := []fmp.PriceDataRaw{bar("2020-08-27", 100), bar("2020-08-28", 101), bar("2020-08-31", 102)}
+	splits := []types.Split{{Date: day("2020-08-31"), Numerator: 4, Denominator: 1}}
+
+	adjusted := AdjustSplits(prices, splits)
+	assert.InDelta(t, 100, adjusted[0].Close, 1e-9)
+	assert.InDelta(t, 101, adjusted[1].Close, 1e-9)
+}
+
+func TestConvertPricesTotalReturn(t *testing.T) {
+	// Flat price of 100 for two years plus one dividend of 5 per year
+	var prices []fmp.PriceDataRaw
+	for m := 1; m <= 24; m++ {
+		date := time.Date(2020, time.Month(m), 1, 0, 0, 0, 0, time.UTC)
+		prices = append(prices, bar(date.Format("2006-01-02"), 100))
+	}
+	dividends := []types.Dividend{
+		{Date: day("2020-06-01"), Dividend: 5, AdjDividend: 5},
+		{Date: day("2021-06-01"), Dividend: 5, AdjDividend: 5},
+	}
+
+	monthly, _ := ConvertPrices(prices, nil, dividends, "TEST", nil, WeeksTrading)
+	require.Len(t, monthly, 24)
+
+	last := monthly[23] // 2021-12 vs. 2020-12
+	require.NotNil(t, last.YoY)
+	require.NotNil(t, last.YoYTR)
+	assert.InDelta(t, 0, *last.YoY, 1e-9)
+	assert.InDelta(t, 5, *last.YoYTR, 1e-9)
+	require.NotNil(t, last.CloseTR)
+	assert.InDelta(t, 110.25, *last.CloseTR, 1e-9, "the index starts at the first close")
+
+	// Without a known dividend history the total-return YoY stays empty
+	monthly, _ = ConvertPrices(prices, nil, nil, "TEST", nil, WeeksTrading)
+	assert.Nil(t, monthly[23].YoYTR)
+	assert.Nil(t, monthly[23].CloseTR)
+
+	// Non-payers have identical price and total-return YoY
+	monthly, _ = ConvertPrices(prices, nil, []types.Dividend{}, "TEST", nil, WeeksTrading)
+	require.NotNil(t, monthly[23].YoYTR)
+	assert.InDelta(t, *monthly[23].YoY, *monthly[23].YoYTR, 1e-9)
+
+	// Closes and price YoY follow the adjusted history, the total-return YoY the raw one
+	adjusted := make([]fmp.PriceDataRaw, len(prices))
+	for i, p := range prices {
+		adjusted[i] = bar(p.Date, 90+float64(i)/2)
+	}
+	monthly, _ = ConvertPrices(adjusted, prices, dividends, "TEST", nil, WeeksTrading)
+	assert.InDelta(t, adjusted[23].Close, monthly[23].Close, 1e-9)
+	assert.InDelta(t, (101.5/95.5-1)*100, *monthly[23].YoY, 1e-9)
+	assert.InDelta(t, 5, *monthly[23].YoYTR, 1e-9)
+}
+
+func TestConvertDailyPricesAdjClose(t *testing.T) {
+	prices := []fmp.PriceDataRaw{bar("2024-01-02", 100), bar("2024-01-03", 95), bar("2024-01-04", 96)}
+	dividends := []types.Dividend{{Date: day("2024-01-03"), Dividend: 5, AdjDividend: 5}}
+
+	daily := ConvertDailyPrices(prices, dividends, "TEST")
+	require.Len(t, daily, 3)
+	require.NotNil(t, daily[0].AdjClose)
+	assert.InDelta(t, 95, *daily[0].AdjClose, 1e-9, "back-adjusted by the dividend")
+	assert.InDelta(t, 96, *daily[2].AdjClose, 1e-9, "latest bar is unadjusted")
+	assert.Equal(t, int64(100), *daily[0].Volume)
+
+	daily = ConvertDailyPrices(prices, nil, "TEST")
+	assert.Nil(t, daily[0].AdjClose)
+}
@@ -0,0 +1,288 @@
+// Package calendar provides exchange trading calendars: regular session hours, holidays and
+// early closes of the major exchanges, loaded from the bundled data files in data/.
+// Outside the years covered by a data file only weekends are treated as closed.
+package calendar
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"strings"
+	"time"
+	_ "time/tzdata" // Calendars must load without zoneinfo on the host
+)
+
+//go:embed data/*.json
+var dataFiles embed.FS
+
+const dateFormat = "2006-01-02"
+
+// Calendar is the trading calendar of an exchange (or of exchanges sharing one).
+// Dates passed to its methods are calendar days: only year, month and day are used.
+// Returned dates are midnight UTC, like the price dates stored in the database.
+type Calendar struct {
+	Code        string         // MIC of the main exchange, e.g. XNYS
+	Name        string         // Human readable name
+	Location    *time.Location // Time zone of the session hours
+	open        clock
+	close       clock
+	fromYear    int // First year with holiday data
+	toYear      int // Last year with holiday data
+	holidays    map[string]string
+	earlyCloses map[string]clock
+}
+
+// clock is a time of day in the calendar's time zone
+type clock struct {
+	hour, minute int
+}
+
+// calendarFile is the format of the bundled data files
+type calendarFile struct {
+	Code        string            `json:"code"`
+	Name        string            `json:"name"`
+	Exchanges   []string          `json:"exchanges"` // Exchange names as reported by FMP
+	Timezone    string            `json:"timezone"`
+	Open        string            `json:"open"`        // HH:MM
+	Close       string            `json:"close"`       // HH:MM
+	Years       [2]int            `json:"years"`       // First and last year with holiday data
+	Holidays    map[string]string `json:"holidays"`    // YYYY-MM-DD -> name
+	EarlyCloses map[string]string `json:"earlyCloses"` // YYYY-MM-DD -> HH:MM
+}
+
+var (
+	byCode     map[string]*Calendar
+	byExchange map[string]*Calendar
+)
+
+func init() {
+	var err error
+	byCode, byExchange, err = load(dataFiles)
+	if err != nil {
+		panic(fmt.Sprintf("calendar: invalid bundled data: %v", err))
+	}
+}
+
+// Get returns the calendar with the given code (e.g. XNYS), nil if unknown
+func Get(code string) *Calendar {
+	return byCode[strings.ToUpper(strings.TrimSpace(code))]
+}
+
+// ForExchange returns the calendar of an exchange by code or name as reported by FMP
+// (e.g. NASDAQ, LSE, XETRA), nil if no calendar is known for it
+func ForExchange(exchange string) *Calendar {
+	return byExchange[strings.ToUpper(strings.TrimSpace(exchange))]
+}
+
+// US returns the calendar of the US exchanges (NYSE and Nasdaq)
+func US() *Calendar {
+	return byCode["XNYS"]
+}
+
+func load(fsys fs.FS) (map[string]*Calendar, map[string]*Calendar, error) {
+	files, err := fs.Glob(fsys, "data/*.json")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	codes := make(map[string]*Calendar)
+	exchanges := make(map[string]*Calendar)
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, nil, err
+		}
+		var raw calendarFile
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", file, err)
+		}
+		cal, err := parseCalendar(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		if _, exists := codes[cal.Code]; exists {
+			return nil, nil, fmt.Errorf("%s: duplicate calendar %s", file, cal.Code)
+		}
+		codes[cal.Code] = cal
+		for _, name := range append([]string{cal.Code}, raw.Exchanges...) {
+			name = strings.ToUpper(name)
+			if other, exists := exchanges[name]; exists {
+				return nil, nil, fmt.Errorf("%s: exchange %s already belongs to %s", file, name, other.Code)
+			}
+			exchanges[name] = cal
+		}
+	}
+	return codes, exchanges, nil
+}
+
+func parseCalendar(raw calendarFile) (*Calendar, error) {
+	location, err := time.LoadLocation(raw.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	open, err := parseClock(raw.Open)
+	if err != nil {
+		return nil, err
+	}
+	closing, err := parseClock(raw.Close)
+	if err != nil {
+		return nil, err
+	}
+	if !open.before(closing) {
+		return nil, fmt.Errorf("session opens at %s after it closes at %s", raw.Open, raw.Close)
+	}
+	if raw.Years[0] > raw.Years[1] {
+		return nil, fmt.Errorf("invalid years %v", raw.Years)
+	}
+
+	cal := &Calendar{
+		Code:        strings.ToUpper(raw.Code),
+		Name:        raw.Name,
+		Location:    location,
+		open:        open,
+		close:       closing,
+		fromYear:    raw.Years[0],
+		toYear:      raw.Years[1],
+		holidays:    make(map[string]string),
+		earlyCloses: make(map[string]clock),
+	}
+	for day, name := range raw.Holidays {
+		if err := cal.checkDay(day); err != nil {
+			return nil, fmt.Errorf("holiday %w", err)
+		}
+		cal.holidays[day] = name
+	}
+	for day, value := range raw.EarlyCloses {
+		if err := cal.checkDay(day); err != nil {
+			return nil, fmt.Errorf("early close %w", err)
+		}
+		if _, isHoliday := cal.holidays[day]; isHoliday {
+			return nil, fmt.Errorf("early close %s is a holiday", day)
+		}
+		earlyClose, err := parseClock(value)
+		if err != nil {
+			return nil, err
+		}
+		if !open.before(earlyClose) || !earlyClose.before(closing) {
+			return nil, fmt.Errorf("early close %s at %s is outside the session", day, value)
+		}
+		cal.earlyCloses[day] = earlyClose
+	}
+	return cal, nil
+}
+
+// checkDay validates a holiday or early close date of the data file
+func (c *Calendar) checkDay(day string) error {
+	date, err := time.Parse(dateFormat, day)
+	if err != nil {
+		return fmt.Errorf("%s: %w", day, err)
+	}
+	if isWeekend(date) {
+		return fmt.Errorf("%s is on a weekend", day)
+	}
+	if date.Year() < c.fromYear || date.Year() > c.toYear {
+		return fmt.Errorf("%s is outside the covered years %d-%d", day, c.fromYear, c.toYear)
+	}
+	return nil
+}
+
+func parseClock(value string) (clock, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return clock{}, fmt.Errorf("invalid time %q", value)
+	}
+	return clock{hour: t.Hour(), minute: t.Minute()}, nil
+}
+
+func (c clock) before(other clock) bool {
+	return c.hour*60+c.minute < other.hour*60+other.minute
+}
+
+func isWeekend(date time.Time) bool {
+	return date.Weekday() == time.Saturday || date.Weekday() == time.Sunday
+}
+
+// dateOf returns the calendar day of t as midnight UTC
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Holiday returns the name of the holiday on date, if the exchange is closed for one
+func (c *Calendar) Holiday(date time.Time) (string, bool) {
+	name, exists := c.holidays[date.Format(dateFormat)]
+	return name, exists
+}
+
+// IsTradingDay reports whether the exchange holds a session on date
+func (c *Calendar) IsTradingDay(date time.Time) bool {
+	if isWeekend(date) {
+		return false
+	}
+	_, isHoliday := c.holidays[date.Format(dateFormat)]
+	return !isHoliday
+}
+
+// IsEarlyClose reports whether the session on date closes early
+func (c *Calendar) IsEarlyClose(date time.Time) bool {
+	_, exists := c.earlyCloses[date.Format(dateFormat)]
+	return exists
+}
+
+// SessionClose returns the closing time of the session on date, zero if it isn't a trading day
+func (c *Calendar) SessionClose(date time.Time) time.Time {
+	if !c.IsTradingDay(date) {
+		return time.Time{}
+	}
+	closing := c.close
+	if earlyClose, exists := c.earlyCloses[date.Format(dateFormat)]; exists {
+		closing = earlyClose
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), closing.hour, closing.minute, 0, 0, c.Location)
+}
+
+// PreviousTradingDay returns the last trading day before date
+func (c *Calendar) PreviousTradingDay(date time.Time) time.Time {
+	day := dateOf(date).AddDate(0, 0, -1)
+	for !c.IsTradingDay(day) {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day
+}
+
+// NextTradingDay returns the first trading day after date
+func (c *Calendar) NextTradingDay(date time.Time) time.Time {
+	day := dateOf(date).AddDate(0, 0, 1)
+	for !c.IsTradingDay(day) {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+// LastSession returns the date of the latest session that has closed at now
+func (c *Calendar) LastSession(now time.Time) time.Time {
+	local := now.In(c.Location)
+	if c.IsTradingDay(local) && !now.Before(c.SessionClose(local)) {
+		return dateOf(local)
+	}
+	return c.PreviousTradingDay(local)
+}
+
+// IsFirstSessionOfWeek reports whether date is the first trading day of its Monday-Sunday week
+func (c *Calendar) IsFirstSessionOfWeek(date time.Time) bool {
+	if !c.IsTradingDay(date) {
+		return false
+	}
+	weekday := (int(date.Weekday()) + 6) % 7 // Monday = 0
+	monday := dateOf(date).AddDate(0, 0, -weekday)
+	return c.PreviousTradingDay(date).Before(monday)
+}
+
+// IsFirstSessionOfMonth reports whether date is the first trading day of its month
+func (c *Calendar) IsFirstSessionOfMonth(date time.Time) bool {
+	if !c.IsTradingDay(date) {
+		return false
+	}
+	return c.PreviousTradingDay(date).Month() != date.Month()
+}
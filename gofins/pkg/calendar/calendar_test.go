@@ -0,0 +1,144 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func day(value string) time.Time {
+	date, err := time.Parse(dateFormat, value)
+	if err != nil {
+		panic(err)
+	}
+	return date
+}
+
+func TestForExchange(t *testing.T) {
+	tests := map[string]string{
+		"NASDAQ":   "XNYS",
+		"nyse":     "XNYS",
+		"XNYS":     "XNYS",
+		"LSE":      "XLON",
+		"XETRA":    "XETR",
+		"EURONEXT": "XPAR",
+		"TSX":      "XTSE",
+	}
+	for exchange, want := range tests {
+		cal := ForExchange(exchange)
+		if cal == nil || cal.Code != want {
+			t.Errorf("%s: got %v, want %s", exchange, cal, want)
+		}
+	}
+	if cal := ForExchange("CRYPTO"); cal != nil {
+		t.Errorf("CRYPTO: got %s, want nil", cal.Code)
+	}
+}
+
+func TestIsTradingDay(t *testing.T) {
+	us := US()
+	tests := []struct {
+		date string
+		want bool
+	}{
+		{"2024-07-03", true},  // Early close
+		{"2024-07-04", false}, // Independence Day
+		{"2024-07-06", false}, // Saturday
+		{"2024-03-29", false}, // Good Friday
+		{"2019-07-04", true},  // Before the holiday data: only weekends are closed
+		{"2019-07-05", true},
+	}
+	for _, tt := range tests {
+		if got := us.IsTradingDay(day(tt.date)); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.date, got, tt.want)
+		}
+	}
+	if name, ok := us.Holiday(day("2024-11-28")); !ok || name != "Thanksgiving Day" {
+		t.Errorf("got %q, %v, want Thanksgiving Day", name, ok)
+	}
+
+	// Holidays differ per exchange
+	if !Get("XLON").IsTradingDay(day("2024-07-04")) {
+		t.Error("LSE is open on US Independence Day")
+	}
+	if Get("XLON").IsTradingDay(day("2024-04-01")) {
+		t.Error("LSE is closed on Easter Monday")
+	}
+}
+
+func TestSessionClose(t *testing.T) {
+	us := US()
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	if got, want := us.SessionClose(day("2024-07-02")), time.Date(2024, 7, 2, 16, 0, 0, 0, newYork); !got.Equal(want) {
+		t.Errorf("regular close: got %s, want %s", got, want)
+	}
+	if got, want := us.SessionClose(day("2024-07-03")), time.Date(2024, 7, 3, 13, 0, 0, 0, newYork); !got.Equal(want) {
+		t.Errorf("early close: got %s, want %s", got, want)
+	}
+	if got := us.SessionClose(day("2024-07-04")); !got.IsZero() {
+		t.Errorf("holiday: got %s, want zero", got)
+	}
+}
+
+func TestLastSession(t *testing.T) {
+	us := US()
+	tests := []struct {
+		now  time.Time
+		want string
+	}{
+		// Tuesday 06:00 New York: Monday's session
+		{time.Date(2024, 3, 5, 11, 0, 0, 0, time.UTC), "2024-03-04"},
+		// Tuesday after the close (16:00 EST = 21:00 UTC)
+		{time.Date(2024, 3, 5, 21, 0, 0, 0, time.UTC), "2024-03-05"},
+		// Saturday and Sunday: Friday's session
+		{time.Date(2024, 3, 9, 11, 0, 0, 0, time.UTC), "2024-03-08"},
+		{time.Date(2024, 3, 10, 23, 0, 0, 0, time.UTC), "2024-03-08"},
+		// Tuesday after Memorial Day: Friday's session
+		{time.Date(2024, 5, 28, 10, 0, 0, 0, time.UTC), "2024-05-24"},
+		// Early close: the session has closed at 14:00 New York
+		{time.Date(2024, 7, 3, 18, 0, 0, 0, time.UTC), "2024-07-03"},
+		// Saturday after Good Friday: Thursday's session
+		{time.Date(2024, 3, 30, 12, 0, 0, 0, time.UTC), "2024-03-28"},
+	}
+	for _, tt := range tests {
+		if got := us.LastSession(tt.now); !got.Equal(day(tt.want)) {
+			t.Errorf("%s: got %s, want %s", tt.now, got.Format(dateFormat), tt.want)
+		}
+	}
+}
+
+func TestFirstSession(t *testing.T) {
+	us := US()
+	tests := []struct {
+		date        string
+		firstOfWeek bool
+		firstOfMon  bool
+	}{
+		{"2024-03-04", true, false},  // Monday
+		{"2024-03-05", false, false}, // Tuesday
+		{"2024-05-28", true, false},  // Tuesday after Memorial Day
+		{"2024-01-02", true, true},   // Tuesday after New Year's Day
+		{"2024-01-01", false, false}, // Holiday
+		{"2024-06-03", true, true},   // Monday the 3rd, June 1 is a Saturday
+		{"2024-01-16", true, false},  // Tuesday after Martin Luther King Jr. Day
+	}
+	for _, tt := range tests {
+		date := day(tt.date)
+		if got := us.IsFirstSessionOfWeek(date); got != tt.firstOfWeek {
+			t.Errorf("%s first of week: got %v, want %v", tt.date, got, tt.firstOfWeek)
+		}
+		if got := us.IsFirstSessionOfMonth(date); got != tt.firstOfMon {
+			t.Errorf("%s first of month: got %v, want %v", tt.date, got, tt.firstOfMon)
+		}
+	}
+}
+
+func TestTradingDayNavigation(t *testing.T) {
+	us := US()
+	if got := us.PreviousTradingDay(day("2024-12-26")); !got.Equal(day("2024-12-24")) {
+		t.Errorf("previous: got %s, want 2024-12-24", got.Format(dateFormat))
+	}
+	if got := us.NextTradingDay(day("2024-12-24")); !got.Equal(day("2024-12-26")) {
+		t.Errorf("next: got %s, want 2024-12-26", got.Format(dateFormat))
+	}
+}
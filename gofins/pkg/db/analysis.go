@@ -3,11 +3,14 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 
 	"github.com/flocko-motion/gofins/pkg/db/generated"
 	"github.com/flocko-motion/gofins/pkg/f"
 	"github.com/flocko-motion/gofins/pkg/types"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sqlc-dev/pqtype"
 )
 
 // CreateAnalysisPackage inserts a new analysis package with status='processing'
@@ -17,23 +20,115 @@ func CreateAnalysisPackage(ctx context.Context, pkg *types.AnalysisPackage) erro
 		return err
 	}
 
+	filters, err := json.Marshal(pkg.Filters)
+	if err != nil {
+		return err
+	}
+	outliers, err := json.Marshal(pkg.Outliers)
+	if err != nil {
+		return err
+	}
+
 	return genQ().CreateAnalysisPackage(ctx, generated.CreateAnalysisPackageParams{
-		ID:           pkgUUID,
-		Name:         pkg.Name,
-		CreatedAt:    pkg.CreatedAt,
-		Interval:     pkg.Interval,
-		TimeFrom:     pkg.TimeFrom,
-		TimeTo:       pkg.TimeTo,
-		HistBins:     int32(pkg.HistBins),
-		HistMin:      pkg.HistMin,
-		HistMax:      pkg.HistMax,
-		McapMin:      f.MaybeInt64ToNullInt64(pkg.McapMin),
-		InceptionMax: f.MaybeTimeToNullTime(pkg.InceptionMax),
-		Status:       pkg.Status,
-		UserID:       pkg.UserID,
+		ID:                  pkgUUID,
+		Name:                pkg.Name,
+		CreatedAt:           pkg.CreatedAt,
+		Interval:            pkg.Interval,
+		TimeFrom:            pkg.TimeFrom,
+		TimeTo:              pkg.TimeTo,
+		HistBins:            int32(pkg.HistBins),
+		HistMin:             pkg.HistMin,
+		HistMax:             pkg.HistMax,
+		McapMin:             f.MaybeInt64ToNullInt64(pkg.McapMin),
+		InceptionMax:        f.MaybeTimeToNullTime(pkg.InceptionMax),
+		Status:              pkg.Status,
+		UserID:              pkg.UserID,
+		ReferenceIndex:      f.MaybeStringToNullString(pkg.ReferenceIndex),
+		ReturnBasis:         returnBasisOrDefault(pkg.ReturnBasis),
+		RiskFreeRate:        pkg.RiskFreeRate,
+		McapMax:             f.MaybeInt64ToNullInt64(pkg.McapMax),
+		Filters:             filters,
+		RollingWindowMonths: int32(pkg.RollingWindowMonths),
+		Outliers:            outliers,
 	})
 }
 
+// returnBasisOrDefault maps an empty return basis (packages created before it existed) to price-only
+func returnBasisOrDefault(basis string) string {
+	if basis == "" {
+		return string(types.ReturnBasisPrice)
+	}
+	return basis
+}
+
+// unmarshalOutliers decodes the outliers column; packages without a strategy use the balanced default
+func unmarshalOutliers(raw json.RawMessage) types.OutlierConfig {
+	var outliers types.OutlierConfig
+	_ = json.Unmarshal(raw, &outliers)
+	if outliers.Strategy == "" {
+		outliers.Strategy = types.OutlierBalanced
+	}
+	return outliers
+}
+
+// unmarshalFilters decodes the filters column; packages without filters use the full universe
+func unmarshalFilters(raw json.RawMessage) types.AnalysisFilters {
+	var filters types.AnalysisFilters
+	_ = json.Unmarshal(raw, &filters)
+	if filters.Universe == "" {
+		filters.Universe = types.UniverseAll
+	}
+	return filters
+}
+
+// marshalMetrics encodes performance metrics for the jsonb column (NULL if nil)
+func marshalMetrics(metrics *types.PerformanceMetrics) (pqtype.NullRawMessage, error) {
+	if metrics == nil {
+		return pqtype.NullRawMessage{}, nil
+	}
+	raw, err := json.Marshal(metrics)
+	if err != nil {
+		return pqtype.NullRawMessage{}, err
+	}
+	return pqtype.NullRawMessage{RawMessage: raw, Valid: true}, nil
+}
+
+// unmarshalMetrics decodes the metrics column, nil for results stored before metrics existed
+func unmarshalMetrics(raw pqtype.NullRawMessage) *types.PerformanceMetrics {
+	if !raw.Valid {
+		return nil
+	}
+	var metrics types.PerformanceMetrics
+	if err := json.Unmarshal(raw.RawMessage, &metrics); err != nil {
+		return nil
+	}
+	return &metrics
+}
+
+// marshalRolling encodes a rolling YoY summary for the jsonb column (NULL if nil)
+func marshalRolling(rolling *types.RollingSummary) (pqtype.NullRawMessage, error) {
+	if rolling == nil {
+		return pqtype.NullRawMessage{}, nil
+	}
+	raw, err := json.Marshal(rolling)
+	if err != nil {
+		return pqtype.NullRawMessage{}, err
+	}
+	return pqtype.NullRawMessage{RawMessage: raw, Valid: true}, nil
+}
+
+// unmarshalRolling decodes the rolling column, nil if the package computed no rolling statistics
+func unmarshalRolling(raw pqtype.NullRawMessage) *types.RollingSummary {
+	if !raw.Valid {
+		return nil
+	}
+	var rolling types.RollingSummary
+	if err := json.Unmarshal(raw.RawMessage, &rolling); err != nil {
+		return nil
+	}
+	return &rolling
+}
+
 // UpdateAnalysisPackageStatus updates the status and symbol count of a package for a specific user
 func UpdateAnalysisPackageStatus(ctx context.Context, userID uuid.UUID, packageID string, status string, symbolCount int) error {
 	pkgUUID, err := uuid.Parse(packageID)
@@ -49,8 +144,8 @@ func UpdateAnalysisPackageStatus(ctx context.Context, userID uuid.UUID, packageI
 }
 
 // SaveAnalysisResult saves a single analysis result (verifies package ownership)
-func SaveAnalysisResult(ctx context.Context, userID uuid.UUID, packageID, ticker string, count int, mean, stddev, variance, min, max float64, histogramJSON []byte) error {
-	pkgUUID, err := uuid.Parse(packageID)
+func SaveAnalysisResult(ctx context.Context, userID uuid.UUID, result types.AnalysisResult, histogramJSON []byte) error {
+	pkgUUID, err := uuid.Parse(result.PackageID)
 	if err != nil {
 		return err
 	}
@@ -67,17 +162,114 @@ func SaveAnalysisResult(ctx context.Context, userID uuid.UUID, packageID, ticker
 		return sql.ErrNoRows
 	}
 
+	metrics, err := marshalMetrics(result.Metrics)
+	if err != nil {
+		return err
+	}
+	rolling, err := marshalRolling(result.Rolling)
+	if err != nil {
+		return err
+	}
+
 	return genQ().SaveAnalysisResult(ctx, generated.SaveAnalysisResultParams{
-		PackageID: pkgUUID,
-		Ticker:    ticker,
-		Count:     int32(count),
-		Mean:      mean,
-		Stddev:    stddev,
-		Variance:  variance,
-		Min:       min,
-		Max:       max,
-		Histogram: histogramJSON,
+		PackageID:       pkgUUID,
+		Ticker:          result.Ticker,
+		Count:           int32(result.Count),
+		Mean:            result.Mean,
+		Stddev:          result.StdDev,
+		Variance:        result.Variance,
+		Min:             result.Min,
+		Max:             result.Max,
+		Histogram:       histogramJSON,
+		Beta:            f.MaybeFloat64ToNullFloat64(result.Beta),
+		Correlation:     f.MaybeFloat64ToNullFloat64(result.Correlation),
+		Alpha:           f.MaybeFloat64ToNullFloat64(result.Alpha),
+		Metrics:         metrics,
+		Rolling:         rolling,
+		OutliersRemoved: int32(result.OutliersRemoved),
+	})
+}
+
+// SaveAnalysisResults inserts a batch of results of one package with COPY in a single
+// transaction (verifies package ownership). Histograms are taken from result.Histogram.
+func SaveAnalysisResults(ctx context.Context, userID uuid.UUID, packageID string, results []types.AnalysisResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	pkgUUID, err := uuid.Parse(packageID)
+	if err != nil {
+		return err
+	}
+
+	exists, err := genQ().VerifyPackageOwnership(ctx, generated.VerifyPackageOwnershipParams{
+		ID:     pkgUUID,
+		UserID: userID,
 	})
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	tx, err := Db().conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("analysis_results",
+		"package_id", "ticker", "count", "mean", "stddev", "variance", "min", "max", "histogram",
+		"beta", "correlation", "alpha", "metrics", "rolling", "outliers_removed"))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, result := range results {
+		// jsonb columns are passed as text: COPY would encode []byte as bytea
+		var metrics interface{}
+		if result.Metrics != nil {
+			raw, err := json.Marshal(result.Metrics)
+			if err != nil {
+				return err
+			}
+			metrics = string(raw)
+		}
+		var rolling interface{}
+		if result.Rolling != nil {
+			raw, err := json.Marshal(result.Rolling)
+			if err != nil {
+				return err
+			}
+			rolling = string(raw)
+		}
+		histogram := "[]"
+		if len(result.Histogram) > 0 {
+			histogram = string(result.Histogram)
+		}
+
+		if _, err := stmt.ExecContext(ctx,
+			pkgUUID, result.Ticker, result.Count, result.Mean, result.StdDev, result.Variance, result.Min, result.Max, histogram,
+			f.MaybeFloat64ToNullFloat64(result.Beta),
+			f.MaybeFloat64ToNullFloat64(result.Correlation),
+			f.MaybeFloat64ToNullFloat64(result.Alpha),
+			metrics,
+			rolling,
+			result.OutliersRemoved,
+		); err != nil {
+			return err
+		}
+	}
+
+	// Flush the COPY buffer
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return err
+	}
+	if err := stmt.Close(); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // GetAnalysisResults retrieves all results for a package (verifies package ownership)
@@ -107,15 +299,21 @@ func GetAnalysisResults(ctx context.Context, userID uuid.UUID, packageID string)
 	results := make([]types.AnalysisResult, len(genResults))
 	for i, r := range genResults {
 		results[i] = types.AnalysisResult{
-			PackageID:     r.PackageID.String(),
-			Ticker:        r.Ticker,
-			Count:         int(r.Count),
-			Mean:          r.Mean,
-			StdDev:        r.Stddev,
-			Variance:      r.Variance,
-			Min:           r.Min,
-			Max:           r.Max,
-			InceptionDate: f.NullTimeToMaybeTime(r.Inception),
+			PackageID:       r.PackageID.String(),
+			Ticker:          r.Ticker,
+			Count:           int(r.Count),
+			Mean:            r.Mean,
+			StdDev:          r.Stddev,
+			Variance:        r.Variance,
+			Min:             r.Min,
+			Max:             r.Max,
+			Beta:            f.NullFloat64ToMaybeFloat64(r.Beta),
+			Correlation:     f.NullFloat64ToMaybeFloat64(r.Correlation),
+			Alpha:           f.NullFloat64ToMaybeFloat64(r.Alpha),
+			InceptionDate:   f.NullTimeToMaybeTime(r.Inception),
+			Metrics:         unmarshalMetrics(r.Metrics),
+			Rolling:         unmarshalRolling(r.Rolling),
+			OutliersRemoved: int(r.OutliersRemoved),
 		}
 	}
 
@@ -146,20 +344,27 @@ func GetAnalysisPackage(ctx context.Context, userID uuid.UUID, packageID string)
 	}
 
 	return &types.AnalysisPackage{
-		ID:           genPkg.ID.String(),
-		Name:         genPkg.Name,
-		CreatedAt:    genPkg.CreatedAt,
-		Interval:     genPkg.Interval,
-		TimeFrom:     genPkg.TimeFrom,
-		TimeTo:       genPkg.TimeTo,
-		HistBins:     int(genPkg.HistBins),
-		HistMin:      genPkg.HistMin,
-		HistMax:      genPkg.HistMax,
-		McapMin:      f.NullInt64ToMaybeInt64(genPkg.McapMin),
-		InceptionMax: f.NullTimeToMaybeTime(genPkg.InceptionMax),
-		SymbolCount:  symbolCount,
-		Status:       genPkg.Status,
-		UserID:       genPkg.UserID,
+		ID:                  genPkg.ID.String(),
+		Name:                genPkg.Name,
+		CreatedAt:           genPkg.CreatedAt,
+		Interval:            genPkg.Interval,
+		TimeFrom:            genPkg.TimeFrom,
+		TimeTo:              genPkg.TimeTo,
+		HistBins:            int(genPkg.HistBins),
+		HistMin:             genPkg.HistMin,
+		HistMax:             genPkg.HistMax,
+		McapMin:             f.NullInt64ToMaybeInt64(genPkg.McapMin),
+		McapMax:             f.NullInt64ToMaybeInt64(genPkg.McapMax),
+		Filters:             unmarshalFilters(genPkg.Filters),
+		InceptionMax:        f.NullTimeToMaybeTime(genPkg.InceptionMax),
+		SymbolCount:         symbolCount,
+		Status:              genPkg.Status,
+		UserID:              genPkg.UserID,
+		ReferenceIndex:      f.NullStringToMaybeString(genPkg.ReferenceIndex),
+		ReturnBasis:         genPkg.ReturnBasis,
+		RiskFreeRate:        genPkg.RiskFreeRate,
+		RollingWindowMonths: int(genPkg.RollingWindowMonths),
+		Outliers:            unmarshalOutliers(genPkg.Outliers),
 	}, nil
 }
 
@@ -178,20 +383,27 @@ func ListAnalysisPackages(ctx context.Context, userID uuid.UUID) ([]types.Analys
 		}
 
 		packages[i] = types.AnalysisPackage{
-			ID:           genPkg.ID.String(),
-			Name:         genPkg.Name,
-			CreatedAt:    genPkg.CreatedAt,
-			Interval:     genPkg.Interval,
-			TimeFrom:     genPkg.TimeFrom,
-			TimeTo:       genPkg.TimeTo,
-			HistBins:     int(genPkg.HistBins),
-			HistMin:      genPkg.HistMin,
-			HistMax:      genPkg.HistMax,
-			McapMin:      f.NullInt64ToMaybeInt64(genPkg.McapMin),
-			InceptionMax: f.NullTimeToMaybeTime(genPkg.InceptionMax),
-			SymbolCount:  symbolCount,
-			Status:       genPkg.Status,
-			UserID:       genPkg.UserID,
+			ID:                  genPkg.ID.String(),
+			Name:                genPkg.Name,
+			CreatedAt:           genPkg.CreatedAt,
+			Interval:            genPkg.Interval,
+			TimeFrom:            genPkg.TimeFrom,
+			TimeTo:              genPkg.TimeTo,
+			HistBins:            int(genPkg.HistBins),
+			HistMin:             genPkg.HistMin,
+			HistMax:             genPkg.HistMax,
+			McapMin:             f.NullInt64ToMaybeInt64(genPkg.McapMin),
+			McapMax:             f.NullInt64ToMaybeInt64(genPkg.McapMax),
+			Filters:             unmarshalFilters(genPkg.Filters),
+			InceptionMax:        f.NullTimeToMaybeTime(genPkg.InceptionMax),
+			SymbolCount:         symbolCount,
+			Status:              genPkg.Status,
+			UserID:              genPkg.UserID,
+			ReferenceIndex:      f.NullStringToMaybeString(genPkg.ReferenceIndex),
+			ReturnBasis:         genPkg.ReturnBasis,
+			RiskFreeRate:        genPkg.RiskFreeRate,
+			RollingWindowMonths: int(genPkg.RollingWindowMonths),
+			Outliers:            unmarshalOutliers(genPkg.Outliers),
 		}
 	}
 
@@ -0,0 +1,181 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db/generated"
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+)
+
+// CreateAnalysisJob queues a job for an analysis package
+func CreateAnalysisJob(ctx context.Context, userID uuid.UUID, packageID string) error {
+	pkgUUID, err := uuid.Parse(packageID)
+	if err != nil {
+		return err
+	}
+	return genQ().CreateAnalysisJob(ctx, generated.CreateAnalysisJobParams{
+		PackageID: pkgUUID,
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	})
+}
+
+// GetAnalysisJob returns the job of a package, nil if the package has none
+func GetAnalysisJob(ctx context.Context, userID uuid.UUID, packageID string) (*types.AnalysisJob, error) {
+	pkgUUID, err := uuid.Parse(packageID)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := genQ().GetAnalysisJob(ctx, generated.GetAnalysisJobParams{
+		PackageID: pkgUUID,
+		UserID:    userID,
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rejections := map[string]int{}
+	_ = json.Unmarshal(job.Rejections, &rejections)
+
+	return &types.AnalysisJob{
+		PackageID:   job.PackageID.String(),
+		UserID:      job.UserID,
+		State:       job.State,
+		Attempts:    int(job.Attempts),
+		Processed:   int(job.Processed),
+		Total:       int(job.Total),
+		Results:     int(job.Results),
+		Rejections:  rejections,
+		Error:       f.NullStringToMaybeString(job.Error),
+		CreatedAt:   job.CreatedAt,
+		StartedAt:   f.NullTimeToMaybeTime(job.StartedAt),
+		FinishedAt:  f.NullTimeToMaybeTime(job.FinishedAt),
+		HeartbeatAt: f.NullTimeToMaybeTime(job.HeartbeatAt),
+	}, nil
+}
+
+// ClaimAnalysisJob marks the oldest queued job as running and returns it, nil if none is queued.
+// Safe to call from several processes: a job is only ever claimed once.
+func ClaimAnalysisJob(ctx context.Context) (*types.AnalysisJob, error) {
+	row, err := genQ().ClaimAnalysisJob(ctx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &types.AnalysisJob{
+		PackageID: row.PackageID.String(),
+		UserID:    row.UserID,
+		State:     types.JobRunning,
+		Attempts:  int(row.Attempts),
+	}, nil
+}
+
+// UpdateAnalysisJobProgress stores the progress of a running job and refreshes its heartbeat.
+// Returns the current state, so callers notice cancellation; sql.ErrNoRows if the job was deleted.
+func UpdateAnalysisJobProgress(ctx context.Context, packageID string, processed, total, results int, rejections map[string]int) (string, error) {
+	pkgUUID, err := uuid.Parse(packageID)
+	if err != nil {
+		return "", err
+	}
+	rejectionsJSON, err := json.Marshal(rejections)
+	if err != nil {
+		return "", err
+	}
+	return genQ().UpdateAnalysisJobProgress(ctx, generated.UpdateAnalysisJobProgressParams{
+		PackageID:  pkgUUID,
+		Processed:  int32(processed),
+		Total:      int32(total),
+		Results:    int32(results),
+		Rejections: rejectionsJSON,
+	})
+}
+
+// FinishAnalysisJob moves a running job to a final state. Returns false if the job was no
+// longer running (e.g. cancelled in the meantime), in which case nothing is changed.
+func FinishAnalysisJob(ctx context.Context, packageID string, state string, errMsg *string) (bool, error) {
+	pkgUUID, err := uuid.Parse(packageID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := genQ().FinishAnalysisJob(ctx, generated.FinishAnalysisJobParams{
+		PackageID: pkgUUID,
+		State:     state,
+		Error:     f.MaybeStringToNullString(errMsg),
+	})
+	return rows > 0, err
+}
+
+// CancelAnalysisJob cancels a queued or running job of a user.
+// Returns false if there was no such job in a cancellable state.
+func CancelAnalysisJob(ctx context.Context, userID uuid.UUID, packageID string) (bool, error) {
+	pkgUUID, err := uuid.Parse(packageID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := genQ().CancelAnalysisJob(ctx, generated.CancelAnalysisJobParams{
+		PackageID: pkgUUID,
+		UserID:    userID,
+	})
+	return rows > 0, err
+}
+
+// TouchAnalysisJob refreshes the heartbeat of a running job without changing its progress.
+// Returns the current state, so callers notice cancellation; sql.ErrNoRows if the job was deleted.
+func TouchAnalysisJob(ctx context.Context, packageID string) (string, error) {
+	pkgUUID, err := uuid.Parse(packageID)
+	if err != nil {
+		return "", err
+	}
+	return genQ().TouchAnalysisJob(ctx, pkgUUID)
+}
+
+// RecoverOrphanedAnalysisJobs handles running jobs whose heartbeat is older than staleBefore,
+// i.e. whose process died. Jobs with fewer than maxAttempts starts are queued again, the
+// others fail together with their package. The jobs of the packages in running are still
+// running in the calling process and are left alone. Returns the number of requeued and failed jobs.
+func RecoverOrphanedAnalysisJobs(ctx context.Context, staleBefore time.Time, maxAttempts int, running []string) (int64, int64, error) {
+	runningIDs := make([]uuid.UUID, 0, len(running)) // Never nil: ANY(NULL) would match no job at all
+	for _, packageID := range running {
+		pkgUUID, err := uuid.Parse(packageID)
+		if err != nil {
+			return 0, 0, err
+		}
+		runningIDs = append(runningIDs, pkgUUID)
+	}
+
+	stale := sql.NullTime{Time: staleBefore, Valid: true}
+	requeued, err := genQ().RequeueOrphanedAnalysisJobs(ctx, generated.RequeueOrphanedAnalysisJobsParams{
+		HeartbeatAt: stale,
+		Attempts:    int32(maxAttempts),
+		Column3:     runningIDs,
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	failed, err := genQ().FailOrphanedAnalysisJobs(ctx, generated.FailOrphanedAnalysisJobsParams{
+		HeartbeatAt: stale,
+		Attempts:    int32(maxAttempts),
+		Column3:     runningIDs,
+	})
+	return requeued, failed, err
+}
+
+// DeleteAnalysisResults removes all results of a package, e.g. before a job is restarted
+func DeleteAnalysisResults(ctx context.Context, packageID string) error {
+	pkgUUID, err := uuid.Parse(packageID)
+	if err != nil {
+		return err
+	}
+	return genQ().DeleteAnalysisResults(ctx, pkgUUID)
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db/generated"
+)
+
+// ApiUsage persists the weight used by the API rate limiters per day (implements ratelimit.QuotaStore)
+type ApiUsage struct{}
+
+// AddUsage adds to the weight and requests used by a limiter on a day
+func (ApiUsage) AddUsage(ctx context.Context, limiter string, day time.Time, weight, requests int64) error {
+	if Db() == nil {
+		return errors.New("database not connected")
+	}
+	return genQ().AddApiUsage(ctx, generated.AddApiUsageParams{
+		Limiter:  limiter,
+		Day:      day,
+		Weight:   weight,
+		Requests: requests,
+	})
+}
+
+// GetUsage returns the weight used by a limiter on a day and in its month up to the day
+func (ApiUsage) GetUsage(ctx context.Context, limiter string, day time.Time) (daily, monthly int64, err error) {
+	if Db() == nil {
+		return 0, 0, errors.New("database not connected")
+	}
+	row, err := genQ().GetApiUsage(ctx, generated.GetApiUsageParams{Limiter: limiter, Day: day})
+	if err != nil {
+		return 0, 0, err
+	}
+	return row.Daily, row.Monthly, nil
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/flocko-motion/gofins/pkg/db/generated"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+)
+
+// CreateBacktest stores a backtest with its result
+func CreateBacktest(ctx context.Context, backtest *types.Backtest) error {
+	config, err := json.Marshal(backtest.Config)
+	if err != nil {
+		return err
+	}
+	result, err := json.Marshal(backtest.Result)
+	if err != nil {
+		return err
+	}
+	return genQ().CreateBacktest(ctx, generated.CreateBacktestParams{
+		ID:        backtest.ID,
+		UserID:    backtest.UserID,
+		Name:      backtest.Name,
+		Config:    config,
+		Result:    result,
+		CreatedAt: backtest.CreatedAt,
+	})
+}
+
+// ListBacktests returns the backtests of a user without their results, newest first
+func ListBacktests(ctx context.Context, userID uuid.UUID) ([]types.Backtest, error) {
+	rows, err := genQ().ListBacktests(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	backtests := make([]types.Backtest, len(rows))
+	for i, row := range rows {
+		backtests[i] = types.Backtest{
+			ID:        row.ID,
+			UserID:    row.UserID,
+			Name:      row.Name,
+			CreatedAt: row.CreatedAt,
+		}
+		if err := json.Unmarshal(row.Config, &backtests[i].Config); err != nil {
+			return nil, err
+		}
+	}
+	return backtests, nil
+}
+
+// GetBacktest returns a backtest of a user with its result, nil if it doesn't exist
+func GetBacktest(ctx context.Context, userID, id uuid.UUID) (*types.Backtest, error) {
+	row, err := genQ().GetBacktest(ctx, generated.GetBacktestParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	backtest := &types.Backtest{
+		ID:        row.ID,
+		UserID:    row.UserID,
+		Name:      row.Name,
+		CreatedAt: row.CreatedAt,
+	}
+	if err := json.Unmarshal(row.Config, &backtest.Config); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(row.Result, &backtest.Result); err != nil {
+		return nil, err
+	}
+	return backtest, nil
+}
+
+// DeleteBacktest removes a backtest of a user. Returns false if it didn't exist.
+func DeleteBacktest(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	rows, err := genQ().DeleteBacktest(ctx, generated.DeleteBacktestParams{
+		ID:     id,
+		UserID: userID,
+	})
+	return rows > 0, err
+}
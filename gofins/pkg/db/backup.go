@@ -0,0 +1,167 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db/generated"
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+)
+
+// Favorite is a favorited ticker with the time it was added, as stored in backups before version 3
+type Favorite struct {
+	Ticker    string     `json:"ticker"`
+	CreatedAt *time.Time `json:"createdAt"`
+}
+
+// GetAllRatings returns the full rating history of a user, newest first
+func GetAllRatings(ctx context.Context, userID uuid.UUID) ([]UserRating, error) {
+	genRatings, err := genQ().GetAllRatings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]UserRating, len(genRatings))
+	for i, r := range genRatings {
+		result[i] = UserRating{
+			ID:        int(r.ID),
+			Ticker:    r.Ticker,
+			Rating:    int(r.Rating),
+			Notes:     f.NullStringToMaybeString(r.Notes),
+			CreatedAt: r.CreatedAt,
+		}
+	}
+	return result, nil
+}
+
+// ImportFavorite adds a favorite with its original timestamp
+// Returns false if the favorite already existed
+func ImportFavorite(ctx context.Context, userID uuid.UUID, favorite Favorite) (bool, error) {
+	watchlistID, err := DefaultWatchlistID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	entry := types.WatchlistEntry{Ticker: favorite.Ticker}
+	if favorite.CreatedAt != nil {
+		entry.CreatedAt = *favorite.CreatedAt
+	}
+	added, err := addWatchlistEntries(ctx, genQ(), watchlistID, []types.WatchlistEntry{entry})
+	return added > 0, err
+}
+
+// ImportRating inserts a rating with its original timestamp
+// Returns false if a rating for the same ticker and timestamp already existed
+func ImportRating(ctx context.Context, userID uuid.UUID, rating UserRating) (bool, error) {
+	affected, err := genQ().ImportRating(ctx, generated.ImportRatingParams{
+		UserID:    userID,
+		Ticker:    rating.Ticker,
+		Rating:    int32(rating.Rating),
+		Notes:     f.MaybeStringToNullString(rating.Notes),
+		CreatedAt: rating.CreatedAt,
+	})
+	return affected > 0, err
+}
+
+// UpdateRatingAt replaces rating and notes of the rating identified by ticker and timestamp
+func UpdateRatingAt(ctx context.Context, userID uuid.UUID, rating UserRating) (bool, error) {
+	affected, err := genQ().UpdateRatingAt(ctx, generated.UpdateRatingAtParams{
+		Rating:    int32(rating.Rating),
+		Notes:     f.MaybeStringToNullString(rating.Notes),
+		UserID:    userID,
+		Ticker:    rating.Ticker,
+		CreatedAt: rating.CreatedAt,
+	})
+	return affected > 0, err
+}
+
+// GetAnalysisPackageOwner returns the owner of a package, nil if the package doesn't exist
+func GetAnalysisPackageOwner(ctx context.Context, packageID string) (*uuid.UUID, error) {
+	pkgUUID, err := uuid.Parse(packageID)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := genQ().GetAnalysisPackageOwner(ctx, pkgUUID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &owner, nil
+}
+
+// GetAnalysisResultsWithHistogram returns all results of a package including histograms
+// Caller must verify package ownership.
+func GetAnalysisResultsWithHistogram(ctx context.Context, packageID string) ([]types.AnalysisResult, error) {
+	pkgUUID, err := uuid.Parse(packageID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := genQ().GetAnalysisResultsFull(ctx, pkgUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]types.AnalysisResult, len(rows))
+	for i, r := range rows {
+		results[i] = types.AnalysisResult{
+			PackageID:       r.PackageID.String(),
+			Ticker:          r.Ticker,
+			Count:           int(r.Count),
+			Mean:            r.Mean,
+			StdDev:          r.Stddev,
+			Variance:        r.Variance,
+			Min:             r.Min,
+			Max:             r.Max,
+			Beta:            f.NullFloat64ToMaybeFloat64(r.Beta),
+			Correlation:     f.NullFloat64ToMaybeFloat64(r.Correlation),
+			Alpha:           f.NullFloat64ToMaybeFloat64(r.Alpha),
+			Metrics:         unmarshalMetrics(r.Metrics),
+			Rolling:         unmarshalRolling(r.Rolling),
+			OutliersRemoved: int(r.OutliersRemoved),
+			Histogram:       r.Histogram,
+		}
+	}
+	return results, nil
+}
+
+// ImportJournalEntry inserts a journal entry with its original timestamps and ticker links
+func ImportJournalEntry(ctx context.Context, userID uuid.UUID, entry types.JournalEntry) (int, error) {
+	tags, err := marshalTags(entry.Tags)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := Db().conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	q := genQ().WithTx(tx)
+
+	id, err := q.ImportJournalEntry(ctx, generated.ImportJournalEntryParams{
+		UserID:    userID,
+		Title:     entry.Title,
+		Content:   entry.Content,
+		Type:      entry.Type,
+		Tags:      tags,
+		CreatedAt: entry.CreatedAt,
+		UpdatedAt: entry.UpdatedAt,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, ticker := range normalizeTickers(entry.Tickers) {
+		if err := q.AddJournalTicker(ctx, generated.AddJournalTickerParams{JournalID: id, Ticker: ticker}); err != nil {
+			return 0, err
+		}
+	}
+
+	return int(id), tx.Commit()
+}
@@ -0,0 +1,140 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+// PutDividends batch inserts dividends using bulk INSERT
+func PutDividends(dividends []types.Dividend) error {
+	db := Db()
+	if len(dividends) == 0 {
+		return nil
+	}
+
+	// Split into chunks of 1000 to avoid parameter limits
+	chunkSize := 1000
+	for i := 0; i < len(dividends); i += chunkSize {
+		end := i + chunkSize
+		if end > len(dividends) {
+			end = len(dividends)
+		}
+		chunk := dividends[i:end]
+
+		valueStrings := make([]string, 0, len(chunk))
+		valueArgs := make([]interface{}, 0, len(chunk)*5)
+
+		for idx, d := range chunk {
+			paramOffset := idx * 5
+			valueStrings = append(valueStrings, fmt.Sprintf("($%d,$%d,$%d,$%d,$%d)",
+				paramOffset+1, paramOffset+2, paramOffset+3, paramOffset+4, paramOffset+5))
+			valueArgs = append(valueArgs, d.SymbolTicker, d.Date, d.Dividend, d.AdjDividend, d.PaymentDate)
+		}
+
+		query := fmt.Sprintf(`
+			INSERT INTO dividends (symbol_ticker, date, dividend, adj_dividend, payment_date)
+			VALUES %s
+			ON CONFLICT (symbol_ticker, date) DO UPDATE SET
+				dividend = EXCLUDED.dividend,
+				adj_dividend = EXCLUDED.adj_dividend,
+				payment_date = EXCLUDED.payment_date
+		`, joinStrings(valueStrings, ","))
+
+		if _, err := db.conn.Exec(query, valueArgs...); err != nil {
+			return fmt.Errorf("failed to batch insert dividends: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// PutSplits batch inserts stock splits using bulk INSERT
+func PutSplits(splits []types.Split) error {
+	db := Db()
+	if len(splits) == 0 {
+		return nil
+	}
+
+	// Split into chunks of 1000 to avoid parameter limits
+	chunkSize := 1000
+	for i := 0; i < len(splits); i += chunkSize {
+		end := i + chunkSize
+		if end > len(splits) {
+			end = len(splits)
+		}
+		chunk := splits[i:end]
+
+		valueStrings := make([]string, 0, len(chunk))
+		valueArgs := make([]interface{}, 0, len(chunk)*4)
+
+		for idx, s := range chunk {
+			paramOffset := idx * 4
+			valueStrings = append(valueStrings, fmt.Sprintf("($%d,$%d,$%d,$%d)",
+				paramOffset+1, paramOffset+2, paramOffset+3, paramOffset+4))
+			valueArgs = append(valueArgs, s.SymbolTicker, s.Date, s.Numerator, s.Denominator)
+		}
+
+		query := fmt.Sprintf(`
+			INSERT INTO splits (symbol_ticker, date, numerator, denominator)
+			VALUES %s
+			ON CONFLICT (symbol_ticker, date) DO UPDATE SET
+				numerator = EXCLUDED.numerator,
+				denominator = EXCLUDED.denominator
+		`, joinStrings(valueStrings, ","))
+
+		if _, err := db.conn.Exec(query, valueArgs...); err != nil {
+			return fmt.Errorf("failed to batch insert splits: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetDividends returns the dividend history of a symbol, oldest first
+func GetDividends(ticker string) ([]types.Dividend, error) {
+	rows, err := Db().conn.Query(`
+		SELECT symbol_ticker, date, dividend, adj_dividend, payment_date
+		FROM dividends
+		WHERE symbol_ticker = $1
+		ORDER BY date ASC
+	`, ticker)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dividends := []types.Dividend{}
+	for rows.Next() {
+		var d types.Dividend
+		if err := rows.Scan(&d.SymbolTicker, &d.Date, &d.Dividend, &d.AdjDividend, &d.PaymentDate); err != nil {
+			return nil, err
+		}
+		dividends = append(dividends, d)
+	}
+	return dividends, rows.Err()
+}
+
+// GetSplits returns the stock split history of a symbol, oldest first
+func GetSplits(ticker string) ([]types.Split, error) {
+	rows, err := Db().conn.Query(`
+		SELECT symbol_ticker, date, numerator, denominator
+		FROM splits
+		WHERE symbol_ticker = $1
+		ORDER BY date ASC
+	`, ticker)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	splits := []types.Split{}
+	for rows.Next() {
+		var s types.Split
+		if err := rows.Scan(&s.SymbolTicker, &s.Date, &s.Numerator, &s.Denominator); err != nil {
+			return nil, err
+		}
+		splits = append(splits, s)
+	}
+	return splits, rows.Err()
+}
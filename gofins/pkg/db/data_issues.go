@@ -0,0 +1,156 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db/generated"
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+// DataIssueFilter selects data issues, empty fields match all
+type DataIssueFilter struct {
+	Ticker  string
+	Kind    string
+	Status  string
+	ErrorID int
+	Limit   int
+}
+
+// DataIssueCount is the number of data issues of a kind in a status
+type DataIssueCount struct {
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+	Count  int    `json:"count"`
+}
+
+func dataIssueFromGen(row generated.DataIssue) types.DataIssue {
+	issue := types.DataIssue{
+		ID:         int(row.ID),
+		Ticker:     row.Ticker,
+		Interval:   types.PriceInterval(row.Interval.String),
+		Kind:       row.Kind,
+		Period:     f.NullTimeToMaybeTime(row.Period),
+		Severity:   row.Severity,
+		Message:    row.Message,
+		Details:    f.NullStringToMaybeString(row.Details),
+		Status:     row.Status,
+		FirstSeen:  row.FirstSeen,
+		LastSeen:   row.LastSeen,
+		ResolvedAt: f.NullTimeToMaybeTime(row.ResolvedAt),
+	}
+	if row.ErrorID.Valid {
+		issue.ErrorID = f.Ptr(int(row.ErrorID.Int32))
+	}
+	return issue
+}
+
+// PutDataIssues records the issues found by an audit at seenAt and returns how many are new.
+// Known issues are updated and reopened (counting as new) if they were resolved; ignored issues stay ignored.
+func PutDataIssues(ctx context.Context, issues []types.DataIssue, seenAt time.Time) (int, error) {
+	tx, err := Db().conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	q := generated.New(tx)
+	added := 0
+	for _, issue := range issues {
+		isNew, err := q.UpsertDataIssue(ctx, generated.UpsertDataIssueParams{
+			Ticker:    issue.Ticker,
+			Interval:  sql.NullString{String: string(issue.Interval), Valid: issue.Interval != ""},
+			Kind:      issue.Kind,
+			Period:    f.MaybeTimeToNullTime(issue.Period),
+			Severity:  issue.Severity,
+			Message:   issue.Message,
+			Details:   f.MaybeStringToNullString(issue.Details),
+			FirstSeen: seenAt,
+		})
+		if err != nil {
+			return 0, err
+		}
+		if isNew {
+			added++
+		}
+	}
+	return added, tx.Commit()
+}
+
+// ResolveDataIssues marks open issues not seen since the audit started at since as resolved,
+// of a single symbol or of all symbols if ticker is empty
+func ResolveDataIssues(ctx context.Context, ticker string, since time.Time) (int, error) {
+	rows, err := genQ().ResolveDataIssues(ctx, generated.ResolveDataIssuesParams{
+		Column1:    ticker,
+		ResolvedAt: f.MaybeTimeToNullTime(&since),
+	})
+	return int(rows), err
+}
+
+// LinkDataIssuesToError links the open issues first seen since the audit started to the error it logged
+func LinkDataIssuesToError(ctx context.Context, errorID int, since time.Time) (int, error) {
+	rows, err := genQ().LinkDataIssuesToError(ctx, generated.LinkDataIssuesToErrorParams{
+		ErrorID:   sql.NullInt32{Int32: int32(errorID), Valid: true},
+		FirstSeen: since,
+	})
+	return int(rows), err
+}
+
+// ListDataIssues returns data issues, most recently seen first
+func ListDataIssues(ctx context.Context, filter DataIssueFilter) ([]types.DataIssue, error) {
+	rows, err := genQ().ListDataIssues(ctx, generated.ListDataIssuesParams{
+		Column1: filter.Ticker,
+		Column2: filter.Kind,
+		Column3: filter.Status,
+		Column4: int32(filter.ErrorID),
+		Limit:   int32(filter.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	issues := make([]types.DataIssue, len(rows))
+	for i, row := range rows {
+		issues[i] = dataIssueFromGen(row)
+	}
+	return issues, nil
+}
+
+// GetDataIssue returns a data issue by ID, nil if not found
+func GetDataIssue(ctx context.Context, id int) (*types.DataIssue, error) {
+	row, err := genQ().GetDataIssue(ctx, int32(id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	issue := dataIssueFromGen(row)
+	return &issue, nil
+}
+
+// SetDataIssueStatus ignores an issue or reopens an ignored one. Returns false if the issue
+// doesn't exist or is resolved.
+func SetDataIssueStatus(ctx context.Context, id int, status string) (bool, error) {
+	rows, err := genQ().SetDataIssueStatus(ctx, generated.SetDataIssueStatusParams{
+		ID:     int32(id),
+		Status: status,
+	})
+	return rows > 0, err
+}
+
+// CountDataIssues returns the number of data issues by kind and status
+func CountDataIssues(ctx context.Context) ([]DataIssueCount, error) {
+	rows, err := genQ().CountDataIssues(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]DataIssueCount, len(rows))
+	for i, row := range rows {
+		counts[i] = DataIssueCount{Kind: row.Kind, Status: row.Status, Count: int(row.Count)}
+	}
+	return counts, nil
+}
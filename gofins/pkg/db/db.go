@@ -2,6 +2,7 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"sync"
@@ -10,7 +11,7 @@ import (
 	"github.com/flocko-motion/gofins/pkg/db/generated"
 	"github.com/flocko-motion/gofins/pkg/files"
 	"github.com/flocko-motion/gofins/pkg/log"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 func getEnvOrDefault(key, defaultValue string) string {
@@ -116,6 +117,12 @@ func PrepareForShutdown() error {
 	return nil
 }
 
+// IsUniqueViolation reports whether err is a violation of a unique constraint, e.g. a duplicate name
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
 // Internal helper functions - not exported
 func exec(query string, args ...interface{}) (sql.Result, error) {
 	return Db().conn.Exec(query, args...)
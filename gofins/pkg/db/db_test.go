@@ -1,18 +1,100 @@
 package db
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"slices"
 	"testing"
 	"time"
 
 	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestGetFiltered(t *testing.T) {
-	tickers, err := GetFilteredTickers(f.Ptr(int64(1_000_000_000)),
-		f.Ptr(time.Date(2009, 1, 1, 0, 0, 0, 0, time.UTC)))
+	filters := types.AnalysisFilters{
+		Universe:  types.UniverseAll,
+		Exchanges: types.IncludeExclude{Exclude: types.OTCExchanges},
+	}
+	tickers, err := GetFilteredTickers(context.Background(), uuid.Nil, f.Ptr(int64(1_000_000_000)), nil,
+		f.Ptr(time.Date(2009, 1, 1, 0, 0, 0, 0, time.UTC)), filters)
 	assert.NoError(t, err)
 	assert.NotNil(t, tickers)
 	assert.False(t, slices.Contains(tickers, "FNMA"))
 }
+
+func TestGetFilteredResolvedWatchlist(t *testing.T) {
+	// The tickers resolved at creation are used, not the (here unknown) watchlist
+	filters := types.AnalysisFilters{
+		Universe:  types.UniverseWatchlist,
+		Watchlist: f.Ptr(uuid.New()),
+		Tickers:   []string{"aapl"},
+	}
+	tickers, err := GetFilteredTickers(context.Background(), uuid.New(), nil, nil, nil, filters)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"AAPL"}, tickers)
+}
+
+func TestCreateUserFavorites(t *testing.T) {
+	if Db() == nil {
+		t.Skip("Database not available")
+	}
+	ctx := context.Background()
+	user, err := CreateUser(ctx, "favorites-test-"+uuid.NewString())
+	assert.NoError(t, err)
+	defer DeleteUser(ctx, user.Name)
+
+	// The favorites list exists from the start, listing doesn't create it
+	watchlists, err := ListWatchlists(ctx, user.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, watchlists, 1) {
+		assert.True(t, watchlists[0].IsDefault)
+	}
+
+	added, err := ToggleFavorite(ctx, user.ID, "AAPL")
+	assert.NoError(t, err)
+	assert.True(t, added)
+	added, err = ToggleFavorite(ctx, user.ID, "AAPL")
+	assert.NoError(t, err)
+	assert.False(t, added)
+	favorites, err := GetFavorites(ctx, user.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, favorites)
+}
+
+func TestConsumeSchedulerSlot(t *testing.T) {
+	if Db() == nil {
+		t.Skip("Database not available")
+	}
+	ctx := context.Background()
+	name := "slot-test-" + uuid.NewString()
+	defer Db().conn.Exec("DELETE FROM scheduler_jobs WHERE name = $1", name)
+
+	// Two schedulers saw the job never scheduled, only the first takes the slot
+	now := time.Now().Truncate(time.Microsecond)
+	taken, err := ConsumeSchedulerSlot(ctx, name, nil, now)
+	assert.NoError(t, err)
+	assert.True(t, taken)
+	taken, err = ConsumeSchedulerSlot(ctx, name, nil, now.Add(time.Second))
+	assert.NoError(t, err)
+	assert.False(t, taken)
+
+	taken, err = ConsumeSchedulerSlot(ctx, name, &now, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.True(t, taken)
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	assert.True(t, IsUniqueViolation(fmt.Errorf("insert: %w", &pq.Error{Code: "23505"})))
+	assert.False(t, IsUniqueViolation(&pq.Error{Code: "23503"}))
+	assert.False(t, IsUniqueViolation(errors.New("other")))
+}
+
+func TestSearchSnippet(t *testing.T) {
+	headline := "Notes on <script>alert(1)</script> and " + snippetStartSel + "cloud" + snippetStopSel + " & more"
+	assert.Equal(t, "Notes on &lt;script&gt;alert(1)&lt;/script&gt; and <mark>cloud</mark> &amp; more", searchSnippet(headline))
+}
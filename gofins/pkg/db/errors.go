@@ -46,6 +46,18 @@ func LogError(ctx context.Context, source, errorType, message string, details *s
 	})
 }
 
+// LogErrorWithID logs an error like LogError and returns its ID, for records that refer to it
+func LogErrorWithID(ctx context.Context, source, errorType, message string, details *string) (int, error) {
+	fmt.Printf("[%s] %s: %s\n", source, errorType, message)
+	id, err := genQ().InsertErrorReturningID(ctx, generated.InsertErrorReturningIDParams{
+		Source:    source,
+		ErrorType: errorType,
+		Message:   message,
+		Details:   f.MaybeStringToNullString(details),
+	})
+	return int(id), err
+}
+
 // GetRecentErrors retrieves the most recent errors
 func GetRecentErrors(ctx context.Context, limit int) ([]ErrorEntry, error) {
 	rows, err := genQ().GetRecentErrors(ctx, int32(limit))
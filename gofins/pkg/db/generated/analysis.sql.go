@@ -12,29 +12,38 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
 )
 
 const createAnalysisPackage = `-- name: CreateAnalysisPackage :exec
 INSERT INTO analysis_packages (
     id, name, created_at, interval, time_from, time_to,
-    hist_bins, hist_min, hist_max, mcap_min, inception_max, status, user_id
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+    hist_bins, hist_min, hist_max, mcap_min, inception_max, status, user_id, reference_index, return_basis,
+    risk_free_rate, mcap_max, filters, rolling_window_months, outliers
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
 `
 
 type CreateAnalysisPackageParams struct {
-	ID           uuid.UUID     `json:"id"`
-	Name         string        `json:"name"`
-	CreatedAt    time.Time     `json:"created_at"`
-	Interval     string        `json:"interval"`
-	TimeFrom     time.Time     `json:"time_from"`
-	TimeTo       time.Time     `json:"time_to"`
-	HistBins     int32         `json:"hist_bins"`
-	HistMin      float64       `json:"hist_min"`
-	HistMax      float64       `json:"hist_max"`
-	McapMin      sql.NullInt64 `json:"mcap_min"`
-	InceptionMax sql.NullTime  `json:"inception_max"`
-	Status       string        `json:"status"`
-	UserID       uuid.UUID     `json:"user_id"`
+	ID                  uuid.UUID       `json:"id"`
+	Name                string          `json:"name"`
+	CreatedAt           time.Time       `json:"created_at"`
+	Interval            string          `json:"interval"`
+	TimeFrom            time.Time       `json:"time_from"`
+	TimeTo              time.Time       `json:"time_to"`
+	HistBins            int32           `json:"hist_bins"`
+	HistMin             float64         `json:"hist_min"`
+	HistMax             float64         `json:"hist_max"`
+	McapMin             sql.NullInt64   `json:"mcap_min"`
+	InceptionMax        sql.NullTime    `json:"inception_max"`
+	Status              string          `json:"status"`
+	UserID              uuid.UUID       `json:"user_id"`
+	ReferenceIndex      sql.NullString  `json:"reference_index"`
+	ReturnBasis         string          `json:"return_basis"`
+	RiskFreeRate        float64         `json:"risk_free_rate"`
+	McapMax             sql.NullInt64   `json:"mcap_max"`
+	Filters             json.RawMessage `json:"filters"`
+	RollingWindowMonths int32           `json:"rolling_window_months"`
+	Outliers            json.RawMessage `json:"outliers"`
 }
 
 func (q *Queries) CreateAnalysisPackage(ctx context.Context, arg CreateAnalysisPackageParams) error {
@@ -52,6 +61,13 @@ func (q *Queries) CreateAnalysisPackage(ctx context.Context, arg CreateAnalysisP
 		arg.InceptionMax,
 		arg.Status,
 		arg.UserID,
+		arg.ReferenceIndex,
+		arg.ReturnBasis,
+		arg.RiskFreeRate,
+		arg.McapMax,
+		arg.Filters,
+		arg.RollingWindowMonths,
+		arg.Outliers,
 	)
 	return err
 }
@@ -73,7 +89,8 @@ func (q *Queries) DeleteAnalysisPackage(ctx context.Context, arg DeleteAnalysisP
 
 const getAnalysisPackage = `-- name: GetAnalysisPackage :one
 SELECT id, name, created_at, interval, time_from, time_to,
-       hist_bins, hist_min, hist_max, mcap_min, inception_max, symbol_count, status, user_id
+       hist_bins, hist_min, hist_max, mcap_min, inception_max, symbol_count, status, user_id,
+       reference_index, return_basis, risk_free_rate, mcap_max, filters, rolling_window_months, outliers
 FROM analysis_packages
 WHERE id = $1 AND user_id = $2
 `
@@ -101,12 +118,31 @@ func (q *Queries) GetAnalysisPackage(ctx context.Context, arg GetAnalysisPackage
 		&i.SymbolCount,
 		&i.Status,
 		&i.UserID,
+		&i.ReferenceIndex,
+		&i.ReturnBasis,
+		&i.RiskFreeRate,
+		&i.McapMax,
+		&i.Filters,
+		&i.RollingWindowMonths,
+		&i.Outliers,
 	)
 	return i, err
 }
 
+const getAnalysisPackageOwner = `-- name: GetAnalysisPackageOwner :one
+SELECT user_id FROM analysis_packages WHERE id = $1
+`
+
+func (q *Queries) GetAnalysisPackageOwner(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, getAnalysisPackageOwner, id)
+	var user_id uuid.UUID
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
 const getAnalysisResults = `-- name: GetAnalysisResults :many
-SELECT ar.package_id, ar.ticker, ar.count, ar.mean, ar.stddev, ar.variance, ar.min, ar.max, s.inception
+SELECT ar.package_id, ar.ticker, ar.count, ar.mean, ar.stddev, ar.variance, ar.min, ar.max,
+       ar.beta, ar.correlation, ar.alpha, ar.metrics, ar.rolling, ar.outliers_removed, s.inception
 FROM analysis_results ar
 JOIN symbols s ON ar.ticker = s.ticker
 WHERE ar.package_id = $1
@@ -114,15 +150,21 @@ ORDER BY ar.mean DESC
 `
 
 type GetAnalysisResultsRow struct {
-	PackageID uuid.UUID    `json:"package_id"`
-	Ticker    string       `json:"ticker"`
-	Count     int32        `json:"count"`
-	Mean      float64      `json:"mean"`
-	Stddev    float64      `json:"stddev"`
-	Variance  float64      `json:"variance"`
-	Min       float64      `json:"min"`
-	Max       float64      `json:"max"`
-	Inception sql.NullTime `json:"inception"`
+	PackageID       uuid.UUID             `json:"package_id"`
+	Ticker          string                `json:"ticker"`
+	Count           int32                 `json:"count"`
+	Mean            float64               `json:"mean"`
+	Stddev          float64               `json:"stddev"`
+	Variance        float64               `json:"variance"`
+	Min             float64               `json:"min"`
+	Max             float64               `json:"max"`
+	Beta            sql.NullFloat64       `json:"beta"`
+	Correlation     sql.NullFloat64       `json:"correlation"`
+	Alpha           sql.NullFloat64       `json:"alpha"`
+	Metrics         pqtype.NullRawMessage `json:"metrics"`
+	Rolling         pqtype.NullRawMessage `json:"rolling"`
+	OutliersRemoved int32                 `json:"outliers_removed"`
+	Inception       sql.NullTime          `json:"inception"`
 }
 
 func (q *Queries) GetAnalysisResults(ctx context.Context, packageID uuid.UUID) ([]GetAnalysisResultsRow, error) {
@@ -143,6 +185,12 @@ func (q *Queries) GetAnalysisResults(ctx context.Context, packageID uuid.UUID) (
 			&i.Variance,
 			&i.Min,
 			&i.Max,
+			&i.Beta,
+			&i.Correlation,
+			&i.Alpha,
+			&i.Metrics,
+			&i.Rolling,
+			&i.OutliersRemoved,
 			&i.Inception,
 		); err != nil {
 			return nil, err
@@ -158,9 +206,58 @@ func (q *Queries) GetAnalysisResults(ctx context.Context, packageID uuid.UUID) (
 	return items, nil
 }
 
+const getAnalysisResultsFull = `-- name: GetAnalysisResultsFull :many
+SELECT package_id, ticker, count, mean, stddev, variance, min, max, histogram, chart_path,
+       beta, correlation, alpha, metrics, rolling, outliers_removed
+FROM analysis_results
+WHERE package_id = $1
+ORDER BY ticker
+`
+
+func (q *Queries) GetAnalysisResultsFull(ctx context.Context, packageID uuid.UUID) ([]AnalysisResult, error) {
+	rows, err := q.db.QueryContext(ctx, getAnalysisResultsFull, packageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AnalysisResult{}
+	for rows.Next() {
+		var i AnalysisResult
+		if err := rows.Scan(
+			&i.PackageID,
+			&i.Ticker,
+			&i.Count,
+			&i.Mean,
+			&i.Stddev,
+			&i.Variance,
+			&i.Min,
+			&i.Max,
+			&i.Histogram,
+			&i.ChartPath,
+			&i.Beta,
+			&i.Correlation,
+			&i.Alpha,
+			&i.Metrics,
+			&i.Rolling,
+			&i.OutliersRemoved,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listAnalysisPackages = `-- name: ListAnalysisPackages :many
 SELECT id, name, created_at, interval, time_from, time_to,
-       hist_bins, hist_min, hist_max, mcap_min, inception_max, symbol_count, status, user_id
+       hist_bins, hist_min, hist_max, mcap_min, inception_max, symbol_count, status, user_id,
+       reference_index, return_basis, risk_free_rate, mcap_max, filters, rolling_window_months, outliers
 FROM analysis_packages
 WHERE user_id = $1
 ORDER BY created_at DESC
@@ -190,6 +287,13 @@ func (q *Queries) ListAnalysisPackages(ctx context.Context, userID uuid.UUID) ([
 			&i.SymbolCount,
 			&i.Status,
 			&i.UserID,
+			&i.ReferenceIndex,
+			&i.ReturnBasis,
+			&i.RiskFreeRate,
+			&i.McapMax,
+			&i.Filters,
+			&i.RollingWindowMonths,
+			&i.Outliers,
 		); err != nil {
 			return nil, err
 		}
@@ -206,20 +310,27 @@ func (q *Queries) ListAnalysisPackages(ctx context.Context, userID uuid.UUID) ([
 
 const saveAnalysisResult = `-- name: SaveAnalysisResult :exec
 INSERT INTO analysis_results (
-    package_id, ticker, count, mean, stddev, variance, min, max, histogram
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    package_id, ticker, count, mean, stddev, variance, min, max, histogram,
+    beta, correlation, alpha, metrics, rolling, outliers_removed
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 `
 
 type SaveAnalysisResultParams struct {
-	PackageID uuid.UUID       `json:"package_id"`
-	Ticker    string          `json:"ticker"`
-	Count     int32           `json:"count"`
-	Mean      float64         `json:"mean"`
-	Stddev    float64         `json:"stddev"`
-	Variance  float64         `json:"variance"`
-	Min       float64         `json:"min"`
-	Max       float64         `json:"max"`
-	Histogram json.RawMessage `json:"histogram"`
+	PackageID       uuid.UUID             `json:"package_id"`
+	Ticker          string                `json:"ticker"`
+	Count           int32                 `json:"count"`
+	Mean            float64               `json:"mean"`
+	Stddev          float64               `json:"stddev"`
+	Variance        float64               `json:"variance"`
+	Min             float64               `json:"min"`
+	Max             float64               `json:"max"`
+	Histogram       json.RawMessage       `json:"histogram"`
+	Beta            sql.NullFloat64       `json:"beta"`
+	Correlation     sql.NullFloat64       `json:"correlation"`
+	Alpha           sql.NullFloat64       `json:"alpha"`
+	Metrics         pqtype.NullRawMessage `json:"metrics"`
+	Rolling         pqtype.NullRawMessage `json:"rolling"`
+	OutliersRemoved int32                 `json:"outliers_removed"`
 }
 
 func (q *Queries) SaveAnalysisResult(ctx context.Context, arg SaveAnalysisResultParams) error {
@@ -233,6 +344,12 @@ func (q *Queries) SaveAnalysisResult(ctx context.Context, arg SaveAnalysisResult
 		arg.Min,
 		arg.Max,
 		arg.Histogram,
+		arg.Beta,
+		arg.Correlation,
+		arg.Alpha,
+		arg.Metrics,
+		arg.Rolling,
+		arg.OutliersRemoved,
 	)
 	return err
 }
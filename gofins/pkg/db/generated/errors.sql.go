@@ -166,3 +166,28 @@ func (q *Queries) InsertError(ctx context.Context, arg InsertErrorParams) error
 	)
 	return err
 }
+
+const insertErrorReturningID = `-- name: InsertErrorReturningID :one
+INSERT INTO errors (source, error_type, message, details)
+VALUES ($1, $2, $3, $4)
+RETURNING id
+`
+
+type InsertErrorReturningIDParams struct {
+	Source    string         `json:"source"`
+	ErrorType string         `json:"error_type"`
+	Message   string         `json:"message"`
+	Details   sql.NullString `json:"details"`
+}
+
+func (q *Queries) InsertErrorReturningID(ctx context.Context, arg InsertErrorReturningIDParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, insertErrorReturningID,
+		arg.Source,
+		arg.ErrorType,
+		arg.Message,
+		arg.Details,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
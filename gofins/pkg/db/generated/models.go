@@ -13,34 +13,80 @@ import (
 	"github.com/sqlc-dev/pqtype"
 )
 
+type AnalysisJob struct {
+	PackageID   uuid.UUID       `json:"package_id"`
+	UserID      uuid.UUID       `json:"user_id"`
+	State       string          `json:"state"`
+	Attempts    int32           `json:"attempts"`
+	Processed   int32           `json:"processed"`
+	Total       int32           `json:"total"`
+	Results     int32           `json:"results"`
+	Rejections  json.RawMessage `json:"rejections"`
+	Error       sql.NullString  `json:"error"`
+	CreatedAt   time.Time       `json:"created_at"`
+	StartedAt   sql.NullTime    `json:"started_at"`
+	FinishedAt  sql.NullTime    `json:"finished_at"`
+	HeartbeatAt sql.NullTime    `json:"heartbeat_at"`
+}
+
 type AnalysisPackage struct {
-	ID           uuid.UUID     `json:"id"`
-	Name         string        `json:"name"`
-	CreatedAt    time.Time     `json:"created_at"`
-	Interval     string        `json:"interval"`
-	TimeFrom     time.Time     `json:"time_from"`
-	TimeTo       time.Time     `json:"time_to"`
-	HistBins     int32         `json:"hist_bins"`
-	HistMin      float64       `json:"hist_min"`
-	HistMax      float64       `json:"hist_max"`
-	McapMin      sql.NullInt64 `json:"mcap_min"`
-	InceptionMax sql.NullTime  `json:"inception_max"`
-	SymbolCount  sql.NullInt32 `json:"symbol_count"`
-	Status       string        `json:"status"`
-	UserID       uuid.UUID     `json:"user_id"`
+	ID                  uuid.UUID       `json:"id"`
+	Name                string          `json:"name"`
+	CreatedAt           time.Time       `json:"created_at"`
+	Interval            string          `json:"interval"`
+	TimeFrom            time.Time       `json:"time_from"`
+	TimeTo              time.Time       `json:"time_to"`
+	HistBins            int32           `json:"hist_bins"`
+	HistMin             float64         `json:"hist_min"`
+	HistMax             float64         `json:"hist_max"`
+	McapMin             sql.NullInt64   `json:"mcap_min"`
+	InceptionMax        sql.NullTime    `json:"inception_max"`
+	SymbolCount         sql.NullInt32   `json:"symbol_count"`
+	Status              string          `json:"status"`
+	UserID              uuid.UUID       `json:"user_id"`
+	ReferenceIndex      sql.NullString  `json:"reference_index"`
+	ReturnBasis         string          `json:"return_basis"`
+	RiskFreeRate        float64         `json:"risk_free_rate"`
+	McapMax             sql.NullInt64   `json:"mcap_max"`
+	Filters             json.RawMessage `json:"filters"`
+	RollingWindowMonths int32           `json:"rolling_window_months"`
+	Outliers            json.RawMessage `json:"outliers"`
 }
 
 type AnalysisResult struct {
-	PackageID uuid.UUID       `json:"package_id"`
-	Ticker    string          `json:"ticker"`
-	Count     int32           `json:"count"`
-	Mean      float64         `json:"mean"`
-	Stddev    float64         `json:"stddev"`
-	Variance  float64         `json:"variance"`
-	Min       float64         `json:"min"`
-	Max       float64         `json:"max"`
-	Histogram json.RawMessage `json:"histogram"`
-	ChartPath sql.NullString  `json:"chart_path"`
+	PackageID       uuid.UUID             `json:"package_id"`
+	Ticker          string                `json:"ticker"`
+	Count           int32                 `json:"count"`
+	Mean            float64               `json:"mean"`
+	Stddev          float64               `json:"stddev"`
+	Variance        float64               `json:"variance"`
+	Min             float64               `json:"min"`
+	Max             float64               `json:"max"`
+	Histogram       json.RawMessage       `json:"histogram"`
+	ChartPath       sql.NullString        `json:"chart_path"`
+	Beta            sql.NullFloat64       `json:"beta"`
+	Correlation     sql.NullFloat64       `json:"correlation"`
+	Alpha           sql.NullFloat64       `json:"alpha"`
+	Metrics         pqtype.NullRawMessage `json:"metrics"`
+	Rolling         pqtype.NullRawMessage `json:"rolling"`
+	OutliersRemoved int32                 `json:"outliers_removed"`
+}
+
+type ApiUsage struct {
+	Limiter   string    `json:"limiter"`
+	Day       time.Time `json:"day"`
+	Weight    int64     `json:"weight"`
+	Requests  int64     `json:"requests"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Backtest struct {
+	ID        uuid.UUID       `json:"id"`
+	UserID    uuid.UUID       `json:"user_id"`
+	Name      string          `json:"name"`
+	Config    json.RawMessage `json:"config"`
+	Result    json.RawMessage `json:"result"`
+	CreatedAt time.Time       `json:"created_at"`
 }
 
 type BatchUpdateLog struct {
@@ -54,6 +100,46 @@ type BatchUpdateLog struct {
 	ErrorMessage     sql.NullString `json:"error_message"`
 }
 
+type DailyPrice struct {
+	Date         time.Time       `json:"date"`
+	SymbolTicker string          `json:"symbol_ticker"`
+	Open         float64         `json:"open"`
+	High         float64         `json:"high"`
+	Low          float64         `json:"low"`
+	Close        float64         `json:"close"`
+	AdjClose     sql.NullFloat64 `json:"adj_close"`
+	Volume       sql.NullInt64   `json:"volume"`
+	OpenOrig     sql.NullFloat64 `json:"open_orig"`
+	HighOrig     sql.NullFloat64 `json:"high_orig"`
+	LowOrig      sql.NullFloat64 `json:"low_orig"`
+	CloseOrig    sql.NullFloat64 `json:"close_orig"`
+	AdjCloseOrig sql.NullFloat64 `json:"adj_close_orig"`
+}
+
+type DataIssue struct {
+	ID         int32          `json:"id"`
+	Ticker     string         `json:"ticker"`
+	Interval   sql.NullString `json:"interval"`
+	Kind       string         `json:"kind"`
+	Period     sql.NullTime   `json:"period"`
+	Severity   string         `json:"severity"`
+	Message    string         `json:"message"`
+	Details    sql.NullString `json:"details"`
+	Status     string         `json:"status"`
+	ErrorID    sql.NullInt32  `json:"error_id"`
+	FirstSeen  time.Time      `json:"first_seen"`
+	LastSeen   time.Time      `json:"last_seen"`
+	ResolvedAt sql.NullTime   `json:"resolved_at"`
+}
+
+type Dividend struct {
+	SymbolTicker string       `json:"symbol_ticker"`
+	Date         time.Time    `json:"date"`
+	Dividend     float64      `json:"dividend"`
+	AdjDividend  float64      `json:"adj_dividend"`
+	PaymentDate  sql.NullTime `json:"payment_date"`
+}
+
 type Error struct {
 	ID        int32          `json:"id"`
 	Timestamp sql.NullTime   `json:"timestamp"`
@@ -63,6 +149,11 @@ type Error struct {
 	Details   sql.NullString `json:"details"`
 }
 
+type JournalTicker struct {
+	JournalID int32  `json:"journal_id"`
+	Ticker    string `json:"ticker"`
+}
+
 type MonthlyPrice struct {
 	Date         time.Time       `json:"date"`
 	Close        sql.NullFloat64 `json:"close"`
@@ -77,6 +168,9 @@ type MonthlyPrice struct {
 	LowOrig      sql.NullFloat64 `json:"low_orig"`
 	AvgOrig      sql.NullFloat64 `json:"avg_orig"`
 	CloseOrig    sql.NullFloat64 `json:"close_orig"`
+	YoyTr        sql.NullFloat64 `json:"yoy_tr"`
+	YoyRef       sql.NullTime    `json:"yoy_ref"`
+	CloseTr      sql.NullFloat64 `json:"close_tr"`
 }
 
 type Note struct {
@@ -88,6 +182,73 @@ type Note struct {
 	Data    sql.NullString `json:"data"`
 }
 
+type Portfolio struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	CostBasis string    `json:"cost_basis"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PortfolioTransaction struct {
+	ID          uuid.UUID      `json:"id"`
+	PortfolioID uuid.UUID      `json:"portfolio_id"`
+	Ticker      string         `json:"ticker"`
+	Type        string         `json:"type"`
+	Date        time.Time      `json:"date"`
+	Quantity    float64        `json:"quantity"`
+	Price       float64        `json:"price"`
+	Amount      float64        `json:"amount"`
+	Fee         float64        `json:"fee"`
+	Currency    string         `json:"currency"`
+	FxRate      float64        `json:"fx_rate"`
+	Note        sql.NullString `json:"note"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+type SchedulerJob struct {
+	Name            string         `json:"name"`
+	Schedule        sql.NullString `json:"schedule"`
+	Paused          bool           `json:"paused"`
+	SkipNext        bool           `json:"skip_next"`
+	TriggeredAt     sql.NullTime   `json:"triggered_at"`
+	LastScheduledAt sql.NullTime   `json:"last_scheduled_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+type SchedulerRun struct {
+	ID          uuid.UUID      `json:"id"`
+	Job         string         `json:"job"`
+	Trigger     string         `json:"trigger"`
+	Status      string         `json:"status"`
+	Error       sql.NullString `json:"error"`
+	StartedAt   time.Time      `json:"started_at"`
+	FinishedAt  sql.NullTime   `json:"finished_at"`
+	Owner       uuid.NullUUID  `json:"owner"`
+	HeartbeatAt sql.NullTime   `json:"heartbeat_at"`
+}
+
+type ScoringProfile struct {
+	ID            uuid.UUID `json:"id"`
+	UserID        uuid.UUID `json:"user_id"`
+	Name          string    `json:"name"`
+	WeightMean    float64   `json:"weight_mean"`
+	WeightStddev  float64   `json:"weight_stddev"`
+	WeightMin     float64   `json:"weight_min"`
+	WeightMax     float64   `json:"weight_max"`
+	WeightBeta    float64   `json:"weight_beta"`
+	Normalization string    `json:"normalization"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type Split struct {
+	SymbolTicker string    `json:"symbol_ticker"`
+	Date         time.Time `json:"date"`
+	Numerator    float64   `json:"numerator"`
+	Denominator  float64   `json:"denominator"`
+}
+
 type Symbol struct {
 	Ticker            string                `json:"ticker"`
 	Exchange          sql.NullString        `json:"exchange"`
@@ -124,10 +285,15 @@ type User struct {
 	IsAdmin   bool      `json:"isAdmin,omitempty"`
 }
 
-type UserFavorite struct {
-	Ticker    string       `json:"ticker"`
-	CreatedAt sql.NullTime `json:"created_at"`
-	UserID    uuid.UUID    `json:"user_id"`
+type UserJournal struct {
+	ID        int32           `json:"id"`
+	UserID    uuid.UUID       `json:"user_id"`
+	Title     string          `json:"title"`
+	Content   string          `json:"content"`
+	Type      string          `json:"type"`
+	Tags      json.RawMessage `json:"tags"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
 }
 
 type UserRating struct {
@@ -139,6 +305,23 @@ type UserRating struct {
 	UserID    uuid.UUID      `json:"user_id"`
 }
 
+type Watchlist struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	IsDefault bool      `json:"is_default"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WatchlistEntry struct {
+	WatchlistID uuid.UUID       `json:"watchlist_id"`
+	Ticker      string          `json:"ticker"`
+	SortOrder   int32           `json:"sort_order"`
+	Note        sql.NullString  `json:"note"`
+	TargetPrice sql.NullFloat64 `json:"target_price"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
 type WeeklyPrice struct {
 	Date         time.Time       `json:"date"`
 	Close        sql.NullFloat64 `json:"close"`
@@ -153,4 +336,7 @@ type WeeklyPrice struct {
 	LowOrig      sql.NullFloat64 `json:"low_orig"`
 	AvgOrig      sql.NullFloat64 `json:"avg_orig"`
 	CloseOrig    sql.NullFloat64 `json:"close_orig"`
+	YoyTr        sql.NullFloat64 `json:"yoy_tr"`
+	YoyRef       sql.NullTime    `json:"yoy_ref"`
+	CloseTr      sql.NullFloat64 `json:"close_tr"`
 }
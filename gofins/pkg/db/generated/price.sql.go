@@ -8,7 +8,6 @@ package generated
 import (
 	"context"
 	"database/sql"
-	"time"
 
 	"github.com/lib/pq"
 )
@@ -32,53 +31,6 @@ func (q *Queries) CountStalePrices(ctx context.Context, arg CountStalePricesPara
 	return count, err
 }
 
-const getFilteredTickers = `-- name: GetFilteredTickers :many
-SELECT DISTINCT s.ticker FROM symbols s
-WHERE s.is_actively_trading = true
-  AND s.type = ANY($1::text[])
-  AND ($2::BIGINT IS NULL OR s.market_cap >= $2)
-  AND ($3::TIMESTAMP IS NULL OR s.inception <= $3)
-  AND s.last_price_status = $4
-  AND s.last_price_update IS NOT NULL
-  AND s.exchange NOT IN ('OTC','PINK', 'GREY', 'OTCQB', 'OTCQX')
-ORDER BY s.ticker
-`
-
-type GetFilteredTickersParams struct {
-	Column1         []string       `json:"column_1"`
-	Column2         int64          `json:"column_2"`
-	Column3         time.Time      `json:"column_3"`
-	LastPriceStatus sql.NullString `json:"last_price_status"`
-}
-
-func (q *Queries) GetFilteredTickers(ctx context.Context, arg GetFilteredTickersParams) ([]string, error) {
-	rows, err := q.db.QueryContext(ctx, getFilteredTickers,
-		pq.Array(arg.Column1),
-		arg.Column2,
-		arg.Column3,
-		arg.LastPriceStatus,
-	)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	items := []string{}
-	for rows.Next() {
-		var ticker string
-		if err := rows.Scan(&ticker); err != nil {
-			return nil, err
-		}
-		items = append(items, ticker)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
 const getOldestPriceDate = `-- name: GetOldestPriceDate :one
 SELECT MIN(date) 
 FROM monthly_prices 
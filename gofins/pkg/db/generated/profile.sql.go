@@ -8,6 +8,8 @@ package generated
 import (
 	"context"
 	"database/sql"
+
+	"github.com/lib/pq"
 )
 
 const countActivelyTrading = `-- name: CountActivelyTrading :one
@@ -110,6 +112,45 @@ func (q *Queries) GetAllTickers(ctx context.Context) ([]string, error) {
 	return items, nil
 }
 
+const getCurrentPrices = `-- name: GetCurrentPrices :many
+SELECT ticker, current_price_usd, current_price_time
+FROM symbols
+WHERE ticker = ANY($1::text[]) AND current_price_usd IS NOT NULL
+`
+
+type GetCurrentPricesRow struct {
+	Ticker           string          `json:"ticker"`
+	CurrentPriceUsd  sql.NullFloat64 `json:"current_price_usd"`
+	CurrentPriceTime sql.NullTime    `json:"current_price_time"`
+}
+
+func (q *Queries) GetCurrentPrices(ctx context.Context, dollar_1 []string) ([]GetCurrentPricesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getCurrentPrices, pq.Array(dollar_1))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetCurrentPricesRow{}
+	for rows.Next() {
+		var i GetCurrentPricesRow
+		if err := rows.Scan(
+			&i.Ticker,
+			&i.CurrentPriceUsd,
+			&i.CurrentPriceTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getOldestProfileUpdate = `-- name: GetOldestProfileUpdate :one
 SELECT MIN(last_profile_update) FROM symbols
 WHERE last_profile_update IS NOT NULL
@@ -166,10 +207,8 @@ SELECT s.ticker, s.exchange, s.last_price_update, s.last_profile_update,
        s.name, s.type, s.currency, s.sector, s.industry, s.country,
        s.description, s.website, s.isin, s.cik, s.inception, s.oldest_price, 
        s.is_actively_trading, s.market_cap, s.primary_listing,
-       s.ath12m, s.current_price_usd, s.current_price_time,
-       COALESCE(f.ticker IS NOT NULL, false) as is_favorite
+       s.ath12m, s.current_price_usd, s.current_price_time
 FROM symbols s
-LEFT JOIN user_favorites f ON s.ticker = f.ticker
 WHERE s.ticker = $1
 `
 
@@ -198,7 +237,6 @@ type GetSymbolRow struct {
 	Ath12m            sql.NullFloat64 `json:"ath12m"`
 	CurrentPriceUsd   sql.NullFloat64 `json:"current_price_usd"`
 	CurrentPriceTime  sql.NullTime    `json:"current_price_time"`
-	IsFavorite        interface{}     `json:"is_favorite"`
 }
 
 func (q *Queries) GetSymbol(ctx context.Context, ticker string) (GetSymbolRow, error) {
@@ -229,7 +267,6 @@ func (q *Queries) GetSymbol(ctx context.Context, ticker string) (GetSymbolRow, e
 		&i.Ath12m,
 		&i.CurrentPriceUsd,
 		&i.CurrentPriceTime,
-		&i.IsFavorite,
 	)
 	return i, err
 }
@@ -13,20 +13,6 @@ import (
 	"github.com/google/uuid"
 )
 
-const addFavorite = `-- name: AddFavorite :exec
-INSERT INTO user_favorites (user_id, ticker) VALUES ($1, $2)
-`
-
-type AddFavoriteParams struct {
-	UserID uuid.UUID `json:"user_id"`
-	Ticker string    `json:"ticker"`
-}
-
-func (q *Queries) AddFavorite(ctx context.Context, arg AddFavoriteParams) error {
-	_, err := q.db.ExecContext(ctx, addFavorite, arg.UserID, arg.Ticker)
-	return err
-}
-
 const addRating = `-- name: AddRating :one
 INSERT INTO user_ratings (user_id, ticker, rating, notes)
 VALUES ($1, $2, $3, $4)
@@ -124,15 +110,6 @@ func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
-const deleteUserFavorites = `-- name: DeleteUserFavorites :exec
-DELETE FROM user_favorites WHERE user_id = $1
-`
-
-func (q *Queries) DeleteUserFavorites(ctx context.Context, userID uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteUserFavorites, userID)
-	return err
-}
-
 const deleteUserRatings = `-- name: DeleteUserRatings :exec
 DELETE FROM user_ratings WHERE user_id = $1
 `
@@ -230,8 +207,55 @@ func (q *Queries) GetAllNotesChronological(ctx context.Context, userID uuid.UUID
 	return items, nil
 }
 
+const getAllRatings = `-- name: GetAllRatings :many
+SELECT id, ticker, rating, notes, created_at
+FROM user_ratings
+WHERE user_id = $1
+ORDER BY created_at DESC
+`
+
+type GetAllRatingsRow struct {
+	ID        int32          `json:"id"`
+	Ticker    string         `json:"ticker"`
+	Rating    int32          `json:"rating"`
+	Notes     sql.NullString `json:"notes"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+func (q *Queries) GetAllRatings(ctx context.Context, userID uuid.UUID) ([]GetAllRatingsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAllRatings, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetAllRatingsRow{}
+	for rows.Next() {
+		var i GetAllRatingsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Ticker,
+			&i.Rating,
+			&i.Notes,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFavorites = `-- name: GetFavorites :many
-SELECT ticker FROM user_favorites WHERE user_id = $1 ORDER BY created_at DESC
+SELECT e.ticker FROM watchlist_entries e
+JOIN watchlists w ON w.id = e.watchlist_id
+WHERE w.user_id = $1 AND w.is_default
+ORDER BY e.sort_order, e.created_at
 `
 
 func (q *Queries) GetFavorites(ctx context.Context, userID uuid.UUID) ([]string, error) {
@@ -376,8 +400,40 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 	return i, err
 }
 
+const importRating = `-- name: ImportRating :execrows
+INSERT INTO user_ratings (user_id, ticker, rating, notes, created_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, ticker, created_at) DO NOTHING
+`
+
+type ImportRatingParams struct {
+	UserID    uuid.UUID      `json:"user_id"`
+	Ticker    string         `json:"ticker"`
+	Rating    int32          `json:"rating"`
+	Notes     sql.NullString `json:"notes"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+func (q *Queries) ImportRating(ctx context.Context, arg ImportRatingParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, importRating,
+		arg.UserID,
+		arg.Ticker,
+		arg.Rating,
+		arg.Notes,
+		arg.CreatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const isFavorite = `-- name: IsFavorite :one
-SELECT EXISTS(SELECT 1 FROM user_favorites WHERE user_id = $1 AND ticker = $2)
+SELECT EXISTS(
+    SELECT 1 FROM watchlist_entries e
+    JOIN watchlists w ON w.id = e.watchlist_id
+    WHERE w.user_id = $1 AND w.is_default AND e.ticker = $2
+)
 `
 
 type IsFavoriteParams struct {
@@ -392,6 +448,68 @@ func (q *Queries) IsFavorite(ctx context.Context, arg IsFavoriteParams) (bool, e
 	return exists, err
 }
 
+const listTimelineRatings = `-- name: ListTimelineRatings :many
+SELECT id, ticker, rating, notes, created_at
+FROM user_ratings
+WHERE user_id = $1
+  AND ($2::text = '' OR ticker = $2)
+  AND ($3::timestamp IS NULL OR created_at >= $3)
+  AND ($4::timestamp IS NULL OR created_at < $4)
+ORDER BY created_at DESC
+LIMIT NULLIF($5::integer, 0)
+`
+
+type ListTimelineRatingsParams struct {
+	UserID  uuid.UUID    `json:"user_id"`
+	Column2 string       `json:"column_2"`
+	Column3 sql.NullTime `json:"column_3"`
+	Column4 sql.NullTime `json:"column_4"`
+	Column5 int32        `json:"column_5"`
+}
+
+type ListTimelineRatingsRow struct {
+	ID        int32          `json:"id"`
+	Ticker    string         `json:"ticker"`
+	Rating    int32          `json:"rating"`
+	Notes     sql.NullString `json:"notes"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+func (q *Queries) ListTimelineRatings(ctx context.Context, arg ListTimelineRatingsParams) ([]ListTimelineRatingsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTimelineRatings,
+		arg.UserID,
+		arg.Column2,
+		arg.Column3,
+		arg.Column4,
+		arg.Column5,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTimelineRatingsRow{}
+	for rows.Next() {
+		var i ListTimelineRatingsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Ticker,
+			&i.Rating,
+			&i.Notes,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUsers = `-- name: ListUsers :many
 SELECT id, name, created_at, is_admin
 FROM users
@@ -426,18 +544,32 @@ func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
 	return items, nil
 }
 
-const removeFavorite = `-- name: RemoveFavorite :exec
-DELETE FROM user_favorites WHERE user_id = $1 AND ticker = $2
+const updateRatingAt = `-- name: UpdateRatingAt :execrows
+UPDATE user_ratings
+SET rating = $1, notes = $2
+WHERE user_id = $3 AND ticker = $4 AND created_at = $5
 `
 
-type RemoveFavoriteParams struct {
-	UserID uuid.UUID `json:"user_id"`
-	Ticker string    `json:"ticker"`
+type UpdateRatingAtParams struct {
+	Rating    int32          `json:"rating"`
+	Notes     sql.NullString `json:"notes"`
+	UserID    uuid.UUID      `json:"user_id"`
+	Ticker    string         `json:"ticker"`
+	CreatedAt time.Time      `json:"created_at"`
 }
 
-func (q *Queries) RemoveFavorite(ctx context.Context, arg RemoveFavoriteParams) error {
-	_, err := q.db.ExecContext(ctx, removeFavorite, arg.UserID, arg.Ticker)
-	return err
+func (q *Queries) UpdateRatingAt(ctx context.Context, arg UpdateRatingAtParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateRatingAt,
+		arg.Rating,
+		arg.Notes,
+		arg.UserID,
+		arg.Ticker,
+		arg.CreatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 const updateUserAdmin = `-- name: UpdateUserAdmin :one
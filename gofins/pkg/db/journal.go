@@ -0,0 +1,348 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db/generated"
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+)
+
+// Timeline entry kinds
+const (
+	TimelineKindRating  = "rating"
+	TimelineKindJournal = "journal"
+)
+
+// TimelineEntry is one item of the unified research timeline: either a rating or a journal entry
+type TimelineEntry struct {
+	Kind    string              `json:"kind"` // "rating" or "journal"
+	Date    time.Time           `json:"date"`
+	Tickers []string            `json:"tickers"`
+	Rating  *UserRating         `json:"rating,omitempty"`
+	Journal *types.JournalEntry `json:"journal,omitempty"`
+}
+
+// JournalFilter selects journal entries, empty fields match all. To is exclusive.
+type JournalFilter struct {
+	Type   string
+	Tag    string
+	Ticker string
+	From   *time.Time
+	To     *time.Time
+	Limit  int // 0 = unlimited
+}
+
+// TimelineFilter selects timeline entries, empty fields match all. To is exclusive.
+type TimelineFilter struct {
+	Kind   string // TimelineKindRating or TimelineKindJournal
+	Ticker string
+	From   *time.Time
+	To     *time.Time
+	Limit  int // 0 = unlimited
+}
+
+// normalizeTickers upper-cases, trims and de-duplicates a ticker list
+func normalizeTickers(tickers []string) []string {
+	seen := make(map[string]bool, len(tickers))
+	result := make([]string, 0, len(tickers))
+	for _, t := range tickers {
+		t = strings.ToUpper(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		result = append(result, t)
+	}
+	return result
+}
+
+func journalEntryFromRow(id int32, title, content, entryType string, tags json.RawMessage, createdAt, updatedAt time.Time, tickers []string) types.JournalEntry {
+	var parsedTags []string
+	_ = json.Unmarshal(tags, &parsedTags)
+	if parsedTags == nil {
+		parsedTags = []string{}
+	}
+	if tickers == nil {
+		tickers = []string{}
+	}
+	return types.JournalEntry{
+		ID:        int(id),
+		Title:     title,
+		Content:   content,
+		Type:      entryType,
+		Tags:      parsedTags,
+		Tickers:   tickers,
+		CreatedAt: createdAt,
+		UpdatedAt: updatedAt,
+	}
+}
+
+func marshalTags(tags []string) (json.RawMessage, error) {
+	if tags == nil {
+		tags = []string{}
+	}
+	return json.Marshal(tags)
+}
+
+// CreateJournalEntry stores a new journal entry and links it to its tickers
+func CreateJournalEntry(ctx context.Context, userID uuid.UUID, entry types.JournalEntry) (*types.JournalEntry, error) {
+	tags, err := marshalTags(entry.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := Db().conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	q := genQ().WithTx(tx)
+
+	created, err := q.CreateJournalEntry(ctx, generated.CreateJournalEntryParams{
+		UserID:  userID,
+		Title:   entry.Title,
+		Content: entry.Content,
+		Type:    entry.Type,
+		Tags:    tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ticker := range normalizeTickers(entry.Tickers) {
+		if err := q.AddJournalTicker(ctx, generated.AddJournalTickerParams{JournalID: created.ID, Ticker: ticker}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return GetJournalEntry(ctx, userID, int(created.ID))
+}
+
+// UpdateJournalEntry replaces title, content, type and tags of an entry.
+// Linked tickers are replaced as well unless entry.Tickers is nil.
+// Returns nil if the entry doesn't exist or belongs to another user.
+func UpdateJournalEntry(ctx context.Context, userID uuid.UUID, entry types.JournalEntry) (*types.JournalEntry, error) {
+	tags, err := marshalTags(entry.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := Db().conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	q := genQ().WithTx(tx)
+
+	_, err = q.UpdateJournalEntry(ctx, generated.UpdateJournalEntryParams{
+		Title:   entry.Title,
+		Content: entry.Content,
+		Type:    entry.Type,
+		Tags:    tags,
+		ID:      int32(entry.ID),
+		UserID:  userID,
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Tickers != nil {
+		if err := q.DeleteJournalTickers(ctx, int32(entry.ID)); err != nil {
+			return nil, err
+		}
+		for _, ticker := range normalizeTickers(entry.Tickers) {
+			if err := q.AddJournalTicker(ctx, generated.AddJournalTickerParams{JournalID: int32(entry.ID), Ticker: ticker}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return GetJournalEntry(ctx, userID, entry.ID)
+}
+
+// DeleteJournalEntry deletes a journal entry (CASCADE removes ticker links)
+// Returns false if the entry doesn't exist or belongs to another user
+func DeleteJournalEntry(ctx context.Context, userID uuid.UUID, id int) (bool, error) {
+	affected, err := genQ().DeleteJournalEntry(ctx, generated.DeleteJournalEntryParams{
+		ID:     int32(id),
+		UserID: userID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// GetJournalEntry retrieves a journal entry with its linked tickers
+func GetJournalEntry(ctx context.Context, userID uuid.UUID, id int) (*types.JournalEntry, error) {
+	row, err := genQ().GetJournalEntry(ctx, generated.GetJournalEntryParams{
+		ID:     int32(id),
+		UserID: userID,
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	entry := journalEntryFromRow(row.ID, row.Title, row.Content, row.Type, row.Tags, row.CreatedAt, row.UpdatedAt, row.Tickers)
+	return &entry, nil
+}
+
+// ListJournalEntries returns the journal entries of a user matching the filter, newest first
+func ListJournalEntries(ctx context.Context, userID uuid.UUID, filter JournalFilter) ([]types.JournalEntry, error) {
+	rows, err := genQ().ListJournalEntries(ctx, generated.ListJournalEntriesParams{
+		UserID:  userID,
+		Column2: filter.Type,
+		Column3: filter.Tag,
+		Column4: strings.ToUpper(filter.Ticker),
+		Column5: f.MaybeTimeToNullTime(filter.From),
+		Column6: f.MaybeTimeToNullTime(filter.To),
+		Column7: int32(filter.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]types.JournalEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = journalEntryFromRow(row.ID, row.Title, row.Content, row.Type, row.Tags, row.CreatedAt, row.UpdatedAt, row.Tickers)
+	}
+	return entries, nil
+}
+
+// ListJournalEntriesByTicker returns all journal entries of a user linked to a ticker, newest first
+func ListJournalEntriesByTicker(ctx context.Context, userID uuid.UUID, ticker string) ([]types.JournalEntry, error) {
+	rows, err := genQ().ListJournalEntriesByTicker(ctx, generated.ListJournalEntriesByTickerParams{
+		UserID: userID,
+		Ticker: strings.ToUpper(ticker),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]types.JournalEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = journalEntryFromRow(row.ID, row.Title, row.Content, row.Type, row.Tags, row.CreatedAt, row.UpdatedAt, row.Tickers)
+	}
+	return entries, nil
+}
+
+// LinkJournalTickers links additional tickers to a journal entry
+// Returns nil if the entry doesn't exist or belongs to another user
+func LinkJournalTickers(ctx context.Context, userID uuid.UUID, id int, tickers []string) (*types.JournalEntry, error) {
+	entry, err := GetJournalEntry(ctx, userID, id)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	for _, ticker := range normalizeTickers(tickers) {
+		if err := genQ().AddJournalTicker(ctx, generated.AddJournalTickerParams{JournalID: int32(id), Ticker: ticker}); err != nil {
+			return nil, err
+		}
+	}
+
+	return GetJournalEntry(ctx, userID, id)
+}
+
+// UnlinkJournalTickers removes ticker links from a journal entry
+// Returns nil if the entry doesn't exist or belongs to another user
+func UnlinkJournalTickers(ctx context.Context, userID uuid.UUID, id int, tickers []string) (*types.JournalEntry, error) {
+	entry, err := GetJournalEntry(ctx, userID, id)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	for _, ticker := range normalizeTickers(tickers) {
+		if err := genQ().RemoveJournalTicker(ctx, generated.RemoveJournalTickerParams{JournalID: int32(id), Ticker: ticker}); err != nil {
+			return nil, err
+		}
+	}
+
+	return GetJournalEntry(ctx, userID, id)
+}
+
+// GetTimeline merges the rating history and the journal entries of a user matching the filter, newest first
+func GetTimeline(ctx context.Context, userID uuid.UUID, filter TimelineFilter) ([]TimelineEntry, error) {
+	var ratings []generated.ListTimelineRatingsRow
+	if filter.Kind == "" || filter.Kind == TimelineKindRating {
+		var err error
+		ratings, err = genQ().ListTimelineRatings(ctx, generated.ListTimelineRatingsParams{
+			UserID:  userID,
+			Column2: strings.ToUpper(filter.Ticker),
+			Column3: f.MaybeTimeToNullTime(filter.From),
+			Column4: f.MaybeTimeToNullTime(filter.To),
+			Column5: int32(filter.Limit),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var entries []types.JournalEntry
+	if filter.Kind == "" || filter.Kind == TimelineKindJournal {
+		var err error
+		entries, err = ListJournalEntries(ctx, userID, JournalFilter{
+			Ticker: filter.Ticker,
+			From:   filter.From,
+			To:     filter.To,
+			Limit:  filter.Limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	timeline := make([]TimelineEntry, 0, len(ratings)+len(entries))
+	for _, r := range ratings {
+		timeline = append(timeline, TimelineEntry{
+			Kind:    TimelineKindRating,
+			Date:    r.CreatedAt,
+			Tickers: []string{r.Ticker},
+			Rating: &UserRating{
+				ID:        int(r.ID),
+				Ticker:    r.Ticker,
+				Rating:    int(r.Rating),
+				Notes:     f.NullStringToMaybeString(r.Notes),
+				CreatedAt: r.CreatedAt,
+			},
+		})
+	}
+	for i := range entries {
+		timeline = append(timeline, TimelineEntry{
+			Kind:    TimelineKindJournal,
+			Date:    entries[i].CreatedAt,
+			Tickers: entries[i].Tickers,
+			Journal: &entries[i],
+		})
+	}
+
+	// Each query returns at most Limit entries, so the newest Limit of both are the page
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].Date.After(timeline[j].Date)
+	})
+	if filter.Limit > 0 && len(timeline) > filter.Limit {
+		timeline = timeline[:filter.Limit]
+	}
+	return timeline, nil
+}
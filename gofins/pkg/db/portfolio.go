@@ -0,0 +1,263 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/flocko-motion/gofins/pkg/db/generated"
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+)
+
+func portfolioFromGen(p generated.Portfolio) types.Portfolio {
+	return types.Portfolio{
+		ID:        p.ID,
+		UserID:    p.UserID,
+		Name:      p.Name,
+		CostBasis: p.CostBasis,
+		CreatedAt: p.CreatedAt,
+	}
+}
+
+// ListPortfolios returns all portfolios of a user, ordered by name
+func ListPortfolios(ctx context.Context, userID uuid.UUID) ([]types.Portfolio, error) {
+	genPortfolios, err := genQ().ListPortfolios(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	portfolios := make([]types.Portfolio, len(genPortfolios))
+	for i, p := range genPortfolios {
+		portfolios[i] = portfolioFromGen(p)
+	}
+	return portfolios, nil
+}
+
+// GetPortfolio retrieves a portfolio of a user, nil if it doesn't exist
+func GetPortfolio(ctx context.Context, userID, id uuid.UUID) (*types.Portfolio, error) {
+	genPortfolio, err := genQ().GetPortfolio(ctx, generated.GetPortfolioParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	portfolio := portfolioFromGen(genPortfolio)
+	return &portfolio, nil
+}
+
+// CreatePortfolio stores a new portfolio for a user
+func CreatePortfolio(ctx context.Context, userID uuid.UUID, name, costBasis string) (*types.Portfolio, error) {
+	genPortfolio, err := genQ().CreatePortfolio(ctx, generated.CreatePortfolioParams{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      name,
+		CostBasis: costBasis,
+	})
+	if err != nil {
+		return nil, err
+	}
+	created := portfolioFromGen(genPortfolio)
+	return &created, nil
+}
+
+// UpdatePortfolio replaces name and cost basis method of a user's portfolio
+// Returns nil if the portfolio doesn't exist or belongs to another user
+func UpdatePortfolio(ctx context.Context, userID uuid.UUID, portfolio types.Portfolio) (*types.Portfolio, error) {
+	genPortfolio, err := genQ().UpdatePortfolio(ctx, generated.UpdatePortfolioParams{
+		Name:      portfolio.Name,
+		CostBasis: portfolio.CostBasis,
+		ID:        portfolio.ID,
+		UserID:    userID,
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	updated := portfolioFromGen(genPortfolio)
+	return &updated, nil
+}
+
+// DeletePortfolio removes a portfolio of a user with all its transactions. Returns false if it didn't exist.
+func DeletePortfolio(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	rows, err := genQ().DeletePortfolio(ctx, generated.DeletePortfolioParams{
+		ID:     id,
+		UserID: userID,
+	})
+	return rows > 0, err
+}
+
+func transactionFromGen(t generated.PortfolioTransaction) types.Transaction {
+	return types.Transaction{
+		ID:          t.ID,
+		PortfolioID: t.PortfolioID,
+		Ticker:      t.Ticker,
+		Type:        t.Type,
+		Date:        t.Date,
+		Quantity:    t.Quantity,
+		Price:       t.Price,
+		Amount:      t.Amount,
+		Fee:         t.Fee,
+		Currency:    t.Currency,
+		FxRate:      t.FxRate,
+		Note:        f.NullStringToMaybeString(t.Note),
+		CreatedAt:   t.CreatedAt,
+	}
+}
+
+// ListPortfolioTransactions returns the transactions of a portfolio ordered by date.
+// The caller is responsible for checking that the portfolio belongs to the user.
+func ListPortfolioTransactions(ctx context.Context, portfolioID uuid.UUID) ([]types.Transaction, error) {
+	return listPortfolioTransactions(ctx, genQ(), portfolioID)
+}
+
+func listPortfolioTransactions(ctx context.Context, q *generated.Queries, portfolioID uuid.UUID) ([]types.Transaction, error) {
+	rows, err := q.ListPortfolioTransactions(ctx, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]types.Transaction, len(rows))
+	for i, row := range rows {
+		transactions[i] = transactionFromGen(row)
+	}
+	return transactions, nil
+}
+
+func createPortfolioTransactions(ctx context.Context, q *generated.Queries, transactions []types.Transaction) error {
+	for _, t := range transactions {
+		if err := q.CreatePortfolioTransaction(ctx, generated.CreatePortfolioTransactionParams{
+			ID:          t.ID,
+			PortfolioID: t.PortfolioID,
+			Ticker:      t.Ticker,
+			Type:        t.Type,
+			Date:        t.Date,
+			Quantity:    t.Quantity,
+			Price:       t.Price,
+			Amount:      t.Amount,
+			Fee:         t.Fee,
+			Currency:    t.Currency,
+			FxRate:      t.FxRate,
+			Note:        f.MaybeStringToNullString(t.Note),
+			CreatedAt:   t.CreatedAt,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddPortfolioTransactions stores transactions of a portfolio in a single database transaction, so imports
+// are all or nothing. The portfolio is locked while check validates the new transactions against the
+// existing ones, so concurrent changes can't invalidate the check before the transactions are stored.
+func AddPortfolioTransactions(ctx context.Context, portfolioID uuid.UUID, transactions []types.Transaction, check func(existing []types.Transaction) error) error {
+	tx, err := Db().conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := genQ().WithTx(tx)
+	if _, err := q.LockPortfolio(ctx, portfolioID); err != nil {
+		return err
+	}
+	existing, err := listPortfolioTransactions(ctx, q, portfolioID)
+	if err != nil {
+		return err
+	}
+	if err := check(existing); err != nil {
+		return err
+	}
+	if err := createPortfolioTransactions(ctx, q, transactions); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeletePortfolioTransaction removes a transaction of a portfolio if check accepts the remaining ones,
+// with the portfolio locked as in AddPortfolioTransactions. Returns false if the transaction didn't exist.
+func DeletePortfolioTransaction(ctx context.Context, portfolioID, id uuid.UUID, check func(remaining []types.Transaction) error) (bool, error) {
+	tx, err := Db().conn.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	q := genQ().WithTx(tx)
+	if _, err := q.LockPortfolio(ctx, portfolioID); err != nil {
+		return false, err
+	}
+	existing, err := listPortfolioTransactions(ctx, q, portfolioID)
+	if err != nil {
+		return false, err
+	}
+	remaining := make([]types.Transaction, 0, len(existing))
+	for _, t := range existing {
+		if t.ID != id {
+			remaining = append(remaining, t)
+		}
+	}
+	if len(remaining) == len(existing) {
+		return false, nil
+	}
+	if err := check(remaining); err != nil {
+		return false, err
+	}
+
+	rows, err := q.DeletePortfolioTransaction(ctx, generated.DeletePortfolioTransactionParams{
+		ID:          id,
+		PortfolioID: portfolioID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, tx.Commit()
+}
+
+// ReplacePortfolioTransactions replaces all transactions of a portfolio in a single database transaction
+func ReplacePortfolioTransactions(ctx context.Context, portfolioID uuid.UUID, transactions []types.Transaction) error {
+	tx, err := Db().conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := genQ().WithTx(tx)
+	if err := q.DeletePortfolioTransactions(ctx, portfolioID); err != nil {
+		return err
+	}
+	if err := createPortfolioTransactions(ctx, q, transactions); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ImportPortfolio stores a portfolio from a backup with its ID, creation time and transactions
+func ImportPortfolio(ctx context.Context, portfolio types.Portfolio, transactions []types.Transaction) error {
+	tx, err := Db().conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := genQ().WithTx(tx)
+	if err := q.ImportPortfolio(ctx, generated.ImportPortfolioParams{
+		ID:        portfolio.ID,
+		UserID:    portfolio.UserID,
+		Name:      portfolio.Name,
+		CostBasis: portfolio.CostBasis,
+		CreatedAt: portfolio.CreatedAt,
+	}); err != nil {
+		return err
+	}
+	if err := createPortfolioTransactions(ctx, q, transactions); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
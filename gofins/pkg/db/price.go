@@ -4,11 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/flocko-motion/gofins/pkg/db/generated"
 	"github.com/flocko-motion/gofins/pkg/f"
 	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
 
@@ -39,8 +41,8 @@ func AppendSinglePrice(price types.PriceData, interval types.PriceInterval) erro
 	tableName := string(interval) + "_prices"
 
 	query := fmt.Sprintf(`
-		INSERT INTO %s (symbol_ticker, date, open, high, low, avg, close, yoy, open_orig, high_orig, low_orig, avg_orig, close_orig)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		INSERT INTO %s (symbol_ticker, date, open, high, low, avg, close, yoy, open_orig, high_orig, low_orig, avg_orig, close_orig, yoy_tr, yoy_ref, close_tr)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		ON CONFLICT (symbol_ticker, date) DO UPDATE SET
 			open = EXCLUDED.open,
 			high = EXCLUDED.high,
@@ -52,13 +54,16 @@ func AppendSinglePrice(price types.PriceData, interval types.PriceInterval) erro
 			high_orig = EXCLUDED.high_orig,
 			low_orig = EXCLUDED.low_orig,
 			avg_orig = EXCLUDED.avg_orig,
-			close_orig = EXCLUDED.close_orig
+			close_orig = EXCLUDED.close_orig,
+			yoy_tr = EXCLUDED.yoy_tr,
+			yoy_ref = EXCLUDED.yoy_ref,
+			close_tr = EXCLUDED.close_tr
 	`, tableName)
 
 	_, err := db.conn.Exec(query,
 		price.SymbolTicker, price.Date, price.Open, price.High, price.Low,
 		price.Avg, price.Close, price.YoY, price.OpenOrig, price.HighOrig,
-		price.LowOrig, price.AvgOrig, price.CloseOrig)
+		price.LowOrig, price.AvgOrig, price.CloseOrig, price.YoYTR, price.YoYRef, price.CloseTR)
 
 	return err
 }
@@ -80,22 +85,22 @@ func PutMonthlyPrices(prices []types.PriceData) error {
 		}
 		chunk := prices[i:end]
 
-		// Build VALUES list: ($1,$2,...), ($13,$14,...), ...
+		// Build VALUES list: ($1,$2,...), ($17,$18,...), ...
 		valueStrings := make([]string, 0, len(chunk))
-		valueArgs := make([]interface{}, 0, len(chunk)*13)
+		valueArgs := make([]interface{}, 0, len(chunk)*16)
 
 		for idx, p := range chunk {
-			paramOffset := idx * 13
-			valueStrings = append(valueStrings, fmt.Sprintf("($%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d)",
+			paramOffset := idx * 16
+			valueStrings = append(valueStrings, fmt.Sprintf("($%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d)",
 				paramOffset+1, paramOffset+2, paramOffset+3, paramOffset+4,
 				paramOffset+5, paramOffset+6, paramOffset+7, paramOffset+8,
-				paramOffset+9, paramOffset+10, paramOffset+11, paramOffset+12, paramOffset+13))
+				paramOffset+9, paramOffset+10, paramOffset+11, paramOffset+12, paramOffset+13, paramOffset+14, paramOffset+15, paramOffset+16))
 			valueArgs = append(valueArgs, p.Date, p.Open, p.High, p.Low, p.Avg, p.Close, p.YoY, p.SymbolTicker,
-				p.OpenOrig, p.HighOrig, p.LowOrig, p.AvgOrig, p.CloseOrig)
+				p.OpenOrig, p.HighOrig, p.LowOrig, p.AvgOrig, p.CloseOrig, p.YoYTR, p.YoYRef, p.CloseTR)
 		}
 
 		query := fmt.Sprintf(`
-			INSERT INTO monthly_prices (date, open, high, low, avg, close, yoy, symbol_ticker, open_orig, high_orig, low_orig, avg_orig, close_orig)
+			INSERT INTO monthly_prices (date, open, high, low, avg, close, yoy, symbol_ticker, open_orig, high_orig, low_orig, avg_orig, close_orig, yoy_tr, yoy_ref, close_tr)
 			VALUES %s
 			ON CONFLICT (date, symbol_ticker) DO UPDATE SET
 				open = EXCLUDED.open,
@@ -108,7 +113,10 @@ func PutMonthlyPrices(prices []types.PriceData) error {
 				high_orig = EXCLUDED.high_orig,
 				low_orig = EXCLUDED.low_orig,
 				avg_orig = EXCLUDED.avg_orig,
-				close_orig = EXCLUDED.close_orig
+				close_orig = EXCLUDED.close_orig,
+				yoy_tr = EXCLUDED.yoy_tr,
+				yoy_ref = EXCLUDED.yoy_ref,
+				close_tr = EXCLUDED.close_tr
 		`, joinStrings(valueStrings, ","))
 
 		_, err := db.conn.Exec(query, valueArgs...)
@@ -148,22 +156,22 @@ func PutWeeklyPrices(prices []types.PriceData) error {
 		}
 		chunk := prices[i:end]
 
-		// Build VALUES list: ($1,$2,...), ($13,$14,...), ...
+		// Build VALUES list: ($1,$2,...), ($17,$18,...), ...
 		valueStrings := make([]string, 0, len(chunk))
-		valueArgs := make([]interface{}, 0, len(chunk)*13)
+		valueArgs := make([]interface{}, 0, len(chunk)*16)
 
 		for idx, p := range chunk {
-			paramOffset := idx * 13
-			valueStrings = append(valueStrings, fmt.Sprintf("($%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d)",
+			paramOffset := idx * 16
+			valueStrings = append(valueStrings, fmt.Sprintf("($%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d)",
 				paramOffset+1, paramOffset+2, paramOffset+3, paramOffset+4,
 				paramOffset+5, paramOffset+6, paramOffset+7, paramOffset+8,
-				paramOffset+9, paramOffset+10, paramOffset+11, paramOffset+12, paramOffset+13))
+				paramOffset+9, paramOffset+10, paramOffset+11, paramOffset+12, paramOffset+13, paramOffset+14, paramOffset+15, paramOffset+16))
 			valueArgs = append(valueArgs, p.Date, p.Open, p.High, p.Low, p.Avg, p.Close, p.YoY, p.SymbolTicker,
-				p.OpenOrig, p.HighOrig, p.LowOrig, p.AvgOrig, p.CloseOrig)
+				p.OpenOrig, p.HighOrig, p.LowOrig, p.AvgOrig, p.CloseOrig, p.YoYTR, p.YoYRef, p.CloseTR)
 		}
 
 		query := fmt.Sprintf(`
-			INSERT INTO weekly_prices (date, open, high, low, avg, close, yoy, symbol_ticker, open_orig, high_orig, low_orig, avg_orig, close_orig)
+			INSERT INTO weekly_prices (date, open, high, low, avg, close, yoy, symbol_ticker, open_orig, high_orig, low_orig, avg_orig, close_orig, yoy_tr, yoy_ref, close_tr)
 			VALUES %s
 			ON CONFLICT (date, symbol_ticker) DO UPDATE SET
 				open = EXCLUDED.open,
@@ -176,7 +184,10 @@ func PutWeeklyPrices(prices []types.PriceData) error {
 				high_orig = EXCLUDED.high_orig,
 				low_orig = EXCLUDED.low_orig,
 				avg_orig = EXCLUDED.avg_orig,
-				close_orig = EXCLUDED.close_orig
+				close_orig = EXCLUDED.close_orig,
+				yoy_tr = EXCLUDED.yoy_tr,
+				yoy_ref = EXCLUDED.yoy_ref,
+				close_tr = EXCLUDED.close_tr
 		`, joinStrings(valueStrings, ","))
 
 		_, err := db.conn.Exec(query, valueArgs...)
@@ -188,13 +199,96 @@ func PutWeeklyPrices(prices []types.PriceData) error {
 	return nil
 }
 
+// PutDailyPrices batch inserts daily bars using bulk INSERT
+func PutDailyPrices(prices []types.DailyPrice) error {
+	db := Db()
+	if len(prices) == 0 {
+		return nil
+	}
+
+	// Split into chunks of 1000 to avoid parameter limits
+	chunkSize := 1000
+	for i := 0; i < len(prices); i += chunkSize {
+		end := i + chunkSize
+		if end > len(prices) {
+			end = len(prices)
+		}
+		chunk := prices[i:end]
+
+		valueStrings := make([]string, 0, len(chunk))
+		valueArgs := make([]interface{}, 0, len(chunk)*13)
+
+		for idx, p := range chunk {
+			paramOffset := idx * 13
+			valueStrings = append(valueStrings, fmt.Sprintf("($%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d)",
+				paramOffset+1, paramOffset+2, paramOffset+3, paramOffset+4,
+				paramOffset+5, paramOffset+6, paramOffset+7, paramOffset+8,
+				paramOffset+9, paramOffset+10, paramOffset+11, paramOffset+12, paramOffset+13))
+			valueArgs = append(valueArgs, p.Date, p.SymbolTicker, p.Open, p.High, p.Low, p.Close, p.AdjClose, p.Volume,
+				p.OpenOrig, p.HighOrig, p.LowOrig, p.CloseOrig, p.AdjCloseOrig)
+		}
+
+		query := fmt.Sprintf(`
+			INSERT INTO daily_prices (date, symbol_ticker, open, high, low, close, adj_close, volume, open_orig, high_orig, low_orig, close_orig, adj_close_orig)
+			VALUES %s
+			ON CONFLICT (symbol_ticker, date) DO UPDATE SET
+				open = EXCLUDED.open,
+				high = EXCLUDED.high,
+				low = EXCLUDED.low,
+				close = EXCLUDED.close,
+				adj_close = EXCLUDED.adj_close,
+				volume = EXCLUDED.volume,
+				open_orig = EXCLUDED.open_orig,
+				high_orig = EXCLUDED.high_orig,
+				low_orig = EXCLUDED.low_orig,
+				close_orig = EXCLUDED.close_orig,
+				adj_close_orig = EXCLUDED.adj_close_orig
+		`, joinStrings(valueStrings, ","))
+
+		_, err := db.conn.Exec(query, valueArgs...)
+		if err != nil {
+			return fmt.Errorf("failed to batch insert daily prices: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetDailyPrices retrieves daily bars for a symbol
+func GetDailyPrices(ticker string, from, to time.Time) ([]types.DailyPrice, error) {
+	db := Db()
+
+	rows, err := db.conn.Query(`
+		SELECT date, symbol_ticker, open, high, low, close, adj_close, volume, open_orig, high_orig, low_orig, close_orig, adj_close_orig
+		FROM daily_prices
+		WHERE symbol_ticker = $1 AND date >= $2 AND date <= $3
+		ORDER BY date ASC
+	`, ticker, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prices []types.DailyPrice
+	for rows.Next() {
+		var p types.DailyPrice
+		if err := rows.Scan(&p.Date, &p.SymbolTicker, &p.Open, &p.High, &p.Low, &p.Close, &p.AdjClose, &p.Volume,
+			&p.OpenOrig, &p.HighOrig, &p.LowOrig, &p.CloseOrig, &p.AdjCloseOrig); err != nil {
+			return nil, err
+		}
+		prices = append(prices, p)
+	}
+
+	return prices, rows.Err()
+}
+
 // GetPrices retrieves price data for a symbol at the specified interval
 func GetPrices(ticker string, from, to time.Time, interval types.PriceInterval) ([]types.PriceData, error) {
 	db := Db()
 	tableName := string(interval) + "_prices"
 
 	query := fmt.Sprintf(`
-		SELECT date, open, high, low, avg, close, yoy, symbol_ticker, open_orig, high_orig, low_orig, avg_orig, close_orig
+		SELECT date, open, high, low, avg, close, yoy, symbol_ticker, open_orig, high_orig, low_orig, avg_orig, close_orig, yoy_tr, yoy_ref, close_tr
 		FROM %s
 		WHERE symbol_ticker = $1 AND date >= $2 AND date <= $3
 		ORDER BY date ASC
@@ -210,7 +304,7 @@ func GetPrices(ticker string, from, to time.Time, interval types.PriceInterval)
 	for rows.Next() {
 		var p types.PriceData
 		if err := rows.Scan(&p.Date, &p.Open, &p.High, &p.Low, &p.Avg, &p.Close, &p.YoY, &p.SymbolTicker,
-			&p.OpenOrig, &p.HighOrig, &p.LowOrig, &p.AvgOrig, &p.CloseOrig); err != nil {
+			&p.OpenOrig, &p.HighOrig, &p.LowOrig, &p.AvgOrig, &p.CloseOrig, &p.YoYTR, &p.YoYRef, &p.CloseTR); err != nil {
 			return nil, err
 		}
 		prices = append(prices, p)
@@ -229,6 +323,42 @@ func GetWeeklyPrices(ticker string, from, to time.Time) ([]types.PriceData, erro
 	return GetPrices(ticker, from, to, types.IntervalWeekly)
 }
 
+// UpdatePriceYoY writes YoY, YoYTR and YoYRef of stored prices, leaving the other columns untouched
+func UpdatePriceYoY(prices []types.PriceData, interval types.PriceInterval) error {
+	db := Db()
+	tableName := string(interval) + "_prices"
+
+	chunkSize := 1000
+	for i := 0; i < len(prices); i += chunkSize {
+		end := i + chunkSize
+		if end > len(prices) {
+			end = len(prices)
+		}
+		chunk := prices[i:end]
+
+		valueStrings := make([]string, 0, len(chunk))
+		valueArgs := make([]interface{}, 0, len(chunk)*5)
+		for idx, p := range chunk {
+			paramOffset := idx * 5
+			valueStrings = append(valueStrings, fmt.Sprintf("($%d::timestamptz,$%d::text,$%d::double precision,$%d::double precision,$%d::timestamptz)",
+				paramOffset+1, paramOffset+2, paramOffset+3, paramOffset+4, paramOffset+5))
+			valueArgs = append(valueArgs, p.Date, p.SymbolTicker, p.YoY, p.YoYTR, p.YoYRef)
+		}
+
+		query := fmt.Sprintf(`
+			UPDATE %s AS p SET yoy = v.yoy, yoy_tr = v.yoy_tr, yoy_ref = v.yoy_ref
+			FROM (VALUES %s) AS v(date, symbol_ticker, yoy, yoy_tr, yoy_ref)
+			WHERE p.date = v.date AND p.symbol_ticker = v.symbol_ticker
+		`, tableName, joinStrings(valueStrings, ","))
+
+		if _, err := db.conn.Exec(query, valueArgs...); err != nil {
+			return fmt.Errorf("failed to update %s YoY: %w", interval, err)
+		}
+	}
+
+	return nil
+}
+
 // GetLatestPriceDate returns the most recent price date for a symbol at the specified interval
 // Returns nil if no prices exist
 func GetLatestPriceDate(ticker string, interval types.PriceInterval) (*time.Time, error) {
@@ -264,7 +394,7 @@ func GetPricesBatch(tickers []string, from, to time.Time, interval types.PriceIn
 	tableName := string(interval) + "_prices"
 
 	query := fmt.Sprintf(`
-		SELECT date, open, high, low, avg, close, yoy, symbol_ticker, open_orig, high_orig, low_orig, avg_orig, close_orig
+		SELECT date, open, high, low, avg, close, yoy, symbol_ticker, open_orig, high_orig, low_orig, avg_orig, close_orig, yoy_tr, yoy_ref, close_tr
 		FROM %s
 		WHERE symbol_ticker = ANY($1) AND date >= $2 AND date <= $3
 		ORDER BY symbol_ticker, date ASC
@@ -280,7 +410,7 @@ func GetPricesBatch(tickers []string, from, to time.Time, interval types.PriceIn
 	for rows.Next() {
 		var p types.PriceData
 		if err := rows.Scan(&p.Date, &p.Open, &p.High, &p.Low, &p.Avg, &p.Close, &p.YoY, &p.SymbolTicker,
-			&p.OpenOrig, &p.HighOrig, &p.LowOrig, &p.AvgOrig, &p.CloseOrig); err != nil {
+			&p.OpenOrig, &p.HighOrig, &p.LowOrig, &p.AvgOrig, &p.CloseOrig, &p.YoYTR, &p.YoYRef, &p.CloseTR); err != nil {
 			return nil, err
 		}
 		result[p.SymbolTicker] = append(result[p.SymbolTicker], p)
@@ -289,33 +419,117 @@ func GetPricesBatch(tickers []string, from, to time.Time, interval types.PriceIn
 	return result, rows.Err()
 }
 
-// GetFilteredTickers returns tickers matching filters (for analysis packages)
-func GetFilteredTickers(ctx context.Context, mcapMin *int64, inceptionMax *time.Time) ([]string, error) {
-	// Handle nullable parameters
-	var mcap int64
+// GetFilteredTickers returns tickers matching the selection of an analysis package.
+// Only actively trading symbols with a successful price update are considered; the universe
+// (all symbols, the user's favorites, one of the user's watchlists or an explicit ticker list) is narrowed by the filters.
+func GetFilteredTickers(ctx context.Context, userID uuid.UUID, mcapMin, mcapMax *int64, inceptionMax *time.Time, filters types.AnalysisFilters) ([]string, error) {
+	args := []interface{}{pq.Array(types.PriceUpdateTypes), types.StatusOK}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	whereConditions := []string{
+		"s.is_actively_trading = true",
+		"s.type = ANY($1::text[])",
+		"s.last_price_status = $2",
+		"s.last_price_update IS NOT NULL",
+	}
+
+	switch {
+	case filters.Universe == types.UniverseTickers || len(filters.Tickers) > 0:
+		// Explicit tickers, or the favorites or watchlist entries resolved when the package was created
+		tickers := make([]string, len(filters.Tickers))
+		for i, ticker := range filters.Tickers {
+			tickers[i] = strings.ToUpper(strings.TrimSpace(ticker))
+		}
+		whereConditions = append(whereConditions, "s.ticker = ANY("+arg(pq.Array(tickers))+"::text[])")
+	case filters.Universe == types.UniverseFavorites:
+		// Packages created before the universe was resolved at creation use the current list
+		whereConditions = append(whereConditions,
+			"s.ticker IN (SELECT e.ticker FROM watchlist_entries e JOIN watchlists w ON w.id = e.watchlist_id"+
+				" WHERE w.user_id = "+arg(userID)+" AND w.is_default)")
+	case filters.Universe == types.UniverseWatchlist:
+		watchlistID := uuid.Nil
+		if filters.Watchlist != nil {
+			watchlistID = *filters.Watchlist
+		}
+		whereConditions = append(whereConditions,
+			"s.ticker IN (SELECT e.ticker FROM watchlist_entries e JOIN watchlists w ON w.id = e.watchlist_id"+
+				" WHERE w.user_id = "+arg(userID)+" AND w.id = "+arg(watchlistID)+")")
+	}
+
 	if mcapMin != nil {
-		mcap = *mcapMin
+		whereConditions = append(whereConditions, "s.market_cap >= "+arg(*mcapMin))
+	}
+	if mcapMax != nil {
+		whereConditions = append(whereConditions, "s.market_cap <= "+arg(*mcapMax))
 	}
-	var inception time.Time
 	if inceptionMax != nil {
-		inception = *inceptionMax
+		whereConditions = append(whereConditions, "s.inception <= "+arg(*inceptionMax))
 	}
 
-	tickers, err := genQ().GetFilteredTickers(ctx, generated.GetFilteredTickersParams{
-		Column1:         types.PriceUpdateTypes,
-		Column2:         mcap,
-		Column3:         inception,
-		LastPriceStatus: f.StringToNullString(types.StatusOK),
-	})
+	attributes := []struct {
+		column string
+		filter types.IncludeExclude
+	}{
+		{"s.sector", filters.Sectors},
+		{"s.industry", filters.Industries},
+		{"s.country", filters.Countries},
+		{"s.exchange", filters.Exchanges},
+		{"s.currency", filters.Currencies},
+		{"s.type", filters.Types},
+	}
+	for _, attribute := range attributes {
+		if len(attribute.filter.Include) > 0 {
+			whereConditions = append(whereConditions,
+				fmt.Sprintf("lower(%s) = ANY(%s::text[])", attribute.column, arg(pq.Array(lowerAll(attribute.filter.Include)))))
+		}
+		if len(attribute.filter.Exclude) > 0 {
+			// Symbols without a value are never excluded
+			whereConditions = append(whereConditions,
+				fmt.Sprintf("(%s IS NULL OR NOT lower(%s) = ANY(%s::text[]))", attribute.column, attribute.column, arg(pq.Array(lowerAll(attribute.filter.Exclude)))))
+		}
+	}
+
+	query := `
+		SELECT s.ticker FROM symbols s
+		WHERE ` + strings.Join(whereConditions, "\n\t\t  AND ") + `
+		ORDER BY s.ticker
+	`
+
+	rows, err := Db().conn.QueryContext(ctx, query, args...)
 	if err != nil {
 		logf("[DB] Query error: %v\n", err)
 		return nil, fmt.Errorf("failed to get filtered tickers: %w", err)
 	}
+	defer rows.Close()
+
+	tickers := []string{}
+	for rows.Next() {
+		var ticker string
+		if err := rows.Scan(&ticker); err != nil {
+			return nil, fmt.Errorf("failed to get filtered tickers: %w", err)
+		}
+		tickers = append(tickers, ticker)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get filtered tickers: %w", err)
+	}
 
 	logf("[DB] GetFilteredTickers returned %d tickers\n", len(tickers))
 	return tickers, nil
 }
 
+// lowerAll returns the values in lower case for case-insensitive matching
+func lowerAll(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, v := range values {
+		lowered[i] = strings.ToLower(strings.TrimSpace(v))
+	}
+	return lowered
+}
+
 // GetTickersWithPrices returns tickers that have price data (limited to specified count)
 func GetTickersWithPrices(ctx context.Context, limit int) ([]string, error) {
 	tickers, err := genQ().GetTickersWithPrices(ctx, int32(limit))
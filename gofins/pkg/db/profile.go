@@ -11,6 +11,7 @@ import (
 	"github.com/flocko-motion/gofins/pkg/db/generated"
 	"github.com/flocko-motion/gofins/pkg/f"
 	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
 )
 
 // PutSymbols inserts or updates multiple symbol profiles using batched transactions
@@ -149,7 +150,6 @@ func GetSymbol(ctx context.Context, ticker string) (*types.Symbol, error) {
 		Ath12M:            f.NullFloat64ToMaybeFloat64(row.Ath12m),
 		CurrentPriceUsd:   f.NullFloat64ToMaybeFloat64(row.CurrentPriceUsd),
 		CurrentPriceTime:  f.NullTimeToMaybeTime(row.CurrentPriceTime),
-		IsFavorite:        row.IsFavorite.(bool),
 	}, nil
 }
 
@@ -228,8 +228,9 @@ func DeactivateSymbolsNotInList(ctx context.Context, keepTickers []string) error
 	return nil
 }
 
-// getFilteredSymbols returns symbols with optional additional WHERE conditions
-func getFilteredSymbols(additionalWhere []string) ([]types.Symbol, error) {
+// getFilteredSymbols returns symbols with optional additional WHERE conditions.
+// IsFavorite is set from the favorites watchlist of the given user; uuid.Nil marks no symbol as favorite.
+func getFilteredSymbols(userID uuid.UUID, additionalWhere []string) ([]types.Symbol, error) {
 	db := Db()
 
 	// Base WHERE conditions
@@ -255,7 +256,12 @@ func getFilteredSymbols(additionalWhere []string) ([]types.Symbol, error) {
 			COALESCE(f.ticker IS NOT NULL, false) as is_favorite,
 			r.rating
 		FROM symbols s
-		LEFT JOIN user_favorites f ON s.ticker = f.ticker
+		LEFT JOIN (
+			SELECT e.ticker
+			FROM watchlist_entries e
+			JOIN watchlists w ON w.id = e.watchlist_id
+			WHERE w.user_id = $2 AND w.is_default
+		) f ON s.ticker = f.ticker
 		LEFT JOIN LATERAL (
 			SELECT rating 
 			FROM user_ratings 
@@ -267,7 +273,7 @@ func getFilteredSymbols(additionalWhere []string) ([]types.Symbol, error) {
 		ORDER BY s.ticker
 	`
 
-	rows, err := db.conn.Query(query, types.TypeStock)
+	rows, err := db.conn.Query(query, types.TypeStock, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -289,13 +295,13 @@ func getFilteredSymbols(additionalWhere []string) ([]types.Symbol, error) {
 }
 
 // GetActiveSymbols returns all actively trading stocks (excludes indices and secondary listings)
-func GetActiveSymbols() ([]types.Symbol, error) {
-	return getFilteredSymbols(nil)
+func GetActiveSymbols(userID uuid.UUID) ([]types.Symbol, error) {
+	return getFilteredSymbols(userID, nil)
 }
 
-// GetFavoriteSymbols returns only favorited actively trading stocks
-func GetFavoriteSymbols() ([]types.Symbol, error) {
-	return getFilteredSymbols([]string{"f.ticker IS NOT NULL"})
+// GetFavoriteSymbols returns the actively trading stocks on the user's favorites watchlist
+func GetFavoriteSymbols(userID uuid.UUID) ([]types.Symbol, error) {
+	return getFilteredSymbols(userID, []string{"f.ticker IS NOT NULL"})
 }
 
 // GetStaleProfiles returns symbols with outdated profiles (older than threshold or null)
@@ -480,6 +486,29 @@ func GetAllSymbolCurrencies(ctx context.Context) (map[string]string, error) {
 	return currencies, nil
 }
 
+// CurrentPrice is the latest quote of a symbol in USD
+type CurrentPrice struct {
+	Price float64
+	Time  *time.Time
+}
+
+// GetCurrentPrices returns the current USD prices of the given tickers, omitting tickers without a price
+func GetCurrentPrices(ctx context.Context, tickers []string) (map[string]CurrentPrice, error) {
+	rows, err := genQ().GetCurrentPrices(ctx, tickers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current prices: %w", err)
+	}
+
+	prices := make(map[string]CurrentPrice, len(rows))
+	for _, row := range rows {
+		prices[row.Ticker] = CurrentPrice{
+			Price: row.CurrentPriceUsd.Float64,
+			Time:  f.NullTimeToMaybeTime(row.CurrentPriceTime),
+		}
+	}
+	return prices, nil
+}
+
 // MarkStaleProfilesAsNotFound marks all profiles that haven't been updated since the given time as not found
 func MarkStaleProfilesAsNotFound(ctx context.Context, since time.Time) (int64, error) {
 	count, err := genQ().MarkStaleProfilesAsNotFound(ctx, generated.MarkStaleProfilesAsNotFoundParams{
@@ -508,10 +537,10 @@ func GetTickersNeedingProfileUpdate(ctx context.Context) (map[string]bool, error
 	return result, nil
 }
 
-// GetTickersNeedingQuoteUpdate returns tickers that don't have quotes from yesterday
-func GetTickersNeedingQuoteUpdate(ctx context.Context) (map[string]bool, error) {
-	yesterday := calculator.StartOfDay(time.Now().AddDate(0, 0, -1))
-	tickers, err := genQ().GetTickersNeedingQuoteUpdate(ctx, f.MaybeTimeToNullTime(&yesterday))
+// GetTickersNeedingQuoteUpdate returns tickers that don't have quotes from the given session or later
+func GetTickersNeedingQuoteUpdate(ctx context.Context, session time.Time) (map[string]bool, error) {
+	session = calculator.StartOfDay(session)
+	tickers, err := genQ().GetTickersNeedingQuoteUpdate(ctx, f.MaybeTimeToNullTime(&session))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tickers needing update: %w", err)
 	}
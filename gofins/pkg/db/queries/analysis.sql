@@ -1,8 +1,9 @@
 -- name: CreateAnalysisPackage :exec
 INSERT INTO analysis_packages (
     id, name, created_at, interval, time_from, time_to,
-    hist_bins, hist_min, hist_max, mcap_min, inception_max, status, user_id
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);
+    hist_bins, hist_min, hist_max, mcap_min, inception_max, status, user_id, reference_index, return_basis,
+    risk_free_rate, mcap_max, filters, rolling_window_months, outliers
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20);
 
 -- name: UpdateAnalysisPackageStatus :exec
 UPDATE analysis_packages 
@@ -14,11 +15,13 @@ SELECT EXISTS(SELECT 1 FROM analysis_packages WHERE id = $1 AND user_id = $2);
 
 -- name: SaveAnalysisResult :exec
 INSERT INTO analysis_results (
-    package_id, ticker, count, mean, stddev, variance, min, max, histogram
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+    package_id, ticker, count, mean, stddev, variance, min, max, histogram,
+    beta, correlation, alpha, metrics, rolling, outliers_removed
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15);
 
 -- name: GetAnalysisResults :many
-SELECT ar.package_id, ar.ticker, ar.count, ar.mean, ar.stddev, ar.variance, ar.min, ar.max, s.inception
+SELECT ar.package_id, ar.ticker, ar.count, ar.mean, ar.stddev, ar.variance, ar.min, ar.max,
+       ar.beta, ar.correlation, ar.alpha, ar.metrics, ar.rolling, ar.outliers_removed, s.inception
 FROM analysis_results ar
 JOIN symbols s ON ar.ticker = s.ticker
 WHERE ar.package_id = $1
@@ -26,13 +29,15 @@ ORDER BY ar.mean DESC;
 
 -- name: GetAnalysisPackage :one
 SELECT id, name, created_at, interval, time_from, time_to,
-       hist_bins, hist_min, hist_max, mcap_min, inception_max, symbol_count, status, user_id
+       hist_bins, hist_min, hist_max, mcap_min, inception_max, symbol_count, status, user_id,
+       reference_index, return_basis, risk_free_rate, mcap_max, filters, rolling_window_months, outliers
 FROM analysis_packages
 WHERE id = $1 AND user_id = $2;
 
 -- name: ListAnalysisPackages :many
 SELECT id, name, created_at, interval, time_from, time_to,
-       hist_bins, hist_min, hist_max, mcap_min, inception_max, symbol_count, status, user_id
+       hist_bins, hist_min, hist_max, mcap_min, inception_max, symbol_count, status, user_id,
+       reference_index, return_basis, risk_free_rate, mcap_max, filters, rolling_window_months, outliers
 FROM analysis_packages
 WHERE user_id = $1
 ORDER BY created_at DESC;
@@ -45,3 +50,13 @@ WHERE id = $2 AND user_id = $3;
 -- name: DeleteAnalysisPackage :exec
 DELETE FROM analysis_packages 
 WHERE id = $1 AND user_id = $2;
+
+-- name: GetAnalysisPackageOwner :one
+SELECT user_id FROM analysis_packages WHERE id = $1;
+
+-- name: GetAnalysisResultsFull :many
+SELECT package_id, ticker, count, mean, stddev, variance, min, max, histogram, chart_path,
+       beta, correlation, alpha, metrics, rolling, outliers_removed
+FROM analysis_results
+WHERE package_id = $1
+ORDER BY ticker;
@@ -0,0 +1,64 @@
+-- name: CreateAnalysisJob :exec
+INSERT INTO analysis_jobs (package_id, user_id, state, created_at)
+VALUES ($1, $2, 'queued', $3);
+
+-- name: GetAnalysisJob :one
+SELECT package_id, user_id, state, attempts, processed, total, results, rejections, error,
+       created_at, started_at, finished_at, heartbeat_at
+FROM analysis_jobs
+WHERE package_id = $1 AND user_id = $2;
+
+-- name: ClaimAnalysisJob :one
+UPDATE analysis_jobs
+SET state = 'running', attempts = attempts + 1, processed = 0, total = 0, results = 0,
+    rejections = '{}'::jsonb, error = NULL, started_at = now(), heartbeat_at = now()
+WHERE package_id = (
+    SELECT package_id FROM analysis_jobs
+    WHERE state = 'queued'
+    ORDER BY created_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING package_id, user_id, attempts;
+
+-- name: UpdateAnalysisJobProgress :one
+UPDATE analysis_jobs
+SET processed = $2, total = $3, results = $4, rejections = $5, heartbeat_at = now()
+WHERE package_id = $1
+RETURNING state;
+
+-- name: FinishAnalysisJob :execrows
+UPDATE analysis_jobs
+SET state = $2, error = $3, finished_at = now()
+WHERE package_id = $1 AND state = 'running';
+
+-- name: CancelAnalysisJob :execrows
+UPDATE analysis_jobs
+SET state = 'cancelled', finished_at = now()
+WHERE package_id = $1 AND user_id = $2 AND state IN ('queued', 'running');
+
+-- name: TouchAnalysisJob :one
+UPDATE analysis_jobs
+SET heartbeat_at = now()
+WHERE package_id = $1
+RETURNING state;
+
+-- name: RequeueOrphanedAnalysisJobs :execrows
+UPDATE analysis_jobs
+SET state = 'queued'
+WHERE state = 'running' AND heartbeat_at < $1 AND attempts < $2
+  AND NOT (package_id = ANY($3::uuid[]));
+
+-- name: FailOrphanedAnalysisJobs :execrows
+WITH failed AS (
+    UPDATE analysis_jobs
+    SET state = 'failed', error = 'orphaned: gave up after ' || attempts || ' attempts', finished_at = now()
+    WHERE state = 'running' AND heartbeat_at < $1 AND attempts >= $2
+      AND NOT (package_id = ANY($3::uuid[]))
+    RETURNING package_id
+)
+UPDATE analysis_packages SET status = 'failed'
+WHERE id IN (SELECT package_id FROM failed);
+
+-- name: DeleteAnalysisResults :exec
+DELETE FROM analysis_results WHERE package_id = $1;
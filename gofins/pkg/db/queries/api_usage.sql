@@ -0,0 +1,13 @@
+-- name: AddApiUsage :exec
+INSERT INTO api_usage (limiter, day, weight, requests)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (limiter, day) DO UPDATE SET
+    weight = api_usage.weight + EXCLUDED.weight,
+    requests = api_usage.requests + EXCLUDED.requests,
+    updated_at = now();
+
+-- name: GetApiUsage :one
+SELECT COALESCE(SUM(weight) FILTER (WHERE day = $2), 0)::bigint AS daily,
+       COALESCE(SUM(weight), 0)::bigint AS monthly
+FROM api_usage
+WHERE limiter = $1 AND day <= $2 AND day >= date_trunc('month', $2::date)::date;
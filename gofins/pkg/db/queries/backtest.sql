@@ -0,0 +1,17 @@
+-- name: CreateBacktest :exec
+INSERT INTO backtests (id, user_id, name, config, result, created_at)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: ListBacktests :many
+SELECT id, user_id, name, config, created_at
+FROM backtests
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: GetBacktest :one
+SELECT id, user_id, name, config, result, created_at
+FROM backtests
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteBacktest :execrows
+DELETE FROM backtests WHERE id = $1 AND user_id = $2;
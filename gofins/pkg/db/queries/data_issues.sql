@@ -0,0 +1,49 @@
+-- name: UpsertDataIssue :one
+INSERT INTO data_issues (ticker, interval, kind, period, severity, message, details, first_seen, last_seen)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+ON CONFLICT (ticker, kind, (COALESCE(interval, '')), (COALESCE(period, '-infinity'::timestamp with time zone))) DO UPDATE SET
+    severity = EXCLUDED.severity,
+    message = EXCLUDED.message,
+    details = EXCLUDED.details,
+    last_seen = EXCLUDED.last_seen,
+    resolved_at = NULL,
+    status = CASE WHEN data_issues.status = 'ignored' THEN 'ignored' ELSE 'open' END,
+    first_seen = CASE WHEN data_issues.status = 'resolved' THEN EXCLUDED.first_seen ELSE data_issues.first_seen END,
+    error_id = CASE WHEN data_issues.status = 'resolved' THEN NULL ELSE data_issues.error_id END
+RETURNING first_seen = $8 AS is_new;
+
+-- name: ResolveDataIssues :execrows
+UPDATE data_issues
+SET status = 'resolved', resolved_at = $2
+WHERE ($1::text = '' OR ticker = $1) AND status = 'open' AND last_seen < $2;
+
+-- name: LinkDataIssuesToError :execrows
+UPDATE data_issues
+SET error_id = $1
+WHERE error_id IS NULL AND status = 'open' AND first_seen >= $2;
+
+-- name: ListDataIssues :many
+SELECT id, ticker, interval, kind, period, severity, message, details, status, error_id, first_seen, last_seen, resolved_at
+FROM data_issues
+WHERE ($1::text = '' OR ticker = $1)
+  AND ($2::text = '' OR kind = $2)
+  AND ($3::text = '' OR status = $3)
+  AND ($4::integer = 0 OR error_id = $4)
+ORDER BY last_seen DESC, ticker, kind, period
+LIMIT $5;
+
+-- name: GetDataIssue :one
+SELECT id, ticker, interval, kind, period, severity, message, details, status, error_id, first_seen, last_seen, resolved_at
+FROM data_issues
+WHERE id = $1;
+
+-- name: SetDataIssueStatus :execrows
+UPDATE data_issues
+SET status = $2, resolved_at = NULL
+WHERE id = $1 AND status != 'resolved';
+
+-- name: CountDataIssues :many
+SELECT kind, status, COUNT(*) AS count
+FROM data_issues
+GROUP BY kind, status
+ORDER BY kind, status;
@@ -28,3 +28,8 @@ WHERE id = $1;
 
 -- name: ClearAllErrors :execrows
 DELETE FROM errors;
+
+-- name: InsertErrorReturningID :one
+INSERT INTO errors (source, error_type, message, details)
+VALUES ($1, $2, $3, $4)
+RETURNING id;
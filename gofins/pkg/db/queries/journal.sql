@@ -0,0 +1,62 @@
+-- name: CreateJournalEntry :one
+INSERT INTO user_journal (user_id, title, content, type, tags)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, user_id, title, content, type, tags, created_at, updated_at;
+
+-- name: UpdateJournalEntry :one
+UPDATE user_journal
+SET title = $1, content = $2, type = $3, tags = $4, updated_at = NOW()
+WHERE id = $5 AND user_id = $6
+RETURNING id, user_id, title, content, type, tags, created_at, updated_at;
+
+-- name: DeleteJournalEntry :execrows
+DELETE FROM user_journal WHERE id = $1 AND user_id = $2;
+
+-- name: GetJournalEntry :one
+SELECT j.id, j.title, j.content, j.type, j.tags, j.created_at, j.updated_at,
+       COALESCE(array_agg(jt.ticker ORDER BY jt.ticker) FILTER (WHERE jt.ticker IS NOT NULL), '{}')::text[] AS tickers
+FROM user_journal j
+LEFT JOIN journal_tickers jt ON jt.journal_id = j.id
+WHERE j.id = $1 AND j.user_id = $2
+GROUP BY j.id;
+
+-- name: ListJournalEntries :many
+SELECT j.id, j.title, j.content, j.type, j.tags, j.created_at, j.updated_at,
+       COALESCE(array_agg(jt.ticker ORDER BY jt.ticker) FILTER (WHERE jt.ticker IS NOT NULL), '{}')::text[] AS tickers
+FROM user_journal j
+LEFT JOIN journal_tickers jt ON jt.journal_id = j.id
+WHERE j.user_id = $1
+  AND ($2::text = '' OR j.type = $2)
+  AND ($3::text = '' OR j.tags @> jsonb_build_array($3::text))
+  AND ($4::text = '' OR EXISTS(SELECT 1 FROM journal_tickers t WHERE t.journal_id = j.id AND t.ticker = $4))
+  AND ($5::timestamptz IS NULL OR j.created_at >= $5)
+  AND ($6::timestamptz IS NULL OR j.created_at < $6)
+GROUP BY j.id
+ORDER BY j.created_at DESC
+LIMIT NULLIF($7::integer, 0);
+
+-- name: ListJournalEntriesByTicker :many
+SELECT j.id, j.title, j.content, j.type, j.tags, j.created_at, j.updated_at,
+       COALESCE(array_agg(jt.ticker ORDER BY jt.ticker) FILTER (WHERE jt.ticker IS NOT NULL), '{}')::text[] AS tickers
+FROM user_journal j
+LEFT JOIN journal_tickers jt ON jt.journal_id = j.id
+WHERE j.user_id = $1
+  AND EXISTS(SELECT 1 FROM journal_tickers t WHERE t.journal_id = j.id AND t.ticker = $2)
+GROUP BY j.id
+ORDER BY j.created_at DESC;
+
+-- name: AddJournalTicker :exec
+INSERT INTO journal_tickers (journal_id, ticker)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveJournalTicker :exec
+DELETE FROM journal_tickers WHERE journal_id = $1 AND ticker = $2;
+
+-- name: DeleteJournalTickers :exec
+DELETE FROM journal_tickers WHERE journal_id = $1;
+
+-- name: ImportJournalEntry :one
+INSERT INTO user_journal (user_id, title, content, type, tags, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id;
@@ -0,0 +1,48 @@
+-- name: ListPortfolios :many
+SELECT id, user_id, name, cost_basis, created_at
+FROM portfolios
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: GetPortfolio :one
+SELECT id, user_id, name, cost_basis, created_at
+FROM portfolios
+WHERE id = $1 AND user_id = $2;
+
+-- name: CreatePortfolio :one
+INSERT INTO portfolios (id, user_id, name, cost_basis)
+VALUES ($1, $2, $3, $4)
+RETURNING id, user_id, name, cost_basis, created_at;
+
+-- name: UpdatePortfolio :one
+UPDATE portfolios
+SET name = $1, cost_basis = $2
+WHERE id = $3 AND user_id = $4
+RETURNING id, user_id, name, cost_basis, created_at;
+
+-- name: ImportPortfolio :exec
+INSERT INTO portfolios (id, user_id, name, cost_basis, created_at)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: DeletePortfolio :execrows
+DELETE FROM portfolios WHERE id = $1 AND user_id = $2;
+
+-- name: LockPortfolio :one
+SELECT id FROM portfolios WHERE id = $1 FOR UPDATE;
+
+-- name: ListPortfolioTransactions :many
+SELECT id, portfolio_id, ticker, type, date, quantity, price, amount, fee, currency, fx_rate, note, created_at
+FROM portfolio_transactions
+WHERE portfolio_id = $1
+ORDER BY date, created_at, id;
+
+-- name: CreatePortfolioTransaction :exec
+INSERT INTO portfolio_transactions (
+    id, portfolio_id, ticker, type, date, quantity, price, amount, fee, currency, fx_rate, note, created_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);
+
+-- name: DeletePortfolioTransaction :execrows
+DELETE FROM portfolio_transactions WHERE id = $1 AND portfolio_id = $2;
+
+-- name: DeletePortfolioTransactions :exec
+DELETE FROM portfolio_transactions WHERE portfolio_id = $1;
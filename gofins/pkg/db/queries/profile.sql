@@ -4,10 +4,8 @@ SELECT s.ticker, s.exchange, s.last_price_update, s.last_profile_update,
        s.name, s.type, s.currency, s.sector, s.industry, s.country,
        s.description, s.website, s.isin, s.cik, s.inception, s.oldest_price, 
        s.is_actively_trading, s.market_cap, s.primary_listing,
-       s.ath12m, s.current_price_usd, s.current_price_time,
-       COALESCE(f.ticker IS NOT NULL, false) as is_favorite
+       s.ath12m, s.current_price_usd, s.current_price_time
 FROM symbols s
-LEFT JOIN user_favorites f ON s.ticker = f.ticker
 WHERE s.ticker = $1;
 
 -- name: GetAllTickers :many
@@ -84,3 +82,8 @@ SELECT ticker
 FROM symbols 
 WHERE current_price_time IS NULL 
    OR current_price_time < $1;
+
+-- name: GetCurrentPrices :many
+SELECT ticker, current_price_usd, current_price_time
+FROM symbols
+WHERE ticker = ANY($1::text[]) AND current_price_usd IS NOT NULL;
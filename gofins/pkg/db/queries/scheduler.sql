@@ -0,0 +1,65 @@
+-- name: ListSchedulerJobs :many
+SELECT name, schedule, paused, skip_next, triggered_at, last_scheduled_at, updated_at
+FROM scheduler_jobs
+ORDER BY name;
+
+-- name: SetSchedulerJobSchedule :exec
+INSERT INTO scheduler_jobs (name, schedule)
+VALUES ($1, $2)
+ON CONFLICT (name) DO UPDATE SET schedule = EXCLUDED.schedule, updated_at = now();
+
+-- name: SetSchedulerJobPaused :exec
+INSERT INTO scheduler_jobs (name, paused)
+VALUES ($1, $2)
+ON CONFLICT (name) DO UPDATE SET paused = EXCLUDED.paused, updated_at = now();
+
+-- name: SetSchedulerJobSkipNext :exec
+INSERT INTO scheduler_jobs (name, skip_next)
+VALUES ($1, $2)
+ON CONFLICT (name) DO UPDATE SET skip_next = EXCLUDED.skip_next, updated_at = now();
+
+-- name: TriggerSchedulerJob :exec
+INSERT INTO scheduler_jobs (name, triggered_at)
+VALUES ($1, $2)
+ON CONFLICT (name) DO UPDATE SET triggered_at = COALESCE(scheduler_jobs.triggered_at, EXCLUDED.triggered_at), updated_at = now();
+
+-- name: ClearSchedulerJobTrigger :execrows
+UPDATE scheduler_jobs
+SET triggered_at = NULL, updated_at = now()
+WHERE name = $1 AND triggered_at IS NOT NULL;
+
+-- name: ConsumeSchedulerSlot :execrows
+INSERT INTO scheduler_jobs (name, last_scheduled_at)
+VALUES ($1, $2)
+ON CONFLICT (name) DO UPDATE SET last_scheduled_at = EXCLUDED.last_scheduled_at, skip_next = false, updated_at = now()
+WHERE scheduler_jobs.last_scheduled_at IS NOT DISTINCT FROM $3;
+
+-- name: CreateSchedulerRun :exec
+INSERT INTO scheduler_runs (id, job, trigger, status, error, started_at, finished_at, owner, heartbeat_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: TouchSchedulerRun :exec
+UPDATE scheduler_runs SET heartbeat_at = now() WHERE id = $1;
+
+-- name: FinishSchedulerRun :exec
+UPDATE scheduler_runs
+SET status = $1, error = $2, finished_at = $3
+WHERE id = $4;
+
+-- name: FailOrphanedSchedulerRuns :execrows
+UPDATE scheduler_runs
+SET status = 'failed', error = $1, finished_at = $2
+WHERE status = 'running'
+  AND (owner = $3 OR heartbeat_at < $4);
+
+-- name: ListSchedulerRuns :many
+SELECT id, job, trigger, status, error, started_at, finished_at, owner, heartbeat_at
+FROM scheduler_runs
+WHERE ($1::text = '' OR job = $1)
+ORDER BY started_at DESC
+LIMIT $2;
+
+-- name: ListLastSchedulerRuns :many
+SELECT DISTINCT ON (job) id, job, trigger, status, error, started_at, finished_at, owner, heartbeat_at
+FROM scheduler_runs
+ORDER BY job, started_at DESC;
@@ -0,0 +1,30 @@
+-- name: ListScoringProfiles :many
+SELECT id, user_id, name, weight_mean, weight_stddev, weight_min, weight_max, weight_beta,
+       normalization, created_at, updated_at
+FROM scoring_profiles
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: GetScoringProfile :one
+SELECT id, user_id, name, weight_mean, weight_stddev, weight_min, weight_max, weight_beta,
+       normalization, created_at, updated_at
+FROM scoring_profiles
+WHERE id = $1 AND user_id = $2;
+
+-- name: CreateScoringProfile :one
+INSERT INTO scoring_profiles (
+    id, user_id, name, weight_mean, weight_stddev, weight_min, weight_max, weight_beta, normalization
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, user_id, name, weight_mean, weight_stddev, weight_min, weight_max, weight_beta,
+          normalization, created_at, updated_at;
+
+-- name: UpdateScoringProfile :one
+UPDATE scoring_profiles
+SET name = $1, weight_mean = $2, weight_stddev = $3, weight_min = $4, weight_max = $5,
+    weight_beta = $6, normalization = $7, updated_at = NOW()
+WHERE id = $8 AND user_id = $9
+RETURNING id, user_id, name, weight_mean, weight_stddev, weight_min, weight_max, weight_beta,
+          normalization, created_at, updated_at;
+
+-- name: DeleteScoringProfile :exec
+DELETE FROM scoring_profiles WHERE id = $1 AND user_id = $2;
@@ -33,20 +33,18 @@ DELETE FROM users WHERE id = $1;
 -- name: DeleteUserRatings :exec
 DELETE FROM user_ratings WHERE user_id = $1;
 
--- name: DeleteUserFavorites :exec
-DELETE FROM user_favorites WHERE user_id = $1;
-
 -- name: IsFavorite :one
-SELECT EXISTS(SELECT 1 FROM user_favorites WHERE user_id = $1 AND ticker = $2);
-
--- name: AddFavorite :exec
-INSERT INTO user_favorites (user_id, ticker) VALUES ($1, $2);
-
--- name: RemoveFavorite :exec
-DELETE FROM user_favorites WHERE user_id = $1 AND ticker = $2;
+SELECT EXISTS(
+    SELECT 1 FROM watchlist_entries e
+    JOIN watchlists w ON w.id = e.watchlist_id
+    WHERE w.user_id = $1 AND w.is_default AND e.ticker = $2
+);
 
 -- name: GetFavorites :many
-SELECT ticker FROM user_favorites WHERE user_id = $1 ORDER BY created_at DESC;
+SELECT e.ticker FROM watchlist_entries e
+JOIN watchlists w ON w.id = e.watchlist_id
+WHERE w.user_id = $1 AND w.is_default
+ORDER BY e.sort_order, e.created_at;
 
 -- name: AddRating :one
 INSERT INTO user_ratings (user_id, ticker, rating, notes)
@@ -80,3 +78,29 @@ SELECT id, ticker, rating, notes, created_at
 FROM user_ratings
 WHERE user_id = $1 AND notes IS NOT NULL AND notes != ''
 ORDER BY created_at DESC;
+
+-- name: GetAllRatings :many
+SELECT id, ticker, rating, notes, created_at
+FROM user_ratings
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: ListTimelineRatings :many
+SELECT id, ticker, rating, notes, created_at
+FROM user_ratings
+WHERE user_id = $1
+  AND ($2::text = '' OR ticker = $2)
+  AND ($3::timestamp IS NULL OR created_at >= $3)
+  AND ($4::timestamp IS NULL OR created_at < $4)
+ORDER BY created_at DESC
+LIMIT NULLIF($5::integer, 0);
+
+-- name: ImportRating :execrows
+INSERT INTO user_ratings (user_id, ticker, rating, notes, created_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, ticker, created_at) DO NOTHING;
+
+-- name: UpdateRatingAt :execrows
+UPDATE user_ratings
+SET rating = $1, notes = $2
+WHERE user_id = $3 AND ticker = $4 AND created_at = $5;
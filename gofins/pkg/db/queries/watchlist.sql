@@ -0,0 +1,59 @@
+-- name: ListWatchlists :many
+SELECT w.id, w.user_id, w.name, w.is_default, w.created_at, COUNT(e.ticker) AS entry_count
+FROM watchlists w
+LEFT JOIN watchlist_entries e ON e.watchlist_id = w.id
+WHERE w.user_id = $1
+GROUP BY w.id
+ORDER BY w.is_default DESC, w.name;
+
+-- name: GetWatchlist :one
+SELECT id, user_id, name, is_default, created_at
+FROM watchlists
+WHERE id = $1 AND user_id = $2;
+
+-- name: CreateWatchlist :one
+INSERT INTO watchlists (id, user_id, name)
+VALUES ($1, $2, $3)
+RETURNING id, user_id, name, is_default, created_at;
+
+-- name: EnsureDefaultWatchlist :one
+INSERT INTO watchlists (id, user_id, name, is_default)
+VALUES ($1, $2, $3, true)
+ON CONFLICT (user_id) WHERE is_default DO UPDATE SET is_default = true
+RETURNING id, user_id, name, is_default, created_at;
+
+-- name: RenameWatchlist :one
+UPDATE watchlists
+SET name = $1
+WHERE id = $2 AND user_id = $3
+RETURNING id, user_id, name, is_default, created_at;
+
+-- name: DeleteWatchlist :execrows
+DELETE FROM watchlists WHERE id = $1 AND user_id = $2 AND NOT is_default;
+
+-- name: DeleteUserWatchlists :exec
+DELETE FROM watchlists WHERE user_id = $1;
+
+-- name: ListWatchlistEntries :many
+SELECT e.ticker, e.sort_order, e.note, e.target_price, e.created_at,
+       s.name, s.current_price_usd, s.current_price_time
+FROM watchlist_entries e
+LEFT JOIN symbols s ON s.ticker = e.ticker
+WHERE e.watchlist_id = $1
+ORDER BY e.sort_order, e.created_at;
+
+-- name: AddWatchlistEntry :execrows
+INSERT INTO watchlist_entries (watchlist_id, ticker, sort_order, note, target_price, created_at)
+VALUES ($1, $2, (SELECT COALESCE(MAX(sort_order) + 1, 0) FROM watchlist_entries WHERE watchlist_id = $1), $3, $4, $5)
+ON CONFLICT DO NOTHING;
+
+-- name: UpdateWatchlistEntry :execrows
+UPDATE watchlist_entries
+SET note = $1, target_price = $2
+WHERE watchlist_id = $3 AND ticker = $4;
+
+-- name: RemoveWatchlistEntries :execrows
+DELETE FROM watchlist_entries WHERE watchlist_id = $1 AND ticker = ANY($2::text[]);
+
+-- name: SetWatchlistEntryOrder :exec
+UPDATE watchlist_entries SET sort_order = $1 WHERE watchlist_id = $2 AND ticker = $3;
@@ -0,0 +1,183 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db/generated"
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+)
+
+// SchedulerJobState is the persisted state of a scheduled job. Jobs without a row use the defaults.
+type SchedulerJobState struct {
+	Name            string
+	Schedule        *string // Overrides the default schedule of the job
+	Paused          bool
+	SkipNext        bool
+	TriggeredAt     *time.Time // Pending manual trigger
+	LastScheduledAt *time.Time // Time the last due schedule was run or skipped
+}
+
+func schedulerRunFromGen(r generated.SchedulerRun) types.SchedulerRun {
+	return types.SchedulerRun{
+		ID:         r.ID,
+		Job:        r.Job,
+		Trigger:    r.Trigger,
+		Status:     r.Status,
+		Error:      f.NullStringToMaybeString(r.Error),
+		StartedAt:  r.StartedAt,
+		FinishedAt: f.NullTimeToMaybeTime(r.FinishedAt),
+	}
+}
+
+// ListSchedulerJobStates returns the persisted state of all scheduled jobs by name
+func ListSchedulerJobStates(ctx context.Context) (map[string]SchedulerJobState, error) {
+	rows, err := genQ().ListSchedulerJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]SchedulerJobState, len(rows))
+	for _, row := range rows {
+		states[row.Name] = SchedulerJobState{
+			Name:            row.Name,
+			Schedule:        f.NullStringToMaybeString(row.Schedule),
+			Paused:          row.Paused,
+			SkipNext:        row.SkipNext,
+			TriggeredAt:     f.NullTimeToMaybeTime(row.TriggeredAt),
+			LastScheduledAt: f.NullTimeToMaybeTime(row.LastScheduledAt),
+		}
+	}
+	return states, nil
+}
+
+// SetSchedulerJobSchedule overrides the schedule of a job, nil restores the default
+func SetSchedulerJobSchedule(ctx context.Context, name string, schedule *string) error {
+	return genQ().SetSchedulerJobSchedule(ctx, generated.SetSchedulerJobScheduleParams{
+		Name:     name,
+		Schedule: f.MaybeStringToNullString(schedule),
+	})
+}
+
+// SetSchedulerJobPaused suspends or resumes the scheduled runs of a job
+func SetSchedulerJobPaused(ctx context.Context, name string, paused bool) error {
+	return genQ().SetSchedulerJobPaused(ctx, generated.SetSchedulerJobPausedParams{
+		Name:   name,
+		Paused: paused,
+	})
+}
+
+// SetSchedulerJobSkipNext marks the next scheduled run of a job to be skipped
+func SetSchedulerJobSkipNext(ctx context.Context, name string, skip bool) error {
+	return genQ().SetSchedulerJobSkipNext(ctx, generated.SetSchedulerJobSkipNextParams{
+		Name:     name,
+		SkipNext: skip,
+	})
+}
+
+// TriggerSchedulerJob requests a manual run of a job. A pending request is kept, not duplicated.
+func TriggerSchedulerJob(ctx context.Context, name string) error {
+	now := time.Now()
+	return genQ().TriggerSchedulerJob(ctx, generated.TriggerSchedulerJobParams{
+		Name:        name,
+		TriggeredAt: f.MaybeTimeToNullTime(&now),
+	})
+}
+
+// ClearSchedulerJobTrigger removes the pending manual trigger of a job when its run starts.
+// Returns false if there was no trigger, e.g. because another process already took it.
+func ClearSchedulerJobTrigger(ctx context.Context, name string) (bool, error) {
+	rows, err := genQ().ClearSchedulerJobTrigger(ctx, name)
+	return rows > 0, err
+}
+
+// ConsumeSchedulerSlot records that the schedule of a job was due at the given time and resets SkipNext.
+// The slot is only taken if the last scheduled time is still the one the caller read (nil if never
+// scheduled), so of several processes seeing the same due slot only one gets true.
+func ConsumeSchedulerSlot(ctx context.Context, name string, lastScheduled *time.Time, at time.Time) (bool, error) {
+	rows, err := genQ().ConsumeSchedulerSlot(ctx, generated.ConsumeSchedulerSlotParams{
+		Name:              name,
+		LastScheduledAt:   f.MaybeTimeToNullTime(&at),
+		LastScheduledAt_2: f.MaybeTimeToNullTime(lastScheduled),
+	})
+	return rows > 0, err
+}
+
+// CreateSchedulerRun adds a run to the history. Running runs record the scheduler running them
+// as owner and start with a fresh heartbeat.
+func CreateSchedulerRun(ctx context.Context, run types.SchedulerRun, owner uuid.UUID) error {
+	return genQ().CreateSchedulerRun(ctx, generated.CreateSchedulerRunParams{
+		ID:          run.ID,
+		Job:         run.Job,
+		Trigger:     run.Trigger,
+		Status:      run.Status,
+		Error:       f.MaybeStringToNullString(run.Error),
+		StartedAt:   run.StartedAt,
+		FinishedAt:  f.MaybeTimeToNullTime(run.FinishedAt),
+		Owner:       uuid.NullUUID{UUID: owner, Valid: true},
+		HeartbeatAt: sql.NullTime{Time: time.Now(), Valid: true},
+	})
+}
+
+// TouchSchedulerRun refreshes the heartbeat of a running run
+func TouchSchedulerRun(ctx context.Context, id uuid.UUID) error {
+	return genQ().TouchSchedulerRun(ctx, id)
+}
+
+// FinishSchedulerRun stores the outcome of a run
+func FinishSchedulerRun(ctx context.Context, run types.SchedulerRun) error {
+	return genQ().FinishSchedulerRun(ctx, generated.FinishSchedulerRunParams{
+		Status:     run.Status,
+		Error:      f.MaybeStringToNullString(run.Error),
+		FinishedAt: f.MaybeTimeToNullTime(run.FinishedAt),
+		ID:         run.ID,
+	})
+}
+
+// FailOrphanedSchedulerRuns marks runs that are still running but can't be anymore as failed:
+// runs of owner, which calls this between its runs, and runs whose heartbeat is older than
+// staleBefore, i.e. whose process died. Runs of other live schedulers are left alone.
+func FailOrphanedSchedulerRuns(ctx context.Context, message string, owner uuid.UUID, staleBefore time.Time) (int, error) {
+	now := time.Now()
+	rows, err := genQ().FailOrphanedSchedulerRuns(ctx, generated.FailOrphanedSchedulerRunsParams{
+		Error:       f.StringToNullString(message),
+		FinishedAt:  f.MaybeTimeToNullTime(&now),
+		Owner:       uuid.NullUUID{UUID: owner, Valid: true},
+		HeartbeatAt: sql.NullTime{Time: staleBefore, Valid: true},
+	})
+	return int(rows), err
+}
+
+// ListSchedulerRuns returns the run history newest first, of all jobs if job is empty
+func ListSchedulerRuns(ctx context.Context, job string, limit int) ([]types.SchedulerRun, error) {
+	rows, err := genQ().ListSchedulerRuns(ctx, generated.ListSchedulerRunsParams{
+		Column1: job,
+		Limit:   int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]types.SchedulerRun, len(rows))
+	for i, row := range rows {
+		runs[i] = schedulerRunFromGen(row)
+	}
+	return runs, nil
+}
+
+// ListLastSchedulerRuns returns the latest run of each job by name
+func ListLastSchedulerRuns(ctx context.Context) (map[string]types.SchedulerRun, error) {
+	rows, err := genQ().ListLastSchedulerRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make(map[string]types.SchedulerRun, len(rows))
+	for _, row := range rows {
+		runs[row.Job] = schedulerRunFromGen(row)
+	}
+	return runs, nil
+}
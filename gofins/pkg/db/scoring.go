@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/flocko-motion/gofins/pkg/db/generated"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+)
+
+func scoringProfileFromGen(p generated.ScoringProfile) types.ScoringProfile {
+	return types.ScoringProfile{
+		ID:            p.ID,
+		Name:          p.Name,
+		WeightMean:    p.WeightMean,
+		WeightStdDev:  p.WeightStddev,
+		WeightMin:     p.WeightMin,
+		WeightMax:     p.WeightMax,
+		WeightBeta:    p.WeightBeta,
+		Normalization: p.Normalization,
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
+	}
+}
+
+// ListScoringProfiles returns all scoring profiles of a user, ordered by name
+func ListScoringProfiles(ctx context.Context, userID uuid.UUID) ([]types.ScoringProfile, error) {
+	genProfiles, err := genQ().ListScoringProfiles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]types.ScoringProfile, len(genProfiles))
+	for i, p := range genProfiles {
+		profiles[i] = scoringProfileFromGen(p)
+	}
+	return profiles, nil
+}
+
+// GetScoringProfile retrieves a scoring profile by ID for a specific user
+func GetScoringProfile(ctx context.Context, userID, profileID uuid.UUID) (*types.ScoringProfile, error) {
+	genProfile, err := genQ().GetScoringProfile(ctx, generated.GetScoringProfileParams{
+		ID:     profileID,
+		UserID: userID,
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	profile := scoringProfileFromGen(genProfile)
+	return &profile, nil
+}
+
+// CreateScoringProfile stores a new scoring profile for a user
+func CreateScoringProfile(ctx context.Context, userID uuid.UUID, profile types.ScoringProfile) (*types.ScoringProfile, error) {
+	genProfile, err := genQ().CreateScoringProfile(ctx, generated.CreateScoringProfileParams{
+		ID:            uuid.New(),
+		UserID:        userID,
+		Name:          profile.Name,
+		WeightMean:    profile.WeightMean,
+		WeightStddev:  profile.WeightStdDev,
+		WeightMin:     profile.WeightMin,
+		WeightMax:     profile.WeightMax,
+		WeightBeta:    profile.WeightBeta,
+		Normalization: profile.Normalization,
+	})
+	if err != nil {
+		return nil, err
+	}
+	created := scoringProfileFromGen(genProfile)
+	return &created, nil
+}
+
+// UpdateScoringProfile replaces name, weights and normalization of a user's scoring profile
+// Returns nil if the profile doesn't exist or belongs to another user
+func UpdateScoringProfile(ctx context.Context, userID uuid.UUID, profile types.ScoringProfile) (*types.ScoringProfile, error) {
+	genProfile, err := genQ().UpdateScoringProfile(ctx, generated.UpdateScoringProfileParams{
+		Name:          profile.Name,
+		WeightMean:    profile.WeightMean,
+		WeightStddev:  profile.WeightStdDev,
+		WeightMin:     profile.WeightMin,
+		WeightMax:     profile.WeightMax,
+		WeightBeta:    profile.WeightBeta,
+		Normalization: profile.Normalization,
+		ID:            profile.ID,
+		UserID:        userID,
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	updated := scoringProfileFromGen(genProfile)
+	return &updated, nil
+}
+
+// DeleteScoringProfile deletes a scoring profile for a specific user
+func DeleteScoringProfile(ctx context.Context, userID, profileID uuid.UUID) error {
+	return genQ().DeleteScoringProfile(ctx, generated.DeleteScoringProfileParams{
+		ID:     profileID,
+		UserID: userID,
+	})
+}
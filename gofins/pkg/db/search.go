@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Full-text search expressions. These must match the GIN indexes in schema.sql
+// (idx_symbols_search, idx_user_ratings_notes_search, idx_user_journal_search).
+const (
+	symbolSearchVector = `(setweight(to_tsvector('english', coalesce(s.name, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(s.industry, '')), 'B') ||
+		setweight(to_tsvector('english', coalesce(s.description, '')), 'C'))`
+	ratingSearchVector  = `to_tsvector('english', coalesce(r.notes, ''))`
+	journalSearchVector = `(setweight(to_tsvector('english', j.title), 'A') ||
+		setweight(to_tsvector('english', j.content), 'B'))`
+
+	// ts_headline doesn't escape the text, so it marks matches with sentinels that are replaced
+	// by <mark> tags after escaping the snippet in searchSnippet
+	searchHeadlineOptions = `'StartSel=` + snippetStartSel + `, StopSel=` + snippetStopSel +
+		`, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "'`
+	snippetStartSel = "\ue000"
+	snippetStopSel  = "\ue001"
+)
+
+// searchSnippet HTML-escapes a ts_headline result and turns its sentinels into <mark> tags
+func searchSnippet(headline string) string {
+	headline = html.EscapeString(headline)
+	return strings.NewReplacer(snippetStartSel, "<mark>", snippetStopSel, "</mark>").Replace(headline)
+}
+
+// SearchSymbols searches ticker, name, industry and description of all symbols.
+// An exact ticker match always ranks first. The ticker match is a separate branch of the
+// union, so the full-text branch can use idx_symbols_search.
+func SearchSymbols(ctx context.Context, query string, limit int) ([]types.SearchHit, error) {
+	sqlQuery := `
+		WITH q AS (SELECT websearch_to_tsquery('english', $1) AS query),
+		candidates AS (
+			SELECT s.ticker FROM symbols s, q WHERE ` + symbolSearchVector + ` @@ q.query
+			UNION
+			SELECT s.ticker FROM symbols s WHERE s.ticker = upper($1)
+		),
+		matches AS (
+			SELECT s.ticker, s.name, s.industry, s.description,
+			       ts_rank_cd(` + symbolSearchVector + `, q.query)
+			       + CASE WHEN s.ticker = upper($1) THEN 10 ELSE 0 END AS rank
+			FROM candidates c
+			JOIN symbols s ON s.ticker = c.ticker
+			CROSS JOIN q
+			ORDER BY rank DESC, s.ticker
+			LIMIT $2
+		)
+		SELECT m.ticker, coalesce(m.name, m.ticker), m.rank,
+		       ts_headline('english',
+		           coalesce(m.name, '') || ' — ' || coalesce(m.industry, '') || '. ' || coalesce(m.description, ''),
+		           q.query, ` + searchHeadlineOptions + `)
+		FROM matches m, q
+		ORDER BY m.rank DESC, m.ticker
+	`
+
+	rows, err := Db().conn.QueryContext(ctx, sqlQuery, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := []types.SearchHit{}
+	for rows.Next() {
+		hit := types.SearchHit{Kind: types.SearchKindSymbol}
+		var ticker string
+		if err := rows.Scan(&ticker, &hit.Title, &hit.Rank, &hit.Snippet); err != nil {
+			return nil, err
+		}
+		hit.Snippet = searchSnippet(hit.Snippet)
+		hit.Tickers = []string{ticker}
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
+
+// SearchRatingNotes searches the notes of a user's own ratings
+func SearchRatingNotes(ctx context.Context, userID uuid.UUID, query string, limit int) ([]types.SearchHit, error) {
+	sqlQuery := `
+		WITH q AS (SELECT websearch_to_tsquery('english', $1) AS query),
+		matches AS (
+			SELECT r.id, r.ticker, r.rating, r.notes, r.created_at,
+			       ts_rank_cd(` + ratingSearchVector + `, q.query) AS rank
+			FROM user_ratings r, q
+			WHERE r.user_id = $2
+			  AND ` + ratingSearchVector + ` @@ q.query
+			ORDER BY rank DESC, r.created_at DESC
+			LIMIT $3
+		)
+		SELECT m.id, m.ticker, m.rating, m.created_at, m.rank,
+		       ts_headline('english', m.notes, q.query, ` + searchHeadlineOptions + `)
+		FROM matches m, q
+		ORDER BY m.rank DESC, m.created_at DESC
+	`
+
+	rows, err := Db().conn.QueryContext(ctx, sqlQuery, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := []types.SearchHit{}
+	for rows.Next() {
+		hit := types.SearchHit{Kind: types.SearchKindRating}
+		var id, rating int
+		var ticker string
+		var createdAt time.Time
+		if err := rows.Scan(&id, &ticker, &rating, &createdAt, &hit.Rank, &hit.Snippet); err != nil {
+			return nil, err
+		}
+		hit.ID = &id
+		hit.Snippet = searchSnippet(hit.Snippet)
+		hit.Title = ticker
+		hit.Tickers = []string{ticker}
+		hit.Rating = &rating
+		hit.CreatedAt = &createdAt
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
+
+// SearchJournal searches title and content of a user's own journal entries
+func SearchJournal(ctx context.Context, userID uuid.UUID, query string, limit int) ([]types.SearchHit, error) {
+	sqlQuery := `
+		WITH q AS (SELECT websearch_to_tsquery('english', $1) AS query),
+		matches AS (
+			SELECT j.id, j.title, j.content, j.created_at,
+			       ts_rank_cd(` + journalSearchVector + `, q.query) AS rank
+			FROM user_journal j, q
+			WHERE j.user_id = $2
+			  AND ` + journalSearchVector + ` @@ q.query
+			ORDER BY rank DESC, j.created_at DESC
+			LIMIT $3
+		)
+		SELECT m.id, m.title, m.created_at, m.rank,
+		       ts_headline('english', m.title || '. ' || m.content, q.query, ` + searchHeadlineOptions + `),
+		       COALESCE((SELECT array_agg(jt.ticker ORDER BY jt.ticker) FROM journal_tickers jt WHERE jt.journal_id = m.id), '{}')::text[]
+		FROM matches m, q
+		ORDER BY m.rank DESC, m.created_at DESC
+	`
+
+	rows, err := Db().conn.QueryContext(ctx, sqlQuery, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := []types.SearchHit{}
+	for rows.Next() {
+		hit := types.SearchHit{Kind: types.SearchKindJournal}
+		var id int
+		var createdAt time.Time
+		if err := rows.Scan(&id, &hit.Title, &createdAt, &hit.Rank, &hit.Snippet, pq.Array(&hit.Tickers)); err != nil {
+			return nil, err
+		}
+		hit.ID = &id
+		hit.Snippet = searchSnippet(hit.Snippet)
+		hit.CreatedAt = &createdAt
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
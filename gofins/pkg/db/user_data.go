@@ -24,7 +24,14 @@ func CreateUser(ctx context.Context, name string) (*types.User, error) {
 	// Generate stable UUID from username (hash-based)
 	userID := f.StringToUUID(name)
 
-	genUser, err := genQ().CreateUser(ctx, generated.CreateUserParams{
+	tx, err := Db().conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	q := genQ().WithTx(tx)
+	genUser, err := q.CreateUser(ctx, generated.CreateUserParams{
 		ID:      userID,
 		Name:    name,
 		IsAdmin: isAdmin,
@@ -32,6 +39,13 @@ func CreateUser(ctx context.Context, name string) (*types.User, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Every user has a favorites watchlist, so reading the watchlists never has to create it
+	if _, err := ensureDefaultWatchlist(ctx, q, userID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 
 	return &types.User{
 		ID:        genUser.ID,
@@ -94,7 +108,7 @@ func ListUsers(ctx context.Context) ([]types.User, error) {
 	return users, nil
 }
 
-// DeleteUser deletes a user and all their data (ratings, favorites)
+// DeleteUser deletes a user and all their data (ratings, watchlists)
 func DeleteUser(ctx context.Context, name string) error {
 	// Get user to find their UUID
 	user, err := GetUser(ctx, name)
@@ -119,8 +133,8 @@ func DeleteUser(ctx context.Context, name string) error {
 		return err
 	}
 
-	// Delete user's favorites
-	if err := q.DeleteUserFavorites(ctx, user.ID); err != nil {
+	// Delete user's watchlists including favorites
+	if err := q.DeleteUserWatchlists(ctx, user.ID); err != nil {
 		return err
 	}
 
@@ -183,35 +197,36 @@ type UserRating struct {
 	CreatedAt time.Time `json:"createdAt"`
 }
 
-// ToggleFavorite adds or removes a symbol from favorites
+// ToggleFavorite adds or removes a symbol from the user's favorites watchlist
 func ToggleFavorite(ctx context.Context, userID uuid.UUID, ticker string) (bool, error) {
-	// Check if already favorited
-	exists, err := genQ().IsFavorite(ctx, generated.IsFavoriteParams{
-		UserID: userID,
-		Ticker: ticker,
-	})
+	tx, err := Db().conn.Begin()
 	if err != nil {
 		return false, err
 	}
+	defer tx.Rollback()
 
-	if exists {
-		// Remove from favorites
-		err = genQ().RemoveFavorite(ctx, generated.RemoveFavoriteParams{
-			UserID: userID,
-			Ticker: ticker,
-		})
+	q := genQ().WithTx(tx)
+	watchlist, err := ensureDefaultWatchlist(ctx, q, userID)
+	if err != nil {
 		return false, err
-	} else {
-		// Add to favorites
-		err = genQ().AddFavorite(ctx, generated.AddFavoriteParams{
-			UserID: userID,
-			Ticker: ticker,
-		})
-		return true, err
 	}
+	removed, err := q.RemoveWatchlistEntries(ctx, generated.RemoveWatchlistEntriesParams{
+		WatchlistID: watchlist.ID,
+		Column2:     []string{ticker},
+	})
+	if err != nil {
+		return false, err
+	}
+	added := 0
+	if removed == 0 {
+		if added, err = addWatchlistEntries(ctx, q, watchlist.ID, []types.WatchlistEntry{{Ticker: ticker}}); err != nil {
+			return false, err
+		}
+	}
+	return added > 0, tx.Commit()
 }
 
-// IsFavorite checks if a symbol is favorited
+// IsFavorite checks if a symbol is on the user's favorites watchlist
 func IsFavorite(ctx context.Context, userID uuid.UUID, ticker string) (bool, error) {
 	return genQ().IsFavorite(ctx, generated.IsFavoriteParams{
 		UserID: userID,
@@ -219,7 +234,7 @@ func IsFavorite(ctx context.Context, userID uuid.UUID, ticker string) (bool, err
 	})
 }
 
-// GetFavorites returns all favorited tickers
+// GetFavorites returns the tickers of the user's favorites watchlist in list order
 func GetFavorites(ctx context.Context, userID uuid.UUID) ([]string, error) {
 	return genQ().GetFavorites(ctx, userID)
 }
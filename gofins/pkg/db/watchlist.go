@@ -0,0 +1,249 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db/generated"
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+)
+
+func watchlistFromGen(w generated.Watchlist) types.Watchlist {
+	return types.Watchlist{
+		ID:        w.ID,
+		UserID:    w.UserID,
+		Name:      w.Name,
+		IsDefault: w.IsDefault,
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+// ensureDefaultWatchlist returns the favorites watchlist of a user, creating it on first use
+func ensureDefaultWatchlist(ctx context.Context, q *generated.Queries, userID uuid.UUID) (generated.Watchlist, error) {
+	return q.EnsureDefaultWatchlist(ctx, generated.EnsureDefaultWatchlistParams{
+		ID:     uuid.New(),
+		UserID: userID,
+		Name:   types.DefaultWatchlistName,
+	})
+}
+
+// ListWatchlists returns all watchlists of a user with their entry counts, the favorites list first.
+// Read-only: the favorites list is created together with the user in CreateUser.
+func ListWatchlists(ctx context.Context, userID uuid.UUID) ([]types.Watchlist, error) {
+	rows, err := genQ().ListWatchlists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	watchlists := make([]types.Watchlist, len(rows))
+	for i, row := range rows {
+		watchlists[i] = types.Watchlist{
+			ID:         row.ID,
+			UserID:     row.UserID,
+			Name:       row.Name,
+			IsDefault:  row.IsDefault,
+			EntryCount: int(row.EntryCount),
+			CreatedAt:  row.CreatedAt,
+		}
+	}
+	return watchlists, nil
+}
+
+// GetWatchlist retrieves a watchlist of a user with its entries in list order, nil if it doesn't exist
+func GetWatchlist(ctx context.Context, userID, id uuid.UUID) (*types.Watchlist, error) {
+	genWatchlist, err := genQ().GetWatchlist(ctx, generated.GetWatchlistParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := genQ().ListWatchlistEntries(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	watchlist := watchlistFromGen(genWatchlist)
+	watchlist.EntryCount = len(rows)
+	watchlist.Entries = make([]types.WatchlistEntry, len(rows))
+	for i, row := range rows {
+		entry := types.WatchlistEntry{
+			Ticker:           row.Ticker,
+			Position:         i,
+			Note:             f.NullStringToMaybeString(row.Note),
+			TargetPrice:      f.NullFloat64ToMaybeFloat64(row.TargetPrice),
+			Name:             f.NullStringToMaybeString(row.Name),
+			CurrentPriceUsd:  f.NullFloat64ToMaybeFloat64(row.CurrentPriceUsd),
+			CurrentPriceTime: f.NullTimeToMaybeTime(row.CurrentPriceTime),
+			CreatedAt:        row.CreatedAt,
+		}
+		if entry.TargetPrice != nil && entry.CurrentPriceUsd != nil && *entry.CurrentPriceUsd > 0 {
+			entry.TargetDistance = f.Ptr((*entry.TargetPrice/(*entry.CurrentPriceUsd) - 1) * 100)
+		}
+		watchlist.Entries[i] = entry
+	}
+	return &watchlist, nil
+}
+
+// CreateWatchlist stores a new, empty watchlist for a user
+func CreateWatchlist(ctx context.Context, userID uuid.UUID, name string) (*types.Watchlist, error) {
+	// The favorites list must exist first, so it can't be blocked by a user list of the same name
+	if _, err := ensureDefaultWatchlist(ctx, genQ(), userID); err != nil {
+		return nil, err
+	}
+	genWatchlist, err := genQ().CreateWatchlist(ctx, generated.CreateWatchlistParams{
+		ID:     uuid.New(),
+		UserID: userID,
+		Name:   name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	created := watchlistFromGen(genWatchlist)
+	return &created, nil
+}
+
+// RenameWatchlist changes the name of a user's watchlist
+// Returns nil if the watchlist doesn't exist or belongs to another user
+func RenameWatchlist(ctx context.Context, userID, id uuid.UUID, name string) (*types.Watchlist, error) {
+	genWatchlist, err := genQ().RenameWatchlist(ctx, generated.RenameWatchlistParams{
+		Name:   name,
+		ID:     id,
+		UserID: userID,
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	renamed := watchlistFromGen(genWatchlist)
+	return &renamed, nil
+}
+
+// DeleteWatchlist removes a watchlist of a user with its entries. The favorites list is never deleted.
+// Returns false if no watchlist was deleted.
+func DeleteWatchlist(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	rows, err := genQ().DeleteWatchlist(ctx, generated.DeleteWatchlistParams{
+		ID:     id,
+		UserID: userID,
+	})
+	return rows > 0, err
+}
+
+// AddWatchlistEntries appends entries to the end of a watchlist in the given order.
+// Tickers already on the list are left unchanged. Returns the number of added entries.
+func AddWatchlistEntries(ctx context.Context, watchlistID uuid.UUID, entries []types.WatchlistEntry) (int, error) {
+	tx, err := Db().conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	q := genQ().WithTx(tx)
+	added, err := addWatchlistEntries(ctx, q, watchlistID, entries)
+	if err != nil {
+		return 0, err
+	}
+	return added, tx.Commit()
+}
+
+func addWatchlistEntries(ctx context.Context, q *generated.Queries, watchlistID uuid.UUID, entries []types.WatchlistEntry) (int, error) {
+	added := 0
+	for _, entry := range entries {
+		createdAt := entry.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+		rows, err := q.AddWatchlistEntry(ctx, generated.AddWatchlistEntryParams{
+			WatchlistID: watchlistID,
+			Ticker:      entry.Ticker,
+			Note:        f.MaybeStringToNullString(entry.Note),
+			TargetPrice: f.MaybeFloat64ToNullFloat64(entry.TargetPrice),
+			CreatedAt:   createdAt,
+		})
+		if err != nil {
+			return added, err
+		}
+		added += int(rows)
+	}
+	return added, nil
+}
+
+// UpdateWatchlistEntry replaces note and target price of an entry. Returns false if the ticker isn't on the list.
+func UpdateWatchlistEntry(ctx context.Context, watchlistID uuid.UUID, entry types.WatchlistEntry) (bool, error) {
+	rows, err := genQ().UpdateWatchlistEntry(ctx, generated.UpdateWatchlistEntryParams{
+		Note:        f.MaybeStringToNullString(entry.Note),
+		TargetPrice: f.MaybeFloat64ToNullFloat64(entry.TargetPrice),
+		WatchlistID: watchlistID,
+		Ticker:      entry.Ticker,
+	})
+	return rows > 0, err
+}
+
+// RemoveWatchlistEntries removes tickers from a watchlist. Returns the number of removed entries.
+func RemoveWatchlistEntries(ctx context.Context, watchlistID uuid.UUID, tickers []string) (int, error) {
+	rows, err := genQ().RemoveWatchlistEntries(ctx, generated.RemoveWatchlistEntriesParams{
+		WatchlistID: watchlistID,
+		Column2:     tickers,
+	})
+	return int(rows), err
+}
+
+// ReorderWatchlist moves the given tickers to the top of a watchlist in the given order.
+// Entries not mentioned keep their relative order after them; unknown tickers are ignored.
+func ReorderWatchlist(ctx context.Context, watchlistID uuid.UUID, tickers []string) error {
+	tx, err := Db().conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := genQ().WithTx(tx)
+	rows, err := q.ListWatchlistEntries(ctx, watchlistID)
+	if err != nil {
+		return err
+	}
+
+	onList := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		onList[row.Ticker] = true
+	}
+	order := make([]string, 0, len(rows))
+	placed := make(map[string]bool, len(rows))
+	for _, ticker := range tickers {
+		if onList[ticker] && !placed[ticker] {
+			order = append(order, ticker)
+			placed[ticker] = true
+		}
+	}
+	for _, row := range rows {
+		if !placed[row.Ticker] {
+			order = append(order, row.Ticker)
+		}
+	}
+
+	for i, ticker := range order {
+		if err := q.SetWatchlistEntryOrder(ctx, generated.SetWatchlistEntryOrderParams{
+			SortOrder:   int32(i),
+			WatchlistID: watchlistID,
+			Ticker:      ticker,
+		}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DefaultWatchlistID returns the ID of the user's favorites watchlist, creating it on first use
+func DefaultWatchlistID(ctx context.Context, userID uuid.UUID) (uuid.UUID, error) {
+	watchlist, err := ensureDefaultWatchlist(ctx, genQ(), userID)
+	return watchlist.ID, err
+}
@@ -0,0 +1,118 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types
+const (
+	TypeAnalysisProgress = "analysis.progress" // Data: types.AnalysisJob of a running job
+	TypeAnalysisFinished = "analysis.finished" // Data: types.AnalysisJob after it became ready, failed or cancelled
+	TypeUpdaterStep      = "updater.step"      // Data: types.SchedulerRun when an updater job starts, finishes or is skipped
+)
+
+// subscriberBuffer is the number of events buffered per subscriber. Events for a
+// subscriber that falls behind further are dropped instead of blocking publishers.
+const subscriberBuffer = 64
+
+// Event is a notification for connected clients. Events with a UserID are only
+// delivered to that user, events with AdminOnly only to admins, others to everyone.
+type Event struct {
+	Type      string     `json:"type"`
+	Time      time.Time  `json:"time"`
+	Data      any        `json:"data"`
+	UserID    *uuid.UUID `json:"-"`
+	AdminOnly bool       `json:"-"`
+}
+
+// Broker fans out published events to subscribers
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	userID uuid.UUID
+	admin  bool
+	ch     chan Event
+}
+
+// NewBroker creates a broker without subscribers
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[*subscriber]struct{})}
+}
+
+// Subscribe registers a subscriber for the events visible to a user.
+// The returned function unsubscribes and closes the channel.
+func (b *Broker) Subscribe(userID uuid.UUID, admin bool) (<-chan Event, func()) {
+	sub := &subscriber{userID: userID, admin: admin, ch: make(chan Event, subscriberBuffer)}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, sub)
+			b.mu.Unlock()
+			close(sub.ch)
+		})
+	}
+}
+
+// Publish delivers an event to all subscribers allowed to see it without blocking
+func (b *Broker) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subscribers {
+		if !sub.allowed(event) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+		}
+	}
+}
+
+// allowed reports whether the subscriber may see the event
+func (s *subscriber) allowed(event Event) bool {
+	if event.AdminOnly && !s.admin {
+		return false
+	}
+	if event.UserID != nil && *event.UserID != s.userID {
+		return false
+	}
+	return true
+}
+
+var defaultBroker = NewBroker()
+
+// Subscribe registers a subscriber at the default broker
+func Subscribe(userID uuid.UUID, admin bool) (<-chan Event, func()) {
+	return defaultBroker.Subscribe(userID, admin)
+}
+
+// Publish sends an event through the default broker
+func Publish(event Event) {
+	defaultBroker.Publish(event)
+}
+
+// PublishUser sends an event only visible to a user through the default broker
+func PublishUser(userID uuid.UUID, eventType string, data any) {
+	defaultBroker.Publish(Event{Type: eventType, Data: data, UserID: &userID})
+}
+
+// PublishAdmin sends an event only visible to admins through the default broker
+func PublishAdmin(eventType string, data any) {
+	defaultBroker.Publish(Event{Type: eventType, Data: data, AdminOnly: true})
+}
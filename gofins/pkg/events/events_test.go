@@ -0,0 +1,73 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPublishFiltersByAudience(t *testing.T) {
+	b := NewBroker()
+	alice, bob := uuid.New(), uuid.New()
+
+	aliceCh, unsubAlice := b.Subscribe(alice, false)
+	defer unsubAlice()
+	adminCh, unsubAdmin := b.Subscribe(bob, true)
+	defer unsubAdmin()
+
+	b.Publish(Event{Type: TypeAnalysisProgress, UserID: &alice})
+	b.Publish(Event{Type: TypeUpdaterStep, AdminOnly: true})
+	b.Publish(Event{Type: "broadcast"})
+
+	if got := drain(aliceCh); len(got) != 2 || got[0].Type != TypeAnalysisProgress || got[1].Type != "broadcast" {
+		t.Errorf("alice got %v, want analysis progress and broadcast", types(got))
+	}
+	if got := drain(adminCh); len(got) != 2 || got[0].Type != TypeUpdaterStep || got[1].Type != "broadcast" {
+		t.Errorf("admin got %v, want updater step and broadcast", types(got))
+	}
+}
+
+func TestPublishDropsWhenSubscriberIsFull(t *testing.T) {
+	b := NewBroker()
+	ch, unsub := b.Subscribe(uuid.New(), false)
+	defer unsub()
+
+	for i := 0; i < subscriberBuffer+10; i++ {
+		b.Publish(Event{Type: "broadcast"})
+	}
+	if got := len(drain(ch)); got != subscriberBuffer {
+		t.Errorf("got %d events, want %d", got, subscriberBuffer)
+	}
+}
+
+func TestUnsubscribeClosesChannel(t *testing.T) {
+	b := NewBroker()
+	ch, unsub := b.Subscribe(uuid.New(), false)
+	unsub()
+	unsub() // must not panic
+
+	if _, ok := <-ch; ok {
+		t.Error("expected closed channel")
+	}
+	b.Publish(Event{Type: "broadcast"}) // must not panic on the closed channel
+}
+
+func drain(ch <-chan Event) []Event {
+	var events []Event
+	for {
+		select {
+		case event := <-ch:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func types(events []Event) []string {
+	names := make([]string, len(events))
+	for i, event := range events {
+		names[i] = event.Type
+	}
+	return names
+}
@@ -65,3 +65,19 @@ func ParseDate(s string) (time.Time, error) {
 
 	return time.Time{}, fmt.Errorf("invalid date format (expected YYYY, YYYY-MM or YYYY-MM-DD)")
 }
+
+// ParseDateEnd parses dates like ParseDate and returns the exclusive end of the given period:
+// the next day for YYYY-MM-DD, the first of the next month for YYYY-MM, the next January 1st for YYYY
+func ParseDateEnd(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t.AddDate(0, 0, 1), nil
+	}
+	if t, err := time.Parse("2006-01", s); err == nil {
+		return t.AddDate(0, 1, 0), nil
+	}
+	if t, err := time.Parse("2006", s); err == nil {
+		return t.AddDate(1, 0, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date format (expected YYYY, YYYY-MM or YYYY-MM-DD)")
+}
@@ -11,12 +11,7 @@ import (
 )
 
 // GetBulkEOD fetches bulk end-of-day data for a specific date
-func GetBulkEOD(date time.Time) (map[string]*types.PriceData, error) {
-	return Fmp().getBulkEOD(date)
-}
-
-// getBulkEOD is the internal implementation that has access to private fields
-func (c *Client) getBulkEOD(date time.Time) (map[string]*types.PriceData, error) {
+func (c *Client) GetBulkEOD(date time.Time) (map[string]*types.DailyPrice, error) {
 	dateStr := date.Format("2006-01-02")
 	endpoint := fmt.Sprintf("stable/eod-bulk?date=%s", dateStr)
 	
@@ -35,7 +30,7 @@ func (c *Client) getBulkEOD(date time.Time) (map[string]*types.PriceData, error)
 		return nil, fmt.Errorf("failed to read CSV header: %w", err)
 	}
 
-	quotes := make(map[string]*types.PriceData)
+	quotes := make(map[string]*types.DailyPrice)
 	for {
 		record, err := reader.Read()
 		if err == io.EOF {
@@ -55,7 +50,8 @@ func (c *Client) getBulkEOD(date time.Time) (map[string]*types.PriceData, error)
 		lowStr := record[3]
 		highStr := record[4]
 		closeStr := record[5]
-		// record[6] is adjClose, record[7] is volume - we don't use these
+		adjCloseStr := record[6]
+		volumeStr := record[7]
 
 		// Parse date
 		quoteDate, err := time.Parse("2006-01-02", dateStr)
@@ -81,17 +77,26 @@ func (c *Client) getBulkEOD(date time.Time) (map[string]*types.PriceData, error)
 			continue // Skip invalid prices
 		}
 
-		// Calculate average price
-		avg := (open + high + low + close) / 4.0
+		// adjClose and volume are optional - keep the quote without them
+		var adjClose *float64
+		if v, err := strconv.ParseFloat(adjCloseStr, 64); err == nil && v > 0 {
+			adjClose = &v
+		}
+		var volume *int64
+		if v, err := strconv.ParseFloat(volumeStr, 64); err == nil && v >= 0 {
+			vol := int64(v)
+			volume = &vol
+		}
 
-		quotes[symbol] = &types.PriceData{
+		quotes[symbol] = &types.DailyPrice{
 			SymbolTicker: symbol,
 			Date:         quoteDate,
 			Open:         open,
 			High:         high,
 			Low:          low,
-			Avg:          avg,
 			Close:        close,
+			AdjClose:     adjClose,
+			Volume:       volume,
 		}
 	}
 
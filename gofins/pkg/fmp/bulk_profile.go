@@ -11,12 +11,7 @@ import (
 
 // GetBulkProfiles fetches all company profiles from the bulk endpoint
 // The API is paginated, so we fetch all pages and merge them
-func GetBulkProfiles() ([]*Profile, error) {
-	return Fmp().getBulkProfiles()
-}
-
-// getBulkProfiles is the internal implementation that has access to private fields
-func (c *Client) getBulkProfiles() ([]*Profile, error) {
+func (c *Client) GetBulkProfiles() ([]*Profile, error) {
 	var allProfiles []*Profile
 	part := 0
 
@@ -11,6 +11,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -98,24 +99,62 @@ func cleanupOldCache() {
 
 const (
 	BaseURL           = "https://financialmodelingprep.com"
-	RequestsPerMinute = 3000 // ultimate: 3000 starter: 300
+	RequestsPerMinute = 3000 // default, ultimate: 3000 starter: 300
 	MaxRetries        = 5
 	BaseRetryDelay    = 3 * time.Second
 	ApiKeyPathDefault = "~/.fins/config/financialmodelingprep.key"
+	BulkRequestDelay  = 30 * time.Second // pause after each uncached bulk download
+	BulkWeight        = 100              // rate limit and quota weight of a bulk download
 )
 
+// Environment variables setting the limits of the FMP plan
+const (
+	EnvRequestsPerMinute = "FMP_REQUESTS_PER_MINUTE"
+	EnvBurst             = "FMP_BURST"
+	EnvDailyQuota        = "FMP_DAILY_QUOTA"
+	EnvMonthlyQuota      = "FMP_MONTHLY_QUOTA"
+)
+
+// endpointWeights weighs the bulk downloads, which are far larger than other requests
+var endpointWeights = map[string]int{
+	"stable/eod-bulk":     BulkWeight,
+	"stable/profile-bulk": BulkWeight,
+}
+
 // Client handles all FMP API interactions
 type Client struct {
-	apiKey      string
-	httpClient  *http.Client
-	rateLimiter *ratelimit.Limiter
+	apiKey           string
+	baseURL          string
+	httpClient       *http.Client
+	rateLimiter      *ratelimit.Limiter
+	bulkRequestDelay time.Duration
+	cacheEnabled     bool
+}
+
+// ClientConfig configures a Client created with NewClient
+type ClientConfig struct {
+	APIKey            string
+	BaseURL           string               // default: BaseURL
+	RequestsPerMinute int                  // default: RequestsPerMinute
+	Burst             int                  // default: one second of requests
+	DailyQuota        int64                // request weight per UTC day (0 = unlimited)
+	MonthlyQuota      int64                // request weight per UTC month (0 = unlimited)
+	QuotaStore        ratelimit.QuotaStore // persists the quota usage (nil = in memory)
+	BulkRequestDelay  time.Duration        // pause after each uncached bulk download (0 = none)
+	DisableCache      bool                 // don't read or write the bulk download cache
 }
 
 var (
 	globalClient *Client
 	clientOnce   sync.Once
+	quotaStore   ratelimit.QuotaStore
 )
 
+// SetQuotaStore sets the store of the quota usage of the global client, before its first use
+func SetQuotaStore(store ratelimit.QuotaStore) {
+	quotaStore = store
+}
+
 // Fmp returns the global FMP client, initializing it on first call
 func Fmp() *Client {
 	clientOnce.Do(func() {
@@ -128,7 +167,9 @@ func Fmp() *Client {
 	return globalClient
 }
 
-// newClient creates a new FMP API client
+// newClient creates the production FMP API client.
+// The base URL can be overridden with FMP_BASE_URL (e.g. to point at a fake server),
+// the limits of the plan with FMP_REQUESTS_PER_MINUTE, FMP_BURST, FMP_DAILY_QUOTA and FMP_MONTHLY_QUOTA.
 func newClient(apiKeyPath *string) (*Client, error) {
 	if apiKeyPath == nil {
 		apiKeyPath = f.Ptr(ApiKeyPathDefault)
@@ -139,10 +180,54 @@ func newClient(apiKeyPath *string) (*Client, error) {
 		return nil, fmt.Errorf("failed to read API key: %w", err)
 	}
 
+	cfg := ClientConfig{
+		APIKey:           apiKey,
+		BaseURL:          os.Getenv("FMP_BASE_URL"),
+		QuotaStore:       quotaStore,
+		BulkRequestDelay: BulkRequestDelay,
+	}
+	var rpm, burst int64
+	limits := map[string]*int64{
+		EnvRequestsPerMinute: &rpm,
+		EnvBurst:             &burst,
+		EnvDailyQuota:        &cfg.DailyQuota,
+		EnvMonthlyQuota:      &cfg.MonthlyQuota,
+	}
+	for key, value := range limits {
+		if *value, err = envInt(key); err != nil {
+			return nil, err
+		}
+	}
+	cfg.RequestsPerMinute, cfg.Burst = int(rpm), int(burst)
+
+	return NewClient(cfg)
+}
+
+// envInt reads a non-negative integer from an environment variable, 0 if unset
+func envInt(key string) (int64, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s: %q", key, value)
+	}
+	return n, nil
+}
+
+// NewClient creates an FMP API client from an explicit configuration
+func NewClient(cfg ClientConfig) (*Client, error) {
 	// Validate API key
-	if len(apiKey) < 10 {
+	if len(cfg.APIKey) < 10 {
 		return nil, fmt.Errorf("invalid API key: too short")
 	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = BaseURL
+	}
+	if cfg.RequestsPerMinute <= 0 {
+		cfg.RequestsPerMinute = RequestsPerMinute
+	}
 
 	// Create HTTP client with connection pooling
 	// Optimized for high-latency connections (e.g., EU server to US API)
@@ -159,12 +244,23 @@ func newClient(apiKeyPath *string) (*Client, error) {
 	}
 
 	// Create rate limiter
-	limiter := ratelimit.NewLimiter(RequestsPerMinute)
+	limiter := ratelimit.NewLimiter(ratelimit.Config{
+		Name:              "fmp",
+		RequestsPerMinute: cfg.RequestsPerMinute,
+		Burst:             cfg.Burst,
+		Weights:           endpointWeights,
+		DailyQuota:        cfg.DailyQuota,
+		MonthlyQuota:      cfg.MonthlyQuota,
+		Store:             cfg.QuotaStore,
+	})
 
 	return &Client{
-		apiKey:      apiKey,
-		httpClient:  httpClient,
-		rateLimiter: limiter,
+		apiKey:           cfg.APIKey,
+		baseURL:          strings.TrimSuffix(cfg.BaseURL, "/"),
+		httpClient:       httpClient,
+		rateLimiter:      limiter,
+		bulkRequestDelay: cfg.BulkRequestDelay,
+		cacheEnabled:     !cfg.DisableCache,
 	}, nil
 }
 
@@ -195,12 +291,17 @@ func (c *Client) apiGet(endpoint string, params map[string]string, result interf
 	if err != nil {
 		return err
 	}
+	name := endpointName(endpoint)
 
 	// Retry loop with exponential backoff
 	var lastErr error
 	for attempt := 0; attempt < MaxRetries; attempt++ {
+		if attempt > 0 {
+			c.rateLimiter.RecordRetry(name)
+		}
+
 		// Wait for rate limit
-		if err := c.rateLimiter.Wait(); err != nil {
+		if err := c.rateLimiter.Wait(name); err != nil {
 			return fmt.Errorf("rate limiter error: %w", err)
 		}
 
@@ -211,6 +312,7 @@ func (c *Client) apiGet(endpoint string, params map[string]string, result interf
 		
 		if err != nil {
 			logger.Printf("Network error (attempt %d/%d) after %.2fs: %v\n", attempt+1, MaxRetries, requestDuration.Seconds(), err)
+			c.rateLimiter.Record(name, ratelimit.ResultError)
 			lastErr = err
 			delay := BaseRetryDelay * time.Duration(1<<uint(attempt))
 			time.Sleep(delay)
@@ -232,31 +334,28 @@ func (c *Client) apiGet(endpoint string, params map[string]string, result interf
 		resp.Body.Close()
 
 		if err == nil {
-			c.rateLimiter.LogRequest(endpoint, "ok")
+			c.rateLimiter.Record(name, ratelimit.ResultOK)
 			return nil
 		}
 
-		// Check for rate limit error
-		if IsRateLimitError(err) {
-			logger.Printf("Rate limit hit on %s - recovering...\n", endpoint)
-			c.rateLimiter.LogRequest(endpoint, "rate-limit")
-			if recErr := c.rateLimiter.RecoverFromLimit(); recErr != nil {
-				return recErr
-			}
+		// Check for rate limit error - the limiter pauses and slows down, the next attempt waits for it
+		if rateLimitErr, ok := err.(*RateLimitError); ok {
+			c.rateLimiter.Throttled(name, rateLimitErr.RetryAfter)
+			lastErr = err
 			continue
 		}
 
 		// Check for bad request (don't retry)
 		if IsBadRequestError(err) {
 			logger.Printf("Bad request on %s: %v\n", endpoint, err)
-			c.rateLimiter.LogRequest(endpoint, "bad-request")
+			c.rateLimiter.Record(name, ratelimit.ResultBadRequest)
 			return err
 		}
 
 		// Other errors - retry with backoff
 		logger.Printf("API error (attempt %d/%d) on %s: %v\n", attempt+1, MaxRetries, endpoint, err)
 		lastErr = err
-		c.rateLimiter.LogRequest(endpoint, fmt.Sprintf("error-%d", attempt))
+		c.rateLimiter.Record(name, ratelimit.ResultError)
 		delay := BaseRetryDelay * time.Duration(1<<uint(attempt))
 		time.Sleep(delay)
 	}
@@ -265,9 +364,27 @@ func (c *Client) apiGet(endpoint string, params map[string]string, result interf
 	return fmt.Errorf("request failed after %d attempts: %w", MaxRetries, lastErr)
 }
 
+// endpointName is the endpoint without query and ticker, as weighed and counted by the rate limiter
+func endpointName(endpoint string) string {
+	name, _, _ := strings.Cut(endpoint, "?")
+	if strings.HasPrefix(name, "api/v3/") && strings.Count(name, "/") > 2 {
+		name = name[:strings.LastIndex(name, "/")] // v3 endpoints take the ticker as last path element
+	}
+	return name
+}
+
+// retryAfter returns the delay requested by the Retry-After header in seconds, 0 if missing
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
 // buildURL constructs the full URL with query parameters
 func (c *Client) buildURL(endpoint string, params map[string]string) (string, error) {
-	baseURL := fmt.Sprintf("%s/%s", BaseURL, endpoint)
+	baseURL := fmt.Sprintf("%s/%s", c.baseURL, endpoint)
 
 	u, err := url.Parse(baseURL)
 	if err != nil {
@@ -357,37 +474,40 @@ func writeToCache(cacheKey string, data []byte) error {
 // apiGetRaw makes a GET request and returns the raw response body (for non-JSON endpoints like CSV)
 // Automatically caches responses based on endpoint, params, and date
 func (c *Client) apiGetRaw(endpoint string, params map[string]string) (io.ReadCloser, error) {
-	// Generate cache key
-	cacheKey := getCacheKey(endpoint, params)
+	// Generate cache key (includes the base URL so a fake server never shares cache entries with FMP)
+	cacheKey := getCacheKey(c.baseURL+"/"+endpoint, params)
 	
 	// Try to read from cache first
-	if cachedData, err := readFromCache(cacheKey); err == nil {
-		// Check if this is a cached error
-		if strings.HasPrefix(string(cachedData), "ERROR:") {
-			errorMsg := strings.TrimPrefix(string(cachedData), "ERROR:")
-			logger.Printf("⚡ Cache HIT (error cached) for %s - returning cached error\n", endpoint)
-			return nil, fmt.Errorf("%s", errorMsg)
+	if c.cacheEnabled {
+		if cachedData, err := readFromCache(cacheKey); err == nil {
+			// Check if this is a cached error
+			if strings.HasPrefix(string(cachedData), "ERROR:") {
+				errorMsg := strings.TrimPrefix(string(cachedData), "ERROR:")
+				logger.Printf("⚡ Cache HIT (error cached) for %s - returning cached error\n", endpoint)
+				return nil, fmt.Errorf("%s", errorMsg)
+			}
+			logger.Printf("⚡ Cache HIT for %s - %d bytes\n", endpoint, len(cachedData))
+			return io.NopCloser(strings.NewReader(string(cachedData))), nil
 		}
-		logger.Printf("⚡ Cache HIT for %s - %d bytes\n", endpoint, len(cachedData))
-		return io.NopCloser(strings.NewReader(string(cachedData))), nil
+		logger.Printf("Cache MISS for %s - fetching from API\n", endpoint)
 	}
 	
-	logger.Printf("Cache MISS for %s - fetching from API\n", endpoint)
-	
 	// Build URL with parameters
 	reqURL, err := c.buildURL(endpoint, params)
 	if err != nil {
 		return nil, err
 	}
 
-	// Wait for rate limit
-	if err := c.rateLimiter.Wait(); err != nil {
+	// Wait for rate limit (bulk downloads weigh BulkWeight)
+	name := endpointName(endpoint)
+	if err := c.rateLimiter.Wait(name); err != nil {
 		return nil, fmt.Errorf("rate limiter error: %w", err)
 	}
 
 	// Make the request
 	resp, err := c.httpClient.Get(reqURL)
 	if err != nil {
+		c.rateLimiter.Record(name, ratelimit.ResultError)
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 
@@ -398,9 +518,18 @@ func (c *Client) apiGetRaw(endpoint string, params map[string]string) (io.ReadCl
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
 		errorMsg := fmt.Sprintf("API error: status %d - %s", resp.StatusCode, string(body))
-		
+
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests, http.StatusPaymentRequired:
+			c.rateLimiter.Throttled(name, retryAfter(resp))
+		case http.StatusBadRequest:
+			c.rateLimiter.Record(name, ratelimit.ResultBadRequest)
+		default:
+			c.rateLimiter.Record(name, ratelimit.ResultError)
+		}
+
 		// Cache 400 errors (invalid parameters, end of pagination, etc.)
-		if resp.StatusCode == http.StatusBadRequest {
+		if resp.StatusCode == http.StatusBadRequest && c.cacheEnabled {
 			cachedError := []byte("ERROR:" + errorMsg)
 			if err := writeToCache(cacheKey, cachedError); err != nil {
 				logger.Printf("Warning: failed to cache error: %v\n", err)
@@ -410,16 +539,20 @@ func (c *Client) apiGetRaw(endpoint string, params map[string]string) (io.ReadCl
 		return nil, fmt.Errorf("%s", errorMsg)
 	}
 
-	c.rateLimiter.LogRequest(endpoint, "ok")
+	c.rateLimiter.Record(name, ratelimit.ResultOK)
 	
 	// Write successful response to cache (ignore errors - caching is best-effort)
-	if err := writeToCache(cacheKey, body); err != nil {
-		logger.Printf("Warning: failed to write to cache: %v\n", err)
+	if c.cacheEnabled {
+		if err := writeToCache(cacheKey, body); err != nil {
+			logger.Printf("Warning: failed to write to cache: %v\n", err)
+		}
 	}
 	
-	// Sleep for 30 seconds to be nice to the API
-	logger.Printf("Sleeping 30s to be nice to the API...\n")
-	time.Sleep(30 * time.Second)
+	// Pause to be nice to the API
+	if c.bulkRequestDelay > 0 {
+		logger.Printf("Sleeping %s to be nice to the API...\n", c.bulkRequestDelay)
+		time.Sleep(c.bulkRequestDelay)
+	}
 	
 	// Return the body as a ReadCloser
 	return io.NopCloser(strings.NewReader(string(body))), nil
@@ -449,18 +582,21 @@ func (c *Client) handleResponse(resp *http.Response, endpoint string, result int
 		return &RateLimitError{Message: string(body)}
 
 	case http.StatusTooManyRequests:
-		return &RateLimitError{Message: "too many requests"}
+		return &RateLimitError{Message: "too many requests", RetryAfter: retryAfter(resp)}
 
 	default:
 		return fmt.Errorf("API error: status %d - %s", resp.StatusCode, string(body))
 	}
 }
 
-// Shutdown gracefully shuts down the client
+// Shutdown gracefully shuts down the global client, if it was used
 func Shutdown() {
-	Fmp().Shutdown()
+	if globalClient != nil {
+		globalClient.Shutdown()
+	}
 }
 
+// Shutdown stops the rate limiter, which stores the pending quota usage
 func (c *Client) Shutdown() {
 	c.rateLimiter.Shutdown()
 }
@@ -480,7 +616,8 @@ func IsBadRequestError(err error) bool {
 }
 
 type RateLimitError struct {
-	Message string
+	Message    string
+	RetryAfter time.Duration // Requested by the Retry-After header, 0 if missing
 }
 
 func (e *RateLimitError) Error() string {
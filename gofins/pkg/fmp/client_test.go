@@ -0,0 +1,71 @@
+package fmp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, cfg ClientConfig) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg.APIKey, cfg.BaseURL, cfg.DisableCache = "test-api-key", server.URL, true
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+	t.Cleanup(client.Shutdown)
+	return client
+}
+
+func TestEndpointName(t *testing.T) {
+	assert.Equal(t, "stable/eod-bulk", endpointName("stable/eod-bulk?date=2024-01-02"))
+	assert.Equal(t, "stable/historical-price-eod/full", endpointName("stable/historical-price-eod/full"))
+	assert.Equal(t, "api/v3/historical-price-full", endpointName("api/v3/historical-price-full/AAPL"))
+}
+
+func TestApiGetRateLimitFeedback(t *testing.T) {
+	var requests atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "Limit Reach", http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`[{"symbol": "AAPL"}]`))
+	}, ClientConfig{DailyQuota: 1})
+
+	start := time.Now()
+	var profiles []Profile
+	require.NoError(t, client.apiGet("stable/profile", nil, &profiles), "the rejected request doesn't use up the quota")
+	assert.GreaterOrEqual(t, time.Since(start), time.Second, "the retry waits for Retry-After")
+	assert.Equal(t, "AAPL", profiles[0].Symbol)
+
+	log := client.rateLimiter.GetLog()
+	require.Len(t, log, 2)
+	assert.Equal(t, ratelimit.ResultRateLimited, log[0].Result)
+	assert.Equal(t, ratelimit.ResultOK, log[1].Result)
+}
+
+func TestApiGetDailyQuota(t *testing.T) {
+	var requests atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`[]`))
+	}, ClientConfig{RequestsPerMinute: 60000, DailyQuota: 100})
+
+	var symbols []Symbol
+	for i := 0; i < 100; i++ {
+		require.NoError(t, client.apiGet("stable/stock-list", nil, &symbols))
+	}
+	err := client.apiGet("stable/stock-list", nil, &symbols)
+	assert.True(t, ratelimit.IsQuotaError(err))
+	_, err = client.apiGetRaw("stable/eod-bulk?date=2024-01-02", nil)
+	assert.True(t, ratelimit.IsQuotaError(err), "bulk downloads count against the quota too")
+	assert.EqualValues(t, 100, requests.Load(), "requests beyond the quota aren't sent")
+}
@@ -0,0 +1,93 @@
+package fmp
+
+import (
+	"sort"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+// dividendRaw represents a dividend from the FMP dividends endpoint
+type dividendRaw struct {
+	Date        string  `json:"date"` // Ex-dividend date
+	PaymentDate string  `json:"paymentDate"`
+	Dividend    float64 `json:"dividend"`
+	AdjDividend float64 `json:"adjDividend"`
+}
+
+// splitRaw represents a stock split from the FMP splits endpoint
+type splitRaw struct {
+	Date        string  `json:"date"`
+	Numerator   float64 `json:"numerator"`
+	Denominator float64 `json:"denominator"`
+}
+
+// FetchDividends fetches the dividend history of a ticker, oldest first.
+// Symbols that never paid a dividend return an empty slice, not an error.
+func (c *Client) FetchDividends(ticker string) ([]types.Dividend, error) {
+	var raw []dividendRaw
+	params := map[string]string{"symbol": ticker}
+	if err := c.apiGet("stable/dividends", params, &raw); err != nil {
+		return nil, err
+	}
+
+	dividends := make([]types.Dividend, 0, len(raw))
+	for _, r := range raw {
+		date, err := time.Parse("2006-01-02", r.Date)
+		if err != nil || r.Dividend <= 0 {
+			continue
+		}
+		adjDividend := r.AdjDividend
+		if adjDividend <= 0 {
+			adjDividend = r.Dividend
+		}
+		dividend := types.Dividend{
+			SymbolTicker: ticker,
+			Date:         date,
+			Dividend:     r.Dividend,
+			AdjDividend:  adjDividend,
+		}
+		if paymentDate, err := time.Parse("2006-01-02", r.PaymentDate); err == nil {
+			dividend.PaymentDate = &paymentDate
+		}
+		dividends = append(dividends, dividend)
+	}
+
+	sort.Slice(dividends, func(i, j int) bool {
+		return dividends[i].Date.Before(dividends[j].Date)
+	})
+	return dividends, nil
+}
+
+// FetchSplits fetches the stock split history of a ticker, oldest first.
+// Symbols that never split return an empty slice, not an error.
+func (c *Client) FetchSplits(ticker string) ([]types.Split, error) {
+	var raw []splitRaw
+	params := map[string]string{"symbol": ticker}
+	if err := c.apiGet("stable/splits", params, &raw); err != nil {
+		return nil, err
+	}
+
+	splits := make([]types.Split, 0, len(raw))
+	for _, r := range raw {
+		date, err := time.Parse("2006-01-02", r.Date)
+		if err != nil {
+			continue
+		}
+		split := types.Split{
+			SymbolTicker: ticker,
+			Date:         date,
+			Numerator:    r.Numerator,
+			Denominator:  r.Denominator,
+		}
+		if split.Ratio() == 0 {
+			continue
+		}
+		splits = append(splits, split)
+	}
+
+	sort.Slice(splits, func(i, j int) bool {
+		return splits[i].Date.Before(splits[j].Date)
+	})
+	return splits, nil
+}
@@ -72,8 +72,7 @@ type HistoricalPriceResponse struct {
 }
 
 // GetProfile fetches the company profile for a ticker
-func GetProfile(ticker string) (*Profile, error) {
-	c := Fmp()
+func (c *Client) GetProfile(ticker string) (*Profile, error) {
 	var profiles []Profile
 	params := map[string]string{
 		"symbol": ticker,
@@ -91,8 +90,7 @@ func GetProfile(ticker string) (*Profile, error) {
 }
 
 // GetProfileByCIK fetches the primary listing profile for a company by CIK
-func GetProfileByCIK(cik string) (*Profile, error) {
-	c := Fmp()
+func (c *Client) GetProfileByCIK(cik string) (*Profile, error) {
 	var profiles []Profile
 	params := map[string]string{
 		"cik": cik,
@@ -110,8 +108,7 @@ func GetProfileByCIK(cik string) (*Profile, error) {
 }
 
 // GetHistoricalPrices fetches historical price data for a ticker
-func GetHistoricalPrices(ticker string, from, to time.Time) (*HistoricalPriceResponse, error) {
-	c := Fmp()
+func (c *Client) GetHistoricalPrices(ticker string, from, to time.Time) (*HistoricalPriceResponse, error) {
 	params := map[string]string{
 		"from": from.Format("2006-01-02"),
 		"to":   to.Format("2006-01-02"),
@@ -128,8 +125,7 @@ func GetHistoricalPrices(ticker string, from, to time.Time) (*HistoricalPriceRes
 }
 
 // FetchStockList fetches the complete list of stocks from FMP
-func FetchStockList() ([]Symbol, error) {
-	c := Fmp()
+func (c *Client) FetchStockList() ([]Symbol, error) {
 	var symbols []Symbol
 	params := map[string]string{}
 
@@ -141,8 +137,7 @@ func FetchStockList() ([]Symbol, error) {
 }
 
 // FetchIndexList fetches the complete list of indices from FMP
-func FetchIndexList() ([]Symbol, error) {
-	c := Fmp()
+func (c *Client) FetchIndexList() ([]Symbol, error) {
 	var symbols []Symbol
 	params := map[string]string{}
 
@@ -154,8 +149,7 @@ func FetchIndexList() ([]Symbol, error) {
 }
 
 // FetchDelistedCompanies fetches all delisted companies from FMP with pagination
-func FetchDelistedCompanies() ([]Symbol, error) {
-	c := Fmp()
+func (c *Client) FetchDelistedCompanies() ([]Symbol, error) {
 	allDelisted := []Symbol{}
 	page := 0
 	limit := 100
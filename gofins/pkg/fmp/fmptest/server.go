@@ -0,0 +1,339 @@
+// Package fmptest provides an in-process fake of the FMP API for tests and offline runs.
+// It serves the endpoints used by the fmp.Client from fixture files:
+//
+//	stock-list.json, index-list.json, delisted-companies.json  symbol lists
+//	profiles.json                                              profiles (single, by CIK and bulk CSV)
+//	prices/<TICKER>.json                                       daily price history (as traded; the
+//	                                                           dividend-adjusted history is derived from it)
+//	dividends/<TICKER>.json, splits/<TICKER>.json              corporate actions
+//	forex/<PAIR>.json                                          forex history (e.g. EURUSD)
+//	eod/<YYYY-MM-DD>.csv or eod/latest.csv                     bulk end-of-day prices
+//
+// Missing files behave like unknown symbols on FMP (empty result).
+package fmptest
+
+import (
+	"embed"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/flocko-motion/gofins/pkg/fmp"
+)
+
+// APIKey is the only key accepted by the fake server
+const APIKey = "fmptest-api-key"
+
+//go:embed fixtures
+var embedded embed.FS
+
+// DefaultFixtures is a small data set: AAPL, MSFT, SAP.DE (EUR), ^GSPC, one delisted company and EURUSD
+var DefaultFixtures fs.FS = mustSub(embedded, "fixtures")
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// Handler serves the fake FMP API and counts requests per endpoint
+type Handler struct {
+	fixtures fs.FS
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+// NewHandler creates a fake FMP handler backed by fixtures (DefaultFixtures if nil)
+func NewHandler(fixtures fs.FS) *Handler {
+	if fixtures == nil {
+		fixtures = DefaultFixtures
+	}
+	return &Handler{fixtures: fixtures, requests: make(map[string]int)}
+}
+
+// Requests returns how often an endpoint (e.g. "/stable/profile") was requested
+func (h *Handler) Requests(path string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.requests[path]
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.requests[r.URL.Path]++
+	h.mu.Unlock()
+
+	q := r.URL.Query()
+	if q.Get("apikey") != APIKey {
+		http.Error(w, `{"Error Message": "Invalid API KEY."}`, http.StatusUnauthorized)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/stable/stock-list":
+		h.serveFile(w, "stock-list.json")
+	case "/stable/index-list":
+		h.serveFile(w, "index-list.json")
+	case "/stable/delisted-companies":
+		h.serveDelisted(w, q.Get("page"), q.Get("limit"))
+	case "/stable/profile":
+		h.serveProfiles(w, func(p fmp.Profile) bool { return p.Symbol == q.Get("symbol") })
+	case "/stable/profile-cik":
+		h.serveProfiles(w, func(p fmp.Profile) bool { return p.CIK == q.Get("cik") })
+	case "/stable/profile-bulk":
+		h.serveBulkProfiles(w, q.Get("part"))
+	case "/stable/historical-price-eod/full":
+		h.serveRows(w, "prices/"+q.Get("symbol")+".json", q.Get("from"), q.Get("to"))
+	case "/stable/historical-price-eod/dividend-adjusted":
+		h.serveAdjustedRows(w, q.Get("symbol"), q.Get("from"), q.Get("to"))
+	case "/stable/dividends":
+		h.serveFile(w, "dividends/"+q.Get("symbol")+".json")
+	case "/stable/splits":
+		h.serveFile(w, "splits/"+q.Get("symbol")+".json")
+	case "/stable/historical-price-eod/light":
+		h.serveRows(w, "forex/"+q.Get("symbol")+".json", q.Get("from"), q.Get("to"))
+	case "/stable/eod-bulk":
+		h.serveBulkEOD(w, q.Get("date"))
+	default:
+		http.Error(w, `{"Error Message": "Unknown endpoint."}`, http.StatusNotFound)
+	}
+}
+
+// readFile returns a fixture file, nil if it doesn't exist
+func (h *Handler) readFile(name string) ([]byte, error) {
+	data, err := fs.ReadFile(h.fixtures, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (h *Handler) serveFile(w http.ResponseWriter, name string) {
+	data, err := h.readFile(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if data == nil {
+		data = []byte("[]")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (h *Handler) serveDelisted(w http.ResponseWriter, pageParam, limitParam string) {
+	var rows []json.RawMessage
+	if err := h.readJSON("delisted-companies.json", &rows); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	page, _ := strconv.Atoi(pageParam)
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	start := min(page*limit, len(rows))
+	end := min(start+limit, len(rows))
+	writeJSON(w, rows[start:end])
+}
+
+func (h *Handler) loadProfiles() ([]fmp.Profile, error) {
+	var profiles []fmp.Profile
+	err := h.readJSON("profiles.json", &profiles)
+	return profiles, err
+}
+
+func (h *Handler) serveProfiles(w http.ResponseWriter, match func(fmp.Profile) bool) {
+	profiles, err := h.loadProfiles()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	matched := []fmp.Profile{}
+	for _, p := range profiles {
+		if match(p) {
+			matched = append(matched, p)
+		}
+	}
+	writeJSON(w, matched)
+}
+
+// serveBulkProfiles returns all profiles as CSV in part 0; later parts fail like FMP does past the last page
+func (h *Handler) serveBulkProfiles(w http.ResponseWriter, part string) {
+	if part != "0" {
+		http.Error(w, `{"Error Message": "Invalid or missing query parameter - part"}`, http.StatusBadRequest)
+		return
+	}
+	profiles, err := h.loadProfiles()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"symbol", "companyName", "exchange", "currency", "industry", "sector", "country", "cik",
+		"marketCap", "price", "ceo", "description", "website", "ipoDate", "fullTimeEmployees",
+		"isActivelyTrading", "isEtf", "isFund", "isAdr"})
+	for _, p := range profiles {
+		cw.Write([]string{p.Symbol, p.CompanyName, p.Exchange, p.Currency, p.Industry, p.Sector, p.Country, p.CIK,
+			strconv.FormatFloat(p.MarketCap, 'f', -1, 64), strconv.FormatFloat(p.Price, 'f', -1, 64),
+			p.CEO, p.Description, p.Website, p.IPODate, p.FullTimeEmployees,
+			strconv.FormatBool(p.IsActivelyTrading), strconv.FormatBool(p.IsEtf),
+			strconv.FormatBool(p.IsFund), strconv.FormatBool(p.IsAdr)})
+	}
+	cw.Flush()
+}
+
+// serveRows returns the rows of a date-keyed fixture that fall into [from, to]
+func (h *Handler) serveRows(w http.ResponseWriter, name, from, to string) {
+	var rows []map[string]any
+	if err := h.readJSON(name, &rows); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	filtered := []map[string]any{}
+	for _, row := range rows {
+		date, _ := row["date"].(string)
+		if (from != "" && date < from) || (to != "" && date > to) {
+			continue
+		}
+		filtered = append(filtered, row)
+	}
+	writeJSON(w, filtered)
+}
+
+// serveAdjustedRows returns the price history of a symbol back-adjusted for its dividends:
+// bars before an ex-date are scaled by 1 - dividend / close of the last bar before it
+func (h *Handler) serveAdjustedRows(w http.ResponseWriter, symbol, from, to string) {
+	var rows []map[string]any
+	var dividends []struct {
+		Date        string  `json:"date"`
+		AdjDividend float64 `json:"adjDividend"`
+	}
+	if err := h.readJSON("prices/"+symbol+".json", &rows); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := h.readJSON("dividends/"+symbol+".json", &dividends); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	date := func(row map[string]any) string { d, _ := row["date"].(string); return d }
+	sort.Slice(rows, func(i, j int) bool { return date(rows[i]) > date(rows[j]) })
+	sort.Slice(dividends, func(i, j int) bool { return dividends[i].Date > dividends[j].Date })
+
+	adjusted := []map[string]any{}
+	factor, d := 1.0, 0
+	for _, row := range rows {
+		close, _ := row["close"].(float64)
+		for d < len(dividends) && dividends[d].Date > date(row) {
+			if close > 0 {
+				factor *= 1 - dividends[d].AdjDividend/close
+			}
+			d++
+		}
+		if (from != "" && date(row) < from) || (to != "" && date(row) > to) {
+			continue
+		}
+		bar := map[string]any{"symbol": row["symbol"], "date": row["date"], "volume": row["volume"]}
+		for _, field := range []string{"open", "high", "low", "close"} {
+			value, _ := row[field].(float64)
+			bar["adj"+strings.ToUpper(field[:1])+field[1:]] = value * factor
+		}
+		adjusted = append(adjusted, bar)
+	}
+	writeJSON(w, adjusted)
+}
+
+// serveBulkEOD serves eod/<date>.csv, or eod/latest.csv re-dated to the requested date
+func (h *Handler) serveBulkEOD(w http.ResponseWriter, date string) {
+	data, err := h.readFile("eod/" + date + ".csv")
+	if err == nil && data == nil {
+		data, err = h.readFile("eod/latest.csv")
+		if data != nil {
+			data, err = redateCSV(data, date)
+		}
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if data == nil {
+		data = []byte("symbol,date,open,low,high,close,adjClose,volume\n")
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	w.Write(data)
+}
+
+// redateCSV replaces the date column of a bulk EOD CSV
+func redateCSV(data []byte, date string) ([]byte, error) {
+	records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i < len(records); i++ {
+		if len(records[i]) > 1 {
+			records[i][1] = date
+		}
+	}
+	var sb strings.Builder
+	cw := csv.NewWriter(&sb)
+	if err := cw.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return []byte(sb.String()), nil
+}
+
+// readJSON decodes a fixture file; a missing file leaves target untouched
+func (h *Handler) readJSON(name string, target any) error {
+	data, err := h.readFile(name)
+	if err != nil || data == nil {
+		return err
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("invalid fixture %s: %w", name, err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// Server is a running fake FMP API
+type Server struct {
+	*httptest.Server
+	*Handler
+}
+
+// NewServer starts a fake FMP API backed by fixtures (DefaultFixtures if nil). Call Close when done.
+func NewServer(fixtures fs.FS) *Server {
+	handler := NewHandler(fixtures)
+	return &Server{Server: httptest.NewServer(handler), Handler: handler}
+}
+
+// Client returns an fmp.Client talking to the fake server, without caching or throttling
+func (s *Server) Client() *fmp.Client {
+	client, err := fmp.NewClient(fmp.ClientConfig{
+		APIKey:            APIKey,
+		BaseURL:           s.URL,
+		RequestsPerMinute: 60000,
+		DisableCache:      true,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
@@ -0,0 +1,92 @@
+package fmptest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/fmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientAgainstFakeServer(t *testing.T) {
+	server := NewServer(nil)
+	defer server.Close()
+	client := server.Client()
+
+	stocks, err := client.FetchStockList()
+	require.NoError(t, err)
+	assert.Len(t, stocks, 3)
+
+	indices, err := client.FetchIndexList()
+	require.NoError(t, err)
+	require.Len(t, indices, 1)
+	assert.True(t, indices[0].IsIndex())
+
+	delisted, err := client.FetchDelistedCompanies()
+	require.NoError(t, err)
+	assert.Len(t, delisted, 1)
+
+	profile, err := client.GetProfile("AAPL")
+	require.NoError(t, err)
+	assert.Equal(t, "Apple Inc.", profile.CompanyName)
+
+	_, err = client.GetProfile("NOPE")
+	assert.True(t, fmp.IsNotFoundError(err))
+
+	byCIK, err := client.GetProfileByCIK("0000789019")
+	require.NoError(t, err)
+	assert.Equal(t, "MSFT", byCIK.Symbol)
+
+	bulk, err := client.GetBulkProfiles()
+	require.NoError(t, err)
+	assert.Len(t, bulk, 3)
+	assert.Equal(t, 2, server.Requests("/stable/profile-bulk"), "part 0 plus the failing part 1")
+
+	prices, err := client.FetchPriceHistory("^GSPC")
+	require.NoError(t, err)
+	assert.NotEmpty(t, prices)
+	assert.Greater(t, prices[0].Close, 0.0)
+	assert.Greater(t, prices[0].Volume, int64(0))
+
+	_, err = client.FetchPriceHistory("NOPE")
+	assert.True(t, fmp.IsNotFoundError(err))
+
+	// The dividend-adjusted history equals the raw one on the latest bar and is lower before dividends
+	adjusted, err := client.FetchPriceHistory("AAPL")
+	require.NoError(t, err)
+	raw, err := client.FetchRawPriceHistory("AAPL")
+	require.NoError(t, err)
+	require.Equal(t, len(raw), len(adjusted))
+	assert.Equal(t, raw[0].Date, adjusted[0].Date)
+	assert.InDelta(t, raw[0].Close, adjusted[0].Close, 1e-9)
+	last := len(raw) - 1
+	assert.Less(t, adjusted[last].Close, raw[last].Close)
+	assert.Equal(t, raw[last].Volume, adjusted[last].Volume)
+
+	forex, err := client.FetchForexHistory("EURUSD")
+	require.NoError(t, err)
+	assert.NotEmpty(t, forex)
+
+	date := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	quotes, err := client.GetBulkEOD(date)
+	require.NoError(t, err)
+	require.Contains(t, quotes, "AAPL")
+	assert.Equal(t, date, quotes["AAPL"].Date, "latest.csv is re-dated to the requested day")
+	require.NotNil(t, quotes["AAPL"].AdjClose)
+	require.NotNil(t, quotes["AAPL"].Volume)
+	assert.Equal(t, int64(42355300), *quotes["AAPL"].Volume)
+}
+
+func TestProviderOverride(t *testing.T) {
+	server := NewServer(nil)
+	defer server.Close()
+
+	fmp.SetProvider(server.Client())
+	defer fmp.SetProvider(nil)
+
+	profile, err := fmp.GetProfile("SAP.DE")
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", profile.Currency)
+	assert.Equal(t, 1, server.Requests("/stable/profile"))
+}
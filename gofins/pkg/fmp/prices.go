@@ -2,34 +2,64 @@ package fmp
 
 import "strings"
 
-// PriceDataRaw represents raw daily price data from FMP API (JSON format)
+// PriceDataRaw represents daily price data from the FMP API, split-adjusted.
+// FetchPriceHistory returns it dividend-adjusted, FetchRawPriceHistory as traded.
 type PriceDataRaw struct {
-	Date  string  `json:"date"`
-	Open  float64 `json:"adjOpen"`
-	High  float64 `json:"adjHigh"`
-	Low   float64 `json:"adjLow"`
-	Close float64 `json:"adjClose"`
+	Date   string  `json:"date"`
+	Open   float64 `json:"open"`
+	High   float64 `json:"high"`
+	Low    float64 `json:"low"`
+	Close  float64 `json:"close"`
+	Volume int64   `json:"volume"`
 }
 
-// FetchPriceHistory fetches historical price data for a ticker.
-// Automatically detects index symbols (starting with ^) and routes to the correct endpoint.
-func FetchPriceHistory(ticker string) ([]PriceDataRaw, error) {
-	c := Fmp()
-	var prices []PriceDataRaw
-	var endpoint string
+// adjustedPriceData is a bar of the dividend-adjusted endpoint
+type adjustedPriceData struct {
+	Date   string  `json:"date"`
+	Open   float64 `json:"adjOpen"`
+	High   float64 `json:"adjHigh"`
+	Low    float64 `json:"adjLow"`
+	Close  float64 `json:"adjClose"`
+	Volume int64   `json:"volume"`
+}
+
+// FetchPriceHistory fetches the dividend-adjusted price history of a ticker, the basis of the
+// stored closes and the price YoY. Indices pay no dividends and use the raw history.
+func (c *Client) FetchPriceHistory(ticker string) ([]PriceDataRaw, error) {
+	if strings.HasPrefix(ticker, "^") {
+		return c.FetchRawPriceHistory(ticker)
+	}
+
+	var adjusted []adjustedPriceData
 	params := map[string]string{
 		"symbol": ticker,
 		"from":   "1900-01-01",
 	}
+	if err := c.apiGet("stable/historical-price-eod/dividend-adjusted", params, &adjusted); err != nil {
+		return nil, err
+	}
 
-	// Index symbols (starting with ^) use a different endpoint
-	if strings.HasPrefix(ticker, "^") {
-		endpoint = "stable/historical-price-eod/full"
-	} else {
-		endpoint = "stable/historical-price-eod/dividend-adjusted"
+	if len(adjusted) == 0 {
+		return nil, &NotFoundError{Ticker: ticker}
+	}
+
+	prices := make([]PriceDataRaw, len(adjusted))
+	for i, a := range adjusted {
+		prices[i] = PriceDataRaw(a)
+	}
+	return prices, nil
+}
+
+// FetchRawPriceHistory fetches the price history of a ticker as traded (split-adjusted only).
+// Together with the dividends (FetchDividends) it is the basis of the total-return index.
+func (c *Client) FetchRawPriceHistory(ticker string) ([]PriceDataRaw, error) {
+	var prices []PriceDataRaw
+	params := map[string]string{
+		"symbol": ticker,
+		"from":   "1900-01-01",
 	}
 
-	if err := c.apiGet(endpoint, params, &prices); err != nil {
+	if err := c.apiGet("stable/historical-price-eod/full", params, &prices); err != nil {
 		return nil, err
 	}
 
@@ -51,8 +81,7 @@ type ForexData struct {
 
 // FetchForexHistory fetches historical forex data.
 // Symbol should be in format like "EURUSD", "GBPUSD", etc.
-func FetchForexHistory(symbol string) ([]ForexData, error) {
-	c := Fmp()
+func (c *Client) FetchForexHistory(symbol string) ([]ForexData, error) {
 	var forexData []ForexData
 	params := map[string]string{
 		"symbol": symbol,
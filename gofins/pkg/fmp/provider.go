@@ -0,0 +1,111 @@
+package fmp
+
+import (
+	"sync"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+// MarketDataProvider is the source of all market data consumed by the updaters and forex cache.
+// The FMP Client is the production implementation; tests and offline runs point a Client at
+// a fake server (see package fmptest) and install it with SetProvider.
+type MarketDataProvider interface {
+	FetchStockList() ([]Symbol, error)
+	FetchIndexList() ([]Symbol, error)
+	FetchDelistedCompanies() ([]Symbol, error)
+	GetProfile(ticker string) (*Profile, error)
+	GetProfileByCIK(cik string) (*Profile, error)
+	GetBulkProfiles() ([]*Profile, error)
+	FetchPriceHistory(ticker string) ([]PriceDataRaw, error)
+	FetchRawPriceHistory(ticker string) ([]PriceDataRaw, error)
+	FetchDividends(ticker string) ([]types.Dividend, error)
+	FetchSplits(ticker string) ([]types.Split, error)
+	GetBulkEOD(date time.Time) (map[string]*types.DailyPrice, error)
+	FetchForexHistory(symbol string) ([]ForexData, error)
+}
+
+var _ MarketDataProvider = (*Client)(nil)
+
+var (
+	activeProvider MarketDataProvider
+	providerMu     sync.RWMutex
+)
+
+// Provider returns the active market data provider (the global FMP client unless replaced)
+func Provider() MarketDataProvider {
+	providerMu.RLock()
+	p := activeProvider
+	providerMu.RUnlock()
+	if p != nil {
+		return p
+	}
+	return Fmp()
+}
+
+// SetProvider replaces the active market data provider. Pass nil to restore the global FMP client.
+func SetProvider(p MarketDataProvider) {
+	providerMu.Lock()
+	activeProvider = p
+	providerMu.Unlock()
+}
+
+// FetchStockList fetches the complete list of stocks from the active provider
+func FetchStockList() ([]Symbol, error) {
+	return Provider().FetchStockList()
+}
+
+// FetchIndexList fetches the complete list of indices from the active provider
+func FetchIndexList() ([]Symbol, error) {
+	return Provider().FetchIndexList()
+}
+
+// FetchDelistedCompanies fetches all delisted companies from the active provider
+func FetchDelistedCompanies() ([]Symbol, error) {
+	return Provider().FetchDelistedCompanies()
+}
+
+// GetProfile fetches the company profile for a ticker from the active provider
+func GetProfile(ticker string) (*Profile, error) {
+	return Provider().GetProfile(ticker)
+}
+
+// GetProfileByCIK fetches the primary listing profile for a company by CIK from the active provider
+func GetProfileByCIK(cik string) (*Profile, error) {
+	return Provider().GetProfileByCIK(cik)
+}
+
+// GetBulkProfiles fetches all company profiles from the active provider
+func GetBulkProfiles() ([]*Profile, error) {
+	return Provider().GetBulkProfiles()
+}
+
+// FetchPriceHistory fetches the dividend-adjusted daily price history of a ticker from the active provider
+func FetchPriceHistory(ticker string) ([]PriceDataRaw, error) {
+	return Provider().FetchPriceHistory(ticker)
+}
+
+// FetchRawPriceHistory fetches the daily price history of a ticker as traded from the active provider
+func FetchRawPriceHistory(ticker string) ([]PriceDataRaw, error) {
+	return Provider().FetchRawPriceHistory(ticker)
+}
+
+// FetchDividends fetches the dividend history of a ticker from the active provider
+func FetchDividends(ticker string) ([]types.Dividend, error) {
+	return Provider().FetchDividends(ticker)
+}
+
+// FetchSplits fetches the stock split history of a ticker from the active provider
+func FetchSplits(ticker string) ([]types.Split, error) {
+	return Provider().FetchSplits(ticker)
+}
+
+// GetBulkEOD fetches end-of-day prices of all symbols for a date from the active provider
+func GetBulkEOD(date time.Time) (map[string]*types.DailyPrice, error) {
+	return Provider().GetBulkEOD(date)
+}
+
+// FetchForexHistory fetches the history of a currency pair (e.g. "EURUSD") from the active provider
+func FetchForexHistory(symbol string) ([]ForexData, error) {
+	return Provider().FetchForexHistory(symbol)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/flocko-motion/gofins/pkg/calculator"
 	"github.com/flocko-motion/gofins/pkg/types"
 )
 
@@ -73,3 +74,31 @@ func GetCachedForex(currency string) (*ForexTimeSeries, error) {
 
 	return ts, nil
 }
+
+// maxRateLookback is how far UsdRate looks back for a rate on days without forex quotes (weekends, holidays)
+const maxRateLookback = 7
+
+// UsdRate returns the USD value of one unit of the currency on the given day.
+// Days without a quote fall back to the latest rate up to a week before.
+func UsdRate(currency string, date time.Time) (float64, error) {
+	if currency == "USD" {
+		return 1, nil
+	}
+
+	ts, err := getUsdForex(date, date, currency)
+	if ts == nil {
+		return 0, fmt.Errorf("failed to get forex data for %s: %w", currency, err)
+	}
+	return usdRateWithTimeSeries(ts, date)
+}
+
+// usdRateWithTimeSeries looks up the close of the day or the closest earlier day within maxRateLookback
+func usdRateWithTimeSeries(ts *ForexTimeSeries, date time.Time) (float64, error) {
+	day := calculator.StartOfDay(date)
+	for i := 0; i <= maxRateLookback; i++ {
+		if priceData, exists := ts.Data[day.AddDate(0, 0, -i)]; exists && priceData.Close > 0 {
+			return priceData.Close, nil
+		}
+	}
+	return 0, fmt.Errorf("no forex data within %d days before %s", maxRateLookback, day.Format("2006-01-02"))
+}
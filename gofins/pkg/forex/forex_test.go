@@ -7,6 +7,7 @@ import (
 
 	"github.com/flocko-motion/gofins/pkg/calculator"
 	"github.com/flocko-motion/gofins/pkg/fmp"
+	"github.com/flocko-motion/gofins/pkg/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -123,3 +124,25 @@ func TestConvertUsdToUsd(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, amount, converted, "USD to USD should return same amount")
 }
+
+func TestUsdRateFallsBackToPreviousDay(t *testing.T) {
+	friday := time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC)
+	ts := &ForexTimeSeries{
+		Data:     map[time.Time]types.PriceData{friday: {Date: friday, Close: 1.09}},
+		TimeFrom: friday,
+		TimeTo:   friday,
+	}
+
+	// Sunday uses Friday's close
+	rate, err := usdRateWithTimeSeries(ts, friday.AddDate(0, 0, 2).Add(15*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 1.09, rate)
+
+	// More than a week without quotes is an error
+	_, err = usdRateWithTimeSeries(ts, friday.AddDate(0, 0, 8))
+	assert.Error(t, err)
+
+	// Days before the series are not extrapolated
+	_, err = usdRateWithTimeSeries(ts, friday.AddDate(0, 0, -1))
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const uriScheme = "gofins://"
+
+var resources = []Resource{
+	{URI: uriScheme + "favorites", Name: "favorites", Description: "Tickers the user marked as favorite", MimeType: "application/json"},
+	{URI: uriScheme + "ratings", Name: "ratings", Description: "The user's latest rating for every rated symbol", MimeType: "application/json"},
+	{URI: uriScheme + "notes", Name: "notes", Description: "All of the user's rating notes, newest first", MimeType: "application/json"},
+	{URI: uriScheme + "analyses", Name: "analyses", Description: "Analysis packages of the user", MimeType: "application/json"},
+}
+
+var resourceTemplates = []ResourceTemplate{
+	{URITemplate: uriScheme + "symbol/{ticker}", Name: "symbol", Description: "Company profile of a symbol", MimeType: "application/json"},
+	{URITemplate: uriScheme + "prices/{interval}/{ticker}", Name: "prices", Description: "Last 5 years of monthly or weekly prices of a symbol", MimeType: "application/json"},
+	{URITemplate: uriScheme + "analysis/{id}/results", Name: "analysis-results", Description: "All results of an analysis package", MimeType: "application/json"},
+}
+
+// readResource resolves a gofins:// URI to its data
+func readResource(ctx context.Context, userID uuid.UUID, uri string) (any, error) {
+	if !strings.HasPrefix(uri, uriScheme) {
+		return nil, fmt.Errorf("unknown resource: %s", uri)
+	}
+	parts := strings.Split(strings.TrimPrefix(uri, uriScheme), "/")
+
+	switch {
+	case len(parts) == 1 && parts[0] == "favorites":
+		return listFavorites(ctx, userID, nil)
+	case len(parts) == 1 && parts[0] == "ratings":
+		return getRatings(ctx, userID, nil)
+	case len(parts) == 1 && parts[0] == "notes":
+		return getNotes(ctx, userID, nil)
+	case len(parts) == 1 && parts[0] == "analyses":
+		return listAnalyses(ctx, userID, nil)
+	case len(parts) == 2 && parts[0] == "symbol":
+		return loadSymbol(ctx, userID, strings.ToUpper(parts[1]))
+	case len(parts) == 3 && parts[0] == "prices":
+		_, _, _, prices, err := loadPrices(priceArgs{Ticker: parts[2], Interval: parts[1]})
+		if err != nil {
+			return nil, err
+		}
+		return pricePoints(prices), nil
+	case len(parts) == 3 && parts[0] == "analysis" && parts[2] == "results":
+		return loadAnalysisResults(ctx, userID, parts[1], 0)
+	}
+	return nil, fmt.Errorf("unknown resource: %s", uri)
+}
@@ -0,0 +1,189 @@
+// Package mcp implements a Model Context Protocol server that exposes symbols, prices,
+// analyses and a user's favorites, ratings and notes to MCP clients.
+// All user data is scoped to the user the transport resolved for the session.
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// serverName and serverVersion are reported to clients during initialize
+const (
+	serverName    = "gofins"
+	serverVersion = "1.0.0"
+)
+
+// Server dispatches MCP requests to tools and resources
+type Server struct {
+	tools     []toolDef
+	toolIndex map[string]toolDef
+}
+
+// NewServer creates an MCP server with all gofins tools registered
+func NewServer() *Server {
+	s := &Server{
+		tools:     tools(),
+		toolIndex: make(map[string]toolDef),
+	}
+	for _, t := range s.tools {
+		s.toolIndex[t.Name] = t
+	}
+	return s
+}
+
+// HandleMessage processes one JSON-RPC message (single request or batch) for a user.
+// Returns nil if the message contained only notifications.
+func (s *Server) HandleMessage(ctx context.Context, userID uuid.UUID, data []byte) []byte {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(data, &batch); err != nil {
+			return mustMarshal(errorResponse(nil, codeParseError, "Parse error: "+err.Error()))
+		}
+		responses := []*Response{}
+		for _, raw := range batch {
+			if resp := s.handleRaw(ctx, userID, raw); resp != nil {
+				responses = append(responses, resp)
+			}
+		}
+		if len(responses) == 0 {
+			return nil
+		}
+		return mustMarshal(responses)
+	}
+
+	if resp := s.handleRaw(ctx, userID, data); resp != nil {
+		return mustMarshal(resp)
+	}
+	return nil
+}
+
+func (s *Server) handleRaw(ctx context.Context, userID uuid.UUID, raw json.RawMessage) *Response {
+	var req Request
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return errorResponse(nil, codeParseError, "Parse error: "+err.Error())
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, codeInvalidRequest, "Invalid request")
+	}
+	return s.Handle(ctx, userID, &req)
+}
+
+// Handle processes a single request. Returns nil for notifications.
+func (s *Server) Handle(ctx context.Context, userID uuid.UUID, req *Request) *Response {
+	result, rpcErr := s.dispatch(ctx, userID, req)
+	if req.IsNotification() {
+		return nil
+	}
+	if rpcErr != nil {
+		return &Response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr}
+	}
+	return &Response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func (s *Server) dispatch(ctx context.Context, userID uuid.UUID, req *Request) (any, *Error) {
+	switch req.Method {
+	case "initialize":
+		return map[string]any{
+			"protocolVersion": ProtocolVersion,
+			"capabilities": map[string]any{
+				"tools":     map[string]any{},
+				"resources": map[string]any{},
+			},
+			"serverInfo": map[string]any{
+				"name":    serverName,
+				"version": serverVersion,
+			},
+			"instructions": "Financial data from FINS: symbol profiles, monthly/weekly price histories with YoY changes, " +
+				"analysis packages, and the current user's favorites, ratings (-5 to +5) and notes.",
+		}, nil
+
+	case "notifications/initialized", "notifications/cancelled", "ping":
+		return map[string]any{}, nil
+
+	case "tools/list":
+		list := make([]Tool, len(s.tools))
+		for i, t := range s.tools {
+			list[i] = t.Tool
+		}
+		return map[string]any{"tools": list}, nil
+
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &Error{Code: codeInvalidParams, Message: "Invalid params: " + err.Error()}
+		}
+		tool, ok := s.toolIndex[params.Name]
+		if !ok {
+			return nil, &Error{Code: codeInvalidParams, Message: "Unknown tool: " + params.Name}
+		}
+		if len(params.Arguments) == 0 || string(params.Arguments) == "null" {
+			params.Arguments = json.RawMessage("{}")
+		}
+		return callTool(ctx, userID, tool, params.Arguments), nil
+
+	case "resources/list":
+		return map[string]any{"resources": resources}, nil
+
+	case "resources/templates/list":
+		return map[string]any{"resourceTemplates": resourceTemplates}, nil
+
+	case "resources/read":
+		var params struct {
+			URI string `json:"uri"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil || params.URI == "" {
+			return nil, &Error{Code: codeInvalidParams, Message: "uri required"}
+		}
+		data, err := readResource(ctx, userID, params.URI)
+		if err != nil {
+			return nil, &Error{Code: codeInvalidParams, Message: err.Error()}
+		}
+		text, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return nil, &Error{Code: codeInternalError, Message: err.Error()}
+		}
+		return map[string]any{
+			"contents": []ResourceContents{{URI: params.URI, MimeType: "application/json", Text: string(text)}},
+		}, nil
+
+	default:
+		return nil, &Error{Code: codeMethodNotFound, Message: "Method not found: " + req.Method}
+	}
+}
+
+// callTool runs a tool and wraps its result (or error) as JSON text content
+func callTool(ctx context.Context, userID uuid.UUID, tool toolDef, args json.RawMessage) ToolResult {
+	result, err := tool.handler(ctx, userID, args)
+	if err != nil {
+		return ToolResult{Content: []Content{{Type: "text", Text: err.Error()}}, IsError: true}
+	}
+	text, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return ToolResult{Content: []Content{{Type: "text", Text: fmt.Sprintf("failed to encode result: %v", err)}}, IsError: true}
+	}
+	return ToolResult{Content: []Content{{Type: "text", Text: string(text)}}}
+}
+
+func errorResponse(id json.RawMessage, code int, message string) *Response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &Response{JSONRPC: "2.0", ID: id, Error: &Error{Code: code, Message: message}}
+}
+
+func mustMarshal(v any) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":null,"error":{"code":%d,"message":%q}}`, codeInternalError, err.Error()))
+	}
+	return data
+}
@@ -0,0 +1,111 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+)
+
+func call(t *testing.T, s *Server, message string) map[string]any {
+	t.Helper()
+	raw := s.HandleMessage(context.Background(), uuid.Nil, []byte(message))
+	if raw == nil {
+		t.Fatalf("no response for %s", message)
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		t.Fatalf("invalid response %s: %v", raw, err)
+	}
+	return resp
+}
+
+func TestInitializeAndToolsList(t *testing.T) {
+	s := NewServer()
+
+	resp := call(t, s, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`)
+	result := resp["result"].(map[string]any)
+	if result["protocolVersion"] != ProtocolVersion {
+		t.Errorf("protocolVersion = %v", result["protocolVersion"])
+	}
+
+	resp = call(t, s, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	toolList := resp["result"].(map[string]any)["tools"].([]any)
+	names := map[string]bool{}
+	for _, tool := range toolList {
+		names[tool.(map[string]any)["name"].(string)] = true
+	}
+	for _, want := range []string{"get_symbol", "get_prices", "get_analysis_results", "get_ratings", "toggle_favorite", "add_rating"} {
+		if !names[want] {
+			t.Errorf("tool %s missing", want)
+		}
+	}
+}
+
+func TestNotificationsAndErrors(t *testing.T) {
+	s := NewServer()
+
+	if raw := s.HandleMessage(context.Background(), uuid.Nil, []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)); raw != nil {
+		t.Errorf("notification must not be answered, got %s", raw)
+	}
+
+	resp := call(t, s, `{"jsonrpc":"2.0","id":"a","method":"does/not/exist"}`)
+	if code := resp["error"].(map[string]any)["code"].(float64); code != codeMethodNotFound {
+		t.Errorf("code = %v, want %d", code, codeMethodNotFound)
+	}
+
+	resp = call(t, s, `not json`)
+	if code := resp["error"].(map[string]any)["code"].(float64); code != codeParseError {
+		t.Errorf("code = %v, want %d", code, codeParseError)
+	}
+
+	// Invalid tool arguments are reported as tool errors, not protocol errors
+	resp = call(t, s, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"add_rating","arguments":{"ticker":"AAPL","rating":9}}}`)
+	if isError, _ := resp["result"].(map[string]any)["isError"].(bool); !isError {
+		t.Errorf("expected isError result, got %v", resp)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	s := NewServer()
+	raw := s.HandleMessage(context.Background(), uuid.Nil, []byte(`[
+		{"jsonrpc":"2.0","id":1,"method":"ping"},
+		{"jsonrpc":"2.0","method":"notifications/initialized"},
+		{"jsonrpc":"2.0","id":2,"method":"resources/templates/list"}
+	]`))
+	var responses []map[string]any
+	if err := json.Unmarshal(raw, &responses); err != nil {
+		t.Fatalf("invalid batch response %s: %v", raw, err)
+	}
+	if len(responses) != 2 {
+		t.Errorf("expected 2 responses, got %d", len(responses))
+	}
+}
+
+func TestCalculatePriceStats(t *testing.T) {
+	start := time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC)
+	prices := []types.PriceData{
+		{Date: start, Close: 100},
+		{Date: start.AddDate(1, 0, 0), Close: 110, YoY: f.Ptr(10.0)},
+		{Date: start.AddDate(2, 0, 0), Close: 121, YoY: f.Ptr(10.0)},
+	}
+
+	stats := calculatePriceStats(prices)
+
+	if stats.CAGR == nil || math.Abs(*stats.CAGR-10) > 0.05 {
+		t.Errorf("CAGR = %v, want ~10", stats.CAGR)
+	}
+	if stats.YoYCount != 2 || *stats.YoYMean != 10 || *stats.YoYStdDev != 0 {
+		t.Errorf("unexpected YoY stats: %+v", stats)
+	}
+
+	short := calculatePriceStats(prices[:1])
+	if short.CAGR != nil || short.YoYMean != nil {
+		t.Errorf("single price must have no CAGR/YoY stats: %+v", short)
+	}
+}
@@ -0,0 +1,419 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultSearchLimit  = 20
+	defaultResultsLimit = 50
+)
+
+// toolHandler runs a tool for a user. args is the raw "arguments" object of tools/call.
+type toolHandler func(ctx context.Context, userID uuid.UUID, args json.RawMessage) (any, error)
+
+type toolDef struct {
+	Tool
+	handler toolHandler
+}
+
+// tickerArgs is the argument object of all tools that take a single ticker
+type tickerArgs struct {
+	Ticker string `json:"ticker"`
+}
+
+// priceArgs selects a price series; interval defaults to monthly, the range to the last 5 years
+type priceArgs struct {
+	Ticker   string `json:"ticker"`
+	Interval string `json:"interval"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
+
+// PricePoint is a compact price record as returned by get_prices
+type PricePoint struct {
+	Date  string   `json:"date"`
+	Close float64  `json:"close"` // USD
+	YoY   *float64 `json:"yoy"`   // Percent change vs. one year earlier
+}
+
+// PriceStats summarizes a price series as returned by get_price_stats
+type PriceStats struct {
+	Ticker      string   `json:"ticker"`
+	Interval    string   `json:"interval"`
+	From        string   `json:"from"`
+	To          string   `json:"to"`
+	Count       int      `json:"count"`
+	FirstClose  float64  `json:"firstClose"`
+	LastClose   float64  `json:"lastClose"`
+	CAGR        *float64 `json:"cagr"`      // Percent per year, nil if the series covers less than a year
+	YoYCount    int      `json:"yoyCount"`  // Number of YoY values the stats below are based on
+	YoYMean     *float64 `json:"yoyMean"`   // Percent
+	YoYStdDev   *float64 `json:"yoyStddev"` // Percent
+	YoYVariance *float64 `json:"yoyVariance"`
+	YoYMin      *float64 `json:"yoyMin"`
+	YoYMax      *float64 `json:"yoyMax"`
+}
+
+func tools() []toolDef {
+	tickerSchema := objectSchema(map[string]any{
+		"ticker": prop("string", "Symbol ticker, e.g. AAPL"),
+	}, "ticker")
+	priceSchema := objectSchema(map[string]any{
+		"ticker":   prop("string", "Symbol ticker, e.g. AAPL"),
+		"interval": enumProp("Price interval (default monthly)", string(types.IntervalMonthly), string(types.IntervalWeekly)),
+		"from":     prop("string", "Start date YYYY, YYYY-MM or YYYY-MM-DD (default 5 years ago)"),
+		"to":       prop("string", "End date YYYY, YYYY-MM or YYYY-MM-DD (default today)"),
+	}, "ticker")
+	noArgs := objectSchema(map[string]any{})
+
+	return []toolDef{
+		{Tool{"search_symbols", "Full-text search over ticker, company name, industry and description", objectSchema(map[string]any{
+			"query": prop("string", "Search text"),
+			"limit": prop("integer", "Maximum number of hits (default 20)"),
+		}, "query")}, searchSymbols},
+		{Tool{"get_symbol", "Company profile, market cap, current price and the user's favorite/rating status for a symbol", tickerSchema}, getSymbol},
+		{Tool{"get_prices", "Price history (USD close and YoY change in percent) of a symbol", priceSchema}, getPrices},
+		{Tool{"get_price_stats", "CAGR and YoY mean, standard deviation, variance, min and max of a symbol's price history", priceSchema}, getPriceStats},
+		{Tool{"list_analyses", "Analysis packages of the user", noArgs}, listAnalyses},
+		{Tool{"get_analysis_results", "Per-symbol YoY statistics of an analysis package, best mean first", objectSchema(map[string]any{
+			"package_id": prop("string", "Analysis package ID"),
+			"limit":      prop("integer", "Maximum number of results (default 50, 0 for all)"),
+		}, "package_id")}, getAnalysisResults},
+		{Tool{"list_favorites", "Tickers the user marked as favorite", noArgs}, listFavorites},
+		{Tool{"toggle_favorite", "Add a symbol to the user's favorites, or remove it if it already is one", tickerSchema}, toggleFavorite},
+		{Tool{"get_ratings", "The user's latest rating (-5 to +5) and notes for every rated symbol", noArgs}, getRatings},
+		{Tool{"get_rating_history", "All ratings the user gave a symbol, newest first", tickerSchema}, getRatingHistory},
+		{Tool{"add_rating", "Rate a symbol from -5 (avoid) to +5 (strong buy), optionally with notes", objectSchema(map[string]any{
+			"ticker": prop("string", "Symbol ticker, e.g. AAPL"),
+			"rating": prop("integer", "Rating from -5 to +5"),
+			"notes":  prop("string", "Optional notes explaining the rating"),
+		}, "ticker", "rating")}, addRating},
+		{Tool{"get_notes", "All of the user's rating notes, newest first", noArgs}, getNotes},
+	}
+}
+
+func objectSchema(properties map[string]any, required ...string) map[string]any {
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func prop(typ, description string) map[string]any {
+	return map[string]any{"type": typ, "description": description}
+}
+
+func enumProp(description string, values ...string) map[string]any {
+	return map[string]any{"type": "string", "description": description, "enum": values}
+}
+
+func decodeArgs(args json.RawMessage, target any) error {
+	if err := json.Unmarshal(args, target); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+func decodeTicker(args json.RawMessage) (string, error) {
+	var a tickerArgs
+	if err := decodeArgs(args, &a); err != nil {
+		return "", err
+	}
+	ticker := strings.ToUpper(strings.TrimSpace(a.Ticker))
+	if ticker == "" {
+		return "", fmt.Errorf("ticker required")
+	}
+	return ticker, nil
+}
+
+func searchSymbols(ctx context.Context, userID uuid.UUID, args json.RawMessage) (any, error) {
+	var a struct {
+		Query string `json:"query"`
+		Limit int    `json:"limit"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(a.Query) == "" {
+		return nil, fmt.Errorf("query required")
+	}
+	if a.Limit <= 0 {
+		a.Limit = defaultSearchLimit
+	}
+	return db.SearchSymbols(ctx, a.Query, a.Limit)
+}
+
+func getSymbol(ctx context.Context, userID uuid.UUID, args json.RawMessage) (any, error) {
+	ticker, err := decodeTicker(args)
+	if err != nil {
+		return nil, err
+	}
+	return loadSymbol(ctx, userID, ticker)
+}
+
+// loadSymbol returns a symbol with the user's favorite and rating status filled in
+func loadSymbol(ctx context.Context, userID uuid.UUID, ticker string) (*types.Symbol, error) {
+	symbol, err := db.GetSymbol(ctx, ticker)
+	if err != nil {
+		return nil, err
+	}
+	if symbol == nil {
+		return nil, fmt.Errorf("symbol %s not found", ticker)
+	}
+
+	symbol.IsFavorite, err = db.IsFavorite(ctx, userID, symbol.Ticker)
+	if err != nil {
+		return nil, err
+	}
+	rating, err := db.GetLatestRating(ctx, userID, symbol.Ticker)
+	if err != nil {
+		return nil, err
+	}
+	if rating != nil {
+		symbol.UserRating = &rating.Rating
+	}
+	return symbol, nil
+}
+
+func getPrices(ctx context.Context, userID uuid.UUID, args json.RawMessage) (any, error) {
+	var a priceArgs
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+	_, _, _, prices, err := loadPrices(a)
+	if err != nil {
+		return nil, err
+	}
+	return pricePoints(prices), nil
+}
+
+func getPriceStats(ctx context.Context, userID uuid.UUID, args json.RawMessage) (any, error) {
+	var a priceArgs
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+	ticker, interval, from, prices, err := loadPrices(a)
+	if err != nil {
+		return nil, err
+	}
+	if len(prices) == 0 {
+		return nil, fmt.Errorf("no %s prices for %s since %s", interval, ticker, from.Format("2006-01-02"))
+	}
+	stats := calculatePriceStats(prices)
+	stats.Ticker = ticker
+	stats.Interval = string(interval)
+	return stats, nil
+}
+
+// loadPrices resolves the defaults of priceArgs and loads the series
+func loadPrices(a priceArgs) (string, types.PriceInterval, time.Time, []types.PriceData, error) {
+	ticker := strings.ToUpper(strings.TrimSpace(a.Ticker))
+	if ticker == "" {
+		return "", "", time.Time{}, nil, fmt.Errorf("ticker required")
+	}
+
+	interval := types.PriceInterval(a.Interval)
+	if interval == "" {
+		interval = types.IntervalMonthly
+	}
+	if interval != types.IntervalMonthly && interval != types.IntervalWeekly {
+		return "", "", time.Time{}, nil, fmt.Errorf("invalid interval '%s' (must be 'monthly' or 'weekly')", a.Interval)
+	}
+
+	to := time.Now()
+	from := to.AddDate(-5, 0, 0)
+	if a.From != "" {
+		parsed, err := f.ParseDate(a.From)
+		if err != nil {
+			return "", "", time.Time{}, nil, fmt.Errorf("invalid from: %w", err)
+		}
+		from = parsed
+	}
+	if a.To != "" {
+		parsed, err := f.ParseDate(a.To)
+		if err != nil {
+			return "", "", time.Time{}, nil, fmt.Errorf("invalid to: %w", err)
+		}
+		to = parsed
+	}
+
+	prices, err := db.GetPrices(ticker, from, to, interval)
+	return ticker, interval, from, prices, err
+}
+
+func pricePoints(prices []types.PriceData) []PricePoint {
+	points := make([]PricePoint, len(prices))
+	for i, p := range prices {
+		points[i] = PricePoint{Date: p.Date.Format("2006-01-02"), Close: p.Close, YoY: p.YoY}
+	}
+	return points
+}
+
+// calculatePriceStats computes CAGR from the first and last close and summary statistics of the YoY values.
+// prices must be sorted by date and not be empty.
+func calculatePriceStats(prices []types.PriceData) PriceStats {
+	first, last := prices[0], prices[len(prices)-1]
+	stats := PriceStats{
+		From:       first.Date.Format("2006-01-02"),
+		To:         last.Date.Format("2006-01-02"),
+		Count:      len(prices),
+		FirstClose: first.Close,
+		LastClose:  last.Close,
+	}
+
+	years := last.Date.Sub(first.Date).Hours() / 24 / 365.25
+	if years >= 1 && first.Close > 0 && last.Close > 0 {
+		stats.CAGR = f.Ptr((math.Pow(last.Close/first.Close, 1/years) - 1) * 100)
+	}
+
+	var yoy []float64
+	for _, p := range prices {
+		if p.YoY != nil {
+			yoy = append(yoy, *p.YoY)
+		}
+	}
+	stats.YoYCount = len(yoy)
+	if len(yoy) == 0 {
+		return stats
+	}
+
+	sum, lo, hi := 0.0, yoy[0], yoy[0]
+	for _, v := range yoy {
+		sum += v
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	mean := sum / float64(len(yoy))
+	sumSquaredDiff := 0.0
+	for _, v := range yoy {
+		sumSquaredDiff += (v - mean) * (v - mean)
+	}
+	variance := sumSquaredDiff / float64(len(yoy))
+
+	stats.YoYMean = &mean
+	stats.YoYVariance = &variance
+	stats.YoYStdDev = f.Ptr(math.Sqrt(variance))
+	stats.YoYMin = &lo
+	stats.YoYMax = &hi
+	return stats
+}
+
+func listAnalyses(ctx context.Context, userID uuid.UUID, args json.RawMessage) (any, error) {
+	packages, err := db.ListAnalysisPackages(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if packages == nil {
+		packages = []types.AnalysisPackage{}
+	}
+	return packages, nil
+}
+
+func getAnalysisResults(ctx context.Context, userID uuid.UUID, args json.RawMessage) (any, error) {
+	a := struct {
+		PackageID string `json:"package_id"`
+		Limit     *int   `json:"limit"`
+	}{}
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+	if a.PackageID == "" {
+		return nil, fmt.Errorf("package_id required")
+	}
+	limit := defaultResultsLimit
+	if a.Limit != nil {
+		limit = *a.Limit
+	}
+	return loadAnalysisResults(ctx, userID, a.PackageID, limit)
+}
+
+// loadAnalysisResults returns the first limit results of a package (all if limit <= 0)
+func loadAnalysisResults(ctx context.Context, userID uuid.UUID, packageID string, limit int) ([]types.AnalysisResult, error) {
+	results, err := db.GetAnalysisResults(ctx, userID, packageID)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	if results == nil {
+		results = []types.AnalysisResult{}
+	}
+	return results, nil
+}
+
+func listFavorites(ctx context.Context, userID uuid.UUID, args json.RawMessage) (any, error) {
+	favorites, err := db.GetFavorites(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if favorites == nil {
+		favorites = []string{}
+	}
+	return favorites, nil
+}
+
+func toggleFavorite(ctx context.Context, userID uuid.UUID, args json.RawMessage) (any, error) {
+	ticker, err := decodeTicker(args)
+	if err != nil {
+		return nil, err
+	}
+	isFavorite, err := db.ToggleFavorite(ctx, userID, ticker)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"ticker": ticker, "isFavorite": isFavorite}, nil
+}
+
+func getRatings(ctx context.Context, userID uuid.UUID, args json.RawMessage) (any, error) {
+	return db.GetAllLatestRatings(ctx, userID)
+}
+
+func getRatingHistory(ctx context.Context, userID uuid.UUID, args json.RawMessage) (any, error) {
+	ticker, err := decodeTicker(args)
+	if err != nil {
+		return nil, err
+	}
+	return db.GetRatingHistory(ctx, userID, ticker)
+}
+
+func addRating(ctx context.Context, userID uuid.UUID, args json.RawMessage) (any, error) {
+	var a struct {
+		Ticker string  `json:"ticker"`
+		Rating *int    `json:"rating"`
+		Notes  *string `json:"notes"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+	ticker := strings.ToUpper(strings.TrimSpace(a.Ticker))
+	if ticker == "" {
+		return nil, fmt.Errorf("ticker required")
+	}
+	if a.Rating == nil || *a.Rating < -5 || *a.Rating > 5 {
+		return nil, fmt.Errorf("rating must be between -5 and +5")
+	}
+	if a.Notes != nil && strings.TrimSpace(*a.Notes) == "" {
+		a.Notes = nil
+	}
+	return db.AddRating(ctx, userID, ticker, *a.Rating, a.Notes)
+}
+
+func getNotes(ctx context.Context, userID uuid.UUID, args json.RawMessage) (any, error) {
+	return db.GetAllNotesChronological(ctx, userID)
+}
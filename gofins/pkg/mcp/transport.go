@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/google/uuid"
+)
+
+// maxMessageSize limits a single JSON-RPC message (4 MB)
+const maxMessageSize = 4 << 20
+
+// ServeStdio reads newline-delimited JSON-RPC messages from r and writes responses to w
+// until r is closed or ctx is cancelled. All requests run as userID.
+func (s *Server) ServeStdio(ctx context.Context, userID uuid.UUID, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if resp := s.HandleMessage(ctx, userID, line); resp != nil {
+			if _, err := fmt.Fprintf(w, "%s\n", resp); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// HTTPHandler serves the streamable HTTP transport on a single endpoint.
+// The user is taken from the X-Remote-User header, falling back to defaultUser;
+// unknown users are created on first access like in the REST API.
+// Responses are always plain JSON - the server never initiates messages, so no SSE stream is offered.
+func (s *Server) HTTPHandler(defaultUser string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		username := r.Header.Get("X-Remote-User")
+		if username == "" {
+			username = defaultUser
+		}
+		if username == "" {
+			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+		userID, err := ResolveUser(r.Context(), username)
+		if err != nil {
+			fmt.Printf("[MCP] Error resolving user '%s': %v\n", username, err)
+			http.Error(w, "Authentication error", http.StatusInternalServerError)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxMessageSize))
+		if err != nil {
+			http.Error(w, "Failed to read request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resp := s.HandleMessage(r.Context(), userID, body)
+		if resp == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(resp)
+	})
+}
+
+// ResolveUser returns the ID of a user, creating the user if it doesn't exist yet
+func ResolveUser(ctx context.Context, username string) (uuid.UUID, error) {
+	user, err := db.GetUser(ctx, username)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if user == nil {
+		user, err = db.CreateUser(ctx, username)
+		if err != nil {
+			return uuid.Nil, err
+		}
+	}
+	return user.ID, nil
+}
+
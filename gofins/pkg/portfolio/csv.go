@@ -0,0 +1,235 @@
+package portfolio
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+// ImportError describes a CSV line that couldn't be imported
+type ImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// csvColumns maps the column names used by broker exports to transaction fields.
+// Header names are compared lowercased with '_' and '-' replaced by spaces.
+var csvColumns = map[string][]string{
+	"date":     {"date", "trade date", "transaction date", "settlement date", "datum"},
+	"type":     {"type", "action", "transaction type", "side", "activity"},
+	"ticker":   {"ticker", "symbol"},
+	"quantity": {"quantity", "shares", "qty", "units"},
+	"price":    {"price", "price per share", "unit price"},
+	"amount":   {"amount", "total", "net amount"},
+	"fee":      {"fee", "fees", "commission", "commissions"},
+	"currency": {"currency", "ccy"},
+	"fx_rate":  {"fx rate", "exchange rate"},
+	"note":     {"note", "notes", "description"},
+}
+
+// csvTypes maps transaction type spellings of broker exports to transaction types
+var csvTypes = map[string]string{
+	"buy":                types.TransactionBuy,
+	"bought":             types.TransactionBuy,
+	"purchase":           types.TransactionBuy,
+	"b":                  types.TransactionBuy,
+	"sell":               types.TransactionSell,
+	"sold":               types.TransactionSell,
+	"sale":               types.TransactionSell,
+	"s":                  types.TransactionSell,
+	"dividend":           types.TransactionDividend,
+	"dividends":          types.TransactionDividend,
+	"div":                types.TransactionDividend,
+	"cash dividend":      types.TransactionDividend,
+	"qualified dividend": types.TransactionDividend,
+}
+
+// csvDateFormats are tried in order, ISO first
+var csvDateFormats = []string{"2006-01-02", "2006-01-02 15:04:05", "2006-01-02T15:04:05Z07:00", "01/02/2006", "02.01.2006", "2006/01/02"}
+
+// ParseCSV reads broker transactions from a CSV export with a header line.
+// The delimiter (',', ';' or tab) is detected from the header. Rows that can't be parsed are reported
+// as ImportError and skipped; an error is only returned if the file as a whole is unreadable.
+// FxRate stays 0 unless the file has an exchange rate column, to be looked up on import.
+func ParseCSV(r io.Reader) ([]types.Transaction, []ImportError, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectDelimiter(data)
+	// Exports separated by ';' come from locales writing decimal commas
+	decimalComma := reader.Comma != ','
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New("empty file")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid header: %w", err)
+	}
+	columns := mapColumns(header)
+	for _, required := range []string{"date", "type", "ticker"} {
+		if _, exists := columns[required]; !exists {
+			return nil, nil, fmt.Errorf("missing column '%s'", required)
+		}
+	}
+
+	transactions := []types.Transaction{}
+	importErrors := []ImportError{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			importErrors = append(importErrors, ImportError{Line: line, Error: err.Error()})
+			continue
+		}
+		tx, err := parseRecord(record, columns, decimalComma)
+		if err != nil {
+			importErrors = append(importErrors, ImportError{Line: line, Error: err.Error()})
+			continue
+		}
+		transactions = append(transactions, tx)
+	}
+	return transactions, importErrors, nil
+}
+
+// detectDelimiter picks the most frequent of ',', ';' and tab in the first line
+func detectDelimiter(data []byte) rune {
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	delimiter, count := ',', bytes.Count(firstLine, []byte(","))
+	for _, candidate := range []rune{';', '\t'} {
+		if n := bytes.Count(firstLine, []byte(string(candidate))); n > count {
+			delimiter, count = candidate, n
+		}
+	}
+	return delimiter
+}
+
+// mapColumns returns the index of each known field in the header
+func mapColumns(header []string) map[string]int {
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		name = strings.NewReplacer("_", " ", "-", " ").Replace(name)
+		for field, aliases := range csvColumns {
+			if _, exists := columns[field]; exists {
+				continue
+			}
+			for _, alias := range aliases {
+				if name == alias {
+					columns[field] = i
+				}
+			}
+		}
+	}
+	return columns
+}
+
+func parseRecord(record []string, columns map[string]int, decimalComma bool) (types.Transaction, error) {
+	value := func(field string) string {
+		if i, exists := columns[field]; exists && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	number := func(field string) (float64, error) {
+		parsed, err := parseNumber(value(field), decimalComma)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s '%s'", field, value(field))
+		}
+		return parsed, nil
+	}
+
+	var tx types.Transaction
+	var err error
+
+	txType, known := csvTypes[strings.ToLower(value("type"))]
+	if !known {
+		return tx, fmt.Errorf("unknown transaction type '%s'", value("type"))
+	}
+	tx.Type = txType
+	tx.Ticker = value("ticker")
+	if tx.Date, err = parseCSVDate(value("date")); err != nil {
+		return tx, err
+	}
+	if tx.Quantity, err = number("quantity"); err != nil {
+		return tx, err
+	}
+	if tx.Price, err = number("price"); err != nil {
+		return tx, err
+	}
+	if tx.Fee, err = number("fee"); err != nil {
+		return tx, err
+	}
+	if tx.FxRate, err = number("fx_rate"); err != nil {
+		return tx, err
+	}
+	tx.Currency = value("currency")
+	if note := value("note"); note != "" {
+		tx.Note = &note
+	}
+
+	// Brokers export sells and fees as negative numbers
+	tx.Quantity = math.Abs(tx.Quantity)
+	tx.Fee = math.Abs(tx.Fee)
+
+	if tx.Type == types.TransactionDividend {
+		amount, err := number("amount")
+		if err != nil {
+			return tx, err
+		}
+		// Dividends may be exported as shares times dividend per share
+		if amount == 0 {
+			amount = tx.Quantity * tx.Price
+		}
+		tx.Amount = math.Abs(amount)
+		tx.Quantity, tx.Price = 0, 0
+	}
+
+	return tx, NormalizeTransaction(&tx)
+}
+
+// parseNumber parses amounts with thousands separators. If both '.' and ',' occur, the last one is the
+// decimal separator. Otherwise decimalComma decides: if set, ',' is the decimal and '.' the thousands
+// separator ("1.234" is 1234), if not the other way round. Empty values are 0.
+func parseNumber(s string, decimalComma bool) (float64, error) {
+	s = strings.NewReplacer(" ", "", "$", "", "€", "", "£", "").Replace(s)
+	if s == "" {
+		return 0, nil
+	}
+	lastDot, lastComma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+	if lastComma > lastDot && (lastDot >= 0 || decimalComma) || lastComma < 0 && decimalComma {
+		// Decimal comma: "1.234,56", "12,5" or "1.234"
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.Replace(s, ",", ".", 1)
+	} else {
+		s = strings.ReplaceAll(s, ",", "")
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+func parseCSVDate(s string) (time.Time, error) {
+	for _, format := range csvDateFormats {
+		if date, err := time.Parse(format, s); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date '%s'", s)
+}
@@ -0,0 +1,73 @@
+package portfolio
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+func TestParseCSV(t *testing.T) {
+	input := "\xef\xbb\xbfTrade Date,Action,Symbol,Quantity,Price,Commission,Currency,Amount,Description\n" +
+		"2024-01-02,Bought,aapl,10,\"1,185.50\",1.00,USD,,first buy\n" +
+		"01/15/2024,SOLD,AAPL,-4,190,-1,,,\n" +
+		"2024-02-01,Dividend,AAPL,,,,USD,2.40,\n" +
+		"2024-02-02,Transfer,AAPL,1,1,,,,\n" +
+		"not a date,Buy,AAPL,1,1,,,,\n"
+
+	transactions, importErrors, err := ParseCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions) != 3 {
+		t.Fatalf("got %d transactions, want 3", len(transactions))
+	}
+
+	buy := transactions[0]
+	if buy.Type != types.TransactionBuy || buy.Ticker != "AAPL" || buy.Price != 1185.5 || buy.Fee != 1 {
+		t.Errorf("buy = %+v", buy)
+	}
+	if buy.Note == nil || *buy.Note != "first buy" {
+		t.Errorf("buy note = %v, want 'first buy'", buy.Note)
+	}
+	sell := transactions[1]
+	if sell.Type != types.TransactionSell || sell.Quantity != 4 || sell.Fee != 1 || sell.Currency != "USD" || sell.Date != day(15) {
+		t.Errorf("sell = %+v", sell)
+	}
+	dividend := transactions[2]
+	if dividend.Type != types.TransactionDividend || dividend.Amount != 2.4 || dividend.Quantity != 0 {
+		t.Errorf("dividend = %+v", dividend)
+	}
+
+	if len(importErrors) != 2 || importErrors[0].Line != 5 || importErrors[1].Line != 6 {
+		t.Errorf("import errors = %+v, want lines 5 and 6", importErrors)
+	}
+}
+
+func TestParseCSVSemicolonDecimalComma(t *testing.T) {
+	input := "Datum;Type;Ticker;Shares;Price;Fee;Currency\n" +
+		"15.01.2024;buy;SAP.DE;2;1.234,56;4,90;eur\n" +
+		"15.01.2024;buy;SAP.DE;1.000;1.234;0;eur\n"
+
+	transactions, importErrors, err := ParseCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(importErrors) > 0 {
+		t.Fatalf("unexpected import errors: %+v", importErrors)
+	}
+	tx := transactions[0]
+	if tx.Date != day(15) || tx.Price != 1234.56 || tx.Fee != 4.9 || tx.Currency != "EUR" || tx.FxRate != 0 {
+		t.Errorf("transaction = %+v", tx)
+	}
+	// Without a comma the dots are thousands separators
+	if tx := transactions[1]; tx.Quantity != 1000 || tx.Price != 1234 {
+		t.Errorf("transaction = %+v, want 1000 at 1234", tx)
+	}
+}
+
+func TestParseCSVMissingColumn(t *testing.T) {
+	if _, _, err := ParseCSV(strings.NewReader("date,ticker,quantity\n2024-01-02,AAPL,1\n")); err == nil {
+		t.Error("expected an error for a file without a type column")
+	}
+}
@@ -0,0 +1,145 @@
+// Package portfolio tracks the holdings of a user: transactions, positions with FIFO or average
+// cost basis, valuation at current prices and import of broker CSV exports. All amounts are in USD.
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/forex"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidTransaction is returned if a transaction fails NormalizeTransaction
+	ErrInvalidTransaction = errors.New("invalid transaction")
+	// ErrNoFxRate is returned if a transaction has no exchange rate and none is available for its date
+	ErrNoFxRate = errors.New("no exchange rate available")
+)
+
+// AddTransactions normalizes and stores transactions of a portfolio. Missing exchange rates are looked up
+// for the transaction date. Nothing is stored if any transaction is invalid or would oversell a position.
+func AddTransactions(ctx context.Context, portfolio *types.Portfolio, transactions []types.Transaction) ([]types.Transaction, error) {
+	for i := range transactions {
+		tx := &transactions[i]
+		if err := NormalizeTransaction(tx); err != nil {
+			return nil, fmt.Errorf("%w %d: %v", ErrInvalidTransaction, i+1, err)
+		}
+		if tx.FxRate == 0 {
+			rate, err := forex.UsdRate(tx.Currency, tx.Date)
+			if err != nil {
+				return nil, fmt.Errorf("%w for %s on %s: %v", ErrNoFxRate, tx.Currency, tx.Date.Format("2006-01-02"), err)
+			}
+			tx.FxRate = rate
+		}
+		tx.ID = uuid.New()
+		tx.PortfolioID = portfolio.ID
+		tx.CreatedAt = time.Now()
+	}
+
+	err := db.AddPortfolioTransactions(ctx, portfolio.ID, transactions, func(existing []types.Transaction) error {
+		_, err := Positions(append(existing, transactions...), portfolio.CostBasis)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// DeleteTransaction removes a transaction unless the remaining ones would oversell a position,
+// e.g. when deleting a buy that later sells depend on. Returns false if the transaction doesn't exist.
+func DeleteTransaction(ctx context.Context, portfolio *types.Portfolio, transactionID uuid.UUID) (bool, error) {
+	return db.DeletePortfolioTransaction(ctx, portfolio.ID, transactionID, func(remaining []types.Transaction) error {
+		_, err := Positions(remaining, portfolio.CostBasis)
+		return err
+	})
+}
+
+// Valuate calculates the positions of a portfolio with the given cost basis method (the portfolio's
+// method if empty) and values them at the current prices of their symbols.
+func Valuate(ctx context.Context, portfolio *types.Portfolio, method string) (*types.PortfolioValuation, error) {
+	if method == "" {
+		method = portfolio.CostBasis
+	}
+	transactions, err := db.ListPortfolioTransactions(ctx, portfolio.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+	positions, err := Positions(transactions, method)
+	if err != nil {
+		return nil, err
+	}
+
+	var tickers []string
+	for _, position := range positions {
+		if position.Quantity != 0 {
+			tickers = append(tickers, position.Ticker)
+		}
+	}
+	prices, err := db.GetCurrentPrices(ctx, tickers)
+	if err != nil {
+		return nil, err
+	}
+	quotes := make(map[string]Quote, len(prices))
+	for ticker, price := range prices {
+		quotes[ticker] = Quote{Price: price.Price, Time: price.Time}
+	}
+
+	valuation := Value(positions, quotes)
+	valuation.PortfolioID = portfolio.ID
+	valuation.CostBasis = method
+	return &valuation, nil
+}
+
+// NormalizeTransaction cleans up ticker, currency and date of a transaction and validates its values
+func NormalizeTransaction(tx *types.Transaction) error {
+	tx.Ticker = strings.ToUpper(strings.TrimSpace(tx.Ticker))
+	tx.Currency = strings.ToUpper(strings.TrimSpace(tx.Currency))
+	if tx.Currency == "" {
+		tx.Currency = "USD"
+	}
+	tx.Date = time.Date(tx.Date.Year(), tx.Date.Month(), tx.Date.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch {
+	case tx.Ticker == "":
+		return errors.New("ticker is required")
+	case !types.IsValidTransactionType(tx.Type):
+		return fmt.Errorf("invalid transaction type '%s'", tx.Type)
+	case tx.Date.IsZero():
+		return errors.New("date is required")
+	case tx.Date.After(time.Now()):
+		return errors.New("date is in the future")
+	case len(tx.Currency) != 3:
+		return fmt.Errorf("invalid currency '%s'", tx.Currency)
+	case tx.Fee < 0:
+		return errors.New("fee must not be negative")
+	case tx.FxRate < 0:
+		return errors.New("fx rate must not be negative")
+	}
+
+	if tx.Type == types.TransactionDividend {
+		if tx.Amount <= 0 {
+			return errors.New("dividend amount must be positive")
+		}
+		if tx.Quantity != 0 || tx.Price != 0 {
+			return errors.New("dividends have an amount, not quantity and price")
+		}
+		return nil
+	}
+	if tx.Quantity <= 0 {
+		return errors.New("quantity must be positive")
+	}
+	if tx.Price < 0 {
+		return errors.New("price must not be negative")
+	}
+	if tx.Amount != 0 {
+		return fmt.Errorf("%s transactions have quantity and price, not an amount", tx.Type)
+	}
+	return nil
+}
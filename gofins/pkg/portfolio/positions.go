@@ -0,0 +1,170 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+// ErrOversold is returned if a sell exceeds the shares held at that date
+var ErrOversold = errors.New("sell exceeds position")
+
+// epsilon absorbs float rounding when shares are sold down to zero
+const epsilon = 1e-9
+
+// Quote is the current USD price of a ticker
+type Quote struct {
+	Price float64
+	Time  *time.Time
+}
+
+// lot is an open buy, consumed oldest first by FIFO sells
+type lot struct {
+	quantity float64
+	cost     float64 // USD including the buy fee
+}
+
+type holding struct {
+	position types.Position
+	lots     []lot
+}
+
+// Positions replays the transactions in date order and returns the resulting position per ticker, sorted by ticker.
+// Closed positions are included for their realized P&L and dividends. Buys and dividends of a day are applied
+// before its sells, so same-day round trips don't fail regardless of their order.
+func Positions(transactions []types.Transaction, method string) ([]types.Position, error) {
+	if !types.IsValidCostBasis(method) {
+		return nil, fmt.Errorf("invalid cost basis method '%s'", method)
+	}
+
+	sorted := make([]types.Transaction, len(transactions))
+	copy(sorted, transactions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].Date.Equal(sorted[j].Date) {
+			return sorted[i].Date.Before(sorted[j].Date)
+		}
+		return sorted[i].Type != types.TransactionSell && sorted[j].Type == types.TransactionSell
+	})
+
+	holdings := make(map[string]*holding)
+	for _, tx := range sorted {
+		h, exists := holdings[tx.Ticker]
+		if !exists {
+			h = &holding{position: types.Position{Ticker: tx.Ticker}}
+			holdings[tx.Ticker] = h
+		}
+		fee := tx.Fee * tx.FxRate
+		h.position.Fees += fee
+
+		switch tx.Type {
+		case types.TransactionBuy:
+			cost := tx.Quantity*tx.Price*tx.FxRate + fee
+			h.position.Quantity += tx.Quantity
+			h.position.CostBasis += cost
+			h.lots = append(h.lots, lot{quantity: tx.Quantity, cost: cost})
+
+		case types.TransactionSell:
+			if tx.Quantity > h.position.Quantity+epsilon {
+				return nil, fmt.Errorf("%w: %s sells %g shares on %s, holding %g",
+					ErrOversold, tx.Ticker, tx.Quantity, tx.Date.Format("2006-01-02"), h.position.Quantity)
+			}
+			basis := h.sell(tx.Quantity, method)
+			h.position.RealizedPnL += tx.Quantity*tx.Price*tx.FxRate - fee - basis
+
+		case types.TransactionDividend:
+			h.position.Dividends += tx.Amount*tx.FxRate - fee
+
+		default:
+			return nil, fmt.Errorf("invalid transaction type '%s'", tx.Type)
+		}
+	}
+
+	positions := make([]types.Position, 0, len(holdings))
+	for _, h := range holdings {
+		if h.position.Quantity > 0 {
+			h.position.AverageCost = h.position.CostBasis / h.position.Quantity
+		}
+		positions = append(positions, h.position)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].Ticker < positions[j].Ticker
+	})
+	return positions, nil
+}
+
+// sell removes shares from the holding and returns their cost basis
+func (h *holding) sell(quantity float64, method string) float64 {
+	var basis float64
+	if method == types.CostBasisAverage {
+		basis = h.position.CostBasis * math.Min(quantity/h.position.Quantity, 1)
+	} else {
+		remaining := quantity
+		for remaining > epsilon && len(h.lots) > 0 {
+			oldest := &h.lots[0]
+			take := math.Min(remaining, oldest.quantity)
+			cost := oldest.cost * take / oldest.quantity
+			basis += cost
+			oldest.cost -= cost
+			oldest.quantity -= take
+			remaining -= take
+			if oldest.quantity <= epsilon {
+				h.lots = h.lots[1:]
+			}
+		}
+	}
+
+	h.position.Quantity -= quantity
+	h.position.CostBasis -= basis
+	if h.position.Quantity <= epsilon {
+		h.position.Quantity = 0
+		h.position.CostBasis = 0
+		h.lots = nil
+	}
+	return basis
+}
+
+// Value prices the open positions with the given quotes and sums up the portfolio.
+// Positions without a quote are listed as unpriced and left out of market value and unrealized P&L.
+func Value(positions []types.Position, quotes map[string]Quote) types.PortfolioValuation {
+	valuation := types.PortfolioValuation{
+		Positions: positions,
+		Unpriced:  []string{},
+		ValuedAt:  time.Now(),
+	}
+
+	for i := range valuation.Positions {
+		p := &valuation.Positions[i]
+		valuation.RealizedPnL += p.RealizedPnL
+		valuation.Dividends += p.Dividends
+		valuation.Fees += p.Fees
+		if p.Quantity == 0 {
+			continue
+		}
+		valuation.Invested += p.CostBasis
+
+		quote, exists := quotes[p.Ticker]
+		if !exists {
+			valuation.Unpriced = append(valuation.Unpriced, p.Ticker)
+			continue
+		}
+		marketValue := p.Quantity * quote.Price
+		unrealized := marketValue - p.CostBasis
+		p.Price = &quote.Price
+		p.PriceTime = quote.Time
+		p.MarketValue = &marketValue
+		p.UnrealizedPnL = &unrealized
+		if p.CostBasis > 0 {
+			pct := unrealized / p.CostBasis * 100
+			p.UnrealizedPct = &pct
+		}
+		valuation.MarketValue += marketValue
+		valuation.UnrealizedPnL += unrealized
+	}
+
+	valuation.TotalPnL = valuation.RealizedPnL + valuation.UnrealizedPnL + valuation.Dividends
+	return valuation
+}
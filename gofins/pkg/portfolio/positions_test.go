@@ -0,0 +1,153 @@
+package portfolio
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+func day(d int) time.Time {
+	return time.Date(2024, time.January, d, 0, 0, 0, 0, time.UTC)
+}
+
+func trade(txType, ticker string, d int, quantity, price, fee float64) types.Transaction {
+	return types.Transaction{Ticker: ticker, Type: txType, Date: day(d), Quantity: quantity, Price: price, Fee: fee, Currency: "USD", FxRate: 1}
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// Two buys at different prices, then a partial sell: FIFO sells the cheap lot, average cost the mix
+func lotTransactions() []types.Transaction {
+	return []types.Transaction{
+		trade(types.TransactionSell, "AAA", 20, 10, 30, 1),
+		trade(types.TransactionBuy, "AAA", 1, 10, 10, 2),
+		trade(types.TransactionBuy, "AAA", 10, 10, 20, 0),
+	}
+}
+
+func TestPositionsFIFO(t *testing.T) {
+	positions, err := Positions(lotTransactions(), types.CostBasisFIFO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := positions[0]
+	// Sold the first lot (100 + 2 fee) for 300 - 1 fee
+	if !approx(p.RealizedPnL, 197) {
+		t.Errorf("realized P&L = %v, want 197", p.RealizedPnL)
+	}
+	if !approx(p.Quantity, 10) || !approx(p.CostBasis, 200) || !approx(p.AverageCost, 20) {
+		t.Errorf("open position = %v shares at %v (avg %v), want 10 at 200 (avg 20)", p.Quantity, p.CostBasis, p.AverageCost)
+	}
+	if !approx(p.Fees, 3) {
+		t.Errorf("fees = %v, want 3", p.Fees)
+	}
+}
+
+func TestPositionsAverageCost(t *testing.T) {
+	positions, err := Positions(lotTransactions(), types.CostBasisAverage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := positions[0]
+	// Average cost of 302 for 20 shares, half of it sold for 299
+	if !approx(p.RealizedPnL, 299-151) {
+		t.Errorf("realized P&L = %v, want 148", p.RealizedPnL)
+	}
+	if !approx(p.CostBasis, 151) || !approx(p.AverageCost, 15.1) {
+		t.Errorf("cost basis = %v (avg %v), want 151 (avg 15.1)", p.CostBasis, p.AverageCost)
+	}
+}
+
+func TestPositionsFxAndDividends(t *testing.T) {
+	buy := trade(types.TransactionBuy, "SAP", 1, 10, 100, 5)
+	buy.Currency, buy.FxRate = "EUR", 1.1
+	dividend := types.Transaction{Ticker: "SAP", Type: types.TransactionDividend, Date: day(15), Amount: 20, Fee: 2, Currency: "EUR", FxRate: 1.2}
+
+	positions, err := Positions([]types.Transaction{buy, dividend}, types.CostBasisFIFO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := positions[0]
+	if !approx(p.CostBasis, 1005*1.1) {
+		t.Errorf("cost basis = %v, want %v", p.CostBasis, 1005*1.1)
+	}
+	if !approx(p.Dividends, 18*1.2) {
+		t.Errorf("dividends = %v, want %v", p.Dividends, 18*1.2)
+	}
+}
+
+func TestPositionsClosedPositionKeepsRealizedPnL(t *testing.T) {
+	positions, err := Positions([]types.Transaction{
+		trade(types.TransactionBuy, "AAA", 1, 3, 10, 0),
+		trade(types.TransactionSell, "AAA", 2, 1, 12, 0),
+		trade(types.TransactionSell, "AAA", 3, 2, 9, 0),
+		trade(types.TransactionBuy, "BBB", 1, 1, 50, 0),
+	}, types.CostBasisFIFO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions) != 2 || positions[0].Ticker != "AAA" || positions[1].Ticker != "BBB" {
+		t.Fatalf("got positions %+v, want AAA and BBB", positions)
+	}
+	closed := positions[0]
+	if closed.Quantity != 0 || closed.CostBasis != 0 || closed.AverageCost != 0 {
+		t.Errorf("closed position still holds %v shares at %v", closed.Quantity, closed.CostBasis)
+	}
+	if !approx(closed.RealizedPnL, 0) {
+		t.Errorf("realized P&L = %v, want 0", closed.RealizedPnL)
+	}
+}
+
+func TestPositionsOversold(t *testing.T) {
+	_, err := Positions([]types.Transaction{
+		trade(types.TransactionBuy, "AAA", 5, 1, 10, 0),
+		trade(types.TransactionSell, "AAA", 4, 1, 10, 0),
+	}, types.CostBasisFIFO)
+	if !errors.Is(err, ErrOversold) {
+		t.Errorf("got %v, want ErrOversold for a sell before the buy", err)
+	}
+}
+
+func TestPositionsSameDayRoundTrip(t *testing.T) {
+	// The sell is listed first but happened after the buy of the same day
+	_, err := Positions([]types.Transaction{
+		trade(types.TransactionSell, "AAA", 5, 1, 11, 0),
+		trade(types.TransactionBuy, "AAA", 5, 1, 10, 0),
+	}, types.CostBasisAverage)
+	if err != nil {
+		t.Errorf("same-day round trip failed: %v", err)
+	}
+}
+
+func TestValue(t *testing.T) {
+	positions, err := Positions([]types.Transaction{
+		trade(types.TransactionBuy, "AAA", 1, 10, 10, 0),
+		trade(types.TransactionBuy, "BBB", 1, 5, 20, 0),
+		trade(types.TransactionBuy, "CCC", 1, 1, 5, 0),
+		trade(types.TransactionSell, "CCC", 2, 1, 8, 0),
+	}, types.CostBasisFIFO)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valuation := Value(positions, map[string]Quote{"AAA": {Price: 15}})
+	if !approx(valuation.Invested, 200) || !approx(valuation.MarketValue, 150) || !approx(valuation.UnrealizedPnL, 50) {
+		t.Errorf("invested %v, market value %v, unrealized %v, want 200, 150, 50",
+			valuation.Invested, valuation.MarketValue, valuation.UnrealizedPnL)
+	}
+	if !approx(valuation.TotalPnL, 53) {
+		t.Errorf("total P&L = %v, want 53", valuation.TotalPnL)
+	}
+	if len(valuation.Unpriced) != 1 || valuation.Unpriced[0] != "BBB" {
+		t.Errorf("unpriced = %v, want [BBB]", valuation.Unpriced)
+	}
+	aaa := valuation.Positions[0]
+	if aaa.UnrealizedPct == nil || !approx(*aaa.UnrealizedPct, 50) {
+		t.Errorf("AAA unrealized %% = %v, want 50", aaa.UnrealizedPct)
+	}
+}
@@ -0,0 +1,152 @@
+package ratelimit
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limiters registered for the metrics
+var (
+	registryMu sync.Mutex
+	registry   []*Limiter
+)
+
+func register(l *Limiter) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, l)
+}
+
+func unregister(l *Limiter) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for i, registered := range registry {
+		if registered == l {
+			registry = append(registry[:i], registry[i+1:]...)
+			return
+		}
+	}
+}
+
+type requestKey struct {
+	endpoint string
+	result   string
+}
+
+// metrics counts the requests of a limiter
+type metrics struct {
+	mu        sync.Mutex
+	requests  map[requestKey]int64
+	retries   map[string]int64
+	throttled time.Duration // Time requests waited in Wait
+}
+
+func (m *metrics) addRequest(endpoint, result string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.requests == nil {
+		m.requests = map[requestKey]int64{}
+	}
+	m.requests[requestKey{endpoint, result}]++
+}
+
+func (m *metrics) addRetry(endpoint string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.retries == nil {
+		m.retries = map[string]int64{}
+	}
+	m.retries[endpoint]++
+}
+
+func (m *metrics) addThrottled(wait time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.throttled += wait
+}
+
+// WriteMetrics writes the counters and state of all active limiters in the Prometheus text format
+func WriteMetrics(w io.Writer) error {
+	registryMu.Lock()
+	limiters := append([]*Limiter(nil), registry...)
+	registryMu.Unlock()
+	return writeMetrics(w, limiters)
+}
+
+func writeMetrics(w io.Writer, limiters []*Limiter) error {
+	var b strings.Builder
+	family := func(name, kind, help string, samples func(l *Limiter)) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, l := range limiters {
+			samples(l)
+		}
+	}
+	sample := func(name string, value float64, labels ...string) {
+		pairs := make([]string, 0, len(labels)/2)
+		for i := 0; i+1 < len(labels); i += 2 {
+			pairs = append(pairs, fmt.Sprintf("%s=%q", labels[i], labels[i+1]))
+		}
+		fmt.Fprintf(&b, "%s{%s} %g\n", name, strings.Join(pairs, ","), value)
+	}
+
+	family("gofins_ratelimit_requests_total", "counter", "Requests by endpoint and result.", func(l *Limiter) {
+		l.metrics.mu.Lock()
+		defer l.metrics.mu.Unlock()
+		keys := make([]requestKey, 0, len(l.metrics.requests))
+		for key := range l.metrics.requests {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].endpoint != keys[j].endpoint {
+				return keys[i].endpoint < keys[j].endpoint
+			}
+			return keys[i].result < keys[j].result
+		})
+		for _, key := range keys {
+			sample("gofins_ratelimit_requests_total", float64(l.metrics.requests[key]),
+				"limiter", l.cfg.Name, "endpoint", key.endpoint, "result", key.result)
+		}
+	})
+	family("gofins_ratelimit_retries_total", "counter", "Requests repeated after a failed attempt.", func(l *Limiter) {
+		l.metrics.mu.Lock()
+		defer l.metrics.mu.Unlock()
+		endpoints := make([]string, 0, len(l.metrics.retries))
+		for endpoint := range l.metrics.retries {
+			endpoints = append(endpoints, endpoint)
+		}
+		sort.Strings(endpoints)
+		for _, endpoint := range endpoints {
+			sample("gofins_ratelimit_retries_total", float64(l.metrics.retries[endpoint]),
+				"limiter", l.cfg.Name, "endpoint", endpoint)
+		}
+	})
+	family("gofins_ratelimit_throttled_seconds_total", "counter", "Time requests waited for the rate limit.", func(l *Limiter) {
+		l.metrics.mu.Lock()
+		defer l.metrics.mu.Unlock()
+		sample("gofins_ratelimit_throttled_seconds_total", l.metrics.throttled.Seconds(), "limiter", l.cfg.Name)
+	})
+	family("gofins_ratelimit_rate_per_minute", "gauge", "Current rate in request weight per minute, reduced after rate limit errors.", func(l *Limiter) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.recover(time.Now())
+		sample("gofins_ratelimit_rate_per_minute", l.rate(), "limiter", l.cfg.Name)
+	})
+	family("gofins_ratelimit_quota_used", "gauge", "Request weight used in the current UTC day and month.", func(l *Limiter) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.quota.roll(time.Now())
+		sample("gofins_ratelimit_quota_used", float64(l.quota.daily), "limiter", l.cfg.Name, "period", "daily")
+		sample("gofins_ratelimit_quota_used", float64(l.quota.monthly), "limiter", l.cfg.Name, "period", "monthly")
+	})
+	family("gofins_ratelimit_quota_limit", "gauge", "Request weight allowed per UTC day and month, 0 if unlimited.", func(l *Limiter) {
+		sample("gofins_ratelimit_quota_limit", float64(l.cfg.DailyQuota), "limiter", l.cfg.Name, "period", "daily")
+		sample("gofins_ratelimit_quota_limit", float64(l.cfg.MonthlyQuota), "limiter", l.cfg.Name, "period", "monthly")
+	})
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,197 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SyncInterval is how often the quota usage is stored and reloaded
+const SyncInterval = 30 * time.Second
+
+// QuotaStore persists the weight used per UTC day, so quotas hold across restarts and
+// are shared by all processes using the same limiter name
+type QuotaStore interface {
+	// AddUsage adds to the weight and requests used by a limiter on a day
+	AddUsage(ctx context.Context, limiter string, day time.Time, weight, requests int64) error
+	// GetUsage returns the weight used by a limiter on a day and in its month up to the day
+	GetUsage(ctx context.Context, limiter string, day time.Time) (daily, monthly int64, err error)
+}
+
+// QuotaError is returned by Wait when a request would exceed a quota
+type QuotaError struct {
+	Period  string // daily or monthly
+	Limit   int64
+	ResetAt time.Time
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s quota of %d exceeded until %s", e.Period, e.Limit, e.ResetAt.Format(time.RFC3339))
+}
+
+func IsQuotaError(err error) bool {
+	var quotaErr *QuotaError
+	return errors.As(err, &quotaErr)
+}
+
+type usage struct {
+	weight   int64
+	requests int64
+}
+
+// quotaState counts the weight used in the current UTC day and month
+type quotaState struct {
+	day     time.Time
+	daily   int64
+	monthly int64
+	pending map[time.Time]usage // Not stored yet, by day
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// roll starts counting a new day and month when the date changed
+func (q *quotaState) roll(now time.Time) {
+	day := startOfDay(now)
+	if day.Equal(q.day) {
+		return
+	}
+	if day.Year() != q.day.Year() || day.Month() != q.day.Month() {
+		q.monthly = 0
+	}
+	q.day, q.daily = day, 0
+}
+
+// reserve counts the weight of a request, unless it would exceed a quota
+func (q *quotaState) reserve(now time.Time, weight int64, cfg Config) error {
+	q.roll(now)
+	if cfg.DailyQuota > 0 && q.daily+weight > cfg.DailyQuota {
+		return &QuotaError{Period: "daily", Limit: cfg.DailyQuota, ResetAt: q.day.AddDate(0, 0, 1)}
+	}
+	if cfg.MonthlyQuota > 0 && q.monthly+weight > cfg.MonthlyQuota {
+		nextMonth := time.Date(q.day.Year(), q.day.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		return &QuotaError{Period: "monthly", Limit: cfg.MonthlyQuota, ResetAt: nextMonth}
+	}
+
+	q.daily += weight
+	q.monthly += weight
+	if q.pending == nil {
+		q.pending = map[time.Time]usage{}
+	}
+	pending := q.pending[q.day]
+	pending.weight += weight
+	pending.requests++
+	q.pending[q.day] = pending
+	return nil
+}
+
+// refund takes back the weight of a request the provider rejected, so its retry doesn't count twice
+func (q *quotaState) refund(now time.Time, weight int64) {
+	q.roll(now)
+	q.daily = max(0, q.daily-weight)
+	q.monthly = max(0, q.monthly-weight)
+	if q.pending == nil {
+		q.pending = map[time.Time]usage{}
+	}
+	pending := q.pending[q.day]
+	pending.weight -= weight
+	pending.requests--
+	q.pending[q.day] = pending
+}
+
+// restore returns usage that couldn't be stored to the pending usage
+func (q *quotaState) restore(unsaved map[time.Time]usage) {
+	if q.pending == nil {
+		q.pending = map[time.Time]usage{}
+	}
+	for day, u := range unsaved {
+		pending := q.pending[day]
+		pending.weight += u.weight
+		pending.requests += u.requests
+		q.pending[day] = pending
+	}
+}
+
+// loadQuota loads the stored usage before the first request
+func (l *Limiter) loadQuota() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := l.syncQuota(ctx); err != nil {
+		logger.Printf("Failed to load quota usage of %s: %v\n", l.cfg.Name, err)
+	}
+}
+
+// persist syncs the quota usage with the store until the limiter is shut down
+func (l *Limiter) persist() {
+	defer close(l.persisted)
+
+	ticker := time.NewTicker(SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := l.syncQuota(ctx); err != nil {
+				logger.Printf("Failed to sync quota usage of %s: %v\n", l.cfg.Name, err)
+			}
+			cancel()
+		case <-l.shutdownChan:
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := l.syncQuota(ctx); err != nil {
+				logger.Printf("Failed to store quota usage of %s: %v\n", l.cfg.Name, err)
+			}
+			cancel()
+			return
+		}
+	}
+}
+
+// syncQuota stores the pending usage and reloads the usage of the day and month, which
+// includes the requests of other processes sharing the quota
+func (l *Limiter) syncQuota(ctx context.Context) error {
+	l.syncMu.Lock()
+	defer l.syncMu.Unlock()
+
+	l.mu.Lock()
+	pending := l.quota.pending
+	l.quota.pending = nil
+	l.mu.Unlock()
+
+	days := make([]time.Time, 0, len(pending))
+	for day := range pending {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	for _, day := range days {
+		u := pending[day]
+		if err := l.cfg.Store.AddUsage(ctx, l.cfg.Name, day, u.weight, u.requests); err != nil {
+			l.mu.Lock()
+			l.quota.restore(pending)
+			l.mu.Unlock()
+			return err
+		}
+		delete(pending, day)
+	}
+
+	day := startOfDay(time.Now())
+	daily, monthly, err := l.cfg.Store.GetUsage(ctx, l.cfg.Name, day)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if day.Before(l.quota.day) {
+		return nil // Midnight passed during the sync
+	}
+	l.quota.roll(day)
+	// Requests reserved during the sync aren't stored yet
+	unsaved := l.quota.pending[day].weight
+	l.quota.daily = daily + unsaved
+	l.quota.monthly = monthly + unsaved
+	return nil
+}
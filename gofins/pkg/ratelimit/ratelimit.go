@@ -1,19 +1,68 @@
 package ratelimit
 
 import (
+	"math"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/flocko-motion/gofins/pkg/log"
+)
+
+var logger = log.New("RATELIM")
+
+const (
+	DefaultPause     = 30 * time.Second // Pause after a rate limit error without Retry-After
+	RecoveryInterval = time.Minute      // The rate doubles after each interval without rate limit errors
+	MinRateFactor    = 1.0 / 16         // Rate limit errors never slow down below this share of the rate
+	LogSize          = 1000             // Requests kept in the request log
+	safetyMargin     = 0.95             // Stay 5% below the configured rate
 )
 
-// Limiter implements a token bucket rate limiter with thread-safe operations
+// Results of requests, as recorded in the request log and the metrics
+const (
+	ResultOK            = "ok"
+	ResultRateLimited   = "rate_limited"
+	ResultBadRequest    = "bad_request"
+	ResultError         = "error"
+	ResultQuotaExceeded = "quota_exceeded" // Refused by the limiter, never sent
+)
+
+// Config configures a Limiter created with NewLimiter
+type Config struct {
+	Name              string         // Label of the metrics and key of the persisted quota usage, e.g. "fmp"
+	RequestsPerMinute int            // Sustained rate, in request weight per minute
+	Burst             int            // Weight that can be spent at once after idling, default: one second of the rate
+	Weights           map[string]int // Weight of the requests to an endpoint or endpoint prefix, default 1
+	DailyQuota        int64          // Weight allowed per UTC day, 0 = unlimited
+	MonthlyQuota      int64          // Weight allowed per UTC month, 0 = unlimited
+	Store             QuotaStore     // Persists the quota usage, nil keeps it in memory
+	Pause             time.Duration  // Pause after a rate limit error without Retry-After, default: DefaultPause
+}
+
+// Limiter is a token bucket rate limiter with weighted requests, quotas and an adaptive rate.
+// Requests take their weight from the bucket, which refills at the configured rate up to the
+// burst size. A rate limit error of the provider pauses all requests and halves the rate, which
+// doubles again after each RecoveryInterval without further rate limit errors.
 type Limiter struct {
-	interval     time.Duration
-	lastRequest  time.Time
+	cfg Config
+
 	mu           sync.Mutex
-	requestLog   []RequestLog
-	logMu        sync.Mutex
+	tokens       float64   // Negative while reserved requests wait for their weight
+	last         time.Time // Time the bucket was last refilled
+	factor       float64   // Share of the configured rate in use, reduced by rate limit errors
+	adjusted     time.Time // Time the factor last changed
+	pausedUntil  time.Time
+	quota        quotaState
 	shutdownChan chan struct{}
 	isShutdown   bool
+
+	loadOnce  sync.Once
+	syncMu    sync.Mutex    // Serializes quota syncs with the store
+	persisted chan struct{} // Closed when the persist loop has stored the final usage
+
+	requestLog requestLog
+	metrics    metrics
 }
 
 // RequestLog tracks each API request for debugging
@@ -23,109 +72,216 @@ type RequestLog struct {
 	Result    string
 }
 
-// NewLimiter creates a new rate limiter with specified requests per minute
-func NewLimiter(requestsPerMinute int) *Limiter {
-	// Add 2% buffer for safety
-	interval := time.Duration(float64(time.Minute) / float64(requestsPerMinute) * 1.05)
+// NewLimiter creates a rate limiter and registers it for the metrics until it is shut down
+func NewLimiter(cfg Config) *Limiter {
+	if cfg.RequestsPerMinute <= 0 {
+		cfg.RequestsPerMinute = 60
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = max(1, cfg.RequestsPerMinute/60)
+	}
+	if cfg.Pause <= 0 {
+		cfg.Pause = DefaultPause
+	}
 
-	return &Limiter{
-		interval:     interval,
-		lastRequest:  time.Time{},
-		requestLog:   make([]RequestLog, 0),
+	l := &Limiter{
+		cfg:          cfg,
+		tokens:       float64(cfg.Burst),
+		factor:       1,
 		shutdownChan: make(chan struct{}),
 	}
+	if cfg.Store != nil {
+		l.persisted = make(chan struct{})
+		go l.persist()
+	}
+	register(l)
+	return l
 }
 
-// Wait blocks until the rate limit allows the next request
-// Releases lock during sleep to allow other goroutines to calculate their wait time
-func (l *Limiter) Wait() error {
-	l.mu.Lock()
+// Weight returns the weight of a request to the endpoint: the weight of the longest matching
+// prefix in the config, 1 if none matches
+func (l *Limiter) Weight(endpoint string) int {
+	weight, matched := 1, -1
+	for prefix, w := range l.cfg.Weights {
+		if strings.HasPrefix(endpoint, prefix) && len(prefix) > matched {
+			weight, matched = w, len(prefix)
+		}
+	}
+	return max(weight, 1)
+}
 
+// Wait blocks until the rate limit allows a request to the endpoint. Returns a *QuotaError
+// without waiting if the request would exceed a quota, and ErrShutdown once shut down.
+// The slot is reserved before sleeping, so concurrent callers queue up without holding the lock.
+func (l *Limiter) Wait(endpoint string) error {
+	if l.cfg.Store != nil {
+		l.loadOnce.Do(l.loadQuota)
+	}
+	weight := l.Weight(endpoint)
+
+	l.mu.Lock()
 	if l.isShutdown {
 		l.mu.Unlock()
 		return ErrShutdown
 	}
+	wait, err := l.reserve(time.Now(), weight)
+	l.mu.Unlock()
 
-	now := time.Now()
-	elapsed := now.Sub(l.lastRequest)
+	if err != nil {
+		l.Record(endpoint, ResultQuotaExceeded)
+		return err
+	}
+	if wait <= 0 {
+		return nil
+	}
+	l.metrics.addThrottled(wait)
 
-	var sleepDuration time.Duration
-	if elapsed < l.interval {
-		sleepDuration = l.interval - elapsed
-		// Reserve this slot by updating timestamp before releasing lock
-		l.lastRequest = now.Add(sleepDuration)
-	} else {
-		l.lastRequest = now
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-l.shutdownChan:
+		return ErrShutdown
 	}
+}
 
-	l.mu.Unlock()
+// reserve takes the weight of a request from the bucket and returns how long the request has to
+// wait for it. Must be called with the lock held.
+func (l *Limiter) reserve(now time.Time, weight int) (time.Duration, error) {
+	l.recover(now)
+	if err := l.quota.reserve(now, int64(weight), l.cfg); err != nil {
+		return 0, err
+	}
 
-	// Sleep outside the lock to allow parallel processing
-	if sleepDuration > 0 {
-		time.Sleep(sleepDuration)
+	start := now
+	if l.pausedUntil.After(start) {
+		start = l.pausedUntil
 	}
+	rate := l.rate() / 60
+	if start.After(l.last) {
+		l.tokens = math.Min(float64(l.cfg.Burst), l.tokens+start.Sub(l.last).Seconds()*rate)
+		l.last = start
+	}
+	l.tokens -= float64(weight)
 
-	return nil
+	wait := start.Sub(now)
+	if l.tokens < 0 {
+		wait += time.Duration(-l.tokens / rate * float64(time.Second))
+	}
+	return wait, nil
 }
 
-// RecoverFromLimit waits for recovery period (60 seconds) after hitting rate limit
-func (l *Limiter) RecoverFromLimit() error {
-	const recoveryTime = 60 * time.Second
+// rate returns the current rate in weight per minute. Must be called with the lock held.
+func (l *Limiter) rate() float64 {
+	return float64(l.cfg.RequestsPerMinute) * l.factor * safetyMargin
+}
 
-	ticker := time.NewTicker(time.Second)
-	defer ticker.Stop()
+// recover doubles the rate for each RecoveryInterval since the last adjustment, up to the
+// configured rate. Must be called with the lock held.
+func (l *Limiter) recover(now time.Time) {
+	for l.factor < 1 && now.Sub(l.adjusted) >= RecoveryInterval {
+		l.factor = math.Min(1, l.factor*2)
+		l.adjusted = l.adjusted.Add(RecoveryInterval)
+	}
+}
 
-	remaining := int(recoveryTime.Seconds())
+// Throttled reports a rate limit error of the provider: requests pause for retryAfter
+// (the configured pause if 0) and the rate is halved. Further rate limit errors during the
+// pause come from requests sent before it and don't slow down the rate again.
+// The rejected request's weight is refunded to the quota, its retry reserves it again.
+func (l *Limiter) Throttled(endpoint string, retryAfter time.Duration) {
+	l.Record(endpoint, ResultRateLimited)
+	weight := l.Weight(endpoint)
 
-	for remaining > 0 {
-		select {
-		case <-ticker.C:
-			remaining--
-			// Could add callback here for progress updates
-		case <-l.shutdownChan:
-			return ErrShutdown
-		}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.quota.refund(now, int64(weight))
+	if l.throttle(now, retryAfter) {
+		logger.Printf("Rate limit hit on %s - pausing until %s at %.0f requests/min\n",
+			endpoint, l.pausedUntil.Format("15:04:05"), l.rate())
 	}
-
-	return nil
 }
 
-// LogRequest records an API request for debugging
-func (l *Limiter) LogRequest(endpoint, result string) {
-	l.logMu.Lock()
-	defer l.logMu.Unlock()
+// throttle pauses and slows down the limiter, returns false if it is already paused.
+// Must be called with the lock held.
+func (l *Limiter) throttle(now time.Time, retryAfter time.Duration) bool {
+	if now.Before(l.pausedUntil) {
+		return false
+	}
+	if retryAfter <= 0 {
+		retryAfter = l.cfg.Pause
+	}
+	l.pausedUntil = now.Add(retryAfter)
+	l.factor = math.Max(MinRateFactor, l.factor/2)
+	l.adjusted = l.pausedUntil
+	// No burst after the pause, reserved requests keep their debt
+	l.tokens = math.Min(l.tokens, 0)
+	l.last = l.pausedUntil
+	return true
+}
 
-	l.requestLog = append(l.requestLog, RequestLog{
-		Timestamp: time.Now(),
-		Endpoint:  endpoint,
-		Result:    result,
-	})
+// Record logs the result of a request and counts it in the metrics
+func (l *Limiter) Record(endpoint, result string) {
+	l.requestLog.add(RequestLog{Timestamp: time.Now(), Endpoint: endpoint, Result: result})
+	l.metrics.addRequest(endpoint, result)
+}
 
-	// Keep only last 1000 entries to prevent unbounded growth
-	if len(l.requestLog) > 1000 {
-		l.requestLog = l.requestLog[len(l.requestLog)-1000:]
-	}
+// RecordRetry counts a request repeated after a failed attempt
+func (l *Limiter) RecordRetry(endpoint string) {
+	l.metrics.addRetry(endpoint)
 }
 
-// GetLog returns a copy of the request log
+// GetLog returns a copy of the last LogSize requests, oldest first
 func (l *Limiter) GetLog() []RequestLog {
-	l.logMu.Lock()
-	defer l.logMu.Unlock()
-
-	logCopy := make([]RequestLog, len(l.requestLog))
-	copy(logCopy, l.requestLog)
-	return logCopy
+	return l.requestLog.list()
 }
 
-// Shutdown signals the limiter to stop accepting new requests
+// Shutdown signals the limiter to stop accepting new requests, stores the pending quota usage
+// and unregisters the limiter from the metrics
 func (l *Limiter) Shutdown() {
 	l.mu.Lock()
-	defer l.mu.Unlock()
+	if l.isShutdown {
+		l.mu.Unlock()
+		return
+	}
+	l.isShutdown = true
+	close(l.shutdownChan)
+	l.mu.Unlock()
 
-	if !l.isShutdown {
-		l.isShutdown = true
-		close(l.shutdownChan)
+	if l.persisted != nil {
+		<-l.persisted
 	}
+	unregister(l)
+}
+
+// requestLog is a ring buffer of the last LogSize requests
+type requestLog struct {
+	mu      sync.Mutex
+	entries []RequestLog
+	next    int // Index of the oldest entry once the buffer is full
+}
+
+func (r *requestLog) add(entry RequestLog) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.entries) < LogSize {
+		r.entries = append(r.entries, entry)
+		return
+	}
+	r.entries[r.next] = entry
+	r.next = (r.next + 1) % LogSize
+}
+
+func (r *requestLog) list() []RequestLog {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := make([]RequestLog, 0, len(r.entries))
+	entries = append(entries, r.entries[r.next:]...)
+	return append(entries, r.entries[:r.next]...)
 }
 
 // Error types
@@ -0,0 +1,220 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var t0 = time.Date(2024, 3, 31, 23, 59, 0, 0, time.UTC)
+
+// newTestLimiter returns an unregistered limiter for driving reserve and throttle with fake times
+func newTestLimiter(cfg Config) *Limiter {
+	l := NewLimiter(cfg)
+	unregister(l)
+	return l
+}
+
+func TestReserveBurstAndRate(t *testing.T) {
+	// 570 weight per minute after the safety margin: one request every 105ms
+	l := newTestLimiter(Config{RequestsPerMinute: 600, Burst: 3})
+	perRequest := time.Minute / 570
+
+	for i := 0; i < 3; i++ {
+		wait, err := l.reserve(t0, 1)
+		require.NoError(t, err)
+		assert.Zero(t, wait, "request %d is in the burst", i)
+	}
+	wait, _ := l.reserve(t0, 1)
+	assert.InDelta(t, perRequest, wait, float64(time.Millisecond))
+	wait, _ = l.reserve(t0, 1)
+	assert.InDelta(t, 2*perRequest, wait, float64(time.Millisecond), "waiting requests queue up")
+
+	// After idling the bucket is full again, but never holds more than the burst
+	later := t0.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		wait, _ = l.reserve(later, 1)
+		assert.Zero(t, wait)
+	}
+	wait, _ = l.reserve(later, 1)
+	assert.Positive(t, wait)
+}
+
+func TestReserveWeights(t *testing.T) {
+	l := newTestLimiter(Config{
+		RequestsPerMinute: 600,
+		Burst:             10,
+		Weights:           map[string]int{"stable/eod-bulk": 100, "stable/": 2},
+	})
+	assert.Equal(t, 100, l.Weight("stable/eod-bulk"))
+	assert.Equal(t, 2, l.Weight("stable/profile"), "longest matching prefix")
+	assert.Equal(t, 1, l.Weight("api/v3/historical-price-full"))
+
+	// A request heavier than the burst waits for the missing weight
+	wait, err := l.reserve(t0, 100)
+	require.NoError(t, err)
+	assert.InDelta(t, 90*time.Minute/570, wait, float64(time.Millisecond))
+}
+
+func TestThrottleSlowsDownAndRecovers(t *testing.T) {
+	l := newTestLimiter(Config{RequestsPerMinute: 600, Burst: 5})
+	_, _ = l.reserve(t0, 1)
+
+	require.True(t, l.throttle(t0, 10*time.Second))
+	assert.Equal(t, 0.5, l.factor)
+	assert.False(t, l.throttle(t0.Add(5*time.Second), 0), "errors during the pause don't slow down again")
+	assert.Equal(t, 0.5, l.factor)
+
+	// Requests wait for the pause, without a burst afterwards
+	wait, _ := l.reserve(t0, 1)
+	assert.InDelta(t, 10*time.Second+time.Minute/285, wait, float64(time.Millisecond))
+
+	// The next error after the pause halves the rate again, its pause defaults to the configured one
+	pausedUntil := l.pausedUntil
+	require.True(t, l.throttle(pausedUntil, 0))
+	assert.Equal(t, 0.25, l.factor)
+	assert.Equal(t, pausedUntil.Add(DefaultPause), l.pausedUntil)
+
+	// The rate doubles each minute without errors after the pause
+	l.recover(l.pausedUntil.Add(59 * time.Second))
+	assert.Equal(t, 0.25, l.factor)
+	l.recover(l.pausedUntil.Add(61 * time.Second))
+	assert.Equal(t, 0.5, l.factor)
+	l.recover(l.pausedUntil.Add(time.Hour))
+	assert.Equal(t, 1.0, l.factor)
+
+	// Never slower than MinRateFactor
+	for i := 0; i < 10; i++ {
+		l.throttle(l.pausedUntil, time.Second)
+	}
+	assert.Equal(t, MinRateFactor, l.factor)
+}
+
+func TestQuota(t *testing.T) {
+	l := newTestLimiter(Config{RequestsPerMinute: 6000, Burst: 1000, DailyQuota: 10, MonthlyQuota: 15})
+
+	_, err := l.reserve(t0, 8)
+	require.NoError(t, err)
+	_, err = l.reserve(t0, 3)
+	var quotaErr *QuotaError
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, "daily", quotaErr.Period)
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), quotaErr.ResetAt)
+	_, err = l.reserve(t0, 2)
+	require.NoError(t, err, "the remaining weight can still be used")
+
+	// A new day resets the daily quota, a new month both
+	_, err = l.reserve(t0.Add(time.Minute), 10)
+	require.NoError(t, err, "April 1st")
+	_, err = l.reserve(t0.Add(25*time.Hour), 6)
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, "monthly", quotaErr.Period)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), quotaErr.ResetAt)
+	assert.True(t, IsQuotaError(fmt.Errorf("wrapped: %w", err)))
+
+	assert.Equal(t, map[time.Time]usage{
+		startOfDay(t0):                {weight: 10, requests: 2},
+		startOfDay(t0.Add(time.Hour)): {weight: 10, requests: 1},
+	}, l.quota.pending)
+}
+
+func TestThrottledRefundsQuota(t *testing.T) {
+	l := newTestLimiter(Config{RequestsPerMinute: 60000, DailyQuota: 150, Weights: map[string]int{"bulk": 100}})
+	today := startOfDay(time.Now())
+
+	require.NoError(t, l.Wait("bulk"))
+	l.Throttled("bulk", time.Millisecond)
+	assert.Zero(t, l.quota.daily, "the rejected request doesn't count against the quota")
+	assert.Equal(t, usage{}, l.quota.pending[today])
+
+	require.NoError(t, l.Wait("bulk"), "the retry fits into the quota")
+	assert.EqualValues(t, 100, l.quota.daily)
+	assert.True(t, IsQuotaError(l.Wait("bulk")))
+}
+
+type memStore struct {
+	usage map[time.Time]int64
+	fail  bool
+}
+
+func (m *memStore) AddUsage(ctx context.Context, limiter string, day time.Time, weight, requests int64) error {
+	if m.fail {
+		return errors.New("database down")
+	}
+	m.usage[day] += weight
+	return nil
+}
+
+func (m *memStore) GetUsage(ctx context.Context, limiter string, day time.Time) (daily, monthly int64, err error) {
+	for d, weight := range m.usage {
+		if d.Year() == day.Year() && d.Month() == day.Month() && !d.After(day) {
+			monthly += weight
+		}
+	}
+	return m.usage[day], monthly, nil
+}
+
+func TestSyncQuota(t *testing.T) {
+	today := startOfDay(time.Now())
+	store := &memStore{usage: map[time.Time]int64{today: 40}} // Used by another process
+	l := newTestLimiter(Config{RequestsPerMinute: 6000, DailyQuota: 50, Store: store})
+	defer l.Shutdown()
+
+	require.NoError(t, l.Wait("a"))
+	assert.EqualValues(t, 41, l.quota.daily, "the stored usage is loaded before the first request")
+	require.NoError(t, l.syncQuota(context.Background()))
+	assert.EqualValues(t, 41, store.usage[today])
+
+	// Usage that can't be stored is kept for the next sync
+	store.fail = true
+	require.NoError(t, l.Wait("a"))
+	require.Error(t, l.syncQuota(context.Background()))
+	assert.EqualValues(t, 1, l.quota.pending[today].weight)
+
+	store.fail = false
+	store.usage[today] += 8
+	require.NoError(t, l.syncQuota(context.Background()))
+	assert.EqualValues(t, 50, l.quota.daily)
+	assert.True(t, IsQuotaError(l.Wait("a")))
+}
+
+func TestRequestLog(t *testing.T) {
+	l := newTestLimiter(Config{})
+	for i := 0; i < LogSize+5; i++ {
+		l.Record(fmt.Sprintf("e%d", i), ResultOK)
+	}
+	entries := l.GetLog()
+	require.Len(t, entries, LogSize)
+	assert.Equal(t, "e5", entries[0].Endpoint)
+	assert.Equal(t, fmt.Sprintf("e%d", LogSize+4), entries[LogSize-1].Endpoint)
+}
+
+func TestWriteMetrics(t *testing.T) {
+	l := newTestLimiter(Config{Name: "fmp", RequestsPerMinute: 600, DailyQuota: 1000})
+	l.Record("stable/profile", ResultOK)
+	l.Record("stable/profile", ResultOK)
+	l.Throttled("stable/profile", time.Second)
+	l.RecordRetry("stable/profile")
+	l.metrics.addThrottled(1500 * time.Millisecond)
+
+	var b strings.Builder
+	require.NoError(t, writeMetrics(&b, []*Limiter{l}))
+	out := b.String()
+	for _, line := range []string{
+		"# TYPE gofins_ratelimit_requests_total counter",
+		`gofins_ratelimit_requests_total{limiter="fmp",endpoint="stable/profile",result="ok"} 2`,
+		`gofins_ratelimit_requests_total{limiter="fmp",endpoint="stable/profile",result="rate_limited"} 1`,
+		`gofins_ratelimit_retries_total{limiter="fmp",endpoint="stable/profile"} 1`,
+		`gofins_ratelimit_throttled_seconds_total{limiter="fmp"} 1.5`,
+		`gofins_ratelimit_rate_per_minute{limiter="fmp"} 285`,
+		`gofins_ratelimit_quota_limit{limiter="fmp",period="daily"} 1000`,
+	} {
+		assert.Contains(t, out, line+"\n")
+	}
+}
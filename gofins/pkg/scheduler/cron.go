@@ -0,0 +1,179 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // CRON_TZ zones must resolve in containers without system tzdata
+)
+
+// Schedule is a parsed cron expression with the five standard fields
+// (minute hour day-of-month month day-of-week).
+type Schedule struct {
+	minute, hour, dom, month, dow uint64 // Bit n is set if value n matches
+	domAny, dowAny                bool   // Field starts with '*', relevant for the day matching rule
+	loc                           *time.Location
+}
+
+// cronMacros are the supported shorthands for common schedules
+var cronMacros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dayNames = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+
+// maxSearchYears bounds the search for the next match of expressions that never match, e.g. "0 0 30 2 *"
+const maxSearchYears = 5
+
+// ParseSchedule parses a cron expression like "30 6 * * 1-5". Fields accept '*', values, ranges,
+// steps ("*/15", "1-5/2") and comma separated lists; months and weekdays also accept names
+// (JAN, MON), Sunday is 0 or 7. The macros @hourly, @daily, @weekly, @monthly and @yearly are
+// supported. Expressions are evaluated in UTC unless prefixed with "CRON_TZ=<zone> ".
+func ParseSchedule(expr string) (*Schedule, error) {
+	s := &Schedule{loc: time.UTC}
+	fields := strings.Fields(expr)
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "CRON_TZ=") {
+		loc, err := time.LoadLocation(strings.TrimPrefix(fields[0], "CRON_TZ="))
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone: %w", err)
+		}
+		s.loc = loc
+		fields = fields[1:]
+	}
+	if len(fields) == 1 && strings.HasPrefix(fields[0], "@") {
+		macro, ok := cronMacros[strings.ToLower(fields[0])]
+		if !ok {
+			return nil, fmt.Errorf("unknown macro '%s'", fields[0])
+		}
+		fields = strings.Fields(macro)
+	}
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields (minute hour day month weekday), got %d", len(fields))
+	}
+
+	var err error
+	if s.minute, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if s.hour, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if s.dom, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	if s.month, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	if s.dow, err = parseField(fields[4], 0, 7, dayNames); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+	// 7 is an alias for Sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAny = strings.HasPrefix(fields[2], "*")
+	s.dowAny = strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+// parseField parses one field of an expression into a bit set of matching values
+func parseField(field string, min, max int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step '%s'", stepPart)
+			}
+		}
+
+		low, high := min, max
+		if rangePart != "*" {
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = parseValue(lowPart, min, max, names); err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				if high, err = parseValue(highPart, min, max, names); err != nil {
+					return 0, err
+				}
+				if high < low {
+					return 0, fmt.Errorf("invalid range '%s'", rangePart)
+				}
+			} else if hasStep {
+				// "5/15" means every 15 starting at 5
+				high = max
+			}
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, min, max int, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < min || v > max {
+		return 0, fmt.Errorf("invalid value '%s' (must be %d-%d)", s, min, max)
+	}
+	return v, nil
+}
+
+// Next returns the first time after t that matches the schedule, in t's location.
+// Returns the zero time if the expression never matches.
+func (s *Schedule) Next(t time.Time) time.Time {
+	origLoc := t.Location()
+	t = t.In(s.loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Truncate(time.Minute).Add(time.Minute)
+			continue
+		}
+		return t.In(origLoc)
+	}
+	return time.Time{}
+}
+
+// dayMatches applies the cron rule for days: if both day of month and day of week are
+// restricted, either may match; otherwise the restricted one has to.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
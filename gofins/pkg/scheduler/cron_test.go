@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// Wednesday
+	from := time.Date(2024, 3, 6, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 3, 6, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 3, 6, 10, 30, 0, 0, time.UTC)},
+		{"0 6 * * *", time.Date(2024, 3, 7, 6, 0, 0, 0, time.UTC)},
+		{"30 22 * * 1-5", time.Date(2024, 3, 6, 22, 30, 0, 0, time.UTC)},
+		{"0 3 * * SUN", time.Date(2024, 3, 10, 3, 0, 0, 0, time.UTC)},
+		{"0 3 * * 7", time.Date(2024, 3, 10, 3, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1,15 JAN-JUN *", time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)},
+		// Day of month and weekday restricted: either matches
+		{"0 0 20 * MON", time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)},
+		// 16:30 New York is 20:30 UTC during daylight saving time (from March 10, 2024)
+		{"CRON_TZ=America/New_York 30 16 * * MON-FRI", time.Date(2024, 3, 6, 21, 30, 0, 0, time.UTC)},
+		{"CRON_TZ=America/New_York 30 16 * * MON", time.Date(2024, 3, 11, 20, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		schedule, err := ParseSchedule(tt.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.expr, err)
+		}
+		if got := schedule.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%s: got %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestScheduleNeverMatches(t *testing.T) {
+	schedule, err := ParseSchedule("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := schedule.Next(time.Now()); !got.IsZero() {
+		t.Errorf("got %s, want zero time", got)
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"@sometimes",
+		"CRON_TZ=Mars/Olympus 0 0 * * *",
+	} {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("%q: expected error", expr)
+		}
+	}
+}
@@ -0,0 +1,482 @@
+// Package scheduler runs jobs on cron schedules with dependency ordering, a persisted run history
+// and admin controls to trigger, pause or skip a job.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/events"
+	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/google/uuid"
+)
+
+const (
+	// pollInterval is how often the scheduler picks up state changed by another process (e.g. the CLI)
+	// without being notified
+	pollInterval = 10 * time.Second
+	// heartbeatInterval is how often a running run refreshes its heartbeat
+	heartbeatInterval = 10 * time.Second
+	// retryInterval is the minimum wait after an iteration that failed on the database, so a
+	// trigger that can't be cleared doesn't make the loop spin
+	retryInterval = 5 * time.Second
+	// orphanTimeout is how long a running run may go without a heartbeat before it counts as orphaned
+	orphanTimeout = time.Minute
+)
+
+var (
+	// ErrUnknownJob is returned for job names that aren't registered
+	ErrUnknownJob = errors.New("unknown job")
+	// ErrInvalidSchedule is returned for cron expressions that can't be parsed
+	ErrInvalidSchedule = errors.New("invalid schedule")
+)
+
+func logf(format string, args ...interface{}) {
+	fmt.Printf("[SCHEDULER] "+format, args...)
+}
+
+// Job is a task run by the scheduler
+type Job struct {
+	Name     string
+	Schedule string   // Default cron expression, can be overridden by an admin
+	After    []string // Jobs that run first when both are due at the same time
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler runs jobs when their schedule is due or they are triggered manually. Due jobs run one
+// at a time in dependency order; a job whose dependency failed in the same round is skipped.
+// Job state lives in the database, so admin actions from other processes take effect within pollInterval.
+// Several schedulers may share a database: each due schedule and trigger is run by only one of them.
+type Scheduler struct {
+	id       uuid.UUID // Owner of the runs of this scheduler
+	jobs     []Job     // Every job comes after the jobs it depends on
+	wake     chan struct{}
+	defaults map[string]*Schedule
+}
+
+// New validates the jobs and their dependencies and creates a scheduler for them
+func New(jobs []Job) (*Scheduler, error) {
+	ordered, err := orderJobs(jobs)
+	if err != nil {
+		return nil, err
+	}
+	defaults := make(map[string]*Schedule, len(jobs))
+	for _, job := range ordered {
+		schedule, err := ParseSchedule(job.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %w", job.Name, err)
+		}
+		defaults[job.Name] = schedule
+	}
+	return &Scheduler{
+		id:       uuid.New(),
+		jobs:     ordered,
+		wake:     make(chan struct{}, 1),
+		defaults: defaults,
+	}, nil
+}
+
+// orderJobs sorts jobs so that each comes after its dependencies, keeping the given order otherwise
+func orderJobs(jobs []Job) ([]Job, error) {
+	byName := make(map[string]Job, len(jobs))
+	for _, job := range jobs {
+		if _, exists := byName[job.Name]; exists {
+			return nil, fmt.Errorf("duplicate job %s", job.Name)
+		}
+		byName[job.Name] = job
+	}
+	for _, job := range jobs {
+		for _, dep := range job.After {
+			if _, exists := byName[dep]; !exists {
+				return nil, fmt.Errorf("job %s depends on %w %s", job.Name, ErrUnknownJob, dep)
+			}
+		}
+	}
+
+	ordered := make([]Job, 0, len(jobs))
+	placed := make(map[string]bool, len(jobs))
+	for len(ordered) < len(jobs) {
+		progress := false
+		for _, job := range jobs {
+			if placed[job.Name] {
+				continue
+			}
+			ready := true
+			for _, dep := range job.After {
+				ready = ready && placed[dep]
+			}
+			if ready {
+				ordered = append(ordered, job)
+				placed[job.Name] = true
+				progress = true
+			}
+		}
+		if !progress {
+			return nil, fmt.Errorf("jobs have a dependency cycle")
+		}
+	}
+	return ordered, nil
+}
+
+// nextRun returns when a schedule is due next, the zero time if never. A job that was never
+// scheduled is due right away; runs missed while the server was down are caught up once.
+func nextRun(schedule *Schedule, lastScheduled *time.Time, now time.Time) time.Time {
+	if lastScheduled == nil {
+		return now
+	}
+	return schedule.Next(*lastScheduled)
+}
+
+// failedDependency returns the first dependency of a job that failed in the current round
+func failedDependency(job Job, failed map[string]bool) string {
+	for _, dep := range job.After {
+		if failed[dep] {
+			return dep
+		}
+	}
+	return ""
+}
+
+// Run executes due jobs until ctx is cancelled. A running job is cancelled with ctx.
+func (s *Scheduler) Run(ctx context.Context) {
+	logf("Scheduler started with %d jobs\n", len(s.jobs))
+
+	for {
+		ok := s.failOrphans(ctx)
+		ok = s.runDue(ctx) && ok
+		jobs, err := s.Jobs(ctx)
+		wait := nextWait(jobs, err == nil && ok, time.Now())
+
+		select {
+		case <-ctx.Done():
+			logf("Scheduler stopped\n")
+			return
+		case <-s.wake:
+		case <-time.After(wait):
+		}
+	}
+}
+
+// nextWait returns how long the run loop sleeps until the next due schedule, at most pollInterval.
+// A pending trigger is picked up right away only after a successful iteration, a failed one waits
+// at least retryInterval.
+func nextWait(jobs []types.SchedulerJob, ok bool, now time.Time) time.Duration {
+	wait := pollInterval
+	for _, job := range jobs {
+		if job.TriggeredAt != nil {
+			wait = 0
+		} else if job.NextRun != nil && job.NextRun.Sub(now) < wait {
+			wait = max(job.NextRun.Sub(now), 0)
+		}
+	}
+	if !ok {
+		wait = max(wait, retryInterval)
+	}
+	return wait
+}
+
+// failOrphans marks runs as failed whose process stopped sending heartbeats, and runs of this
+// scheduler that are still recorded as running although none is (their outcome couldn't be stored).
+// Runs of other live schedulers keep running. Returns false on database errors.
+func (s *Scheduler) failOrphans(ctx context.Context) bool {
+	orphaned, err := db.FailOrphanedSchedulerRuns(ctx, "interrupted: the scheduler running it stopped", s.id, time.Now().Add(-orphanTimeout))
+	if err != nil {
+		if ctx.Err() == nil {
+			_ = db.LogError(ctx, "scheduler", "database", "Failed to clean up orphaned runs", f.Ptr(err.Error()))
+		}
+		return false
+	}
+	if orphaned > 0 {
+		logf("Marked %d interrupted runs as failed\n", orphaned)
+	}
+	return true
+}
+
+// notify wakes up the run loop without blocking
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+type dueJob struct {
+	job       Job
+	trigger   string
+	scheduled bool // This scheduler took the due schedule slot of the job
+}
+
+// runDue runs all jobs that are triggered or whose schedule is due, in dependency order.
+// Returns false if the iteration failed on the database.
+func (s *Scheduler) runDue(ctx context.Context) bool {
+	states, err := db.ListSchedulerJobStates(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			_ = db.LogError(ctx, "scheduler", "database", "Failed to load job state", f.Ptr(err.Error()))
+		}
+		return false
+	}
+
+	ok := true
+
+	now := time.Now()
+	var due []dueJob
+	for _, job := range s.jobs {
+		state := states[job.Name]
+		next := nextRun(s.schedule(job, state), state.LastScheduledAt, now)
+		scheduleDue := !state.Paused && !next.IsZero() && !next.After(now)
+		if scheduleDue {
+			// Another scheduler may have taken the slot since the state was loaded
+			taken, err := db.ConsumeSchedulerSlot(ctx, job.Name, state.LastScheduledAt, now)
+			if err != nil {
+				_ = db.LogError(ctx, "scheduler", "database", "Failed to update job state", f.Ptr(err.Error()))
+				ok = false
+				continue
+			}
+			scheduleDue = taken
+		}
+
+		switch {
+		case state.TriggeredAt != nil:
+			// A manual trigger also satisfies a schedule that is due at the same time
+			due = append(due, dueJob{job, types.RunTriggerManual, scheduleDue})
+		case scheduleDue && state.SkipNext:
+			s.recordSkipped(ctx, job.Name, types.RunTriggerSchedule, "skipped by admin")
+		case scheduleDue:
+			due = append(due, dueJob{job, types.RunTriggerSchedule, true})
+		}
+	}
+
+	failed := make(map[string]bool)
+	for _, d := range due {
+		if ctx.Err() != nil {
+			return ok
+		}
+		if d.trigger == types.RunTriggerManual {
+			cleared, err := db.ClearSchedulerJobTrigger(ctx, d.job.Name)
+			if err != nil {
+				_ = db.LogError(ctx, "scheduler", "database", "Failed to clear trigger", f.Ptr(err.Error()))
+				ok = false
+			}
+			if !cleared {
+				// Another scheduler took the trigger, or it is retried in a later iteration
+				if !d.scheduled {
+					continue
+				}
+				d.trigger = types.RunTriggerSchedule // The taken schedule slot runs anyway
+			}
+		}
+		if dep := failedDependency(d.job, failed); dep != "" {
+			s.recordSkipped(ctx, d.job.Name, d.trigger, fmt.Sprintf("dependency %s failed", dep))
+			failed[d.job.Name] = true
+			continue
+		}
+		if !s.runJob(ctx, d.job, d.trigger) {
+			failed[d.job.Name] = true
+		}
+	}
+	return ok
+}
+
+// runJob runs a job and records it in the history. Returns false if the job didn't complete.
+func (s *Scheduler) runJob(ctx context.Context, job Job, trigger string) bool {
+	run := types.SchedulerRun{
+		ID:        uuid.New(),
+		Job:       job.Name,
+		Trigger:   trigger,
+		Status:    types.RunRunning,
+		StartedAt: time.Now(),
+	}
+	if err := db.CreateSchedulerRun(ctx, run, s.id); err != nil {
+		_ = db.LogError(ctx, "scheduler", "database", "Failed to record run", f.Ptr(err.Error()))
+	}
+	logf("%s started (%s)\n", job.Name, trigger)
+	events.PublishAdmin(events.TypeUpdaterStep, run)
+
+	done := make(chan struct{})
+	go heartbeat(ctx, run.ID, done)
+	err := job.Run(ctx)
+	close(done)
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Status = types.RunCompleted
+	switch {
+	case ctx.Err() != nil:
+		run.Status = types.RunCancelled
+		run.Error = f.Ptr(ctx.Err().Error())
+	case err != nil:
+		run.Status = types.RunFailed
+		run.Error = f.Ptr(err.Error())
+	}
+	// The outcome is stored even if the job was cancelled by shutdown
+	if err := db.FinishSchedulerRun(context.WithoutCancel(ctx), run); err != nil {
+		_ = db.LogError(ctx, "scheduler", "database", "Failed to record run", f.Ptr(err.Error()))
+	}
+	logf("%s %s after %s\n", job.Name, run.Status, f.DurationToString(finishedAt.Sub(run.StartedAt)))
+	events.PublishAdmin(events.TypeUpdaterStep, run)
+	return run.Status == types.RunCompleted
+}
+
+// heartbeat refreshes the heartbeat of a run every heartbeatInterval until done is closed,
+// so other schedulers don't take the run for orphaned
+func heartbeat(ctx context.Context, runID uuid.UUID, done <-chan struct{}) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := db.TouchSchedulerRun(ctx, runID); err != nil && ctx.Err() == nil {
+				_ = db.LogError(ctx, "scheduler", "database", "Failed to refresh run heartbeat", f.Ptr(err.Error()))
+			}
+		}
+	}
+}
+
+// recordSkipped adds a run that didn't execute to the history
+func (s *Scheduler) recordSkipped(ctx context.Context, job, trigger, reason string) {
+	now := time.Now()
+	run := types.SchedulerRun{
+		ID:         uuid.New(),
+		Job:        job,
+		Trigger:    trigger,
+		Status:     types.RunSkipped,
+		Error:      &reason,
+		StartedAt:  now,
+		FinishedAt: &now,
+	}
+	if err := db.CreateSchedulerRun(ctx, run, s.id); err != nil {
+		_ = db.LogError(ctx, "scheduler", "database", "Failed to record run", f.Ptr(err.Error()))
+	}
+	logf("%s skipped: %s\n", job, reason)
+	events.PublishAdmin(events.TypeUpdaterStep, run)
+}
+
+// schedule returns the schedule in effect for a job, the admin override if it is valid
+func (s *Scheduler) schedule(job Job, state db.SchedulerJobState) *Schedule {
+	if state.Schedule != nil {
+		if schedule, err := ParseSchedule(*state.Schedule); err == nil {
+			return schedule
+		}
+	}
+	return s.defaults[job.Name]
+}
+
+// Jobs returns the state of all jobs in dependency order
+func (s *Scheduler) Jobs(ctx context.Context) ([]types.SchedulerJob, error) {
+	states, err := db.ListSchedulerJobStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lastRuns, err := db.ListLastSchedulerRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	jobs := make([]types.SchedulerJob, len(s.jobs))
+	for i, job := range s.jobs {
+		state := states[job.Name]
+		jobs[i] = types.SchedulerJob{
+			Name:            job.Name,
+			Schedule:        job.Schedule,
+			DefaultSchedule: job.Schedule,
+			After:           append([]string{}, job.After...),
+			Paused:          state.Paused,
+			SkipNext:        state.SkipNext,
+			TriggeredAt:     state.TriggeredAt,
+		}
+		if state.Schedule != nil {
+			jobs[i].Schedule = *state.Schedule
+		}
+		if next := nextRun(s.schedule(job, state), state.LastScheduledAt, now); !state.Paused && !next.IsZero() {
+			jobs[i].NextRun = &next
+		}
+		if run, exists := lastRuns[job.Name]; exists {
+			jobs[i].LastRun = &run
+		}
+	}
+	return jobs, nil
+}
+
+// Job returns the state of a job
+func (s *Scheduler) Job(ctx context.Context, name string) (*types.SchedulerJob, error) {
+	jobs, err := s.Jobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		if job.Name == name {
+			return &job, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %s", ErrUnknownJob, name)
+}
+
+// Trigger requests a run of a job as soon as the scheduler is idle, even if the job is paused
+func (s *Scheduler) Trigger(ctx context.Context, name string) error {
+	if err := s.checkJob(name); err != nil {
+		return err
+	}
+	defer s.notify()
+	return db.TriggerSchedulerJob(ctx, name)
+}
+
+// SetPaused suspends or resumes the scheduled runs of a job
+func (s *Scheduler) SetPaused(ctx context.Context, name string, paused bool) error {
+	if err := s.checkJob(name); err != nil {
+		return err
+	}
+	defer s.notify()
+	return db.SetSchedulerJobPaused(ctx, name, paused)
+}
+
+// SetSkipNext marks or unmarks the next scheduled run of a job to be skipped
+func (s *Scheduler) SetSkipNext(ctx context.Context, name string, skip bool) error {
+	if err := s.checkJob(name); err != nil {
+		return err
+	}
+	defer s.notify()
+	return db.SetSchedulerJobSkipNext(ctx, name, skip)
+}
+
+// SetSchedule overrides the cron expression of a job, an empty expression restores the default
+func (s *Scheduler) SetSchedule(ctx context.Context, name, expr string) error {
+	if err := s.checkJob(name); err != nil {
+		return err
+	}
+	var schedule *string
+	if expr != "" {
+		if _, err := ParseSchedule(expr); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+		}
+		schedule = &expr
+	}
+	defer s.notify()
+	return db.SetSchedulerJobSchedule(ctx, name, schedule)
+}
+
+// History returns the latest runs newest first, of all jobs if name is empty
+func (s *Scheduler) History(ctx context.Context, name string, limit int) ([]types.SchedulerRun, error) {
+	if name != "" {
+		if err := s.checkJob(name); err != nil {
+			return nil, err
+		}
+	}
+	return db.ListSchedulerRuns(ctx, name, limit)
+}
+
+func (s *Scheduler) checkJob(name string) error {
+	if _, exists := s.defaults[name]; !exists {
+		return fmt.Errorf("%w %s", ErrUnknownJob, name)
+	}
+	return nil
+}
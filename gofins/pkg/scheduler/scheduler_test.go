@@ -0,0 +1,113 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/types"
+)
+
+func jobNames(jobs []Job) []string {
+	names := make([]string, len(jobs))
+	for i, job := range jobs {
+		names[i] = job.Name
+	}
+	return names
+}
+
+func TestOrderJobs(t *testing.T) {
+	jobs := []Job{
+		{Name: "prices", After: []string{"quotes"}},
+		{Name: "dedupe", After: []string{"profiles"}},
+		{Name: "symbols"},
+		{Name: "quotes", After: []string{"profiles"}},
+		{Name: "profiles", After: []string{"symbols"}},
+	}
+	ordered, err := orderJobs(jobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	position := make(map[string]int)
+	for i, name := range jobNames(ordered) {
+		position[name] = i
+	}
+	for _, job := range jobs {
+		for _, dep := range job.After {
+			if position[dep] > position[job.Name] {
+				t.Errorf("%s runs before its dependency %s: %v", job.Name, dep, jobNames(ordered))
+			}
+		}
+	}
+}
+
+func TestOrderJobsErrors(t *testing.T) {
+	if _, err := orderJobs([]Job{{Name: "a", After: []string{"b"}}, {Name: "b", After: []string{"a"}}}); err == nil {
+		t.Error("expected error for dependency cycle")
+	}
+	if _, err := orderJobs([]Job{{Name: "a", After: []string{"missing"}}}); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("expected ErrUnknownJob, got %v", err)
+	}
+	if _, err := orderJobs([]Job{{Name: "a"}, {Name: "a"}}); err == nil {
+		t.Error("expected error for duplicate job")
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	schedule, err := ParseSchedule("0 6 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 3, 6, 10, 0, 0, 0, time.UTC)
+
+	// Never scheduled: due right away
+	if got := nextRun(schedule, nil, now); !got.Equal(now) {
+		t.Errorf("never scheduled: got %s, want %s", got, now)
+	}
+
+	// Ran this morning: due tomorrow
+	lastRun := time.Date(2024, 3, 6, 6, 0, 5, 0, time.UTC)
+	if got, want := nextRun(schedule, &lastRun, now), time.Date(2024, 3, 7, 6, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("ran today: got %s, want %s", got, want)
+	}
+
+	// Server was down for days: the missed run is due once, in the past
+	lastRun = time.Date(2024, 3, 1, 6, 0, 0, 0, time.UTC)
+	if got := nextRun(schedule, &lastRun, now); got.After(now) {
+		t.Errorf("missed runs: got %s, want a time before %s", got, now)
+	}
+}
+
+func TestNextWait(t *testing.T) {
+	now := time.Date(2024, 3, 6, 10, 0, 0, 0, time.UTC)
+	soon := now.Add(3 * time.Second)
+	triggered := []types.SchedulerJob{{Name: "prices", TriggeredAt: &now}, {Name: "quotes", NextRun: &soon}}
+
+	if got := nextWait(triggered, true, now); got != 0 {
+		t.Errorf("pending trigger: got %s, want 0", got)
+	}
+	// A trigger that couldn't be cleared must not make the loop spin
+	if got := nextWait(triggered, false, now); got != retryInterval {
+		t.Errorf("failed iteration: got %s, want %s", got, retryInterval)
+	}
+	if got := nextWait(triggered[1:], true, now); got != 3*time.Second {
+		t.Errorf("next schedule: got %s, want 3s", got)
+	}
+	if got := nextWait(nil, true, now); got != pollInterval {
+		t.Errorf("nothing due: got %s, want %s", got, pollInterval)
+	}
+	if got := nextWait(nil, false, now); got != pollInterval {
+		t.Errorf("failed iteration keeps the poll interval: got %s, want %s", got, pollInterval)
+	}
+}
+
+func TestFailedDependency(t *testing.T) {
+	job := Job{Name: "prices", After: []string{"profiles", "quotes"}}
+	if dep := failedDependency(job, map[string]bool{}); dep != "" {
+		t.Errorf("got %q, want no failed dependency", dep)
+	}
+	if dep := failedDependency(job, map[string]bool{"quotes": true}); dep != "quotes" {
+		t.Errorf("got %q, want quotes", dep)
+	}
+}
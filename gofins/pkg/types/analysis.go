@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,31 +9,157 @@ import (
 
 // AnalysisPackage represents stored analysis metadata
 type AnalysisPackage struct {
-	ID           string
-	Name         string
-	CreatedAt    time.Time
-	Interval     string
-	TimeFrom     time.Time
-	TimeTo       time.Time
-	HistBins     int
-	HistMin      float64
-	HistMax      float64
-	McapMin      *int64
-	InceptionMax *time.Time
-	SymbolCount  int
-	Status       string
-	UserID       uuid.UUID
+	ID             string
+	Name           string
+	CreatedAt      time.Time
+	Interval       string
+	TimeFrom       time.Time
+	TimeTo         time.Time
+	HistBins       int
+	HistMin        float64
+	HistMax        float64
+	McapMin        *int64
+	McapMax        *int64
+	InceptionMax   *time.Time
+	Filters        AnalysisFilters // Symbol selection beyond market cap and inception
+	SymbolCount    int
+	Status         string
+	UserID         uuid.UUID
+	ReferenceIndex *string // Index ticker used for beta/correlation/alpha (e.g. ^GSPC)
+	ReturnBasis    string  // "price" or "total_return" (see ReturnBasis)
+	RiskFreeRate   float64 // Annual risk-free rate in percent, used for Sharpe/Sortino
+	// RollingWindowMonths is the window of the rolling YoY statistics per result, 0 if not computed
+	RollingWindowMonths int
+	Outliers            OutlierConfig // Outlier removal applied to the YoY values of each symbol
+}
+
+// Analysis job states
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobCancelled = "cancelled"
+	JobFailed    = "failed"
+	JobReady     = "ready"
+)
+
+// AnalysisJob tracks the execution of an analysis package in the job queue.
+// The package status stays "processing" while its job is queued or running.
+type AnalysisJob struct {
+	PackageID   string         `json:"packageId"`
+	UserID      uuid.UUID      `json:"-"`
+	State       string         `json:"state"`
+	Attempts    int            `json:"attempts"`   // Number of times the job was started
+	Processed   int            `json:"processed"`  // Symbols processed so far
+	Total       int            `json:"total"`      // Symbols selected by the package filters
+	Results     int            `json:"results"`    // Symbols with results
+	Rejections  map[string]int `json:"rejections"` // Rejected symbols by reason
+	Error       *string        `json:"error"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	StartedAt   *time.Time     `json:"startedAt"`
+	FinishedAt  *time.Time     `json:"finishedAt"`
+	HeartbeatAt *time.Time     `json:"heartbeatAt"` // Last progress update of a running job
+}
+
+// Analysis universes: which symbols the filters of a package are applied to
+const (
+	UniverseAll       = "all"
+	UniverseFavorites = "favorites"
+	UniverseTickers   = "tickers"
+	UniverseWatchlist = "watchlist"
+)
+
+// OTCExchanges are excluded from new analysis packages unless exchange filters are given
+var OTCExchanges = []string{"OTC", "PINK", "GREY", "OTCQB", "OTCQX"}
+
+// AnalysisFilters select the symbols of an analysis package. Include lists keep only matching
+// symbols, exclude lists drop matching ones; empty lists don't filter. Matching ignores case.
+type AnalysisFilters struct {
+	Universe   string         `json:"universe"`            // "all" (default), "favorites", "tickers" or "watchlist"
+	Tickers    []string       `json:"tickers,omitempty"`   // Explicit universe for "tickers", the list entries at creation for "favorites" and "watchlist"
+	Watchlist  *uuid.UUID     `json:"watchlist,omitempty"` // Watchlist of the user for "watchlist"
+	Sectors    IncludeExclude `json:"sectors"`
+	Industries IncludeExclude `json:"industries"`
+	Countries  IncludeExclude `json:"countries"`
+	Exchanges  IncludeExclude `json:"exchanges"`
+	Currencies IncludeExclude `json:"currencies"`
+	Types      IncludeExclude `json:"types"`
+}
+
+// IncludeExclude is a pair of include and exclude lists for one symbol attribute
+type IncludeExclude struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
 }
 
 // AnalysisResult represents a stored analysis result
 type AnalysisResult struct {
-	PackageID     string     `json:"-"`
-	Ticker        string     `json:"symbol"`
-	Count         int        `json:"-"`
-	Mean          float64    `json:"mean"`
-	StdDev        float64    `json:"stddev"`
-	Variance      float64    `json:"-"`
-	Min           float64    `json:"min"`
-	Max           float64    `json:"max"`
-	InceptionDate *time.Time `json:"inception"`
+	PackageID     string              `json:"-"`
+	Ticker        string              `json:"symbol"`
+	Count         int                 `json:"-"`
+	Mean          float64             `json:"mean"`
+	StdDev        float64             `json:"stddev"`
+	Variance      float64             `json:"-"`
+	Min           float64             `json:"min"`
+	Max           float64             `json:"max"`
+	Beta          *float64            `json:"beta"`        // nil if package has no reference index
+	Correlation   *float64            `json:"correlation"` // Pearson correlation of period returns vs. reference index
+	Alpha         *float64            `json:"alpha"`       // Annualized, in percent
+	InceptionDate *time.Time          `json:"inception"`
+	Metrics       *PerformanceMetrics `json:"metrics"` // nil for results computed before metrics existed
+	Rolling       *RollingSummary     `json:"rolling"` // nil unless the package computes rolling statistics
+	// OutliersRemoved counts YoY values dropped (or capped, for winsorize) by the outlier strategy
+	OutliersRemoved int             `json:"outliersRemoved"`
+	Histogram       json.RawMessage `json:"-"` // Only loaded for backups
+}
+
+// PerformanceMetrics are risk/return metrics of a price series. Returns are close-to-close
+// between consecutive periods; all values are in percent unless noted otherwise.
+type PerformanceMetrics struct {
+	Periods           int              `json:"periods"`           // Number of period returns
+	CAGR              *float64         `json:"cagr"`              // nil if the series covers less than a year
+	MaxDrawdown       float64          `json:"maxDrawdown"`       // Largest peak-to-trough decline (positive)
+	MaxDrawdownDays   int              `json:"maxDrawdownDays"`   // Days from that peak until recovery, or until the last date
+	MaxDrawdownPeak   *time.Time       `json:"maxDrawdownPeak"`   // nil if the series never declined
+	MaxDrawdownTrough *time.Time       `json:"maxDrawdownTrough"` // nil if the series never declined
+	Recovered         bool             `json:"recovered"`         // Whether the max drawdown was recovered
+	Volatility        float64          `json:"volatility"`        // Annualized standard deviation of returns
+	DownsideDeviation float64          `json:"downsideDeviation"` // Annualized, below the risk-free rate
+	Sharpe            *float64         `json:"sharpe"`            // nil if volatility is zero
+	Sortino           *float64         `json:"sortino"`           // nil if downside deviation is zero
+	RiskFreeRate      float64          `json:"riskFreeRate"`      // Annual rate the ratios are based on
+	PositiveYoY       *float64         `json:"positiveYoY"`       // Share of periods with YoY > 0; nil without YoY data
+	YoYPercentiles    *PercentileBands `json:"yoyPercentiles"`    // nil without YoY data
+}
+
+// PercentileBands are percentiles of a distribution, linearly interpolated
+type PercentileBands struct {
+	P5  float64 `json:"p5"`
+	P25 float64 `json:"p25"`
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+	P95 float64 `json:"p95"`
+}
+
+// Outlier strategies: how YoY values are filtered before the statistics of a result are computed
+const (
+	OutlierBalanced     = "balanced"     // Absolute bounds, then IQR with multiplier 1.5 (default)
+	OutlierConservative = "conservative" // Wider absolute bounds, then IQR with multiplier 3
+	OutlierIQR          = "iqr"          // IQR with a configurable multiplier (default 1.5)
+	OutlierStdDev       = "stddev"       // Values beyond n standard deviations from the mean
+	OutlierPercentile   = "percentile"   // Keep the central percentile, trim both ends equally
+	OutlierWinsorize    = "winsorize"    // Cap values beyond the low/high percentiles instead of removing them
+	OutlierNone         = "none"         // Keep all values
+)
+
+// OutlierConfig selects the outlier strategy of a package and its parameters.
+// Zero parameters use the defaults of the strategy; absolute bounds apply to every strategy but none.
+type OutlierConfig struct {
+	Strategy      string   `json:"strategy"`                // Empty means balanced
+	IQRMultiplier float64  `json:"iqrMultiplier,omitempty"` // balanced, conservative, iqr
+	StdDevs       float64  `json:"stddevs,omitempty"`       // stddev
+	AbsMin        *float64 `json:"absMin,omitempty"`        // Lower absolute bound in percent
+	AbsMax        *float64 `json:"absMax,omitempty"`        // Upper absolute bound in percent
+	Percentile    int      `json:"percentile,omitempty"`    // percentile: central share to keep (e.g. 90)
+	WinsorLow     int      `json:"winsorLow,omitempty"`     // winsorize: lower percentile
+	WinsorHigh    int      `json:"winsorHigh,omitempty"`    // winsorize: upper percentile
 }
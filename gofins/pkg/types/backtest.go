@@ -0,0 +1,85 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Rebalancing schedules of a backtest
+const (
+	RebalanceMonthly   = "monthly"
+	RebalanceQuarterly = "quarterly"
+	RebalanceYearly    = "yearly"
+)
+
+// BacktestConfig defines a simulated portfolio: either fixed holdings or the top N of an
+// analysis package, rebalanced to its target weights on a schedule
+type BacktestConfig struct {
+	Holdings  []BacktestHolding `json:"holdings,omitempty"` // Fixed target weights
+	TopN      *BacktestTopN     `json:"topN,omitempty"`     // Alternative to holdings
+	Rebalance string            `json:"rebalance"`          // monthly, quarterly or yearly
+	Interval  PriceInterval     `json:"interval"`           // Price series the simulation steps through
+	Start     time.Time         `json:"start"`
+	End       time.Time         `json:"end"`
+	Benchmark *string           `json:"benchmark"` // Index ticker, nil for none
+}
+
+// BacktestHolding is a ticker with its target weight (fraction of the portfolio)
+type BacktestHolding struct {
+	Ticker string  `json:"ticker"`
+	Weight float64 `json:"weight"`
+}
+
+// BacktestTopN selects the N best ranked results of an analysis package, equally weighted
+type BacktestTopN struct {
+	PackageID string     `json:"packageId"`
+	N         int        `json:"n"`
+	ProfileID *uuid.UUID `json:"profileId,omitempty"` // Scoring profile, nil for the default
+}
+
+// BacktestResult is the outcome of a simulation. Values are in USD, the equity curve starts at 100.
+type BacktestResult struct {
+	Holdings       []BacktestHolding   `json:"holdings"`       // Target weights the simulation used
+	Missing        []string            `json:"missing"`        // Holdings without prices in the range
+	Equity         []EquityPoint       `json:"equity"`         // Portfolio value per period
+	Metrics        *PerformanceMetrics `json:"metrics"`        // CAGR, volatility, drawdown etc., nil if too few periods
+	Turnover       float64             `json:"turnover"`       // One-way turnover summed over all rebalances, in percent
+	AnnualTurnover float64             `json:"annualTurnover"` // Turnover per year, in percent
+	Benchmark      *PerformanceMetrics `json:"benchmark"`      // nil without benchmark
+	Rebalances     []BacktestRebalance `json:"rebalances"`
+}
+
+// EquityPoint is the portfolio value at a period, with the benchmark scaled to the same start value
+type EquityPoint struct {
+	Date      time.Time `json:"date"`
+	Value     float64   `json:"value"`
+	Benchmark *float64  `json:"benchmark,omitempty"` // nil before the benchmark has prices
+}
+
+// BacktestRebalance records the portfolio right after a rebalance
+type BacktestRebalance struct {
+	Date     time.Time          `json:"date"`
+	Value    float64            `json:"value"`
+	Turnover float64            `json:"turnover"` // One-way turnover of this rebalance, in percent
+	Cash     float64            `json:"cash"`     // Fraction held in cash (no holding had a price)
+	Holdings []BacktestPosition `json:"holdings"`
+}
+
+// BacktestPosition is a holding right after a rebalance
+type BacktestPosition struct {
+	Ticker string  `json:"ticker"`
+	Weight float64 `json:"weight"`
+	Price  float64 `json:"price"`
+	Units  float64 `json:"units"`
+}
+
+// Backtest is a stored backtest of a user
+type Backtest struct {
+	ID        uuid.UUID       `json:"id"`
+	UserID    uuid.UUID       `json:"-"`
+	Name      string          `json:"name"`
+	Config    BacktestConfig  `json:"config"`
+	Result    *BacktestResult `json:"result,omitempty"` // Omitted in lists
+	CreatedAt time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,52 @@
+package types
+
+// PackageComparison describes what changed between the results of two analysis packages,
+// typically the same screen rerun with a different time window or interval.
+// Ranks are 1-based positions by score under the scoring profile used for the comparison.
+type PackageComparison struct {
+	BasePackageID  string          `json:"basePackageId"`
+	OtherPackageID string          `json:"otherPackageId"`
+	Profile        ScoringProfile  `json:"profile"`
+	Entered        []RankedTicker  `json:"entered"` // Tickers only in the other package, by rank
+	Left           []RankedTicker  `json:"left"`    // Tickers only in the base package, by rank
+	Changes        []ResultChange  `json:"changes"` // Tickers in both packages, by rank in the other package
+	Summary        ComparisonStats `json:"summary"`
+}
+
+// RankedTicker is a ticker with its rank within one package
+type RankedTicker struct {
+	Ticker string `json:"ticker"`
+	Rank   int    `json:"rank"`
+}
+
+// ResultChange compares the result of a ticker present in both packages
+type ResultChange struct {
+	Ticker     string     `json:"ticker"`
+	RankBase   int        `json:"rankBase"`
+	RankOther  int        `json:"rankOther"`
+	RankChange int        `json:"rankChange"` // Positive if the ticker moved up (RankBase - RankOther)
+	Mean       ValueDelta `json:"mean"`
+	StdDev     ValueDelta `json:"stddev"`
+	Min        ValueDelta `json:"min"`
+	Max        ValueDelta `json:"max"`
+}
+
+// ValueDelta is a statistic in both packages and its change (Other - Base)
+type ValueDelta struct {
+	Base  float64 `json:"base"`
+	Other float64 `json:"other"`
+	Delta float64 `json:"delta"`
+}
+
+// ComparisonStats summarizes a package comparison
+type ComparisonStats struct {
+	BaseCount   int     `json:"baseCount"`
+	OtherCount  int     `json:"otherCount"`
+	Common      int     `json:"common"`
+	Entered     int     `json:"entered"`
+	Left        int     `json:"left"`
+	MovedUp     int     `json:"movedUp"`
+	MovedDown   int     `json:"movedDown"`
+	MeanDelta   float64 `json:"meanDelta"`   // Average change of the mean over common tickers
+	StdDevDelta float64 `json:"stddevDelta"` // Average change of the stddev over common tickers
+}
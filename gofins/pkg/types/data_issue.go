@@ -0,0 +1,43 @@
+package types
+
+import "time"
+
+// Kinds of data issues found by the price auditor
+const (
+	IssueGap              = "gap"                // Periods missing inside a price series
+	IssueDuplicatePeriod  = "duplicate_period"   // More than one row for the same week or month
+	IssueNonPositiveClose = "non_positive_close" // Close of zero or below
+	IssueSplitJump        = "split_jump"         // Jump by a split ratio, likely an unadjusted split
+	IssueCurrencyMismatch = "currency_mismatch"  // USD close doesn't match the original close at the forex rate
+	IssueStale            = "stale"              // Prices of an actively trading symbol weren't updated in time
+)
+
+// Severity of a data issue
+const (
+	SeverityWarning = "warning" // Questionable, may be real market data
+	SeverityError   = "error"   // Wrong data that distorts analyses
+)
+
+// Status of a data issue
+const (
+	IssueOpen     = "open"
+	IssueResolved = "resolved" // No longer found by the auditor
+	IssueIgnored  = "ignored"  // Accepted by an admin, stays ignored while the auditor finds it
+)
+
+// DataIssue is a finding of the price data auditor
+type DataIssue struct {
+	ID         int           `json:"id"`
+	Ticker     string        `json:"ticker"`
+	Interval   PriceInterval `json:"interval,omitempty"` // Empty for symbol-level issues
+	Kind       string        `json:"kind"`
+	Period     *time.Time    `json:"period"` // First affected period, nil for symbol-level issues
+	Severity   string        `json:"severity"`
+	Message    string        `json:"message"`
+	Details    *string       `json:"details,omitempty"`
+	Status     string        `json:"status"`  // open, resolved or ignored
+	ErrorID    *int          `json:"errorId"` // Error logged by the audit run that first found the issue
+	FirstSeen  time.Time     `json:"firstSeen"`
+	LastSeen   time.Time     `json:"lastSeen"`
+	ResolvedAt *time.Time    `json:"resolvedAt"`
+}
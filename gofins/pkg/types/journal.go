@@ -0,0 +1,36 @@
+package types
+
+import "time"
+
+// Journal entry types
+const (
+	JournalTypeNote     = "note"
+	JournalTypeIdea     = "idea"
+	JournalTypeNews     = "news"
+	JournalTypeStrategy = "strategy"
+)
+
+// JournalTypes lists all valid journal entry types
+var JournalTypes = []string{JournalTypeNote, JournalTypeIdea, JournalTypeNews, JournalTypeStrategy}
+
+// JournalEntry is a freeform research note that can be linked to any number of tickers
+type JournalEntry struct {
+	ID        int       `json:"id"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	Type      string    `json:"type"`
+	Tags      []string  `json:"tags"`
+	Tickers   []string  `json:"tickers"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// IsValidJournalType checks if a journal entry type is known
+func IsValidJournalType(t string) bool {
+	for _, valid := range JournalTypes {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
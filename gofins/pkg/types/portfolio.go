@@ -0,0 +1,97 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Portfolio transaction types
+const (
+	TransactionBuy      = "buy"
+	TransactionSell     = "sell"
+	TransactionDividend = "dividend"
+)
+
+// TransactionTypes lists all valid portfolio transaction types
+var TransactionTypes = []string{TransactionBuy, TransactionSell, TransactionDividend}
+
+// Cost basis methods of a portfolio
+const (
+	CostBasisFIFO    = "fifo"    // Sells consume the oldest buy lots first
+	CostBasisAverage = "average" // Sells are valued at the average cost of the position
+)
+
+// Portfolio is a named set of transactions of a user
+type Portfolio struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"-"`
+	Name      string    `json:"name"`
+	CostBasis string    `json:"costBasis"` // Default cost basis method for positions: "fifo" or "average"
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Transaction is a buy, sell or dividend in a portfolio.
+// Prices and amounts are in the transaction currency, FxRate converts them to USD.
+type Transaction struct {
+	ID          uuid.UUID `json:"id"`
+	PortfolioID uuid.UUID `json:"portfolioId"`
+	Ticker      string    `json:"ticker"`
+	Type        string    `json:"type"`
+	Date        time.Time `json:"date"`
+	Quantity    float64   `json:"quantity"` // Shares bought or sold, 0 for dividends
+	Price       float64   `json:"price"`    // Price per share, 0 for dividends
+	Amount      float64   `json:"amount"`   // Cash received for dividends, 0 for buys and sells
+	Fee         float64   `json:"fee"`
+	Currency    string    `json:"currency"`
+	FxRate      float64   `json:"fxRate"` // USD per unit of the currency on the transaction date
+	Note        *string   `json:"note"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Position is the holding of one ticker derived from the transactions of a portfolio. Amounts are in USD.
+type Position struct {
+	Ticker        string     `json:"ticker"`
+	Quantity      float64    `json:"quantity"`      // Open shares, 0 for closed positions
+	CostBasis     float64    `json:"costBasis"`     // Cost of the open shares including buy fees
+	AverageCost   float64    `json:"averageCost"`   // Cost basis per open share
+	RealizedPnL   float64    `json:"realizedPnl"`   // Sell proceeds after fees minus their cost basis
+	Dividends     float64    `json:"dividends"`     // Dividends received after fees
+	Fees          float64    `json:"fees"`          // All fees paid
+	Price         *float64   `json:"price"`         // Current price, nil if unknown
+	PriceTime     *time.Time `json:"priceTime"`     // Time of the current price
+	MarketValue   *float64   `json:"marketValue"`   // Quantity * Price
+	UnrealizedPnL *float64   `json:"unrealizedPnl"` // MarketValue - CostBasis
+	UnrealizedPct *float64   `json:"unrealizedPct"` // UnrealizedPnL in % of CostBasis
+}
+
+// PortfolioValuation sums up the positions of a portfolio at current prices. Amounts are in USD.
+type PortfolioValuation struct {
+	PortfolioID   uuid.UUID  `json:"portfolioId"`
+	CostBasis     string     `json:"costBasisMethod"`
+	Positions     []Position `json:"positions"`
+	Invested      float64    `json:"invested"`      // Cost basis of all open positions
+	MarketValue   float64    `json:"marketValue"`   // Value of the open positions with a known price
+	UnrealizedPnL float64    `json:"unrealizedPnl"` // Of the open positions with a known price
+	RealizedPnL   float64    `json:"realizedPnl"`
+	Dividends     float64    `json:"dividends"`
+	Fees          float64    `json:"fees"`
+	TotalPnL      float64    `json:"totalPnl"` // Realized + unrealized + dividends
+	Unpriced      []string   `json:"unpriced"` // Open positions without a current price, excluded from market value and unrealized P&L
+	ValuedAt      time.Time  `json:"valuedAt"`
+}
+
+// IsValidTransactionType checks if a transaction type is known
+func IsValidTransactionType(t string) bool {
+	for _, valid := range TransactionTypes {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidCostBasis checks if a cost basis method is known
+func IsValidCostBasis(method string) bool {
+	return method == CostBasisFIFO || method == CostBasisAverage
+}
@@ -6,12 +6,15 @@ import "time"
 // Stores both original currency and USD-converted values
 type PriceData struct {
 	Date         time.Time
-	Open         float64  // USD converted
-	High         float64  // USD converted
-	Low          float64  // USD converted
-	Avg          float64  // USD converted
-	Close        float64  // USD converted
-	YoY          *float64 // Percentage, currency-independent
+	Open         float64    // USD converted
+	High         float64    // USD converted
+	Low          float64    // USD converted
+	Avg          float64    // USD converted
+	Close        float64    // USD converted
+	YoY          *float64   // Percentage, currency-independent
+	YoYTR        *float64   // Total-return percentage (dividends reinvested), nil if dividends unknown
+	YoYRef       *time.Time // Date of the prior-year period YoY and YoYTR compare with
+	CloseTR      *float64   // Total-return index (dividends reinvested) at the close, USD converted; nil if unknown
 	SymbolTicker string
 	// Original currency values (before USD conversion)
 	OpenOrig  *float64 // nil if already in USD
@@ -21,6 +24,52 @@ type PriceData struct {
 	CloseOrig *float64
 }
 
+// DailyPrice is a single end-of-day bar as stored in daily_prices
+// Stores both original currency and USD-converted values. Open, high, low and close are as
+// traded (split-adjusted only) for bars from the price history and from bulk quotes alike;
+// the dividend-adjusted close is kept separately in AdjClose.
+type DailyPrice struct {
+	Date         time.Time
+	Open         float64  // USD converted
+	High         float64  // USD converted
+	Low          float64  // USD converted
+	Close        float64  // USD converted
+	AdjClose     *float64 // USD converted, adjusted for splits and dividends
+	Volume       *int64   // Shares traded, nil if unknown
+	SymbolTicker string
+	// Original currency values (before USD conversion)
+	OpenOrig     *float64 // nil if already in USD
+	HighOrig     *float64
+	LowOrig      *float64
+	CloseOrig    *float64
+	AdjCloseOrig *float64
+}
+
+// Dividend is a cash dividend per share, keyed by ex-dividend date
+type Dividend struct {
+	SymbolTicker string
+	Date         time.Time  // Ex-dividend date
+	Dividend     float64    // Original currency, as declared
+	AdjDividend  float64    // Original currency, adjusted for later splits
+	PaymentDate  *time.Time // nil if unknown
+}
+
+// Split is a stock split: Numerator new shares for every Denominator old shares
+type Split struct {
+	SymbolTicker string
+	Date         time.Time
+	Numerator    float64
+	Denominator  float64
+}
+
+// Ratio returns the price divisor of the split (e.g. 4 for a 4:1 split), 0 if invalid
+func (s Split) Ratio() float64 {
+	if s.Numerator <= 0 || s.Denominator <= 0 {
+		return 0
+	}
+	return s.Numerator / s.Denominator
+}
+
 // PriceInterval represents the time interval for price data
 type PriceInterval string
 
@@ -29,6 +78,14 @@ const (
 	IntervalWeekly  PriceInterval = "weekly"
 )
 
+// ReturnBasis selects which YoY series an analysis uses
+type ReturnBasis string
+
+const (
+	ReturnBasisPrice       ReturnBasis = "price"        // Price change only
+	ReturnBasisTotalReturn ReturnBasis = "total_return" // Price change plus reinvested dividends
+)
+
 // Price status constants (mirror updater statuses)
 const (
 	StatusOK       = "ok"
@@ -0,0 +1,43 @@
+package types
+
+import "time"
+
+// RollingYoY is a time series of YoY statistics over a rolling window, used to see whether
+// the return profile of a symbol is stable or has shifted between regimes
+type RollingYoY struct {
+	RollingSummary
+	StepMonths int            `json:"stepMonths"`
+	Points     []RollingPoint `json:"points"`
+}
+
+// RollingPoint holds the YoY statistics of the window ending at Date
+type RollingPoint struct {
+	Date   time.Time `json:"date"`
+	Count  int       `json:"count"` // YoY values in the window
+	Mean   float64   `json:"mean"`
+	StdDev float64   `json:"stddev"`
+	Min    float64   `json:"min"`
+	Max    float64   `json:"max"`
+}
+
+// RollingSummary condenses a rolling YoY series. A low MeanStdDev means the rolling mean
+// hardly moved (stable profile); Shifts lists where it moved away from its level one window earlier.
+type RollingSummary struct {
+	WindowMonths int           `json:"windowMonths"`
+	Windows      int           `json:"windows"`    // Number of rolling windows
+	MeanMin      float64       `json:"meanMin"`    // Lowest rolling mean
+	MeanMax      float64       `json:"meanMax"`    // Highest rolling mean
+	MeanStdDev   float64       `json:"meanStddev"` // Dispersion of the rolling means
+	LastMean     float64       `json:"lastMean"`   // Rolling mean of the most recent window
+	LastStdDev   float64       `json:"lastStddev"` // Rolling stddev of the most recent window
+	Shifts       []RegimeShift `json:"shifts"`
+}
+
+// RegimeShift marks a window whose mean differs from the mean of the preceding,
+// non-overlapping window by more than the typical spread within the two windows
+type RegimeShift struct {
+	Date       time.Time `json:"date"`       // End of the later window
+	MeanBefore float64   `json:"meanBefore"` // Mean of the window ending one window length earlier
+	MeanAfter  float64   `json:"meanAfter"`
+	Change     float64   `json:"change"` // MeanAfter - MeanBefore
+}
@@ -0,0 +1,46 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reasons a scheduler run was started
+const (
+	RunTriggerSchedule = "schedule" // The cron schedule was due, including runs missed while the server was down
+	RunTriggerManual   = "manual"   // Triggered through the API or CLI
+)
+
+// Status of a scheduler run
+const (
+	RunRunning   = "running"
+	RunCompleted = "completed"
+	RunFailed    = "failed"
+	RunSkipped   = "skipped"   // Not run, because it was skipped by an admin or a dependency failed
+	RunCancelled = "cancelled" // Stopped by a server shutdown
+)
+
+// SchedulerJob is the state of a scheduled updater
+type SchedulerJob struct {
+	Name            string        `json:"name"`
+	Schedule        string        `json:"schedule"`        // Cron expression in effect
+	DefaultSchedule string        `json:"defaultSchedule"` // Used unless overridden by an admin
+	After           []string      `json:"after"`           // Jobs that run first when both are due
+	Paused          bool          `json:"paused"`          // Scheduled runs are suspended, manual triggers still run
+	SkipNext        bool          `json:"skipNext"`        // The next scheduled run is recorded as skipped
+	TriggeredAt     *time.Time    `json:"triggeredAt"`     // Pending manual trigger, nil if none
+	NextRun         *time.Time    `json:"nextRun"`         // nil while paused
+	LastRun         *SchedulerRun `json:"lastRun"`
+}
+
+// SchedulerRun is an entry of the run history of a scheduled job
+type SchedulerRun struct {
+	ID         uuid.UUID  `json:"id"`
+	Job        string     `json:"job"`
+	Trigger    string     `json:"trigger"` // schedule or manual
+	Status     string     `json:"status"`  // running, completed, failed, skipped or cancelled
+	Error      *string    `json:"error"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt"`
+}
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Score normalization modes
+const (
+	NormalizationZScore = "zscore" // (value - mean) / stddev across the package
+	NormalizationRank   = "rank"   // rank percentile in [0, 1]
+)
+
+// ScoringProfile is a named set of weights used to rank analysis results
+type ScoringProfile struct {
+	ID            uuid.UUID `json:"id"`
+	Name          string    `json:"name"`
+	WeightMean    float64   `json:"weightMean"`
+	WeightStdDev  float64   `json:"weightStddev"`
+	WeightMin     float64   `json:"weightMin"`
+	WeightMax     float64   `json:"weightMax"`
+	WeightBeta    float64   `json:"weightBeta"`
+	Normalization string    `json:"normalization"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// ScoreContributions holds each component's weighted, normalized share of a score
+type ScoreContributions struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Beta   float64 `json:"beta"`
+}
+
+// ScoredResult is an analysis result with its weighted score and rank within the package
+type ScoredResult struct {
+	AnalysisResult
+	Rank          int                `json:"rank"`
+	Score         float64            `json:"score"`
+	Contributions ScoreContributions `json:"contributions"`
+}
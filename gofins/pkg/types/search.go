@@ -0,0 +1,32 @@
+package types
+
+import "time"
+
+// Search hit kinds
+const (
+	SearchKindSymbol  = "symbol"
+	SearchKindRating  = "rating"
+	SearchKindJournal = "journal"
+)
+
+// SearchKinds lists all searchable kinds in display order
+var SearchKinds = []string{SearchKindSymbol, SearchKindRating, SearchKindJournal}
+
+// SearchHit is a single full-text search match
+type SearchHit struct {
+	Kind      string     `json:"kind"`
+	ID        *int       `json:"id,omitempty"` // Rating or journal entry ID
+	Title     string     `json:"title"`
+	Snippet   string     `json:"snippet"` // HTML-escaped text, matched terms wrapped in <mark></mark>
+	Rank      float64    `json:"rank"`
+	Tickers   []string   `json:"tickers"`
+	Rating    *int       `json:"rating,omitempty"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+}
+
+// SearchGroup holds the hits of one kind, best first
+type SearchGroup struct {
+	Kind  string      `json:"kind"`
+	Count int         `json:"count"`
+	Hits  []SearchHit `json:"hits"`
+}
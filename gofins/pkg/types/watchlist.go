@@ -0,0 +1,34 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultWatchlistName is the name of the watchlist created for every user, which holds their favorites
+const DefaultWatchlistName = "Favorites"
+
+// Watchlist is a named, ordered list of tickers of a user
+type Watchlist struct {
+	ID         uuid.UUID        `json:"id"`
+	UserID     uuid.UUID        `json:"-"`
+	Name       string           `json:"name"`
+	IsDefault  bool             `json:"isDefault"` // The favorites list, can't be deleted
+	EntryCount int              `json:"entryCount"`
+	Entries    []WatchlistEntry `json:"entries,omitempty"` // Only filled for a single watchlist
+	CreatedAt  time.Time        `json:"createdAt"`
+}
+
+// WatchlistEntry is a ticker on a watchlist with the user's note and target price
+type WatchlistEntry struct {
+	Ticker           string     `json:"ticker"`
+	Position         int        `json:"position"`
+	Note             *string    `json:"note"`
+	TargetPrice      *float64   `json:"targetPrice"` // In USD
+	Name             *string    `json:"name"`        // Symbol name, nil for unknown tickers
+	CurrentPriceUsd  *float64   `json:"currentPriceUsd"`
+	CurrentPriceTime *time.Time `json:"currentPriceTime"`
+	TargetDistance   *float64   `json:"targetDistance"` // Change from current price to target price, in percent
+	CreatedAt        time.Time  `json:"createdAt"`
+}
@@ -1,59 +1,31 @@
 package updater
 
 import (
-	"context"
-	"time"
-
-	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/audit"
+	"github.com/flocko-motion/gofins/pkg/scheduler"
 )
 
-// RunAllUpdaters runs all updaters in sequence: symbols -> profiles -> quotes -> prices -> dedupe
-// Quotes must run before prices to enable incremental price updates
-// After completing a full cycle, it sleeps for 8 hours before repeating
-func RunAllUpdaters(ctx context.Context) {
-	log := NewLogger("All")
-	log.Printf("Starting all updaters (symbols -> profiles -> quotes -> prices -> dedupe)\n")
-
-	for {
-		log.Printf("Starting full update cycle...\n")
-		cycleStart := time.Now()
-
-		// Step 1: Sync symbols
-		log.Printf("Step 1/5: Syncing symbols...\n")
-		if err := SyncSymbolsOnce(ctx); err != nil {
-			log.Errorf("Symbol sync failed: %v\n", err)
-		}
-
-		// Step 2: Update profiles
-		log.Printf("Step 2/5: Updating profiles...\n")
-		if err := UpdateProfilesBatchOnce(ctx); err != nil {
-			log.Errorf("Profile update failed: %v\n", err)
-		}
-
-		// Step 3: Update EOD quotes (must run before prices for incremental updates)
-		log.Printf("Step 3/5: Updating quotes...\n")
-		if err := UpdateQuotesOnce(ctx); err != nil {
-			log.Errorf("Quote update failed: %v\n", err)
-		}
-
-		// Step 4: Update prices (can now use incremental updates from quotes)
-		log.Printf("Step 4/5: Updating prices...\n")
-		if err := UpdatePricesOnce(ctx); err != nil {
-			log.Errorf("Price update failed: %v\n", err)
-		}
-
-		// Step 5: Deduplicate
-		log.Printf("Step 5/5: Deduplicating symbols...\n")
-		if err := DedupeSymbolsOnce(ctx); err != nil {
-			log.Errorf("Deduplication failed: %v\n", err)
-		}
-
-		cycleDuration := time.Since(cycleStart)
-		log.Printf("✓ Full cycle completed in %s\n", f.DurationToString(cycleDuration))
+// Default schedules of the updaters. Quotes and prices run in the New York morning after each US
+// session; the quote updater fetches the bulk EOD data of the last closed session.
+const (
+	ScheduleSymbols  = "0 4 * * *"
+	ScheduleProfiles = "0 4 * * *"
+	ScheduleQuotes   = "CRON_TZ=America/New_York 0 6 * * 2-6"
+	SchedulePrices   = "CRON_TZ=America/New_York 0 6 * * 2-6"
+	ScheduleDedupe   = "0 5 * * 0"
+	ScheduleAudit    = "CRON_TZ=America/New_York 0 6 * * 6"
+)
 
-		// Sleep for 8 hours before next cycle
-		const sleepHours = 8
-		log.Printf("Sleeping for %d hours before next cycle...\n", sleepHours)
-		time.Sleep(time.Hour * time.Duration(sleepHours))
+// Jobs returns the updaters as scheduler jobs: symbols -> profiles -> quotes -> prices, dedupe weekly
+// and the price audit weekly after the Saturday price update.
+// Quotes must run before prices to enable incremental price updates.
+func Jobs() []scheduler.Job {
+	return []scheduler.Job{
+		{Name: "symbols", Schedule: ScheduleSymbols, Run: SyncSymbolsOnce},
+		{Name: "profiles", Schedule: ScheduleProfiles, After: []string{"symbols"}, Run: UpdateProfilesBatchOnce},
+		{Name: "quotes", Schedule: ScheduleQuotes, After: []string{"profiles"}, Run: UpdateQuotesOnce},
+		{Name: "prices", Schedule: SchedulePrices, After: []string{"quotes"}, Run: UpdatePricesOnce},
+		{Name: "dedupe", Schedule: ScheduleDedupe, After: []string{"profiles"}, Run: DedupeSymbolsOnce},
+		{Name: "audit", Schedule: ScheduleAudit, After: []string{"prices"}, Run: audit.RunOnce},
 	}
 }
@@ -18,18 +18,6 @@ type DedupeConfig struct {
 	Symbols   []string // Specific symbols to include (empty = all symbols)
 }
 
-func DedupeSymbols(ctx context.Context) {
-	log := NewLogger("Dedupe")
-	config := &DedupeConfig{MaxGroups: 0} // unlimited
-
-	for {
-		if err := dedupeSymbolsImpl(ctx, log, config); err != nil {
-			log.Errorf("Dedupe failed: %v\n", err)
-		}
-		time.Sleep(time.Hour * 24 * 7) // Sleep for 7 days
-	}
-}
-
 func DedupeSymbolsOnce(ctx context.Context) error {
 	return DedupeSymbolsOnceWithConfig(ctx, nil)
 }
@@ -43,17 +31,6 @@ func DedupeSymbolsOnceWithConfig(ctx context.Context, config *DedupeConfig) erro
 }
 
 func dedupeSymbolsImpl(ctx context.Context, log *log.Logger, config *DedupeConfig) error {
-	// Check if we already ran today
-	lastRun, err := db.GetLastBatchUpdate(ctx, "dedupe")
-	if err == nil && lastRun != nil && lastRun.CompletedAt != nil {
-		today := time.Now().Truncate(24 * time.Hour)
-		lastRunDay := lastRun.CompletedAt.Truncate(24 * time.Hour)
-		if today.Equal(lastRunDay) {
-			log.Printf("Dedupe already ran today at %s, skipping\n", lastRun.CompletedAt.Format("15:04:05"))
-			return nil
-		}
-	}
-
 	log.Printf("Starting deduplication (CIK and Name in parallel)...\n")
 	if config.MaxGroups > 0 {
 		log.Printf("Limited to %d groups per deduper\n", config.MaxGroups)
@@ -1,6 +1,7 @@
 package updater
 
 import (
+	"context"
 	"testing"
 
 	"github.com/flocko-motion/gofins/pkg/db"
@@ -11,7 +12,7 @@ func TestDedupeByName(t *testing.T) {
 		Symbols: []string{"AAPL", "APC.DE"},
 	}
 
-	updated, failed, err := dedupeByName(config)
+	updated, failed, err := dedupeByName(context.Background(), config)
 	if err != nil {
 		t.Fatalf("dedupeByName failed: %v", err)
 	}
@@ -23,7 +24,7 @@ func TestDedupeByName(t *testing.T) {
 	}
 
 	// Check if any primary_listing entries exist
-	symbols, err := db.GetSymbolsWithCIK()
+	symbols, err := db.GetSymbolsWithCIK(context.Background())
 	if err != nil {
 		t.Fatalf("Failed to get symbols: %v", err)
 	}
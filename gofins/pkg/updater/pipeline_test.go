@@ -0,0 +1,85 @@
+package updater
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/fmp"
+	"github.com/flocko-motion/gofins/pkg/fmp/fmptest"
+	"github.com/flocko-motion/gofins/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPipelineAgainstFakeFMP runs symbols -> profiles -> quotes -> prices offline against the
+// fake FMP server. The symbol sync deactivates every symbol missing from the fixtures, so it only
+// runs against a disposable database (DB_NAME containing "test").
+func TestPipelineAgainstFakeFMP(t *testing.T) {
+	if !strings.Contains(os.Getenv("DB_NAME"), "test") {
+		t.Skip("Requires a disposable database: run with DB_NAME=<name containing 'test'>")
+	}
+	if db.Db() == nil {
+		t.Skip("Database not available")
+	}
+
+	server := fmptest.NewServer(nil)
+	defer server.Close()
+	client := server.Client()
+	defer client.Shutdown()
+	fmp.SetProvider(client)
+	defer fmp.SetProvider(nil)
+
+	ctx := context.Background()
+	log := NewLoggerTest("Pipeline")
+
+	// Symbols: stock and index lists without the delisted company
+	require.NoError(t, syncSymbolsImpl(ctx, log))
+	tickers, err := db.GetAllTickers(ctx)
+	require.NoError(t, err)
+	assert.Subset(t, tickers, []string{"AAPL", "MSFT", "SAP.DE", "^GSPC"})
+	assert.NotContains(t, tickers, "TWTR")
+
+	// Profiles: currencies are needed for the USD conversion of quotes and prices
+	require.NoError(t, updateProfilesBatchImpl(ctx, log))
+	sap, err := db.GetSymbol(ctx, "SAP.DE")
+	require.NoError(t, err)
+	require.NotNil(t, sap)
+	assert.Equal(t, "EUR", *sap.Currency)
+
+	// Quotes: one bulk EOD request for the session
+	session := time.Date(2024, 12, 27, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, updateQuotesImpl(ctx, session, log))
+	assert.Equal(t, 1, server.Requests("/stable/eod-bulk"))
+	aapl, err := db.GetSymbol(ctx, "AAPL")
+	require.NoError(t, err)
+	require.NotNil(t, aapl.CurrentPriceUsd)
+	assert.InDelta(t, 255.59, *aapl.CurrentPriceUsd, 0.001)
+	sap, err = db.GetSymbol(ctx, "SAP.DE")
+	require.NoError(t, err)
+	require.NotNil(t, sap.CurrentPriceUsd)
+	assert.NotEqual(t, 237.05, *sap.CurrentPriceUsd, "EUR quotes are converted to USD")
+
+	// Prices: history of every symbol with monthly, weekly and daily bars
+	config := DefaultPriceUpdateConfig()
+	config.Workers = 2
+	require.NoError(t, updatePricesImpl(ctx, log, config))
+	for _, ticker := range []string{"AAPL", "MSFT", "SAP.DE", "^GSPC"} {
+		symbol, err := db.GetSymbol(ctx, ticker)
+		require.NoError(t, err)
+		require.NotNil(t, symbol.LastPriceStatus, ticker)
+		assert.Equal(t, types.StatusOK, *symbol.LastPriceStatus, ticker)
+
+		monthly, err := db.GetPrices(ticker, session.AddDate(-1, 0, 0), session, types.IntervalMonthly)
+		require.NoError(t, err)
+		assert.NotEmpty(t, monthly, ticker)
+		daily, err := db.GetDailyPrices(ticker, session.AddDate(0, -1, 0), session)
+		require.NoError(t, err)
+		assert.NotEmpty(t, daily, ticker)
+	}
+	assert.Equal(t, 3, server.Requests("/stable/historical-price-eod/dividend-adjusted"))
+	assert.Equal(t, 4, server.Requests("/stable/historical-price-eod/full"), "raw history of the stocks and the index")
+}
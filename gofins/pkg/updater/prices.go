@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/flocko-motion/gofins/pkg/calculator"
+	"github.com/flocko-motion/gofins/pkg/calendar"
 	"github.com/flocko-motion/gofins/pkg/db"
+	"github.com/flocko-motion/gofins/pkg/f"
 	"github.com/flocko-motion/gofins/pkg/fmp"
 	"github.com/flocko-motion/gofins/pkg/forex"
 	"github.com/flocko-motion/gofins/pkg/log"
@@ -54,6 +57,23 @@ type PriceStats struct {
 	FailureReasons map[string]string // ticker -> error message
 }
 
+// priceHistory is the price data of one or more symbols as collected by updatePrices
+type priceHistory struct {
+	Monthly   []types.PriceData
+	Weekly    []types.PriceData
+	Daily     []types.DailyPrice
+	Dividends []types.Dividend
+	Splits    []types.Split
+}
+
+func (h *priceHistory) append(other priceHistory) {
+	h.Monthly = append(h.Monthly, other.Monthly...)
+	h.Weekly = append(h.Weekly, other.Weekly...)
+	h.Daily = append(h.Daily, other.Daily...)
+	h.Dividends = append(h.Dividends, other.Dividends...)
+	h.Splits = append(h.Splits, other.Splits...)
+}
+
 var (
 	batchWriteMutex sync.Mutex
 	writeJobCounter int
@@ -63,7 +83,7 @@ var (
 // Blocks if another batch write is in progress, then runs in background
 // Ensures only one background write happens at a time
 
-func batchWritePrices(symbols []types.Symbol, monthly []types.PriceData, weekly []types.PriceData, config PriceUpdateConfig, log *log.Logger) {
+func batchWritePrices(symbols []types.Symbol, history priceHistory, config PriceUpdateConfig, log *log.Logger) {
 	// Wait for any previous batch write to complete, then start new one
 	// writeJob := writeJobCounter
 	// writeJobCounter++
@@ -84,35 +104,30 @@ func batchWritePrices(symbols []types.Symbol, monthly []types.PriceData, weekly
 
 		// startTime := time.Now()
 
-		// Run all 3 writes concurrently for 3x speedup
-		var wg sync.WaitGroup
-
-		if len(monthly) > 0 {
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				if err := db.PutMonthlyPrices(monthly); err != nil {
-					log.Errorf("Failed to batch write monthly prices: %v\n", err)
-				}
-			}()
-		}
-
-		if len(weekly) > 0 {
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				if err := db.PutWeeklyPrices(weekly); err != nil {
-					log.Errorf("Failed to batch write weekly prices: %v\n", err)
-				}
-			}()
+		writes := []struct {
+			name  string
+			count int
+			put   func() error
+		}{
+			{"monthly prices", len(history.Monthly), func() error { return db.PutMonthlyPrices(history.Monthly) }},
+			{"weekly prices", len(history.Weekly), func() error { return db.PutWeeklyPrices(history.Weekly) }},
+			{"daily prices", len(history.Daily), func() error { return db.PutDailyPrices(history.Daily) }},
+			{"dividends", len(history.Dividends), func() error { return db.PutDividends(history.Dividends) }},
+			{"splits", len(history.Splits), func() error { return db.PutSplits(history.Splits) }},
+			{"symbols", len(symbols), func() error { return db.PutSymbols(symbols) }},
 		}
 
-		if len(symbols) > 0 {
+		// Run all writes concurrently
+		var wg sync.WaitGroup
+		for _, write := range writes {
+			if write.count == 0 {
+				continue
+			}
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				if err := db.PutSymbols(symbols); err != nil {
-					log.Errorf("Failed to batch write symbols: %v\n", err)
+				if err := write.put(); err != nil {
+					log.Errorf("Failed to batch write %s: %v\n", write.name, err)
 				}
 			}()
 		}
@@ -124,28 +139,6 @@ func batchWritePrices(symbols []types.Symbol, monthly []types.PriceData, weekly
 	}()
 }
 
-func UpdatePrices(ctx context.Context) {
-	log := NewLogger("Prices")
-	config := DefaultPriceUpdateConfig()
-
-	for {
-		select {
-		case <-ctx.Done():
-			log.Stopped()
-			return
-		default:
-		}
-
-		if err := updatePricesImpl(ctx, log, config); err != nil {
-			log.Errorf("Price update failed: %v\n", err)
-		}
-
-		const sleepTimeHours = 8
-		log.AllDone(sleepTimeHours)
-		time.Sleep(time.Duration(sleepTimeHours) * time.Hour)
-	}
-}
-
 func UpdatePricesOnce(ctx context.Context) error {
 	log := NewLogger("Prices")
 	config := DefaultPriceUpdateConfig()
@@ -154,7 +147,7 @@ func UpdatePricesOnce(ctx context.Context) error {
 
 func updatePricesImpl(ctx context.Context, log *log.Logger, config PriceUpdateConfig) error {
 	// Ensure last batch write completes before exit
-	defer batchWritePrices(nil, nil, nil, config, log)
+	defer batchWritePrices(nil, priceHistory{}, config, log)
 
 	totalStale, err := db.CountStalePrices(ctx)
 	if err != nil {
@@ -199,8 +192,7 @@ func updatePricesImpl(ctx context.Context, log *log.Logger, config PriceUpdateCo
 		// Collect results for batch writing
 		var resultsMu sync.Mutex
 		updatedSymbols := make([]types.Symbol, 0)
-		monthlyPrices := make([]types.PriceData, 0)
-		weeklyPrices := make([]types.PriceData, 0)
+		var collected priceHistory
 
 		// Worker pool
 		symbolChan := make(chan types.Symbol, len(symbols))
@@ -215,7 +207,7 @@ func updatePricesImpl(ctx context.Context, log *log.Logger, config PriceUpdateCo
 					// Process with WriteToDb=false to collect data
 					workerConfig := config
 					workerConfig.WriteToDb = false
-					updatedSymbol, monthly, weekly, err := updatePrices(ctx, symbol, workerConfig, log)
+					updatedSymbol, history, err := updatePrices(ctx, symbol, workerConfig, log)
 
 					statsMu.Lock()
 					if updatedSymbol.LastPriceStatus != nil {
@@ -237,8 +229,7 @@ func updatePricesImpl(ctx context.Context, log *log.Logger, config PriceUpdateCo
 					if updatedSymbol.LastPriceStatus != nil && *updatedSymbol.LastPriceStatus == types.StatusOK {
 						resultsMu.Lock()
 						updatedSymbols = append(updatedSymbols, updatedSymbol)
-						monthlyPrices = append(monthlyPrices, monthly...)
-						weeklyPrices = append(weeklyPrices, weekly...)
+						collected.append(history)
 						resultsMu.Unlock()
 					}
 				}
@@ -254,7 +245,7 @@ func updatePricesImpl(ctx context.Context, log *log.Logger, config PriceUpdateCo
 
 		// Batch write all results in background
 		if len(updatedSymbols) > 0 {
-			batchWritePrices(updatedSymbols, monthlyPrices, weeklyPrices, config, log)
+			batchWritePrices(updatedSymbols, collected, config, log)
 		}
 		// log.Printf("  cycle completed - now writing in db\n")
 
@@ -286,10 +277,10 @@ func updatePricesImpl(ctx context.Context, log *log.Logger, config PriceUpdateCo
 	}
 }
 
-func updatePrices(ctx context.Context, symbol types.Symbol, config PriceUpdateConfig, log *log.Logger) (types.Symbol, []types.PriceData, []types.PriceData, error) {
+func updatePrices(ctx context.Context, symbol types.Symbol, config PriceUpdateConfig, log *log.Logger) (types.Symbol, priceHistory, error) {
 	startTime := time.Now()
 
-	dailyPrices, err := fmp.FetchPriceHistory(symbol.Ticker)
+	dailyPrices, rawPrices, dividends, splits, err := fetchPriceHistory(symbol.Ticker)
 	fetchDuration := time.Since(startTime)
 
 	now := time.Now()
@@ -306,25 +297,27 @@ func updatePrices(ctx context.Context, symbol types.Symbol, config PriceUpdateCo
 			db.PutSymbols([]types.Symbol{symbol})
 		}
 
-		return symbol, nil, nil, err
+		return symbol, priceHistory{}, err
 	}
 
-	// Sort by date
-	sort.Slice(dailyPrices, func(i, j int) bool {
-		return dailyPrices[i].Date < dailyPrices[j].Date
-	})
-
-	// Single-loop conversion: daily → weekly + monthly + YoY
+	// Single-loop conversion: daily → weekly + monthly + YoY (price and total return).
+	// Closes are dividend-adjusted, daily bars are stored as traded with the adjusted close.
 	convertStart := time.Now()
-	monthly, weekly := calculator.ConvertPrices(dailyPrices, symbol.Ticker)
+	dailyPrices = calculator.AdjustSplits(dailyPrices, splits)
+	rawPrices = calculator.AdjustSplits(rawPrices, splits)
+	cal := calendar.ForExchange(f.MaybeToString(symbol.Exchange, ""))
+	monthly, weekly := calculator.ConvertPrices(dailyPrices, rawPrices, dividends, symbol.Ticker, cal, calculator.DefaultWeekConvention())
+	daily := calculator.ConvertDailyPrices(rawPrices, dividends, symbol.Ticker)
 	convertDuration := time.Since(convertStart)
 
 	forexStart := time.Now()
 	var forexDuration time.Duration
 	if symbol.Currency != nil && *symbol.Currency != "USD" {
 		monthly, weekly = convertForexPrices(monthly, weekly, *symbol.Currency)
+		daily = convertForexDailyPrices(daily, *symbol.Currency)
 		forexDuration = time.Since(forexStart)
 	}
+	history := priceHistory{Monthly: monthly, Weekly: weekly, Daily: daily, Dividends: dividends, Splits: splits}
 
 	// Parse oldest price date
 	var oldestPrice *time.Time
@@ -371,7 +364,7 @@ func updatePrices(ctx context.Context, symbol types.Symbol, config PriceUpdateCo
 			_ = db.LogError(ctx, "updater.prices", "db_constraint_violation",
 				fmt.Sprintf("Failed to insert monthly prices for %s: %v", tickerInfo, err), nil)
 
-			return symbol, monthly, weekly, fmt.Errorf("failed to insert monthly prices: %w", err)
+			return symbol, history, fmt.Errorf("failed to insert monthly prices: %w", err)
 		}
 		if err := db.PutWeeklyPrices(weekly); err != nil {
 			failStatus := types.StatusFailed
@@ -382,7 +375,24 @@ func updatePrices(ctx context.Context, symbol types.Symbol, config PriceUpdateCo
 			_ = db.LogError(ctx, "updater.prices", "db_constraint_violation",
 				fmt.Sprintf("Failed to insert weekly prices for %s: %v", symbol.Ticker, err), nil)
 
-			return symbol, monthly, weekly, fmt.Errorf("failed to insert weekly prices: %w", err)
+			return symbol, history, fmt.Errorf("failed to insert weekly prices: %w", err)
+		}
+		if err := db.PutDailyPrices(daily); err != nil {
+			failStatus := types.StatusFailed
+			symbol.LastPriceStatus = &failStatus
+			db.PutSymbols([]types.Symbol{symbol})
+
+			_ = db.LogError(ctx, "updater.prices", "db_constraint_violation",
+				fmt.Sprintf("Failed to insert daily prices for %s: %v", symbol.Ticker, err), nil)
+
+			return symbol, history, fmt.Errorf("failed to insert daily prices: %w", err)
+		}
+		// Corporate actions are informational - a failed write doesn't fail the symbol
+		if err := db.PutDividends(dividends); err != nil {
+			log.Errorf("Failed to insert dividends for %s: %v\n", symbol.Ticker, err)
+		}
+		if err := db.PutSplits(splits); err != nil {
+			log.Errorf("Failed to insert splits for %s: %v\n", symbol.Ticker, err)
 		}
 		db.PutSymbols([]types.Symbol{symbol})
 	}
@@ -391,12 +401,55 @@ func updatePrices(ctx context.Context, symbol types.Symbol, config PriceUpdateCo
 
 	// Log timing breakdown if profiling enabled
 	if config.EnableProfiling {
-		log.Printf("[TIMING] %s: total=%v fetch=%v convert=%v forex=%v (monthly=%d weekly=%d)\n",
+		log.Printf("[TIMING] %s: total=%v fetch=%v convert=%v forex=%v (monthly=%d weekly=%d daily=%d)\n",
 			symbol.Ticker, totalDuration, fetchDuration, convertDuration, forexDuration,
-			len(monthly), len(weekly))
+			len(monthly), len(weekly), len(daily))
+	}
+
+	return symbol, history, nil
+}
+
+// fetchPriceHistory fetches the dividend-adjusted and the raw price history of a symbol, both
+// sorted by date, and its corporate actions. Indices have a single history and no corporate actions.
+func fetchPriceHistory(ticker string) (adjusted, raw []fmp.PriceDataRaw, dividends []types.Dividend, splits []types.Split, err error) {
+	byDate := func(prices []fmp.PriceDataRaw) {
+		sort.Slice(prices, func(i, j int) bool { return prices[i].Date < prices[j].Date })
+	}
+
+	adjusted, err = fmp.FetchPriceHistory(ticker)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	byDate(adjusted)
+	if strings.HasPrefix(ticker, "^") {
+		return adjusted, adjusted, nil, nil, nil
+	}
+
+	raw, err = fmp.FetchRawPriceHistory(ticker)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to fetch raw prices: %w", err)
+	}
+	byDate(raw)
+
+	dividends, splits, err = fetchCorporateActions(ticker)
+	return adjusted, raw, dividends, splits, err
+}
+
+// fetchCorporateActions fetches the dividend and split history of a stock. Without them the
+// total-return values and split adjustments would silently be missing, so a failed fetch fails
+// the symbol and it is retried on the next run.
+func fetchCorporateActions(ticker string) ([]types.Dividend, []types.Split, error) {
+	dividends, err := fmp.FetchDividends(ticker)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch dividends: %w", err)
+	}
+
+	splits, err := fmp.FetchSplits(ticker)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch splits: %w", err)
 	}
 
-	return symbol, monthly, weekly, nil
+	return dividends, splits, nil
 }
 
 // convertForexPrices converts stock prices from a foreign currency to USD
@@ -425,7 +478,10 @@ func convertForexPrices(monthly, weekly []types.PriceData, currency string) ([]t
 			High:         price.High * rate,
 			Low:          price.Low * rate,
 			Avg:          price.Avg * rate,
-			YoY:          price.YoY,          // Preserve YoY percentage
+			YoY:          price.YoY,   // Preserve YoY percentage
+			YoYTR:        price.YoYTR, // Preserve total-return YoY percentage
+			YoYRef:       price.YoYRef,
+			CloseTR:      scaleOptional(price.CloseTR, rate),
 			SymbolTicker: price.SymbolTicker, // Preserve ticker
 			// Store original currency values
 			OpenOrig:  &price.Open,
@@ -455,7 +511,10 @@ func convertForexPrices(monthly, weekly []types.PriceData, currency string) ([]t
 			High:         price.High * rate,
 			Low:          price.Low * rate,
 			Avg:          price.Avg * rate,
-			YoY:          price.YoY,          // Preserve YoY percentage
+			YoY:          price.YoY,   // Preserve YoY percentage
+			YoYTR:        price.YoYTR, // Preserve total-return YoY percentage
+			YoYRef:       price.YoYRef,
+			CloseTR:      scaleOptional(price.CloseTR, rate),
 			SymbolTicker: price.SymbolTicker, // Preserve ticker
 			// Store original currency values
 			OpenOrig:  &price.Open,
@@ -470,3 +529,34 @@ func convertForexPrices(monthly, weekly []types.PriceData, currency string) ([]t
 
 	return convertedMonthly, convertedWeekly
 }
+
+// convertForexDailyPrices converts daily bars from a foreign currency to USD
+func convertForexDailyPrices(daily []types.DailyPrice, currency string) []types.DailyPrice {
+	ts, err := forex.GetCachedForex(currency)
+	if err != nil {
+		// If we can't get forex data, return unconverted (better than failing)
+		return daily
+	}
+
+	converted := make([]types.DailyPrice, 0, len(daily))
+	for _, price := range daily {
+		rate, err := forex.ConvertToUsdWithTimeSeries(1.0, ts, price.Date)
+		if err != nil {
+			// Skip this bar if no forex data available (e.g. forex holiday)
+			continue
+		}
+
+		converted = append(converted, convertDailyBar(price, rate))
+	}
+
+	return converted
+}
+
+// scaleOptional returns value * rate, nil if value is nil
+func scaleOptional(value *float64, rate float64) *float64 {
+	if value == nil {
+		return nil
+	}
+	scaled := *value * rate
+	return &scaled
+}
@@ -1,16 +1,78 @@
 package updater
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math"
 	"testing"
 
 	"github.com/flocko-motion/gofins/pkg/db"
 	"github.com/flocko-motion/gofins/pkg/f"
+	"github.com/flocko-motion/gofins/pkg/fmp"
+	"github.com/flocko-motion/gofins/pkg/fmp/fmptest"
 	"github.com/flocko-motion/gofins/pkg/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func TestUpdatePricesBasis(t *testing.T) {
+	server := fmptest.NewServer(nil)
+	defer server.Close()
+	client := server.Client()
+	defer client.Shutdown()
+	fmp.SetProvider(client)
+	defer fmp.SetProvider(nil)
+
+	log := NewLoggerTest("BasisTest")
+	symbol, history, err := updatePrices(context.Background(), types.Symbol{Ticker: "AAPL"}, PriceUpdateConfig{}, log)
+	require.NoError(t, err)
+	assert.Equal(t, types.StatusOK, *symbol.LastPriceStatus)
+
+	adjusted, err := client.FetchPriceHistory("AAPL")
+	require.NoError(t, err)
+	raw, err := client.FetchRawPriceHistory("AAPL")
+	require.NoError(t, err)
+	oldest := len(adjusted) - 1
+
+	// Closes are dividend-adjusted, daily bars as traded with the adjusted close
+	first := history.Monthly[0]
+	assert.InDelta(t, adjusted[oldest].Open, first.Open, 1e-9)
+	assert.InDelta(t, raw[oldest].Close, history.Daily[0].Close, 1e-9)
+	require.NotNil(t, history.Daily[0].AdjClose)
+	assert.Less(t, *history.Daily[0].AdjClose, history.Daily[0].Close)
+	assert.NotNil(t, history.Monthly[len(history.Monthly)-1].YoYTR)
+}
+
+// failingDividends is a provider whose dividend endpoint fails
+type failingDividends struct {
+	fmp.MarketDataProvider
+}
+
+func (failingDividends) FetchDividends(ticker string) ([]types.Dividend, error) {
+	return nil, errors.New("service unavailable")
+}
+
+func TestUpdatePricesDividendError(t *testing.T) {
+	server := fmptest.NewServer(nil)
+	defer server.Close()
+	client := server.Client()
+	defer client.Shutdown()
+	fmp.SetProvider(failingDividends{client})
+	defer fmp.SetProvider(nil)
+
+	log := NewLoggerTest("DividendTest")
+	symbol, history, err := updatePrices(context.Background(), types.Symbol{Ticker: "AAPL"}, PriceUpdateConfig{}, log)
+	require.ErrorContains(t, err, "failed to fetch dividends")
+	assert.Equal(t, types.StatusFailed, *symbol.LastPriceStatus)
+	assert.Empty(t, history.Monthly, "no prices without total-return values")
+
+	// Indices have no dividends
+	_, history, err = updatePrices(context.Background(), types.Symbol{Ticker: "^GSPC"}, PriceUpdateConfig{}, log)
+	require.NoError(t, err)
+	assert.NotEmpty(t, history.Monthly)
+}
+
 func TestUpdatePrices(t *testing.T) {
 	log := NewLoggerTest("PricesTest")
 	config := PriceUpdateConfig{
@@ -19,7 +81,7 @@ func TestUpdatePrices(t *testing.T) {
 		WriteToDb:       true,
 		EnableProfiling: false,
 	}
-	err := updatePricesImpl(log, config)
+	err := updatePricesImpl(context.Background(), log, config)
 	if err != nil {
 		t.Logf("Price update failed (may be expected due to API limits): %v", err)
 	} else {
@@ -33,7 +95,8 @@ func TestFetchPrices(t *testing.T) {
 	// Call updatePrices - uses db.Db() singleton internally
 	log := NewLoggerTest("FetchTest")
 	config := PriceUpdateConfig{WriteToDb: true, EnableProfiling: false}
-	symbol, monthly, weekly, _ := updatePrices(types.Symbol{Ticker: ticker}, config, log)
+	symbol, history, _ := updatePrices(context.Background(), types.Symbol{Ticker: ticker}, config, log)
+	monthly, weekly := history.Monthly, history.Weekly
 
 	assert.Equal(t, ticker, symbol.Ticker)
 	assert.NotNil(t, symbol.LastPriceStatus)
@@ -56,11 +119,11 @@ func TestFetchPricesCurrencyConversion(t *testing.T) {
 	tickerEUR := "EBA.DE" // German ticker in EUR
 
 	// Fetch both symbols from database
-	symbolUSD, err := db.GetSymbol(tickerUSD)
+	symbolUSD, err := db.GetSymbol(context.Background(), tickerUSD)
 	assert.NoError(t, err)
 	assert.NotNil(t, symbolUSD)
 
-	symbolEUR, err := db.GetSymbol(tickerEUR)
+	symbolEUR, err := db.GetSymbol(context.Background(), tickerEUR)
 	assert.NoError(t, err)
 	assert.NotNil(t, symbolEUR)
 	fmt.Printf("currency: %v\n", symbolEUR.Currency)
@@ -68,8 +131,10 @@ func TestFetchPricesCurrencyConversion(t *testing.T) {
 	// Fetch prices for both tickers (test mode - no DB writes)
 	log := NewLoggerTest("CurrTest")
 	config := PriceUpdateConfig{WriteToDb: false, EnableProfiling: false}
-	_, monthlyUSD, weeklyUSD, _ := updatePrices(*symbolUSD, config, log)
-	_, monthlyEUR, weeklyEUR, _ := updatePrices(*symbolEUR, config, log)
+	_, historyUSD, _ := updatePrices(context.Background(), *symbolUSD, config, log)
+	_, historyEUR, _ := updatePrices(context.Background(), *symbolEUR, config, log)
+	monthlyUSD, weeklyUSD := historyUSD.Monthly, historyUSD.Weekly
+	monthlyEUR, weeklyEUR := historyEUR.Monthly, historyEUR.Weekly
 
 	// Both should have data
 	assert.NotEmpty(t, monthlyUSD)
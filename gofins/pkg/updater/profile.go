@@ -34,27 +34,6 @@ func normalizeCurrencyCode(currency string) string {
 	return currency
 }
 
-func UpdateProfiles(ctx context.Context) {
-	log := NewLogger("Profile")
-
-	for {
-		select {
-		case <-ctx.Done():
-			log.Stopped()
-			return
-		default:
-		}
-
-		if err := updateProfilesImpl(ctx, log); err != nil {
-			log.Errorf("Profile update failed: %v\n", err)
-		}
-
-		const sleepTimeHours = 8
-		log.AllDone(sleepTimeHours)
-		time.Sleep(time.Duration(sleepTimeHours) * time.Hour)
-	}
-}
-
 func UpdateProfilesOnce(ctx context.Context) error {
 	log := NewLogger("Profile")
 	return updateProfilesImpl(ctx, log)
@@ -3,7 +3,6 @@ package updater
 import (
 	"context"
 	"fmt"
-	"sync"
 	"time"
 
 	"github.com/flocko-motion/gofins/pkg/db"
@@ -15,13 +14,7 @@ import (
 )
 
 const (
-	ProfileBatchUpdateInterval = 30 * 24 * time.Hour
-	ProfileBulkBatchSize       = 1000 // Write to DB in batches
-)
-
-var (
-	profileBatchUpdaterRunning bool
-	profileBatchUpdaterMutex   sync.Mutex
+	ProfileBulkBatchSize = 1000 // Write to DB in batches
 )
 
 // UpdateProfilesBatch fetches bulk profile data and updates all profiles
@@ -200,44 +193,3 @@ func updateProfilesInBatches(symbols []types.Symbol, log *log.Logger) (int, erro
 	log.Printf("  ✓ Updated %d symbols\n", len(symbols))
 	return len(symbols), nil
 }
-
-// RunProfileBatchUpdater runs the batch profile updater in a loop
-func RunProfileBatchUpdater(ctx context.Context, wg *sync.WaitGroup, log *log.Logger) {
-	defer wg.Done()
-
-	// Singleton check
-	profileBatchUpdaterMutex.Lock()
-	if profileBatchUpdaterRunning {
-		log.Printf("Profile batch updater already running, skipping\n")
-		profileBatchUpdaterMutex.Unlock()
-		return
-	}
-	profileBatchUpdaterRunning = true
-	profileBatchUpdaterMutex.Unlock()
-
-	defer func() {
-		profileBatchUpdaterMutex.Lock()
-		profileBatchUpdaterRunning = false
-		profileBatchUpdaterMutex.Unlock()
-	}()
-
-	ticker := time.NewTicker(ProfileBatchUpdateInterval)
-	defer ticker.Stop()
-
-	// Run immediately on start
-	if err := UpdateProfilesBatchOnce(ctx); err != nil {
-		log.Errorf("Profile batch update failed: %v\n", err)
-	}
-
-	for {
-		select {
-		case <-ctx.Done():
-			log.Printf("Profile batch updater stopped\n")
-			return
-		case <-ticker.C:
-			if err := UpdateProfilesBatchOnce(ctx); err != nil {
-				log.Errorf("Profile batch update failed: %v\n", err)
-			}
-		}
-	}
-}
@@ -3,10 +3,10 @@ package updater
 import (
 	"context"
 	"fmt"
-	"sync"
 	"time"
 
 	"github.com/flocko-motion/gofins/pkg/calculator"
+	"github.com/flocko-motion/gofins/pkg/calendar"
 	"github.com/flocko-motion/gofins/pkg/db"
 	"github.com/flocko-motion/gofins/pkg/fmp"
 	"github.com/flocko-motion/gofins/pkg/forex"
@@ -15,25 +15,26 @@ import (
 )
 
 const (
-	QuoteUpdateInterval = 24 * time.Hour
-	QuoteBatchSize      = 1000 // Write to DB in batches
+	QuoteBatchSize = 1000 // Write to DB in batches
 )
 
-var (
-	quoteUpdaterRunning bool
-	quoteUpdaterMutex   sync.Mutex
-)
+// quoteCalendar defines the sessions of the bulk EOD data, which follows the US exchanges
+var quoteCalendar = calendar.US()
 
-// UpdateQuotes fetches bulk EOD data and updates current prices for all symbols
+// UpdateQuotes fetches bulk EOD data of a trading session and updates current prices for all symbols
 func UpdateQuotes(ctx context.Context, date time.Time, log *log.Logger) error {
-	return updateQuotesImpl(ctx, date, log)
+	return updateQuotesImpl(ctx, calculator.StartOfDay(date), log)
 }
 
 func updateQuotesImpl(ctx context.Context, date time.Time, log *log.Logger) error {
+	if !quoteCalendar.IsTradingDay(date) {
+		log.Printf("No trading session on %s - skipping quote update\n", date.Format("2006-01-02"))
+		return nil
+	}
 	log.Printf("Starting quote update for %s\n", date.Format("2006-01-02"))
 
-	// Get list of tickers that need quote updates (not from yesterday)
-	tickersNeedingUpdate, err := db.GetTickersNeedingQuoteUpdate(ctx)
+	// Get list of tickers that need quote updates (no quote from this session)
+	tickersNeedingUpdate, err := db.GetTickersNeedingQuoteUpdate(ctx, date)
 	if err != nil {
 		log.Errorf("Failed to get tickers needing update: %v\n", err)
 		return fmt.Errorf("failed to get tickers needing update: %w", err)
@@ -54,12 +55,12 @@ func updateQuotesImpl(ctx context.Context, date time.Time, log *log.Logger) erro
 	}
 
 	// Check if we can do incremental price history updates
-	isStartOfWeek := calculator.IsStartOfWeek(date)
-	isStartOfMonth := calculator.IsStartOfMonth(date)
+	isStartOfWeek := quoteCalendar.IsFirstSessionOfWeek(date)
+	isStartOfMonth := quoteCalendar.IsFirstSessionOfMonth(date)
 
 	var weeklyUpdateMap, monthlyUpdateMap map[string]bool
 	if isStartOfWeek || isStartOfMonth {
-		log.Printf("  Today is start of week/month - checking for incremental price updates\n")
+		log.Printf("  First session of week/month - checking for incremental price updates\n")
 		weeklyUpdateMap, monthlyUpdateMap, err = getSymbolsNeedingIncrementalUpdate(ctx, date, log)
 		if err != nil {
 			log.Errorf("Failed to get incremental update candidates: %v\n", err)
@@ -92,7 +93,7 @@ func updateQuotesImpl(ctx context.Context, date time.Time, log *log.Logger) erro
 	log.Printf("  Fetched %d quotes from FMP\n", len(bulkQuotes))
 
 	// Filter to only quotes that need updating
-	filteredQuotes := make(map[string]*types.PriceData)
+	filteredQuotes := make(map[string]*types.DailyPrice)
 	for ticker, priceData := range bulkQuotes {
 		if tickersNeedingUpdate[ticker] {
 			filteredQuotes[ticker] = priceData
@@ -101,16 +102,14 @@ func updateQuotesImpl(ctx context.Context, date time.Time, log *log.Logger) erro
 
 	log.Printf("  Filtered to %d quotes that need updates\n", len(filteredQuotes))
 
-	// Convert prices to USD and prepare for database update
-	// Use yesterday as the quote date (normalized to start of day)
-	yesterday := calculator.Yesterday()
-	quotes := convertQuotesToUSD(ctx, filteredQuotes, symbolCurrencies, yesterday, log)
+	// Convert prices to USD and prepare for database update, dated to the session
+	quotes, dailyBars := convertQuotesToUSD(ctx, filteredQuotes, symbolCurrencies, date, log)
 	log.Printf("  Converted %d quotes to USD\n", len(quotes))
 
 	// Process incremental price history updates if applicable
 	incrementalUpdates := 0
 	if len(weeklyUpdateMap) > 0 || len(monthlyUpdateMap) > 0 {
-		incrementalUpdates = processIncrementalPriceUpdates(quotes, bulkQuotes, weeklyUpdateMap, monthlyUpdateMap, date, log)
+		incrementalUpdates = processIncrementalPriceUpdates(dailyBars, weeklyUpdateMap, monthlyUpdateMap, date, log)
 		if incrementalUpdates > 0 {
 			log.Printf("  ✓ Applied %d incremental price history updates\n", incrementalUpdates)
 		}
@@ -124,6 +123,13 @@ func updateQuotesImpl(ctx context.Context, date time.Time, log *log.Logger) erro
 	}
 	updated := len(quotes)
 
+	// Append the session to the daily price history
+	if err := db.PutDailyPrices(dailyBars); err != nil {
+		log.Errorf("Failed to write daily prices: %v\n", err)
+		_ = db.LogError(ctx, "updater.quote", "db_error",
+			fmt.Sprintf("Failed to write %d daily prices: %v", len(dailyBars), err), nil)
+	}
+
 	// Complete batch update log
 	if err := db.CompleteBatchUpdate(ctx, logID, len(bulkQuotes), updated); err != nil {
 		log.Errorf("Failed to complete batch update log: %v\n", err)
@@ -134,8 +140,10 @@ func updateQuotesImpl(ctx context.Context, date time.Time, log *log.Logger) erro
 }
 
 // convertQuotesToUSD converts quotes to USD based on symbol currencies
-func convertQuotesToUSD(ctx context.Context, bulkQuotes map[string]*types.PriceData, symbolCurrencies map[string]string, date time.Time, log *log.Logger) []types.Symbol {
+// Returns the current price updates and the matching daily bars
+func convertQuotesToUSD(ctx context.Context, bulkQuotes map[string]*types.DailyPrice, symbolCurrencies map[string]string, date time.Time, log *log.Logger) ([]types.Symbol, []types.DailyPrice) {
 	var quotes []types.Symbol
+	var bars []types.DailyPrice
 	conversionErrors := 0
 
 	// Normalize the date to start of day for consistency
@@ -149,6 +157,8 @@ func convertQuotesToUSD(ctx context.Context, bulkQuotes map[string]*types.PriceD
 		}
 
 		var closeUSD float64
+		bar := *quote
+		bar.Date = calculator.StartOfDay(quote.Date)
 
 		// Convert to USD if needed
 		if currency == "" || currency == "USD" {
@@ -164,6 +174,7 @@ func convertQuotesToUSD(ctx context.Context, bulkQuotes map[string]*types.PriceD
 				continue
 			}
 			closeUSD = converted
+			bar = convertDailyBar(bar, converted/quote.Close)
 		}
 
 		quotes = append(quotes, types.Symbol{
@@ -171,20 +182,40 @@ func convertQuotesToUSD(ctx context.Context, bulkQuotes map[string]*types.PriceD
 			CurrentPriceUsd:  &closeUSD,
 			CurrentPriceTime: &normalizedDate,
 		})
+		bars = append(bars, bar)
 	}
 
 	if conversionErrors > 0 {
 		log.Warnf("%d currency conversion errors\n", conversionErrors)
 	}
 
-	return quotes
+	return quotes, bars
 }
 
-// UpdateQuotesOnce runs a single quote update for yesterday's date
+// convertDailyBar converts a daily bar with the given USD rate, keeping the original values
+func convertDailyBar(bar types.DailyPrice, rate float64) types.DailyPrice {
+	open, high, low, close := bar.Open, bar.High, bar.Low, bar.Close
+	converted := bar
+	converted.Open = open * rate
+	converted.High = high * rate
+	converted.Low = low * rate
+	converted.Close = close * rate
+	converted.OpenOrig = &open
+	converted.HighOrig = &high
+	converted.LowOrig = &low
+	converted.CloseOrig = &close
+	converted.AdjCloseOrig = bar.AdjClose
+	if bar.AdjClose != nil {
+		adjClose := *bar.AdjClose * rate
+		converted.AdjClose = &adjClose
+	}
+	return converted
+}
+
+// UpdateQuotesOnce runs a single quote update for the last closed US trading session
 func UpdateQuotesOnce(ctx context.Context) error {
 	log := NewLogger("Quote")
-	yesterday := time.Now().AddDate(0, 0, -1)
-	return UpdateQuotes(ctx, yesterday, log)
+	return UpdateQuotes(ctx, quoteCalendar.LastSession(time.Now()), log)
 }
 
 // getSymbolsNeedingIncrementalUpdate returns two maps of symbols that need incremental updates
@@ -199,8 +230,8 @@ func getSymbolsNeedingIncrementalUpdate(ctx context.Context, date time.Time, log
 		return nil, nil, fmt.Errorf("failed to get stale symbols: %w", err)
 	}
 
-	isStartOfWeek := calculator.IsStartOfWeek(date)
-	isStartOfMonth := calculator.IsStartOfMonth(date)
+	isStartOfWeek := quoteCalendar.IsFirstSessionOfWeek(date)
+	isStartOfMonth := quoteCalendar.IsFirstSessionOfMonth(date)
 	todayWeekStart := calculator.StartOfWeek(date)
 	todayMonthStart := calculator.StartOfMonth(date)
 
@@ -231,33 +262,28 @@ func getSymbolsNeedingIncrementalUpdate(ctx context.Context, date time.Time, log
 	return weeklyMap, monthlyMap, nil
 }
 
-// processIncrementalPriceUpdates appends price points for symbols in the weekly and monthly maps
-func processIncrementalPriceUpdates(quotes []types.Symbol, bulkQuotes map[string]*types.PriceData, weeklyMap, monthlyMap map[string]bool, date time.Time, log *log.Logger) int {
+// processIncrementalPriceUpdates appends price points for symbols in the weekly and monthly maps,
+// built from the USD converted daily bars of the session
+func processIncrementalPriceUpdates(bars []types.DailyPrice, weeklyMap, monthlyMap map[string]bool, date time.Time, log *log.Logger) int {
 	updated := 0
 
 	weekStart := calculator.StartOfWeek(date)
 	monthStart := calculator.StartOfMonth(date)
 
-	for _, quote := range quotes {
-		// Get the raw price data for this symbol
-		priceData, exists := bulkQuotes[quote.Ticker]
-		if !exists {
-			continue
-		}
-
+	for _, bar := range bars {
 		// Check if needs weekly update
-		if weeklyMap[quote.Ticker] {
-			if err := appendPricePoint(priceData, weekStart, types.IntervalWeekly); err != nil {
-				log.Errorf("Failed to append weekly price for %s: %v\n", quote.Ticker, err)
+		if weeklyMap[bar.SymbolTicker] {
+			if err := appendPricePoint(bar, weekStart, types.IntervalWeekly); err != nil {
+				log.Errorf("Failed to append weekly price for %s: %v\n", bar.SymbolTicker, err)
 			} else {
 				updated++
 			}
 		}
 
 		// Check if needs monthly update
-		if monthlyMap[quote.Ticker] {
-			if err := appendPricePoint(priceData, monthStart, types.IntervalMonthly); err != nil {
-				log.Errorf("Failed to append monthly price for %s: %v\n", quote.Ticker, err)
+		if monthlyMap[bar.SymbolTicker] {
+			if err := appendPricePoint(bar, monthStart, types.IntervalMonthly); err != nil {
+				log.Errorf("Failed to append monthly price for %s: %v\n", bar.SymbolTicker, err)
 			} else {
 				updated++
 			}
@@ -267,59 +293,55 @@ func processIncrementalPriceUpdates(quotes []types.Symbol, bulkQuotes map[string
 	return updated
 }
 
-// appendPricePoint creates and appends a price point to the database
-func appendPricePoint(priceData *types.PriceData, periodStart time.Time, interval types.PriceInterval) error {
-	newPrice := types.PriceData{
-		Date:         periodStart,
-		Open:         priceData.Open,
-		High:         priceData.High,
-		Low:          priceData.Low,
-		Close:        priceData.Close,
-		Avg:          priceData.Close, // Use close as avg for single-day period
-		SymbolTicker: priceData.SymbolTicker,
+// appendPricePoint creates and appends a price point to the database. The total-return index
+// is carried forward from the stored previous period, the YoY compares with the stored
+// prior-year period.
+func appendPricePoint(bar types.DailyPrice, periodStart time.Time, interval types.PriceInterval) error {
+	newPrice := periodPriceFromQuote(bar, periodStart)
+
+	alignment := calculator.NewAlignment(interval, calculator.DefaultWeekConvention())
+	target := alignment.Target(periodStart)
+	stored, err := db.GetPrices(bar.SymbolTicker, target.Add(-alignment.Tolerance), periodStart.AddDate(0, 0, -1), interval)
+	if err != nil {
+		return fmt.Errorf("failed to get stored prices: %w", err)
 	}
+	setPeriodReturns(&newPrice, stored, alignment)
 	return db.AppendSinglePrice(newPrice, interval)
 }
 
-// RunQuoteUpdater runs the quote updater in a loop
-func RunQuoteUpdater(ctx context.Context, wg *sync.WaitGroup, log *log.Logger) {
-	defer wg.Done()
+// setPeriodReturns sets CloseTR, YoY and YoYTR of a new period from the stored earlier periods
+// (oldest first), the last of which is the previous period
+func setPeriodReturns(period *types.PriceData, stored []types.PriceData, a calculator.Alignment) {
+	if len(stored) > 0 {
+		calculator.CarryTotalReturn(period, stored[len(stored)-1])
+	}
+	calculator.SetYoYFromStored(period, stored, a)
+}
 
-	// Singleton check
-	quoteUpdaterMutex.Lock()
-	if quoteUpdaterRunning {
-		log.Printf("Quote updater already running, skipping\n")
-		quoteUpdaterMutex.Unlock()
-		return
+// periodPriceFromQuote turns a daily bar into the first price point of a period. Monthly and
+// weekly closes are dividend-adjusted, so the bar is scaled to its adjusted close. The original
+// currency values are kept for bars converted to USD.
+func periodPriceFromQuote(bar types.DailyPrice, periodStart time.Time) types.PriceData {
+	factor := 1.0
+	if bar.AdjClose != nil && bar.Close > 0 {
+		factor = *bar.AdjClose / bar.Close
 	}
-	quoteUpdaterRunning = true
-	quoteUpdaterMutex.Unlock()
-
-	defer func() {
-		quoteUpdaterMutex.Lock()
-		quoteUpdaterRunning = false
-		quoteUpdaterMutex.Unlock()
-	}()
-
-	ticker := time.NewTicker(QuoteUpdateInterval)
-	defer ticker.Stop()
-
-	// Run immediately on start
-	yesterday := time.Now().AddDate(0, 0, -1)
-	if err := UpdateQuotes(ctx, yesterday, log); err != nil {
-		log.Errorf("Quote update failed: %v\n", err)
+	close := bar.Close * factor
+	price := types.PriceData{
+		Date:         periodStart,
+		Open:         bar.Open * factor,
+		High:         bar.High * factor,
+		Low:          bar.Low * factor,
+		Close:        close,
+		Avg:          close, // Use close as avg for single-day period
+		SymbolTicker: bar.SymbolTicker,
 	}
-
-	for {
-		select {
-		case <-ctx.Done():
-			log.Printf("Quote updater stopped\n")
-			return
-		case <-ticker.C:
-			yesterday := time.Now().AddDate(0, 0, -1)
-			if err := UpdateQuotes(ctx, yesterday, log); err != nil {
-				log.Errorf("Quote update failed: %v\n", err)
-			}
-		}
+	if bar.CloseOrig != nil {
+		price.OpenOrig = scaleOptional(bar.OpenOrig, factor)
+		price.HighOrig = scaleOptional(bar.HighOrig, factor)
+		price.LowOrig = scaleOptional(bar.LowOrig, factor)
+		price.CloseOrig = scaleOptional(bar.CloseOrig, factor)
+		price.AvgOrig = price.CloseOrig
 	}
+	return price
 }
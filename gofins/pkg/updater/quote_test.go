@@ -5,7 +5,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/flocko-motion/gofins/pkg/calculator"
+	"github.com/flocko-motion/gofins/pkg/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUpdateQuotesOnce(t *testing.T) {
@@ -42,3 +45,52 @@ func TestUpdateQuotesOnce(t *testing.T) {
 		_ = UpdateQuotes(ctx2, yesterday, log)
 	})
 }
+
+func TestPeriodPriceFromQuote(t *testing.T) {
+	adjClose := 99.0
+	quote := &types.DailyPrice{Open: 98, High: 102, Low: 97, Close: 100, AdjClose: &adjClose, SymbolTicker: "AAPL"}
+	monthStart := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	price := periodPriceFromQuote(*quote, monthStart)
+	assert.Equal(t, monthStart, price.Date)
+	assert.InDelta(t, 99, price.Close, 1e-9, "monthly closes are dividend-adjusted")
+	assert.InDelta(t, 99, price.Avg, 1e-9)
+	assert.InDelta(t, 100.98, price.High, 1e-9)
+	assert.InDelta(t, 97.02, price.Open, 1e-9)
+
+	quote.AdjClose = nil
+	price = periodPriceFromQuote(*quote, monthStart)
+	assert.InDelta(t, 100, price.Close, 1e-9, "quotes without an adjusted close are taken as is")
+}
+
+func TestPeriodPriceFromQuoteNonUSD(t *testing.T) {
+	adjClose := 99.0
+	quote := types.DailyPrice{Open: 98, High: 102, Low: 97, Close: 100, AdjClose: &adjClose, SymbolTicker: "SAP.DE"}
+	bar := convertDailyBar(quote, 1.2) // EUR to USD
+	monthStart := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	price := periodPriceFromQuote(bar, monthStart)
+	assert.InDelta(t, 118.8, price.Close, 1e-9, "the period is stored in USD")
+	assert.InDelta(t, 116.424, price.Open, 1e-9)
+	require.NotNil(t, price.CloseOrig)
+	assert.InDelta(t, 99, *price.CloseOrig, 1e-9, "the adjusted close in EUR is kept")
+	assert.InDelta(t, 97.02, *price.OpenOrig, 1e-9)
+	assert.InDelta(t, 100.98, *price.HighOrig, 1e-9)
+	assert.InDelta(t, 99, *price.AvgOrig, 1e-9)
+
+	ptr := func(v float64) *float64 { return &v }
+	// Stored periods at a rate of 1.1, with the total-return index 1.22 times the close
+	stored := []types.PriceData{
+		{Date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Close: 88, CloseOrig: ptr(80.0), CloseTR: ptr(110.0)},
+		{Date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Close: 99, CloseOrig: ptr(90.0), CloseTR: ptr(121.0)},
+	}
+	setPeriodReturns(&price, stored, calculator.NewAlignment(types.IntervalMonthly, calculator.WeeksTrading))
+
+	require.NotNil(t, price.CloseTR, "the total-return index is carried forward")
+	assert.InDelta(t, 145.2, *price.CloseTR, 1e-9)
+	require.NotNil(t, price.YoY)
+	assert.InDelta(t, 23.75, *price.YoY, 1e-9, "compared in EUR: 99 against 80")
+	require.NotNil(t, price.YoYTR, "total-return packages keep the newest period")
+	assert.InDelta(t, 21, *price.YoYTR, 1e-9, "compared in EUR: 121 against 100")
+	assert.Equal(t, stored[0].Date, *price.YoYRef)
+}
@@ -3,7 +3,6 @@ package updater
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/flocko-motion/gofins/pkg/db"
 	"github.com/flocko-motion/gofins/pkg/f"
@@ -12,34 +11,12 @@ import (
 	"github.com/flocko-motion/gofins/pkg/types"
 )
 
-func SyncSymbols(ctx context.Context) {
-	log := NewLogger("Symbols")
-
-	for {
-		if err := syncSymbolsImpl(ctx, log); err != nil {
-			log.Errorf("Symbol sync failed: %v\n", err)
-		}
-		time.Sleep(time.Hour * 24 * 7) // Sleep for 7 days
-	}
-}
-
 func SyncSymbolsOnce(ctx context.Context) error {
 	log := NewLogger("Symbols")
 	return syncSymbolsImpl(ctx, log)
 }
 
 func syncSymbolsImpl(ctx context.Context, log *log.Logger) error {
-	// Check if we already ran today
-	lastRun, err := db.GetLastBatchUpdate(ctx, "symbols")
-	if err == nil && lastRun != nil && lastRun.CompletedAt != nil {
-		today := time.Now().Truncate(24 * time.Hour)
-		lastRunDay := lastRun.CompletedAt.Truncate(24 * time.Hour)
-		if today.Equal(lastRunDay) {
-			log.Printf("Symbol sync already ran today at %s, skipping\n", lastRun.CompletedAt.Format("15:04:05"))
-			return nil
-		}
-	}
-
 	// Start batch log
 	batchID, err := db.StartBatchUpdate(ctx, "symbols")
 	if err != nil {